@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// listenConfig is the part of the configuration containerized deployments
+// usually set through the environment rather than flags
+type listenConfig struct {
+	grpcPort int
+	httpPort int
+	shards   int
+}
+
+// listenEnv names the environment variable read for each listenConfig flag
+// that is not given on the command line
+var listenEnv = []struct{ flag, env string }{
+	{"grpc-port", "TTT_GRPC_PORT"},
+	{"http-port", "TTT_HTTP_PORT"},
+	{"shards", "TTT_SHARDS"},
+}
+
+// parseListenConfig reads the listen flags from fs after it has been parsed.
+// A flag set explicitly wins; otherwise a non-empty environment variable from
+// listenEnv overrides the flag's default.
+func parseListenConfig(fs *flag.FlagSet, getenv func(string) string) (listenConfig, error) {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	values := make(map[string]int, len(listenEnv))
+	for _, fallback := range listenEnv {
+		value := fs.Lookup(fallback.flag).Value.(flag.Getter).Get().(int)
+		if raw := getenv(fallback.env); raw != "" && !explicit[fallback.flag] {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return listenConfig{}, fmt.Errorf("%s must be an integer, got %q", fallback.env, raw)
+			}
+			value = n
+		}
+		values[fallback.flag] = value
+	}
+
+	cfg := listenConfig{
+		grpcPort: values["grpc-port"],
+		httpPort: values["http-port"],
+		shards:   values["shards"],
+	}
+	return cfg, cfg.validate()
+}
+
+// validate checks that the ports are usable and there is at least one shard
+func (c listenConfig) validate() error {
+	for _, port := range []struct {
+		name  string
+		value int
+	}{{"gRPC port", c.grpcPort}, {"HTTP port", c.httpPort}} {
+		if port.value < 1 || port.value > 65535 {
+			return fmt.Errorf("%s must be between 1 and 65535, got %d", port.name, port.value)
+		}
+	}
+	if c.shards < 1 {
+		return fmt.Errorf("shards must be at least 1, got %d", c.shards)
+	}
+	return nil
+}
+
+// String describes the effective configuration for the startup log
+func (c listenConfig) String() string {
+	return fmt.Sprintf("grpc-port=%d http-port=%d shards=%d", c.grpcPort, c.httpPort, c.shards)
+}
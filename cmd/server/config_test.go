@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newListenFlags defines the listen flags main defines, on a fresh flag set
+func newListenFlags(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Int("grpc-port", 50051, "")
+	fs.Int("http-port", 8080, "")
+	fs.Int("shards", 64, "")
+	require.NoError(t, fs.Parse(args))
+	return fs
+}
+
+func TestParseListenConfig(t *testing.T) {
+	env := map[string]string{"TTT_SHARDS": "16", "TTT_GRPC_PORT": "6000"}
+	getenv := func(key string) string { return env[key] }
+
+	// Defaults without flags or environment
+	cfg, err := parseListenConfig(newListenFlags(t), func(string) string { return "" })
+	require.NoError(t, err)
+	assert.Equal(t, listenConfig{grpcPort: 50051, httpPort: 8080, shards: 64}, cfg)
+
+	// The environment replaces defaults
+	cfg, err = parseListenConfig(newListenFlags(t), getenv)
+	require.NoError(t, err)
+	assert.Equal(t, listenConfig{grpcPort: 6000, httpPort: 8080, shards: 16}, cfg)
+
+	// An explicit flag wins, even when it repeats the default
+	cfg, err = parseListenConfig(newListenFlags(t, "-shards", "64"), getenv)
+	require.NoError(t, err)
+	assert.Equal(t, 64, cfg.shards)
+	assert.Equal(t, 6000, cfg.grpcPort)
+	assert.Equal(t, "grpc-port=6000 http-port=8080 shards=64", cfg.String())
+}
+
+func TestParseListenConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+	}{
+		{"non-numeric env", nil, map[string]string{"TTT_SHARDS": "many"}},
+		{"zero shards from env", nil, map[string]string{"TTT_SHARDS": "0"}},
+		{"zero shards from flag", []string{"-shards", "0"}, nil},
+		{"port out of range", nil, map[string]string{"TTT_HTTP_PORT": "70000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseListenConfig(newListenFlags(t, tt.args...), func(key string) string { return tt.env[key] })
+			assert.Error(t, err)
+		})
+	}
+}
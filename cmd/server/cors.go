@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// defaultCORSMethods are the methods browsers may use cross-origin
+	defaultCORSMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	// defaultCORSHeaders are the request headers browsers may send cross-origin
+	defaultCORSHeaders = "Content-Type, Authorization"
+)
+
+// corsConfig decides which browser origins may call the HTTP API
+type corsConfig struct {
+	origins []string // Allowed origins; "*" allows any origin
+	methods []string
+	headers []string
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request
+// from origin, or "" when the origin is not allowed
+func (c corsConfig) allowOrigin(origin string) string {
+	switch {
+	case slices.Contains(c.origins, "*"):
+		return "*"
+	case origin != "" && slices.Contains(c.origins, origin):
+		return origin
+	default:
+		return ""
+	}
+}
+
+// handler adds CORS headers to responses to allowed origins and answers
+// preflight requests. Responses to other origins carry no CORS headers, so
+// browsers refuse them.
+func (c corsConfig) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := c.allowOrigin(r.Header.Get("Origin"))
+		if allowed != "*" {
+			// The answer depends on the requesting origin, so caches must not share it
+			w.Header().Add("Vary", "Origin")
+		}
+		if allowed != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowed)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.methods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.headers, ", "))
+		}
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSHandler(t *testing.T) {
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	request := func(cors corsConfig, method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/games", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		cors.handler(api).ServeHTTP(rec, req)
+		return rec
+	}
+
+	cors := corsConfig{
+		origins: []string{"https://play.example.com", "http://localhost:3000"},
+		methods: []string{"GET", "POST"},
+		headers: []string{"Content-Type"},
+	}
+
+	// An allowed origin is echoed back with the configured methods and headers
+	rec := request(cors, http.MethodGet, "http://localhost:3000")
+	assert.Equal(t, "http://localhost:3000", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+	assert.Equal(t, "ok", rec.Body.String())
+
+	// Other origins, and requests without one, get no CORS headers but are still served
+	for _, origin := range []string{"https://evil.example.com", ""} {
+		rec = request(cors, http.MethodGet, origin)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"), origin)
+		assert.Equal(t, "ok", rec.Body.String())
+	}
+
+	// Preflight requests are answered without reaching the API
+	rec = request(cors, http.MethodOptions, "https://play.example.com")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://play.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Body.String())
+
+	// Without configured origins there are no CORS headers at all
+	rec = request(corsConfig{}, http.MethodGet, "https://play.example.com")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// * is an explicit opt-in to any origin
+	cors.origins = []string{"*"}
+	rec = request(cors, http.MethodGet, "https://evil.example.com")
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Vary"))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/proto"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/server"
+)
+
+// gatewayHeaderMatcher returns the game version GetGame sends as a plain
+// ETag header and every other response metadata key the default way, as
+// Grpc-Metadata-<key>
+func gatewayHeaderMatcher(key string) (string, bool) {
+	if key == server.ETagHeader {
+		return "ETag", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// notModified answers a GetGame whose if_version is still current with 304
+// Not Modified and no body
+func notModified(_ context.Context, w http.ResponseWriter, resp proto.Message) error {
+	if resp, ok := resp.(*pb.GetGameResponse); ok && resp.NotModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return nil
+}
+
+// conditionalGet turns the ETag in a GET request's If-None-Match header into
+// the if_version query parameter, unless the request already sets one.
+// Lists of tags and "*" are left alone, so the request is answered in full.
+func conditionalGet(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method == http.MethodGet && !query.Has("if_version") {
+			tag := strings.TrimPrefix(strings.TrimSpace(r.Header.Get("If-None-Match")), "W/")
+			if unquoted, err := strconv.Unquote(tag); err == nil {
+				if version, err := strconv.ParseInt(unquoted, 10, 64); err == nil && version > 0 {
+					query.Set("if_version", unquoted)
+					r = r.Clone(r.Context())
+					r.URL.RawQuery = query.Encode()
+				}
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+func TestGateway_ETag(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	pb.RegisterTicTacToeServiceServer(grpcServer, server.NewTicTacToeServer(store.NewGameStore(4), store.NewStatsStore(4)))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gwMux := runtime.NewServeMux(
+		runtime.WithOutgoingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithForwardResponseOption(notModified),
+	)
+	require.NoError(t, pb.RegisterTicTacToeServiceHandlerFromEndpoint(ctx, gwMux, listener.Addr().String(),
+		[]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}))
+	httpServer := httptest.NewServer(conditionalGet(gwMux))
+	defer httpServer.Close()
+
+	resp, err := http.Post(httpServer.URL+"/api/v1/games", "application/json", strings.NewReader(`{"user_id":"alice"}`))
+	require.NoError(t, err)
+	var created struct {
+		Game struct {
+			GameID string `json:"gameId"`
+		} `json:"game"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+	gameURL := httpServer.URL + "/api/v1/games/" + created.Game.GameID
+
+	get := func(ifNoneMatch string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, gameURL, nil)
+		require.NoError(t, err)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp = get("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	// The client's copy is current
+	resp = get(etag)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, get("W/"+etag).StatusCode)
+
+	resp, err = http.Post(gameURL+"/join", "application/json", strings.NewReader(`{"user_id":"bob"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp = get(etag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	// Tags that are not a version get the full response
+	assert.Equal(t, http.StatusOK, get("*").StatusCode)
+	assert.Equal(t, http.StatusOK, get(`"1", "2"`).StatusCode)
+}
+
+func TestGatewayHeaderMatcher(t *testing.T) {
+	header, ok := gatewayHeaderMatcher(server.ETagHeader)
+	assert.True(t, ok)
+	assert.Equal(t, "ETag", header)
+
+	header, ok = gatewayHeaderMatcher(server.RequestIDHeader)
+	assert.True(t, ok)
+	assert.Equal(t, "Grpc-Metadata-x-request-id", header)
+}
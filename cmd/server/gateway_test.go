@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+func TestGateway_ISOTimestamps(t *testing.T) {
+	grpcServer := grpc.NewServer()
+	pb.RegisterTicTacToeServiceServer(grpcServer, server.NewTicTacToeServer(store.NewGameStore(4), store.NewStatsStore(4)))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gwMux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterTicTacToeServiceHandlerFromEndpoint(ctx, gwMux, listener.Addr().String(),
+		[]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}))
+	httpServer := httptest.NewServer(gwMux)
+	defer httpServer.Close()
+
+	resp, err := http.Post(httpServer.URL+"/api/v1/games", "application/json", strings.NewReader(`{"user_id":"alice"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// int64 fields arrive as JSON strings
+	var body struct {
+		Game struct {
+			CreatedAt    string `json:"createdAt"`
+			UpdatedAt    string `json:"updatedAt"`
+			CreatedAtIso string `json:"createdAtIso"`
+			UpdatedAtIso string `json:"updatedAtIso"`
+		} `json:"game"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	for _, pair := range [][2]string{
+		{body.Game.CreatedAt, body.Game.CreatedAtIso},
+		{body.Game.UpdatedAt, body.Game.UpdatedAtIso},
+	} {
+		unix, err := strconv.ParseInt(pair[0], 10, 64)
+		require.NoError(t, err)
+		iso, err := time.Parse(time.RFC3339, pair[1])
+		require.NoError(t, err, "%q is not RFC 3339", pair[1])
+		assert.Equal(t, unix, iso.Unix())
+		assert.True(t, strings.HasSuffix(pair[1], "Z"), "%q is not UTC", pair[1])
+	}
+}
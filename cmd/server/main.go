@@ -6,47 +6,228 @@ import (
 	"fmt"
 	"io/fs"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/ratelimit"
 	"tictactoe/internal/server"
 	"tictactoe/internal/store"
 	"tictactoe/internal/swagger"
 )
 
+// version is the build version reported by Ping, set with
+// -ldflags "-X main.version=<version>"
+var version = "dev"
+
 func main() {
 	// Parse command line flags
-	grpcPort := flag.Int("grpc-port", 50051, "The gRPC server port")
-	httpPort := flag.Int("http-port", 8080, "The HTTP/REST server port")
-	shards := flag.Int("shards", 64, "Number of shards for data stores (higher = better concurrency)")
+	flag.Int("grpc-port", 50051, "The gRPC server port (or $TTT_GRPC_PORT)")
+	flag.Int("http-port", 8080, "The HTTP/REST server port (or $TTT_HTTP_PORT)")
+	flag.Int("shards", 64, "Number of shards for data stores, higher = better concurrency (or $TTT_SHARDS)")
+	fingerprintIndex := flag.Bool("fingerprint-index", false, "Track board fingerprints to detect duplicate in-progress games (adds per-move overhead)")
+	maxMovesInFlight := flag.Int("max-moves-in-flight", 0, "Per-game cap on concurrent move attempts; excess attempts are rejected (0 = unlimited)")
+	authTokensFile := flag.String("auth-tokens-file", "", "File of \"<token> <user_id>\" lines; when set, non-read-only RPCs require a bearer token")
+	adminUsers := flag.String("admin-users", "", "Comma-separated user IDs allowed to call admin RPCs such as ResetUserStats when -auth-tokens-file is set")
+	tieBreak := flag.String("leaderboard-tiebreak", store.TieBreakMostGames.String(), "Order of leaderboard users with equal wins: most-games, fewest-games or user-id")
+	leaderboardDebounce := flag.Duration("leaderboard-debounce", server.DefaultLeaderboardDebounce, "Shortest interval between WatchLeaderboard updates; results recorded meanwhile share one update")
+	abandonPolicy := flag.String("abandon-policy", store.AbandonUncounted.String(), "How abandoned games count in stats: uncounted, or loss for the player who abandoned")
+	defaultBoardSize := flag.Int("default-board-size", server.DefaultBoardSize, "Board size for games created without board_size")
+	defaultWinLength := flag.Int("default-win-length", server.DefaultWinLength, "Win length for games created without win_length (shortened to fit smaller boards)")
+	maxBoardSize := flag.Int("max-board-size", server.MaxBoardSize, "Largest board_size accepted; larger boards cost more memory and CPU per game")
+	maxListLimit := flag.Int("max-list-limit", server.MaxListLimit, "Largest page returned by list RPCs such as ListPendingGames")
+	maxActiveGames := flag.Int("max-active-games", 0, "Cap on pending and in-progress games a user may be seated in at once (0 = unlimited)")
+	maxGames := flag.Int("max-games", 0, "Cap on games held in memory; the least recently updated finished, then pending, games are evicted to make room (0 = unlimited)")
+	maxPendingGames := flag.Int("max-pending-games", 0, "Cap on games waiting for an opponent across all users (0 = unlimited)")
+	maxStatsUsers := flag.Int("max-stats-users", 0, "Cap on users tracked in stats; least recently updated users without active games are evicted (0 = unlimited)")
+	rateLimit := flag.Float64("rate-limit", 0, "Unary requests per second allowed per user (or per IP when unauthenticated); 0 disables rate limiting")
+	rateBurst := flag.Int("rate-burst", 20, "Requests a caller may burst above -rate-limit")
+	trustedProxies := flag.String("trusted-proxies", "127.0.0.0/8,::1/128", "Comma-separated CIDRs of proxies whose x-forwarded-for names the client to rate limit; the default covers the built-in REST gateway")
+	idempotencyTTL := flag.Duration("idempotency-ttl", server.DefaultIdempotencyTTL, "How long CreateGame returns the same game for a repeated idempotency_key (0 = ignore keys)")
+	pendingGameTTL := flag.Duration("pending-game-ttl", 0, "How long a game may wait for an opponent, or a full game for its players to start it, before it expires and is removed (0 = never)")
+	spectatorStreamLifetime := flag.Duration("spectator-stream-lifetime", 0, "How long a spectator may stream a game before the stream ends; players are exempt (0 = forever)")
+	maxGameDuration := flag.Duration("max-game-duration", 0, "How long a game may be in progress, or wait for its players to start it, before it is abandoned, blaming the player on turn or holding up the start (0 = forever)")
+	maxTournamentsPerUser := flag.Int("max-tournaments-per-user", server.DefaultMaxTournamentsPerUser, "Cap on the tournaments under way each user may create (0 = unlimited)")
+	finishedTournamentTTL := flag.Duration("finished-tournament-ttl", server.DefaultFinishedTournamentTTL, "How long a decided tournament is kept before it is removed (0 = forever)")
+	maxMoves := flag.Int("max-moves", 0, "Cap on the moves in each game, below one per cell (0 = one per cell)")
+	streamBuffer := flag.Int("stream-buffer", server.DefaultStreamBuffer, "Updates buffered per game update stream for a slow client")
+	streamOverflow := flag.String("stream-overflow", server.OverflowDropNewest.String(), "What happens to an update for a stream whose buffer is full: drop-newest, drop-oldest, or block for up to -stream-block-timeout")
+	streamBlockTimeout := flag.Duration("stream-block-timeout", server.DefaultStreamBlockTimeout, "How long the block overflow policy waits for a slow stream before dropping the update")
+	compressStreams := flag.Bool("compress-streams", false, "Gzip streamed updates for every client that accepts gzip; otherwise only calls made with gzip are compressed")
+	serverStatsTTL := flag.Duration("server-stats-ttl", time.Second, "How long GetServerStats may serve cached counts (0 = always recount)")
+	userIDPolicy := flag.String("user-id-policy", server.UserIDExact.String(), "Which user IDs count as one user when joining a game: exact, trim (ignore surrounding whitespace) or fold (also ignore case)")
+	anonymousUserPrefix := flag.String("anonymous-user-prefix", "", "Enables CreateAnonymousUser, issuing IDs of this prefix plus a UUID (and a token when -auth-tokens-file is set); empty disables it")
+	anonymousUserTTL := flag.Duration("anonymous-user-ttl", server.DefaultAnonymousUserTTL, "How long an anonymous identity lives before its token stops working (0 = forever)")
+	maxAnonymousUsers := flag.Int("max-anonymous-users", server.DefaultMaxAnonymousUsers, "Cap on anonymous identities alive at once (0 = unlimited)")
+	anonymousUserRate := flag.Float64("anonymous-user-rate", server.DefaultAnonymousUserRate, "CreateAnonymousUser calls per second allowed per caller, with bursts of -anonymous-user-burst")
+	anonymousUserBurst := flag.Int("anonymous-user-burst", server.DefaultAnonymousUserBurst, "CreateAnonymousUser calls a caller may burst above -anonymous-user-rate")
+	corsOrigins := flag.String("cors-origins", "", "Comma-separated browser origins allowed to call the HTTP API, or * for any origin; empty sends no CORS headers")
+	corsMethods := flag.String("cors-methods", defaultCORSMethods, "Comma-separated methods allowed in cross-origin requests")
+	corsHeaders := flag.String("cors-headers", defaultCORSHeaders, "Comma-separated request headers allowed in cross-origin requests")
+	statsToken := flag.String("stats-token", "", "Bearer token required by the /stats JSON endpoint (empty = open)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file; when set with -tls-key, gRPC and HTTP are served over TLS")
+	tlsKey := flag.String("tls-key", "", "TLS private key file")
+	tlsServerName := flag.String("tls-server-name", "localhost", "Name the REST gateway expects in the gRPC server's TLS certificate")
 	flag.Parse()
 
+	// Ports and shards may also come from the environment
+	listenCfg, err := parseListenConfig(flag.CommandLine, os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	log.Printf("Starting version %s; effective configuration: %s", version, listenCfg)
+
+	tlsCfg := tlsFiles{certFile: *tlsCert, keyFile: *tlsKey, serverName: *tlsServerName}
+	if err := tlsCfg.validate(); err != nil {
+		log.Fatalf("Invalid TLS flags: %v", err)
+	}
+
+	if *maxBoardSize < 3 {
+		log.Fatalf("Invalid -max-board-size: must be at least 3, got %d", *maxBoardSize)
+	}
+	if *maxMoves < 0 {
+		log.Fatalf("Invalid -max-moves: must not be negative, got %d", *maxMoves)
+	}
+	if *anonymousUserTTL < 0 || *maxAnonymousUsers < 0 {
+		log.Fatalf("Invalid -anonymous-user-ttl or -max-anonymous-users: must not be negative")
+	}
+	if *anonymousUserRate <= 0 {
+		log.Fatalf("Invalid -anonymous-user-rate: must be positive, got %v", *anonymousUserRate)
+	}
+	if *maxTournamentsPerUser < 0 || *finishedTournamentTTL < 0 {
+		log.Fatalf("Invalid -max-tournaments-per-user or -finished-tournament-ttl: must not be negative")
+	}
+	if *maxListLimit < 1 {
+		log.Fatalf("Invalid -max-list-limit: must be at least 1, got %d", *maxListLimit)
+	}
+	if err := server.ValidateDefaultBoard(*defaultBoardSize, *defaultWinLength, *maxBoardSize); err != nil {
+		log.Fatalf("Invalid -default-board-size or -default-win-length: %v", err)
+	}
+
+	if *streamBuffer < 1 {
+		log.Fatalf("Invalid -stream-buffer: must be at least 1, got %d", *streamBuffer)
+	}
+	streamOverflowPolicy, err := server.ParseOverflowPolicy(*streamOverflow)
+	if err != nil {
+		log.Fatalf("Invalid -stream-overflow: %v", err)
+	}
+
+	joinUserIDPolicy, err := server.ParseUserIDPolicy(*userIDPolicy)
+	if err != nil {
+		log.Fatalf("Invalid -user-id-policy: %v", err)
+	}
+
+	leaderboardTieBreak, err := store.ParseTieBreak(*tieBreak)
+	if err != nil {
+		log.Fatalf("Invalid -leaderboard-tiebreak: %v", err)
+	}
+	statsAbandonPolicy, err := store.ParseAbandonPolicy(*abandonPolicy)
+	if err != nil {
+		log.Fatalf("Invalid -abandon-policy: %v", err)
+	}
+	proxies, err := parsePrefixes(*trustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid -trusted-proxies: %v", err)
+	}
+
 	// Create stores
-	gameStore := store.NewGameStore(*shards)
-	statsStore := store.NewStatsStore(*shards)
+	gameStore := store.NewGameStore(listenCfg.shards,
+		store.WithMaxActiveGames(*maxActiveGames),
+		store.WithMaxGames(*maxGames),
+		store.WithMaxPendingGames(*maxPendingGames),
+	)
+	statsStore := store.NewStatsStore(listenCfg.shards,
+		store.WithMaxUsers(*maxStatsUsers),
+		store.WithActiveUsers(gameStore.ActivePlayers),
+	)
 
-	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	// Metrics are served at /metrics
+	metricsRegistry := prometheus.NewRegistry()
 
-	// Register our service
-	ticTacToeServer := server.NewTicTacToeServer(gameStore, statsStore)
+	// Optional server features
+	serverOpts := []server.Option{
+		server.WithVersion(version),
+		server.WithRequestLogging(slog.Default()),
+		server.WithMetrics(metricsRegistry),
+		server.WithDefaultBoard(*defaultBoardSize, *defaultWinLength),
+		server.WithMaxBoardSize(*maxBoardSize),
+		server.WithMaxListLimit(*maxListLimit),
+		server.WithMoveAdmissionLimit(*maxMovesInFlight),
+		server.WithLeaderboardTieBreak(leaderboardTieBreak),
+		server.WithLeaderboardDebounce(*leaderboardDebounce),
+		server.WithAbandonPolicy(statsAbandonPolicy),
+		server.WithServerStatsCache(*serverStatsTTL),
+		server.WithPendingGameTTL(*pendingGameTTL),
+		server.WithIdempotencyTTL(*idempotencyTTL),
+		server.WithMaxGameDuration(*maxGameDuration),
+		server.WithMaxTournamentsPerUser(*maxTournamentsPerUser),
+		server.WithFinishedTournamentTTL(*finishedTournamentTTL),
+		server.WithMaxMoves(*maxMoves),
+		server.WithSpectatorStreamLifetime(*spectatorStreamLifetime),
+		server.WithStreamBuffer(*streamBuffer),
+		server.WithStreamOverflow(streamOverflowPolicy, *streamBlockTimeout),
+		server.WithStreamCompression(*compressStreams),
+		server.WithUserIDPolicy(joinUserIDPolicy),
+		server.WithTrustedProxies(proxies),
+	}
+	if *anonymousUserPrefix != "" {
+		serverOpts = append(serverOpts,
+			server.WithAnonymousUsers(*anonymousUserPrefix),
+			server.WithAnonymousUserLimits(*anonymousUserTTL, *maxAnonymousUsers),
+			server.WithAnonymousUserRateLimit(ratelimit.New(*anonymousUserRate, *anonymousUserBurst)),
+		)
+	}
+	if *fingerprintIndex {
+		serverOpts = append(serverOpts, server.WithFingerprintIndex(store.NewFingerprintIndex()))
+	}
+	if *rateLimit > 0 {
+		serverOpts = append(serverOpts, server.WithRateLimit(ratelimit.New(*rateLimit, *rateBurst)))
+	}
+	if *authTokensFile != "" {
+		tokens, err := loadTokens(*authTokensFile)
+		if err != nil {
+			log.Fatalf("Failed to load auth tokens: %v", err)
+		}
+		serverOpts = append(serverOpts,
+			server.WithAuth(tokens, server.DefaultPublicMethods),
+			server.WithAdmins(parseList(*adminUsers)),
+		)
+	}
+
+	// Create our service and a gRPC server with the interceptors it needs
+	ticTacToeServer := server.NewTicTacToeServer(gameStore, statsStore, serverOpts...)
+	grpcOpts, err := tlsCfg.grpcServerOptions()
+	if err != nil {
+		log.Fatalf("Failed to load TLS credentials: %v", err)
+	}
+	grpcOpts = append(grpcOpts, ticTacToeServer.GRPCServerOptions()...)
+	grpcServer := grpc.NewServer(grpcOpts...)
 	pb.RegisterTicTacToeServiceServer(grpcServer, ticTacToeServer)
 
+	// Standard gRPC health service for load balancers and Kubernetes probes
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
 	// Register reflection service for tools like grpcurl
 	reflection.Register(grpcServer)
 
 	// Start gRPC server
-	grpcAddr := fmt.Sprintf(":%d", *grpcPort)
+	grpcAddr := fmt.Sprintf(":%d", listenCfg.grpcPort)
 	grpcListener, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen on %s: %v", grpcAddr, err)
@@ -61,21 +242,35 @@ func main() {
 
 	// Create gRPC-Gateway mux
 	ctx := context.Background()
-	gwMux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	// GetGame's version doubles as an HTTP ETag for polling clients
+	gwMux := runtime.NewServeMux(
+		runtime.WithOutgoingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithForwardResponseOption(notModified),
+	)
+	dialOpt, err := tlsCfg.gatewayDialOption()
+	if err != nil {
+		log.Fatalf("Failed to load gateway TLS credentials: %v", err)
+	}
+	opts := []grpc.DialOption{dialOpt}
 
 	err = pb.RegisterTicTacToeServiceHandlerFromEndpoint(ctx, gwMux, grpcAddr, opts)
 	if err != nil {
 		log.Fatalf("Failed to register gateway: %v", err)
 	}
 
+	// Readiness probes reach the backend the same way the gateway does
+	readyConn, err := grpc.NewClient(grpcAddr, opts...)
+	if err != nil {
+		log.Fatalf("Failed to create readiness client: %v", err)
+	}
+	defer readyConn.Close()
+
 	// Create HTTP mux for serving Swagger UI and API
 	httpMux := http.NewServeMux()
 
 	// Serve Swagger JSON
 	httpMux.HandleFunc("/swagger.json", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		http.ServeFile(w, r, "api/swagger/tictactoe.swagger.json")
 	})
 
@@ -91,48 +286,55 @@ func main() {
 		http.Redirect(w, r, "/swagger/", http.StatusMovedPermanently)
 	})
 
-	// Health check endpoint
+	// Prometheus metrics endpoint
+	httpMux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+
+	// Server counts as JSON for humans; /metrics is for Prometheus
+	httpMux.Handle("/stats", statsHandler(ticTacToeServer.GetServerStats, *statsToken))
+
+	// Liveness: the HTTP server is up
 	httpMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
-	// CORS middleware wrapper
-	corsHandler := func(h http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-			if r.Method == "OPTIONS" {
-				w.WriteHeader(http.StatusOK)
-				return
-			}
+	// Readiness: the gRPC backend is reachable and serving
+	httpMux.Handle("/ready", readyHandler(healthpb.NewHealthClient(readyConn)))
 
-			h.ServeHTTP(w, r)
-		})
+	// Browsers may call the API only from the configured origins
+	cors := corsConfig{
+		origins: parseList(*corsOrigins),
+		methods: parseList(*corsMethods),
+		headers: parseList(*corsHeaders),
 	}
 
 	// Route API requests to gRPC-Gateway, others to httpMux
+	apiHandler := conditionalGet(gwMux)
 	mainHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasPrefix(r.URL.Path, "/api/") {
-			gwMux.ServeHTTP(w, r)
+			apiHandler.ServeHTTP(w, r)
 		} else {
 			httpMux.ServeHTTP(w, r)
 		}
 	})
 
 	// Start HTTP server
-	httpAddr := fmt.Sprintf(":%d", *httpPort)
+	httpAddr := fmt.Sprintf(":%d", listenCfg.httpPort)
 	httpServer := &http.Server{
 		Addr:    httpAddr,
-		Handler: corsHandler(mainHandler),
+		Handler: cors.handler(mainHandler),
 	}
 
 	go func() {
-		log.Printf("HTTP/REST server listening on %s", httpAddr)
-		log.Printf("Swagger UI available at http://localhost%s/swagger/", httpAddr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		scheme := "http"
+		serve := httpServer.ListenAndServe
+		if tlsCfg.enabled() {
+			scheme = "https"
+			serve = func() error { return httpServer.ListenAndServeTLS(tlsCfg.certFile, tlsCfg.keyFile) }
+		}
+		log.Printf("HTTP/REST server listening on %s (%s)", httpAddr, scheme)
+		log.Printf("Swagger UI available at %s://localhost%s/swagger/", scheme, httpAddr)
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to serve HTTP: %v", err)
 		}
 	}()
@@ -143,7 +345,50 @@ func main() {
 	<-sigCh
 
 	log.Println("Shutting down servers...")
+	// Fail readiness and health checks so no new traffic is routed here
+	healthServer.Shutdown()
+	// End update streams first; both servers wait for them to finish
+	ticTacToeServer.Close()
 	httpServer.Shutdown(ctx)
 	grpcServer.GracefulStop()
 	log.Println("Servers stopped")
 }
+
+// loadTokens reads a token file into a new token store
+func loadTokens(path string) (*store.TokenStore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := store.NewTokenStore()
+	if err := tokens.Load(f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// parseList splits a comma-separated list such as user IDs, dropping blanks
+func parseList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parsePrefixes parses a comma-separated list of CIDRs
+func parsePrefixes(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range parseList(list) {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/ratelimit"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+func TestGateway_RateLimitPerClient(t *testing.T) {
+	proxies, err := parsePrefixes("127.0.0.0/8, ::1/128")
+	require.NoError(t, err)
+	srv := server.NewTicTacToeServer(store.NewGameStore(4), store.NewStatsStore(4),
+		server.WithRateLimit(ratelimit.New(0.001, 1)),
+		server.WithTrustedProxies(proxies),
+	)
+	grpcServer := grpc.NewServer(srv.GRPCServerOptions()...)
+	pb.RegisterTicTacToeServiceServer(grpcServer, srv)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gwMux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterTicTacToeServiceHandlerFromEndpoint(ctx, gwMux, listener.Addr().String(),
+		[]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}))
+	httpServer := httptest.NewServer(gwMux)
+	defer httpServer.Close()
+
+	// Stand-ins for clients behind a proxy in front of the gateway
+	get := func(client string) int {
+		req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/api/v1/games:pending", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Forwarded-For", client)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Each client has its own limit, although every call comes from the gateway
+	assert.Equal(t, http.StatusOK, get("203.0.113.1"))
+	assert.Equal(t, http.StatusOK, get("203.0.113.2"))
+	assert.Equal(t, http.StatusTooManyRequests, get("203.0.113.1"))
+
+	// Entries left of an untrusted hop are the client's word and not trusted
+	assert.Equal(t, http.StatusTooManyRequests, get("198.51.100.7, 203.0.113.2"))
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := parsePrefixes("10.0.0.0/8, ,fd00::/8")
+	require.NoError(t, err)
+	assert.Len(t, prefixes, 2)
+
+	_, err = parsePrefixes("10.0.0.1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// readinessTimeout bounds how long /ready waits on the gRPC backend
+const readinessTimeout = 2 * time.Second
+
+// readyHandler serves /ready. Unlike /health, which only shows the HTTP
+// server is up, it asks the gRPC backend's health service whether it is
+// serving, over a connection dialed like the gateway's. It answers 503 when
+// the backend is unreachable or draining.
+func readyHandler(health healthpb.HealthClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		body := map[string]string{"status": "ready"}
+		code := http.StatusOK
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{})
+		switch {
+		case err != nil:
+			body = map[string]string{"status": "unavailable", "error": err.Error()}
+			code = http.StatusServiceUnavailable
+		case resp.Status != healthpb.HealthCheckResponse_SERVING:
+			body = map[string]string{"status": "unavailable", "error": "gRPC server is " + resp.Status.String()}
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(body)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+func TestReadyHandler(t *testing.T) {
+	// A backend with auth enabled still answers health checks without a token
+	ticTacToeServer := server.NewTicTacToeServer(store.NewGameStore(4), store.NewStatsStore(4),
+		server.WithAuth(store.NewTokenStore(), server.DefaultPublicMethods))
+	grpcServer := grpc.NewServer(ticTacToeServer.GRPCServerOptions()...)
+	pb.RegisterTicTacToeServiceServer(grpcServer, ticTacToeServer)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	handler := readyHandler(healthpb.NewHealthClient(conn))
+
+	probe := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return rec
+	}
+
+	rec := probe()
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"ready"}`, rec.Body.String())
+
+	// Draining during shutdown
+	healthServer.Shutdown()
+	rec = probe()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "NOT_SERVING")
+
+	// Backend gone entirely
+	grpcServer.Stop()
+	rec = probe()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"unavailable"`)
+}
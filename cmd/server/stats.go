@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	pb "tictactoe/api/gen/tictactoe"
+)
+
+// statsJSON is the body /stats serves
+type statsJSON struct {
+	TotalGames        int32 `json:"total_games"`
+	PendingGames      int32 `json:"pending_games"`
+	InProgressGames   int32 `json:"in_progress_games"`
+	FinishedGames     int32 `json:"finished_games"`
+	Users             int32 `json:"users"`
+	StreamSubscribers int32 `json:"stream_subscribers"`
+	GeneratedAt       int64 `json:"generated_at"`
+}
+
+// statsHandler serves /stats: the GetServerStats counts as plain JSON, for
+// a quick look with curl where no Prometheus scraper reads /metrics. When
+// token is set, requests must carry it as "Authorization: Bearer <token>".
+func statsHandler(stats func(context.Context, *pb.GetServerStatsRequest) (*pb.GetServerStatsResponse, error), token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "missing or invalid stats token"})
+			return
+		}
+
+		resp, err := stats(r.Context(), &pb.GetServerStatsRequest{})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(statsJSON{
+			TotalGames:        resp.TotalGames,
+			PendingGames:      resp.PendingGames,
+			InProgressGames:   resp.InProgressGames,
+			FinishedGames:     resp.FinishedGames,
+			Users:             resp.Users,
+			StreamSubscribers: resp.StreamSubscribers,
+			GeneratedAt:       resp.GeneratedAt,
+		})
+	}
+}
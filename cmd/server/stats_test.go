@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/internal/game"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+func TestStatsHandler(t *testing.T) {
+	gameStore := store.NewGameStore(4)
+	ticTacToeServer := server.NewTicTacToeServer(gameStore, store.NewStatsStore(4))
+	g, err := game.NewGame("game-1", "alice", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, gameStore.Create(g))
+
+	get := func(handler http.Handler, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get(statsHandler(ticTacToeServer.GetServerStats, ""), "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 1.0, body["total_games"])
+	assert.Equal(t, 1.0, body["pending_games"])
+	assert.Equal(t, 0.0, body["in_progress_games"])
+	assert.Equal(t, 0.0, body["stream_subscribers"])
+	assert.NotZero(t, body["generated_at"])
+
+	// With a token only requests carrying it are answered
+	protected := statsHandler(ticTacToeServer.GetServerStats, "s3cret")
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		rec = get(protected, auth)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, auth)
+		assert.NotContains(t, rec.Body.String(), "total_games")
+	}
+	rec = get(protected, "Bearer s3cret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"total_games":1`)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// tlsFiles is the optional certificate shared by the gRPC and HTTP servers
+type tlsFiles struct {
+	certFile string
+	keyFile  string
+
+	// serverName is the name the gateway verifies the gRPC backend's certificate against
+	serverName string
+}
+
+// enabled reports whether TLS was configured
+func (t tlsFiles) enabled() bool {
+	return t.certFile != "" || t.keyFile != ""
+}
+
+// validate checks that the certificate and key are given together
+func (t tlsFiles) validate() error {
+	if (t.certFile == "") != (t.keyFile == "") {
+		return fmt.Errorf("-tls-cert and -tls-key must be set together")
+	}
+	return nil
+}
+
+// grpcServerOptions returns the transport credentials for the gRPC server
+func (t tlsFiles) grpcServerOptions() ([]grpc.ServerOption, error) {
+	if !t.enabled() {
+		return nil, nil
+	}
+	creds, err := credentials.NewServerTLSFromFile(t.certFile, t.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.ServerOption{grpc.Creds(creds)}, nil
+}
+
+// gatewayDialOption returns the credentials the REST gateway dials the gRPC
+// backend with. Under TLS the gateway trusts only the server's own
+// certificate, which must be valid for serverName.
+func (t tlsFiles) gatewayDialOption() (grpc.DialOption, error) {
+	if !t.enabled() {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+	creds, err := credentials.NewClientTLSFromFile(t.certFile, t.serverName)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(creds), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+// writeSelfSignedCert writes a self-signed certificate for localhost and 127.0.0.1
+// to dir and returns the certificate and key paths
+func writeSelfSignedCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestTLS_EndToEnd(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t, t.TempDir())
+	tlsCfg := tlsFiles{certFile: certFile, keyFile: keyFile, serverName: "localhost"}
+	require.NoError(t, tlsCfg.validate())
+
+	// gRPC server over TLS
+	grpcOpts, err := tlsCfg.grpcServerOptions()
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(grpcOpts...)
+	pb.RegisterTicTacToeServiceServer(grpcServer, server.NewTicTacToeServer(store.NewGameStore(4), store.NewStatsStore(4)))
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(grpcListener)
+	defer grpcServer.Stop()
+
+	// Gateway dialing the backend over TLS, served over HTTPS
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	dialOpt, err := tlsCfg.gatewayDialOption()
+	require.NoError(t, err)
+	gwMux := runtime.NewServeMux()
+	require.NoError(t, pb.RegisterTicTacToeServiceHandlerFromEndpoint(ctx, gwMux, grpcListener.Addr().String(), []grpc.DialOption{dialOpt}))
+
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	httpServer := &http.Server{Handler: gwMux}
+	go httpServer.ServeTLS(httpListener, certFile, keyFile)
+	defer httpServer.Close()
+
+	// An HTTPS client trusting the self-signed cert reaches the gRPC backend
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(certPEM))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	resp, err := client.Post("https://"+httpListener.Addr().String()+"/api/v1/games", "application/json", strings.NewReader(`{"user_id":"alice"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"playerXId":"alice"`)
+
+	// Plaintext gRPC clients are refused
+	conn, err := grpc.NewClient(grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = pb.NewTicTacToeServiceClient(conn).GetGame(ctx, &pb.GetGameRequest{GameId: "any"})
+	assert.Error(t, err)
+}
+
+func TestTLSFiles_Validate(t *testing.T) {
+	assert.NoError(t, tlsFiles{}.validate())
+	assert.False(t, tlsFiles{}.enabled())
+	assert.Error(t, tlsFiles{certFile: "cert.pem"}.validate())
+	assert.Error(t, tlsFiles{keyFile: "key.pem"}.validate())
+}
@@ -0,0 +1,66 @@
+package ai
+
+import "tictactoe/internal/game"
+
+// lineDirections are the four directions a winning line can run in
+var lineDirections = [4][2]int{
+	{0, 1},  // horizontal
+	{1, 0},  // vertical
+	{1, 1},  // diagonal
+	{1, -1}, // anti-diagonal
+}
+
+// evaluate scores the position for mark by looking at every window of
+// WinLength cells: windows holding only one player's marks count for that
+// player, weighted steeply by how many marks they already contain
+func (s *searcher) evaluate(mark game.Mark) int {
+	b := s.board
+	score := 0
+
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Cols; col++ {
+			for _, dir := range lineDirections {
+				endRow := row + dir[0]*(b.WinLength-1)
+				endCol := col + dir[1]*(b.WinLength-1)
+				if endRow < 0 || endRow >= b.Rows || endCol < 0 || endCol >= b.Cols {
+					continue
+				}
+
+				own, opp := 0, 0
+				for i := 0; i < b.WinLength; i++ {
+					switch b.Cells[(row+dir[0]*i)*b.Cols+col+dir[1]*i] {
+					case mark:
+						own++
+					case game.MarkEmpty:
+					default:
+						opp++
+					}
+				}
+
+				switch {
+				case own > 0 && opp == 0:
+					score += windowWeight(own)
+				case opp > 0 && own == 0:
+					score -= windowWeight(opp)
+				}
+			}
+		}
+	}
+
+	if score > maxHeuristic {
+		return maxHeuristic
+	}
+	if score < -maxHeuristic {
+		return -maxHeuristic
+	}
+	return score
+}
+
+// windowWeight grows by an order of magnitude per mark in an open window
+func windowWeight(marks int) int {
+	w := 1
+	for i := 1; i < marks; i++ {
+		w *= 10
+	}
+	return w
+}
@@ -0,0 +1,369 @@
+// Package ai implements move search for computer-controlled players and analysis.
+package ai
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+
+	"tictactoe/internal/game"
+)
+
+const (
+	// WinScore is the score of a forced win; wins found sooner score higher
+	WinScore = 1_000_000
+
+	// maxHeuristic bounds static evaluations so they never look like a forced result
+	maxHeuristic = WinScore / 2
+
+	// nodesPerDeadlineCheck controls how often the search polls the context
+	nodesPerDeadlineCheck = 64
+
+	// fullWidthCells is the board area up to which every empty cell is searched;
+	// larger boards only consider cells next to existing marks
+	fullWidthCells = 16
+)
+
+// ErrNoMoves is returned when the board has no empty cells
+var ErrNoMoves = errors.New("no legal moves available")
+
+// Move is a board coordinate chosen by the search
+type Move struct {
+	Row int
+	Col int
+}
+
+// Options tunes a search
+type Options struct {
+	// MaxDepth caps the iterative deepening depth (0 means no cap beyond the number of empty cells)
+	MaxDepth int
+	// Rand, if set, picks among the moves that score equally best instead of
+	// taking the first of them, at the cost of pruning less at the root
+	Rand *rand.Rand
+}
+
+// Result describes the outcome of a search
+type Result struct {
+	Move  Move
+	Score int  // From the perspective of the player to move
+	Depth int  // Deepest fully completed iteration (0 means the heuristic fallback was used)
+	Exact bool // Score is the game-theoretic value with perfect play
+	Nodes int  // Positions visited
+	// TimedOut is set when the deadline interrupted the search before it finished
+	TimedOut bool
+}
+
+// Proven reports whether Score is a game-theoretic result rather than a
+// heuristic estimate: the search was exact or it found a forced win or loss
+func (r Result) Proven() bool {
+	return r.Exact || r.Score > maxHeuristic || r.Score < -maxHeuristic
+}
+
+// BestMove returns the best move found for mark before ctx is done
+func BestMove(ctx context.Context, board *game.Board, mark game.Mark) (Move, error) {
+	res, err := Search(ctx, board, mark, Options{})
+	if err != nil {
+		return Move{}, err
+	}
+	return res.Move, nil
+}
+
+// Search looks for the best move for mark using iterative deepening alpha-beta.
+// It never runs meaningfully past the context deadline: when time runs out it
+// returns the best move of the deepest completed iteration, or a heuristic
+// move if not even the first iteration finished. The board is not modified.
+func Search(ctx context.Context, board *game.Board, mark game.Mark, opts Options) (Result, error) {
+	s := &searcher{
+		ctx:   ctx,
+		board: board.Clone(),
+		rand:  opts.Rand,
+	}
+
+	empty := 0
+	for _, cell := range s.board.Cells {
+		if cell == game.MarkEmpty {
+			empty++
+		}
+	}
+	if empty == 0 {
+		return Result{}, ErrNoMoves
+	}
+
+	maxDepth := empty
+	if opts.MaxDepth > 0 && opts.MaxDepth < maxDepth {
+		maxDepth = opts.MaxDepth
+	}
+
+	// Cheap, always-available answer in case the deadline is very tight
+	fallback := s.heuristicMove(mark)
+	result := Result{Move: fallback, Score: s.evaluate(mark)}
+
+	// Pruned move generation on large boards means results there are never exact
+	fullWidth := len(s.board.Cells) <= fullWidthCells
+
+	var ordered []Move
+	for depth := 1; depth <= maxDepth; depth++ {
+		s.truncated = false
+		s.table = make(map[string]tableEntry)
+		move, score, ok := s.searchRoot(mark, depth, ordered)
+		if !ok {
+			result.TimedOut = true
+			break
+		}
+
+		result.Move = move
+		result.Score = score
+		result.Depth = depth
+		result.Exact = fullWidth && !s.truncated
+		ordered = []Move{move}
+
+		if result.Exact || score >= WinScore-depth || score <= -(WinScore-depth) {
+			break
+		}
+	}
+
+	result.Nodes = s.nodes
+	return result, nil
+}
+
+// searcher holds the mutable state of one search
+type searcher struct {
+	ctx     context.Context
+	board   *game.Board
+	rand    *rand.Rand // Breaks ties between the best root moves when set
+	nodes   int
+	aborted bool
+	// truncated is set when the current iteration hit its depth limit on a non-terminal position
+	truncated bool
+	// table holds the scores of the current iteration's positions by Board.Canonical,
+	// so rotations and reflections of a position already searched are not searched again
+	table map[string]tableEntry
+}
+
+// bound tells how a stored score relates to the position's true score
+type bound int
+
+const (
+	boundExact bound = iota
+	boundLower       // The search failed high: the score is at least this
+	boundUpper       // The search failed low: the score is at most this
+)
+
+// tableEntry is a transposition table entry. Within one iteration a position
+// is always reached at the same ply with the same depth left, so neither
+// needs storing.
+type tableEntry struct {
+	score     int
+	bound     bound
+	truncated bool
+}
+
+// expired polls the context every few nodes and latches the result
+func (s *searcher) expired() bool {
+	if s.aborted {
+		return true
+	}
+	s.nodes++
+	if s.nodes%nodesPerDeadlineCheck == 0 && s.ctx.Err() != nil {
+		s.aborted = true
+	}
+	return s.aborted
+}
+
+// searchRoot runs one fixed-depth iteration; ok is false if the deadline interrupted it
+func (s *searcher) searchRoot(mark game.Mark, depth int, first []Move) (Move, int, bool) {
+	if s.ctx.Err() != nil {
+		return Move{}, 0, false
+	}
+
+	candidates := orderMoves(s.candidates(), first)
+	best := candidates[0]
+	bestScore := -WinScore - 1
+	alpha, beta := -WinScore-1, WinScore+1
+
+	// With a Rand, each move is searched just below the best score so far:
+	// one that ties scores exactly instead of being cut off at the bound
+	var ties []Move
+	window := 0
+	if s.rand != nil {
+		window = 1
+	}
+
+	for _, m := range candidates {
+		score := s.scoreMove(m, mark, depth, 1, alpha-window, beta)
+		if s.aborted {
+			return Move{}, 0, false
+		}
+		switch {
+		case score > bestScore:
+			bestScore = score
+			best = m
+			ties = append(ties[:0], m)
+		case score == bestScore:
+			ties = append(ties, m)
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	if s.rand != nil && len(ties) > 1 {
+		best = ties[s.rand.IntN(len(ties))]
+	}
+	return best, bestScore, true
+}
+
+// scoreMove plays m for mark, scores the result from mark's perspective and undoes it
+func (s *searcher) scoreMove(m Move, mark game.Mark, depth, ply, alpha, beta int) int {
+	if s.expired() {
+		return 0
+	}
+
+	idx := m.Row*s.board.Cols + m.Col
+	s.board.Cells[idx] = mark
+	defer func() { s.board.Cells[idx] = game.MarkEmpty }()
+
+	if s.board.CheckWinner(m.Row, m.Col) == mark {
+		return WinScore - ply
+	}
+	if s.board.IsFull() {
+		return 0
+	}
+	if depth <= 1 {
+		s.truncated = true
+		return s.evaluate(mark)
+	}
+	return -s.negamax(mark.Opponent(), depth-1, ply+1, -beta, -alpha)
+}
+
+// negamax returns the score of the position for the player to move
+func (s *searcher) negamax(toMove game.Mark, depth, ply, alpha, beta int) int {
+	key := s.board.Canonical()
+	if entry, ok := s.table[key]; ok {
+		if entry.bound == boundExact ||
+			(entry.bound == boundLower && entry.score >= beta) ||
+			(entry.bound == boundUpper && entry.score <= alpha) {
+			s.truncated = s.truncated || entry.truncated
+			return entry.score
+		}
+	}
+
+	// Track truncation of this subtree alone so its entry records it
+	outerTruncated := s.truncated
+	s.truncated = false
+	defer func() { s.truncated = s.truncated || outerTruncated }()
+
+	origAlpha := alpha
+	best := -WinScore - 1
+	for _, m := range s.candidates() {
+		score := s.scoreMove(m, toMove, depth, ply, alpha, beta)
+		if s.aborted {
+			return 0
+		}
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	entry := tableEntry{score: best, truncated: s.truncated}
+	switch {
+	case best <= origAlpha:
+		entry.bound = boundUpper
+	case best >= beta:
+		entry.bound = boundLower
+	}
+	s.table[key] = entry
+	return best
+}
+
+// candidates lists the moves worth searching in the current position
+func (s *searcher) candidates() []Move {
+	b := s.board
+	fullWidth := len(b.Cells) <= fullWidthCells
+
+	if !fullWidth && b.IsEmpty() {
+		// Opening on a large board: the center is as good as anything
+		return []Move{{Row: b.Rows / 2, Col: b.Cols / 2}}
+	}
+
+	// Small boards consider every empty cell, large boards only cells next to a mark
+	var moves []Move
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Cols; col++ {
+			if b.Cells[row*b.Cols+col] != game.MarkEmpty {
+				continue
+			}
+			if fullWidth || s.hasNeighbor(row, col) {
+				moves = append(moves, Move{Row: row, Col: col})
+			}
+		}
+	}
+	return moves
+}
+
+// hasNeighbor reports whether any of the 8 surrounding cells is occupied
+func (s *searcher) hasNeighbor(row, col int) bool {
+	b := s.board
+	for dr := -1; dr <= 1; dr++ {
+		for dc := -1; dc <= 1; dc++ {
+			if dr == 0 && dc == 0 {
+				continue
+			}
+			r, c := row+dr, col+dc
+			if r >= 0 && r < b.Rows && c >= 0 && c < b.Cols && b.Cells[r*b.Cols+c] != game.MarkEmpty {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// heuristicMove picks a move without searching: win if possible, otherwise
+// block the opponent's immediate win, otherwise the best static evaluation
+func (s *searcher) heuristicMove(mark game.Mark) Move {
+	candidates := s.candidates()
+
+	for _, target := range []game.Mark{mark, mark.Opponent()} {
+		for _, m := range candidates {
+			idx := m.Row*s.board.Cols + m.Col
+			s.board.Cells[idx] = target
+			won := s.board.CheckWinner(m.Row, m.Col) == target
+			s.board.Cells[idx] = game.MarkEmpty
+			if won {
+				return m
+			}
+		}
+	}
+
+	best := candidates[0]
+	bestScore := -WinScore
+	for _, m := range candidates {
+		idx := m.Row*s.board.Cols + m.Col
+		s.board.Cells[idx] = mark
+		score := s.evaluate(mark)
+		s.board.Cells[idx] = game.MarkEmpty
+		if score > bestScore {
+			bestScore = score
+			best = m
+		}
+	}
+	return best
+}
+
+// orderMoves moves the given moves (typically the previous iteration's best) to the front
+func orderMoves(moves, first []Move) []Move {
+	for _, f := range first {
+		for i, m := range moves {
+			if m == f {
+				moves[0], moves[i] = moves[i], moves[0]
+				break
+			}
+		}
+	}
+	return moves
+}
@@ -0,0 +1,181 @@
+package ai
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/internal/game"
+)
+
+func newBoard(t *testing.T, size, winLength int, moves map[[2]int]game.Mark) *game.Board {
+	t.Helper()
+	board, err := game.NewBoard(size, winLength)
+	require.NoError(t, err)
+	for pos, mark := range moves {
+		require.NoError(t, board.Set(pos[0], pos[1], mark))
+	}
+	return board
+}
+
+func TestSearch_LargeBoardRespectsDeadline(t *testing.T) {
+	// 15x15 five-in-a-row with a few stones down is far beyond a full search
+	board := newBoard(t, 15, 5, map[[2]int]game.Mark{
+		{7, 7}: game.MarkX,
+		{7, 8}: game.MarkO,
+		{8, 8}: game.MarkX,
+		{6, 6}: game.MarkO,
+	})
+
+	const deadline = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+
+	start := time.Now()
+	res, err := Search(ctx, board, game.MarkX, Options{})
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+
+	assert.Less(t, elapsed, deadline+50*time.Millisecond)
+	assert.True(t, res.TimedOut)
+	assert.False(t, res.Exact)
+
+	mark, err := board.Get(res.Move.Row, res.Move.Col)
+	require.NoError(t, err)
+	assert.Equal(t, game.MarkEmpty, mark)
+}
+
+func TestSearch_ExpiredContextFallsBack(t *testing.T) {
+	// X X .
+	// O O .
+	// . . .
+	board := newBoard(t, 3, 3, map[[2]int]game.Mark{
+		{0, 0}: game.MarkX,
+		{0, 1}: game.MarkX,
+		{1, 0}: game.MarkO,
+		{1, 1}: game.MarkO,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res, err := Search(ctx, board, game.MarkX, Options{})
+	require.NoError(t, err)
+	assert.True(t, res.TimedOut)
+	assert.Equal(t, 0, res.Depth)
+	assert.False(t, res.Proven())
+	// The heuristic still takes the immediate win
+	assert.Equal(t, Move{Row: 0, Col: 2}, res.Move)
+}
+
+func TestSearch_TakesWin(t *testing.T) {
+	// X . X
+	// O O .
+	// . . .
+	board := newBoard(t, 3, 3, map[[2]int]game.Mark{
+		{0, 0}: game.MarkX,
+		{0, 2}: game.MarkX,
+		{1, 0}: game.MarkO,
+		{1, 1}: game.MarkO,
+	})
+
+	res, err := Search(context.Background(), board, game.MarkX, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, Move{Row: 0, Col: 1}, res.Move)
+	assert.Greater(t, res.Score, WinScore-10)
+	assert.True(t, res.Proven())
+}
+
+func TestSearch_BlocksOpponent(t *testing.T) {
+	// O O .
+	// X . .
+	// . . X
+	board := newBoard(t, 3, 3, map[[2]int]game.Mark{
+		{0, 0}: game.MarkO,
+		{0, 1}: game.MarkO,
+		{1, 0}: game.MarkX,
+		{2, 2}: game.MarkX,
+	})
+
+	move, err := BestMove(context.Background(), board, game.MarkX)
+	require.NoError(t, err)
+	assert.Equal(t, Move{Row: 0, Col: 2}, move)
+}
+
+func TestSearch_EmptyBoardIsDraw(t *testing.T) {
+	board := newBoard(t, 3, 3, nil)
+
+	res, err := Search(context.Background(), board, game.MarkX, Options{})
+	require.NoError(t, err)
+	assert.True(t, res.Exact)
+	assert.False(t, res.TimedOut)
+	assert.Equal(t, 0, res.Score)
+
+	// The input board is left untouched
+	assert.True(t, board.IsEmpty())
+}
+
+func TestSearch_RandBreaksTies(t *testing.T) {
+	// Every first move on an empty 3x3 board draws, so all tie
+	board := newBoard(t, 3, 3, nil)
+	search := func(opts Options) Move {
+		res, err := Search(context.Background(), board, game.MarkX, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 0, res.Score)
+		return res.Move
+	}
+
+	assert.Equal(t, search(Options{}), search(Options{}))
+
+	chosen := make(map[Move]bool)
+	for seed := uint64(0); seed < 20; seed++ {
+		move := search(Options{Rand: rand.New(rand.NewPCG(seed, 0))})
+		assert.Equal(t, move, search(Options{Rand: rand.New(rand.NewPCG(seed, 0))}), "seed %d", seed)
+		chosen[move] = true
+	}
+	assert.Greater(t, len(chosen), 1)
+
+	// Randomness never trades a better move for a worse one
+	board = newBoard(t, 3, 3, map[[2]int]game.Mark{
+		{0, 0}: game.MarkX, {0, 1}: game.MarkX,
+		{1, 0}: game.MarkO, {1, 1}: game.MarkO,
+	})
+	for seed := uint64(0); seed < 20; seed++ {
+		res, err := Search(context.Background(), board, game.MarkX, Options{Rand: rand.New(rand.NewPCG(seed, 0))})
+		require.NoError(t, err)
+		assert.Equal(t, Move{Row: 0, Col: 2}, res.Move)
+	}
+}
+
+func TestSearch_TranspositionsCutNodes(t *testing.T) {
+	// Without a transposition table the full 3x3 search visits over 55,000
+	// positions; sharing scores across symmetric positions cuts that by far
+	board := newBoard(t, 3, 3, nil)
+
+	res, err := Search(context.Background(), board, game.MarkX, Options{})
+	require.NoError(t, err)
+	assert.Less(t, res.Nodes, 10_000)
+
+	// Scores stay correct: X to move after a corner-and-edge start wins
+	board = newBoard(t, 3, 3, map[[2]int]game.Mark{{0, 0}: game.MarkX, {0, 1}: game.MarkO})
+	res, err = Search(context.Background(), board, game.MarkX, Options{})
+	require.NoError(t, err)
+	assert.True(t, res.Proven())
+	assert.Greater(t, res.Score, maxHeuristic)
+}
+
+func TestSearch_FullBoard(t *testing.T) {
+	board := newBoard(t, 3, 3, nil)
+	mark := game.MarkX
+	for i := range board.Cells {
+		board.Cells[i] = mark
+		mark = mark.Opponent()
+	}
+
+	_, err := Search(context.Background(), board, game.MarkX, Options{})
+	assert.ErrorIs(t, err, ErrNoMoves)
+}
@@ -1,8 +1,11 @@
 package game
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/fnv"
 )
 
 // Mark represents a cell state on the board
@@ -12,6 +15,8 @@ const (
 	MarkEmpty Mark = iota
 	MarkX
 	MarkO
+	// MarkTriangle is the third player's mark in a three-player game
+	MarkTriangle
 )
 
 func (m Mark) String() string {
@@ -22,6 +27,8 @@ func (m Mark) String() string {
 		return "X"
 	case MarkO:
 		return "O"
+	case MarkTriangle:
+		return "△"
 	default:
 		return "?"
 	}
@@ -39,6 +46,19 @@ func (m Mark) Opponent() Mark {
 	}
 }
 
+// Next returns the mark that moves after m in a game of numPlayers players:
+// X, then O, then the triangle when there are three
+func (m Mark) Next(numPlayers int) Mark {
+	switch {
+	case m == MarkX:
+		return MarkO
+	case m == MarkO && numPlayers == 3:
+		return MarkTriangle
+	default:
+		return MarkX
+	}
+}
+
 // Status represents the current status of a game
 type Status int
 
@@ -48,6 +68,10 @@ const (
 	StatusXWon
 	StatusOWon
 	StatusDraw
+	StatusAbandoned
+	StatusCancelled
+	StatusTriangleWon
+	StatusReady // Every seat is filled; waiting for the players to start (see Game.Start)
 )
 
 func (s Status) String() string {
@@ -62,63 +86,133 @@ func (s Status) String() string {
 		return "O_WON"
 	case StatusDraw:
 		return "DRAW"
+	case StatusAbandoned:
+		return "ABANDONED"
+	case StatusCancelled:
+		return "CANCELLED"
+	case StatusTriangleWon:
+		return "TRIANGLE_WON"
+	case StatusReady:
+		return "READY"
 	default:
 		return "UNKNOWN"
 	}
 }
 
-// IsFinished returns true if the game has ended
+// IsFinished returns true if the game has ended, including by abandonment
+// or by its creator cancelling it before anyone joined
 func (s Status) IsFinished() bool {
-	return s == StatusXWon || s == StatusOWon || s == StatusDraw
+	return s == StatusXWon || s == StatusOWon || s == StatusTriangleWon || s == StatusDraw ||
+		s == StatusAbandoned || s == StatusCancelled
+}
+
+// wonBy returns the status of a game won by mark
+func wonBy(mark Mark) Status {
+	switch mark {
+	case MarkX:
+		return StatusXWon
+	case MarkO:
+		return StatusOWon
+	default:
+		return StatusTriangleWon
+	}
 }
 
 // Common errors
 var (
-	ErrInvalidBoardSize   = errors.New("invalid board size: must be at least 3")
-	ErrInvalidWinLength   = errors.New("invalid win length: must be at least 3 and at most board size")
-	ErrInvalidPosition    = errors.New("invalid position: out of bounds")
-	ErrCellOccupied       = errors.New("cell is already occupied")
-	ErrGameNotInProgress  = errors.New("game is not in progress")
-	ErrNotYourTurn        = errors.New("not your turn")
-	ErrPlayerNotInGame    = errors.New("player is not part of this game")
-	ErrGameAlreadyStarted = errors.New("game has already started")
-	ErrCannotJoinOwnGame  = errors.New("cannot join your own game")
+	ErrInvalidBoardSize     = errors.New("invalid board size: must be at least 3")
+	ErrInvalidWinLength     = errors.New("invalid win length: must be at least 3 and at most board size")
+	ErrInvalidPosition      = errors.New("invalid position: out of bounds")
+	ErrCellOccupied         = errors.New("cell is already occupied")
+	ErrGameNotInProgress    = errors.New("game is not in progress")
+	ErrNotYourTurn          = errors.New("not your turn")
+	ErrPlayerNotInGame      = errors.New("player is not part of this game")
+	ErrGameAlreadyStarted   = errors.New("game has already started")
+	ErrCannotJoinOwnGame    = errors.New("cannot join your own game")
+	ErrColumnFull           = errors.New("column is full")
+	ErrNotLowestEmptyRow    = errors.New("gravity games only allow the lowest empty cell in a column")
+	ErrNotGravityGame       = errors.New("drop moves are only allowed in gravity games")
+	ErrStaleNonce           = errors.New("move nonce must be greater than the last accepted nonce")
+	ErrMoveCountMismatch    = errors.New("game has a different number of moves than expected")
+	ErrTooManyMovesInFlight = errors.New("too many concurrent moves for this game")
+	ErrDrawOfferPending     = errors.New("a draw offer is already pending")
+	ErrNoDrawOffer          = errors.New("no pending draw offer from the opponent")
+	ErrGravityOnCube        = errors.New("gravity mode is not supported on 3D boards")
+	ErrWrongJoinCode        = errors.New("join code does not match")
+	ErrInvalidPlayerCount   = errors.New("number of players must be 2 or 3")
+	ErrMisereMultiplayer    = errors.New("misère mode needs exactly two players")
+	ErrTwoPlayerOnly        = errors.New("only available in two-player games")
+	ErrCubeNotSquare        = errors.New("3D boards must be square")
+	ErrGameNotReady         = errors.New("game is not waiting for its players to start it")
+	ErrMoveLimitReached     = errors.New("game has reached its move limit")
+	ErrIllegalBoard         = errors.New("board cannot arise in play")
 )
 
-// Board represents the game board
+// Position is a cell on the board. Layer is always 0 on a flat board.
+type Position struct {
+	Row   int
+	Col   int
+	Layer int
+}
+
+// Board represents the game board. A flat board has a single layer of Rows
+// by Cols cells; a cube (see NewCubeBoard) has Size layers stored one after
+// another. Each layer is in row-major order.
 type Board struct {
-	Size      int
+	Size      int // The longer side; the same as Rows and Cols on a square board
+	Rows      int
+	Cols      int
 	WinLength int
+	Depth     int
 	Cells     []Mark
+
+	// hash caches Hash once hashed is set
+	hash   uint64
+	hashed bool
 }
 
-// NewBoard creates a new board with the given size and win length
+// NewBoard creates a new square board with the given size and win length
 func NewBoard(size, winLength int) (*Board, error) {
-	if size < 3 {
+	return NewRectBoard(size, size, winLength)
+}
+
+// NewRectBoard creates a new flat board of rows by cols cells. Both sides
+// must be at least 3, and a line must fit along the longer side.
+func NewRectBoard(rows, cols, winLength int) (*Board, error) {
+	if rows < 3 || cols < 3 {
 		return nil, ErrInvalidBoardSize
 	}
+	size := max(rows, cols)
 	if winLength < 3 || winLength > size {
 		return nil, ErrInvalidWinLength
 	}
 
-	cells := make([]Mark, size*size)
+	cells := make([]Mark, rows*cols)
 	for i := range cells {
 		cells[i] = MarkEmpty
 	}
 
 	return &Board{
 		Size:      size,
+		Rows:      rows,
+		Cols:      cols,
 		WinLength: winLength,
+		Depth:     1,
 		Cells:     cells,
 	}, nil
 }
 
+// IsSquare reports whether the board has as many rows as columns
+func (b *Board) IsSquare() bool {
+	return b.Rows == b.Cols
+}
+
 // Get returns the mark at the given position
 func (b *Board) Get(row, col int) (Mark, error) {
 	if !b.isValidPosition(row, col) {
 		return MarkEmpty, ErrInvalidPosition
 	}
-	return b.Cells[row*b.Size+col], nil
+	return b.Cells[row*b.Cols+col], nil
 }
 
 // Set places a mark at the given position
@@ -126,17 +220,55 @@ func (b *Board) Set(row, col int, mark Mark) error {
 	if !b.isValidPosition(row, col) {
 		return ErrInvalidPosition
 	}
-	idx := row*b.Size + col
+	idx := row*b.Cols + col
 	if b.Cells[idx] != MarkEmpty {
 		return ErrCellOccupied
 	}
 	b.Cells[idx] = mark
+	b.updateHash(idx, mark)
 	return nil
 }
 
 // isValidPosition checks if the position is within bounds
 func (b *Board) isValidPosition(row, col int) bool {
-	return row >= 0 && row < b.Size && col >= 0 && col < b.Size
+	return row >= 0 && row < b.Rows && col >= 0 && col < b.Cols
+}
+
+// DropColumn returns the lowest empty row in a column
+func (b *Board) DropColumn(col int) (int, error) {
+	if col < 0 || col >= b.Cols {
+		return 0, ErrInvalidPosition
+	}
+	for row := b.Rows - 1; row >= 0; row-- {
+		if b.Cells[row*b.Cols+col] == MarkEmpty {
+			return row, nil
+		}
+	}
+	return 0, ErrColumnFull
+}
+
+// EmptyCells returns every unoccupied position in row-major order, layer by layer
+func (b *Board) EmptyCells() []Position {
+	var cells []Position
+	layerCells := b.Rows * b.Cols
+	for i, cell := range b.Cells {
+		if cell == MarkEmpty {
+			rem := i % layerCells
+			cells = append(cells, Position{Row: rem / b.Cols, Col: rem % b.Cols, Layer: i / layerCells})
+		}
+	}
+	return cells
+}
+
+// landingCells returns the lowest empty cell of each column that is not full
+func (b *Board) landingCells() []Position {
+	var cells []Position
+	for col := 0; col < b.Cols; col++ {
+		if row, err := b.DropColumn(col); err == nil {
+			cells = append(cells, Position{Row: row, Col: col})
+		}
+	}
+	return cells
 }
 
 // IsFull returns true if all cells are occupied
@@ -149,23 +281,20 @@ func (b *Board) IsFull() bool {
 	return true
 }
 
+// lineDirections are the directions a line can run in: horizontal, vertical,
+// diagonal and anti-diagonal
+var lineDirections = [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
 // CheckWinner checks if there's a winner after a move at (row, col)
-// Returns the winning mark or MarkEmpty if no winner
+// Returns the winning mark or MarkEmpty if no winner.
+// CheckWinnerLine and LinesThrough report which lines the move completed.
 func (b *Board) CheckWinner(row, col int) Mark {
 	mark, err := b.Get(row, col)
 	if err != nil || mark == MarkEmpty {
 		return MarkEmpty
 	}
 
-	// Check all directions: horizontal, vertical, diagonal, anti-diagonal
-	directions := [][2]int{
-		{0, 1},  // horizontal
-		{1, 0},  // vertical
-		{1, 1},  // diagonal
-		{1, -1}, // anti-diagonal
-	}
-
-	for _, dir := range directions {
+	for _, dir := range lineDirections {
 		count := 1 // Count the current cell
 
 		// Count in positive direction
@@ -182,6 +311,176 @@ func (b *Board) CheckWinner(row, col int) Mark {
 	return MarkEmpty
 }
 
+// CheckWinnerLine is CheckWinner that also returns the line the move at
+// (row, col) completed, from its first cell to its last, or nil if none.
+// When the move completed several lines the first direction's is returned.
+func (b *Board) CheckWinnerLine(row, col int) (Mark, [][2]int) {
+	lines := b.LinesThrough(row, col)
+	if len(lines) == 0 {
+		return MarkEmpty, nil
+	}
+	return b.Cells[row*b.Cols+col], lines[0]
+}
+
+// LinesThrough returns every run of at least WinLength marks passing through
+// (row, col), at most one per direction. A single move can complete several
+// lines at once; each run is listed in full from its first cell to its last.
+func (b *Board) LinesThrough(row, col int) [][][2]int {
+	mark, err := b.Get(row, col)
+	if err != nil || mark == MarkEmpty {
+		return nil
+	}
+
+	var lines [][][2]int
+	for _, dir := range lineDirections {
+		back := b.countInDirection(row, col, -dir[0], -dir[1], mark)
+		length := 1 + back + b.countInDirection(row, col, dir[0], dir[1], mark)
+		if length >= b.WinLength {
+			lines = append(lines, runCells(row-back*dir[0], col-back*dir[1], dir, length))
+		}
+	}
+	return lines
+}
+
+// WinningLines returns every completed run of at least WinLength marks on
+// the board, ordered by their first cell in row-major order, or nil if there
+// is none. Each run is listed in full from its first cell to its last.
+func (b *Board) WinningLines() [][][2]int {
+	var lines [][][2]int
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Cols; col++ {
+			mark := b.Cells[row*b.Cols+col]
+			if mark == MarkEmpty {
+				continue
+			}
+			for _, dir := range lineDirections {
+				// Only report a run from its first cell
+				if prev, err := b.Get(row-dir[0], col-dir[1]); err == nil && prev == mark {
+					continue
+				}
+				length := 1 + b.countInDirection(row, col, dir[0], dir[1], mark)
+				if length >= b.WinLength {
+					lines = append(lines, runCells(row, col, dir, length))
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// WinPossible reports whether mark could still complete a line: some run of
+// WinLength cells in any direction holds only mark and empty cells. On a
+// cube lines may run in any of its 13 directions.
+func (b *Board) WinPossible(mark Mark) bool {
+	directions := cubeDirections
+	if !b.IsCube() {
+		directions = make([][3]int, len(lineDirections))
+		for i, dir := range lineDirections {
+			directions[i] = [3]int{0, dir[0], dir[1]}
+		}
+	}
+
+	for layer := 0; layer < b.layers(); layer++ {
+		for row := 0; row < b.Rows; row++ {
+			for col := 0; col < b.Cols; col++ {
+				for _, dir := range directions {
+					if b.lineOpen(row, col, layer, dir, mark) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Validate checks that the board could arise in a game with the given
+// player to move and status, to catch corrupted records before they are
+// served. Players mark in turn from X, so no mark is ahead of an earlier one
+// or more than one behind X; a game that has not started has no marks; only
+// the player who moved last can have completed a line, and only in a won
+// game; and in a game in progress it is the next player's turn. The game is
+// taken to have three players if the board, turn or status involve △.
+func (b *Board) Validate(turn Mark, status Status) error {
+	counts := make(map[Mark]int)
+	for _, cell := range b.Cells {
+		if cell < MarkEmpty || cell > MarkTriangle {
+			return fmt.Errorf("%w: unknown mark %d", ErrIllegalBoard, int(cell))
+		}
+		counts[cell]++
+	}
+	xs, os, ts := counts[MarkX], counts[MarkO], counts[MarkTriangle]
+	order := []Mark{MarkX, MarkO}
+	if ts > 0 || turn == MarkTriangle || status == StatusTriangleWon {
+		order = append(order, MarkTriangle)
+	}
+	if len(order) == 2 && xs != os && xs != os+1 {
+		return fmt.Errorf("%w: %d X and %d O marks", ErrIllegalBoard, xs, os)
+	}
+	if len(order) == 3 && !(xs >= os && os >= ts && ts >= xs-1) {
+		return fmt.Errorf("%w: %d X, %d O and %d △ marks", ErrIllegalBoard, xs, os, ts)
+	}
+	placed := xs + os + ts
+
+	switch status {
+	case StatusPending, StatusReady, StatusCancelled:
+		if placed > 0 {
+			return fmt.Errorf("%w: %s game has %d marks", ErrIllegalBoard, status, placed)
+		}
+	case StatusInProgress:
+		if next := order[placed%len(order)]; turn != next {
+			return fmt.Errorf("%w: %s to move after %d marks, want %s", ErrIllegalBoard, turn, placed, next)
+		}
+	}
+
+	owner := MarkEmpty
+	for layer := 0; layer < b.layers(); layer++ {
+		for row := 0; row < b.Rows; row++ {
+			for col := 0; col < b.Cols; col++ {
+				winner := b.CheckWinnerAt(row, col, layer)
+				if winner == MarkEmpty || winner == owner {
+					continue
+				}
+				if owner != MarkEmpty {
+					return fmt.Errorf("%w: both %s and %s completed lines", ErrIllegalBoard, owner, winner)
+				}
+				owner = winner
+			}
+		}
+	}
+	if owner == MarkEmpty {
+		return nil
+	}
+	if last := order[(placed-1)%len(order)]; owner != last {
+		return fmt.Errorf("%w: %s completed a line but %s moved last", ErrIllegalBoard, owner, last)
+	}
+	if status != StatusXWon && status != StatusOWon && status != StatusTriangleWon {
+		return fmt.Errorf("%w: %s game shows a completed line", ErrIllegalBoard, status)
+	}
+	return nil
+}
+
+// lineOpen reports whether the WinLength cells from (row, col, layer) along a
+// {dLayer, dRow, dCol} direction are on the board and hold only mark or nothing
+func (b *Board) lineOpen(row, col, layer int, dir [3]int, mark Mark) bool {
+	for i := 0; i < b.WinLength; i++ {
+		cell, err := b.GetAt(row+i*dir[1], col+i*dir[2], layer+i*dir[0])
+		if err != nil || (cell != MarkEmpty && cell != mark) {
+			return false
+		}
+	}
+	return true
+}
+
+// runCells lists length cells starting at (row, col) and stepping by dir
+func runCells(row, col int, dir [2]int, length int) [][2]int {
+	cells := make([][2]int, length)
+	for i := range cells {
+		cells[i] = [2]int{row + i*dir[0], col + i*dir[1]}
+	}
+	return cells
+}
+
 // countInDirection counts consecutive marks in a direction
 func (b *Board) countInDirection(row, col, dRow, dCol int, mark Mark) int {
 	count := 0
@@ -200,26 +499,101 @@ func (b *Board) countInDirection(row, col, dRow, dCol int, mark Mark) int {
 	return count
 }
 
-// Clone creates a deep copy of the board
+// Fingerprint returns a hash of the board configuration and cell contents.
+// Boards with the same shape, win length and marks share a fingerprint.
+func (b *Board) Fingerprint() uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 0, 12+len(b.Cells))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(b.Rows))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(b.Cols))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(b.WinLength))
+	for _, cell := range b.Cells {
+		buf = append(buf, byte(cell))
+	}
+	h.Write(buf)
+	return h.Sum64()
+}
+
+// symmetries map a cell to its image under each rotation and reflection of
+// a rows x cols board. Only the first four keep a rectangle's shape; the rest
+// swap rows and columns and apply to square boards alone.
+var symmetries = []func(row, col, rows, cols int) (int, int){
+	func(r, c, rows, cols int) (int, int) { return r, c },
+	func(r, c, rows, cols int) (int, int) { return rows - 1 - r, c },
+	func(r, c, rows, cols int) (int, int) { return r, cols - 1 - c },
+	func(r, c, rows, cols int) (int, int) { return rows - 1 - r, cols - 1 - c },
+	func(r, c, rows, cols int) (int, int) { return c, r },
+	func(r, c, rows, cols int) (int, int) { return c, rows - 1 - r },
+	func(r, c, rows, cols int) (int, int) { return cols - 1 - c, r },
+	func(r, c, rows, cols int) (int, int) { return cols - 1 - c, rows - 1 - r },
+}
+
+// Canonical returns a key shared by every rotation and reflection of the
+// board's marks: the smallest of its cell strings under the 8 symmetries of a
+// square board, or the 4 of a rectangular one. A 3D board is turned the same
+// way on every layer. Keys only compare boards of the same shape.
+func (b *Board) Canonical() string {
+	transforms := symmetries
+	if !b.IsSquare() {
+		transforms = symmetries[:4]
+	}
+
+	best := make([]byte, len(b.Cells))
+	cur := make([]byte, len(b.Cells))
+	perLayer := b.Rows * b.Cols
+	for i, transform := range transforms {
+		for idx, cell := range b.Cells {
+			layer, rest := idx/perLayer, idx%perLayer
+			row, col := transform(rest/b.Cols, rest%b.Cols, b.Rows, b.Cols)
+			cur[layer*perLayer+row*b.Cols+col] = '0' + byte(cell)
+		}
+		if i == 0 || bytes.Compare(cur, best) < 0 {
+			copy(best, cur)
+		}
+	}
+	return string(best)
+}
+
+// IsEmpty returns true if no marks have been placed
+func (b *Board) IsEmpty() bool {
+	for _, cell := range b.Cells {
+		if cell != MarkEmpty {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone creates a deep copy of the board. The copy hashes afresh, so its
+// cells may be written directly.
 func (b *Board) Clone() *Board {
 	cells := make([]Mark, len(b.Cells))
 	copy(cells, b.Cells)
 	return &Board{
 		Size:      b.Size,
+		Rows:      b.Rows,
+		Cols:      b.Cols,
 		WinLength: b.WinLength,
+		Depth:     b.Depth,
 		Cells:     cells,
 	}
 }
 
-// String returns a string representation of the board
+// String returns a string representation of the board, with a blank line
+// between the layers of a cube
 func (b *Board) String() string {
 	var result string
-	for row := 0; row < b.Size; row++ {
-		for col := 0; col < b.Size; col++ {
-			mark, _ := b.Get(row, col)
-			result += fmt.Sprintf("[%s]", mark)
+	for layer := 0; layer < b.layers(); layer++ {
+		if layer > 0 {
+			result += "\n"
+		}
+		for row := 0; row < b.Rows; row++ {
+			for col := 0; col < b.Cols; col++ {
+				mark, _ := b.GetAt(row, col, layer)
+				result += fmt.Sprintf("[%s]", mark)
+			}
+			result += "\n"
 		}
-		result += "\n"
 	}
 	return result
 }
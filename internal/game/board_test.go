@@ -183,6 +183,104 @@ func TestBoard_CheckWinner_AntiDiagonal(t *testing.T) {
 	assert.Equal(t, MarkX, winner)
 }
 
+func TestRectBoard_HorizontalWin(t *testing.T) {
+	board, err := NewRectBoard(3, 4, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, board.Size)
+	assert.Len(t, board.Cells, 12)
+	assert.False(t, board.IsSquare())
+
+	// . . . .
+	// O O O .
+	// X X X X
+	for col := 0; col < 3; col++ {
+		require.NoError(t, board.Set(2, col, MarkX))
+		require.NoError(t, board.Set(1, col, MarkO))
+	}
+	assert.Equal(t, MarkEmpty, board.CheckWinner(2, 2))
+	require.NoError(t, board.Set(2, 3, MarkX))
+	assert.Equal(t, MarkX, board.CheckWinner(2, 3))
+	assert.Equal(t, [][][2]int{{{2, 0}, {2, 1}, {2, 2}, {2, 3}}}, board.WinningLines())
+
+	// Only the columns extend past the third row
+	assert.ErrorIs(t, board.Set(3, 0, MarkO), ErrInvalidPosition)
+	require.NoError(t, board.Set(0, 3, MarkO))
+	mark, err := board.Get(0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, MarkO, mark)
+}
+
+func TestRectBoard_AllDirections(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells [][2]int
+	}{
+		{"vertical", [][2]int{{0, 3}, {1, 3}, {2, 3}}},
+		{"diagonal", [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+		{"anti-diagonal", [][2]int{{0, 2}, {1, 1}, {2, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewRectBoard(3, 4, 3)
+			require.NoError(t, err)
+			for _, cell := range tt.cells {
+				require.NoError(t, board.Set(cell[0], cell[1], MarkO))
+			}
+			last := tt.cells[len(tt.cells)-1]
+			assert.Equal(t, MarkO, board.CheckWinner(last[0], last[1]))
+		})
+	}
+}
+
+func TestNewRectBoard_Validation(t *testing.T) {
+	_, err := NewRectBoard(2, 5, 3)
+	assert.ErrorIs(t, err, ErrInvalidBoardSize)
+	_, err = NewRectBoard(3, 4, 5)
+	assert.ErrorIs(t, err, ErrInvalidWinLength)
+
+	// A line may be longer than the short side
+	board, err := NewRectBoard(4, 3, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, board.Rows)
+	assert.Equal(t, 3, board.Cols)
+
+	_, err = NewRectGame("game-1", "player-1", 3, 4, 3, With3D())
+	assert.ErrorIs(t, err, ErrCubeNotSquare)
+}
+
+func TestBoard_WinPossible(t *testing.T) {
+	board, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	assert.True(t, board.WinPossible(MarkX))
+
+	// X O X
+	// O X .
+	// O X O
+	for _, cell := range [][2]int{{0, 0}, {0, 2}, {1, 1}, {2, 1}} {
+		require.NoError(t, board.Set(cell[0], cell[1], MarkX))
+	}
+	for _, cell := range [][2]int{{0, 1}, {1, 0}, {2, 0}, {2, 2}} {
+		require.NoError(t, board.Set(cell[0], cell[1], MarkO))
+	}
+	assert.False(t, board.WinPossible(MarkX))
+	assert.False(t, board.WinPossible(MarkO))
+
+	// A line clipped by the edge of a rectangle does not count
+	rect, err := NewRectBoard(3, 4, 4)
+	require.NoError(t, err)
+	require.NoError(t, rect.Set(0, 0, MarkO))
+	require.NoError(t, rect.Set(1, 1, MarkO))
+	require.NoError(t, rect.Set(2, 2, MarkO))
+	assert.False(t, rect.WinPossible(MarkX))
+	assert.True(t, rect.WinPossible(MarkO))
+
+	// Lines through the layers of a cube stay open
+	cube, err := NewCubeBoard(3, 3)
+	require.NoError(t, err)
+	copy(cube.Cells, board.Cells)
+	assert.True(t, cube.WinPossible(MarkX))
+}
+
 func TestBoard_CheckWinner_NoWinner(t *testing.T) {
 	board, err := NewBoard(3, 3)
 	require.NoError(t, err)
@@ -242,6 +340,312 @@ func TestBoard_Clone(t *testing.T) {
 	assert.Equal(t, MarkEmpty, cloneMark)
 }
 
+func TestBoard_Canonical(t *testing.T) {
+	// An X in one corner and an O beside it, in all 8 orientations
+	corners := [][2][2]int{
+		{{0, 0}, {0, 1}}, {{0, 0}, {1, 0}},
+		{{0, 2}, {0, 1}}, {{0, 2}, {1, 2}},
+		{{2, 0}, {2, 1}}, {{2, 0}, {1, 0}},
+		{{2, 2}, {2, 1}}, {{2, 2}, {1, 2}},
+	}
+	keys := make(map[string]bool)
+	for _, marks := range corners {
+		b, err := NewBoard(3, 3)
+		require.NoError(t, err)
+		require.NoError(t, b.Set(marks[0][0], marks[0][1], MarkX))
+		require.NoError(t, b.Set(marks[1][0], marks[1][1], MarkO))
+		keys[b.Canonical()] = true
+	}
+	assert.Len(t, keys, 1)
+
+	// Swapping the marks is a different position
+	b, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	b.Set(0, 0, MarkO)
+	b.Set(0, 1, MarkX)
+	assert.False(t, keys[b.Canonical()])
+
+	// A rectangle can be flipped but not turned on its side
+	rect := func(row, col int) string {
+		b, err := NewRectBoard(3, 4, 3)
+		require.NoError(t, err)
+		require.NoError(t, b.Set(row, col, MarkX))
+		return b.Canonical()
+	}
+	assert.Equal(t, rect(0, 0), rect(2, 3))
+	assert.Equal(t, rect(0, 1), rect(2, 2))
+	assert.NotEqual(t, rect(0, 0), rect(0, 1))
+}
+
+func TestBoard_Fingerprint(t *testing.T) {
+	a, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	b, err := NewBoard(3, 3)
+	require.NoError(t, err)
+
+	assert.True(t, a.IsEmpty())
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+
+	a.Set(1, 1, MarkX)
+	assert.False(t, a.IsEmpty())
+	assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+
+	b.Set(1, 1, MarkX)
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+
+	// Same marks under a different configuration hash differently
+	c, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	c.Set(1, 1, MarkO)
+	assert.NotEqual(t, a.Fingerprint(), c.Fingerprint())
+
+	d, err := NewBoard(4, 3)
+	require.NoError(t, err)
+	e, err := NewBoard(4, 4)
+	require.NoError(t, err)
+	assert.NotEqual(t, d.Fingerprint(), e.Fingerprint())
+}
+
+func TestBoard_Hash(t *testing.T) {
+	a, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	b, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	// A single different cell changes the hash
+	require.NoError(t, a.Set(1, 1, MarkX))
+	assert.NotEqual(t, a.Hash(), b.Hash())
+	c := b.Clone()
+	require.NoError(t, c.Set(1, 1, MarkO))
+	assert.NotEqual(t, a.Hash(), c.Hash())
+
+	// The same position reached in a different order hashes equal, and
+	// incremental updates agree with hashing from scratch
+	require.NoError(t, a.Set(0, 2, MarkO))
+	require.NoError(t, b.Set(0, 2, MarkO))
+	require.NoError(t, b.Set(1, 1, MarkX))
+	assert.Equal(t, a.Hash(), b.Hash())
+	fresh := b.Clone()
+	assert.Equal(t, b.Hash(), fresh.Hash())
+
+	// A failed Set leaves the hash alone
+	before := a.Hash()
+	assert.ErrorIs(t, a.Set(1, 1, MarkO), ErrCellOccupied)
+	assert.Equal(t, before, a.Hash())
+
+	// Direct writes to Cells need a Rehash
+	a.Cells[0] = MarkX
+	a.Rehash()
+	require.NoError(t, b.Set(0, 0, MarkX))
+	assert.Equal(t, b.Hash(), a.Hash())
+
+	// Layers and board shapes are told apart
+	cube, err := NewCubeBoard(3, 3)
+	require.NoError(t, err)
+	flat, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	assert.NotEqual(t, cube.Hash(), flat.Hash())
+	require.NoError(t, cube.SetAt(0, 0, 1, MarkX))
+	other := cube.Clone()
+	require.NoError(t, cube.SetAt(0, 0, 2, MarkX))
+	require.NoError(t, other.SetAt(0, 0, 2, MarkX))
+	assert.Equal(t, other.Hash(), cube.Hash())
+	rect, err := NewRectBoard(3, 4, 3)
+	require.NoError(t, err)
+	wide, err := NewRectBoard(4, 3, 3)
+	require.NoError(t, err)
+	assert.NotEqual(t, rect.Hash(), wide.Hash())
+}
+
+func TestBoard_WinningLines(t *testing.T) {
+	board, err := NewBoard(4, 3)
+	require.NoError(t, err)
+	assert.Nil(t, board.WinningLines())
+
+	// Two in a row is not a line
+	board.Set(0, 3, MarkO)
+	board.Set(1, 2, MarkO)
+	assert.Nil(t, board.WinningLines())
+
+	// Anti-diagonal from the top-right corner
+	board.Set(2, 1, MarkO)
+	assert.Equal(t, [][][2]int{{{0, 3}, {1, 2}, {2, 1}}}, board.WinningLines())
+
+	// A run longer than WinLength is one line, listed in full
+	board.Set(3, 0, MarkO)
+	assert.Equal(t, [][][2]int{{{0, 3}, {1, 2}, {2, 1}, {3, 0}}}, board.WinningLines())
+}
+
+func TestBoard_LinesThrough_DoubleCompletion(t *testing.T) {
+	board, err := NewBoard(5, 5)
+	require.NoError(t, err)
+
+	// X threatens both the middle row and the middle column through (2, 2)
+	for i := 0; i < 5; i++ {
+		if i != 2 {
+			board.Set(2, i, MarkX)
+			board.Set(i, 2, MarkX)
+		}
+	}
+	assert.Nil(t, board.LinesThrough(2, 2))
+	assert.Nil(t, board.WinningLines())
+
+	board.Set(2, 2, MarkX)
+	assert.Equal(t, MarkX, board.CheckWinner(2, 2))
+
+	row := [][2]int{{2, 0}, {2, 1}, {2, 2}, {2, 3}, {2, 4}}
+	col := [][2]int{{0, 2}, {1, 2}, {2, 2}, {3, 2}, {4, 2}}
+	assert.Equal(t, [][][2]int{row, col}, board.LinesThrough(2, 2))
+
+	// Every completed run is reported, not just the first found
+	assert.ElementsMatch(t, [][][2]int{row, col}, board.WinningLines())
+
+	// The lines pass through the edge cells too, but only from their own directions
+	assert.Equal(t, [][][2]int{row}, board.LinesThrough(2, 0))
+	assert.Nil(t, board.LinesThrough(0, 0))
+}
+
+func TestBoard_CheckWinnerLine_Edges(t *testing.T) {
+	// Five-cell runs on a 7x7 board that end flush against the bottom and
+	// right edges, in each direction
+	tests := []struct {
+		name string
+		line [][2]int
+	}{
+		{"horizontal", [][2]int{{6, 2}, {6, 3}, {6, 4}, {6, 5}, {6, 6}}},
+		{"vertical", [][2]int{{2, 6}, {3, 6}, {4, 6}, {5, 6}, {6, 6}}},
+		{"diagonal", [][2]int{{2, 2}, {3, 3}, {4, 4}, {5, 5}, {6, 6}}},
+		{"anti-diagonal", [][2]int{{2, 6}, {3, 5}, {4, 4}, {5, 3}, {6, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The last move may complete the run from either end or the middle
+			for last := range tt.line {
+				board, err := NewBoard(7, 5)
+				require.NoError(t, err)
+				for i, cell := range tt.line {
+					if i != last {
+						require.NoError(t, board.Set(cell[0], cell[1], MarkO))
+					}
+				}
+				lastCell := tt.line[last]
+				mark, line := board.CheckWinnerLine(lastCell[0], lastCell[1])
+				assert.Equal(t, MarkEmpty, mark, "four in a row is not a win")
+				assert.Nil(t, line)
+
+				require.NoError(t, board.Set(lastCell[0], lastCell[1], MarkO))
+				mark, line = board.CheckWinnerLine(lastCell[0], lastCell[1])
+				assert.Equal(t, MarkO, mark, "completed at %v", lastCell)
+				assert.Equal(t, tt.line, line, "completed at %v", lastCell)
+				assert.Equal(t, MarkO, board.CheckWinner(lastCell[0], lastCell[1]))
+			}
+		})
+	}
+}
+
+func TestBoard_CheckWinnerLine_NoWrap(t *testing.T) {
+	board, err := NewBoard(7, 5)
+	require.NoError(t, err)
+
+	// Five cells in a row in storage order, split across the last two rows
+	for _, cell := range [][2]int{{5, 5}, {5, 6}, {6, 0}, {6, 1}, {6, 2}} {
+		require.NoError(t, board.Set(cell[0], cell[1], MarkX))
+	}
+	for _, cell := range [][2]int{{5, 6}, {6, 0}} {
+		mark, line := board.CheckWinnerLine(cell[0], cell[1])
+		assert.Equal(t, MarkEmpty, mark)
+		assert.Nil(t, line)
+	}
+	assert.Nil(t, board.WinningLines())
+}
+
+func TestBoard_DropColumn(t *testing.T) {
+	board, err := NewBoard(3, 3)
+	require.NoError(t, err)
+
+	// Each drop lands one row above the last, starting at the bottom
+	for want := 2; want >= 0; want-- {
+		row, err := board.DropColumn(1)
+		require.NoError(t, err)
+		assert.Equal(t, want, row)
+		require.NoError(t, board.Set(row, 1, MarkX))
+	}
+
+	_, err = board.DropColumn(1)
+	assert.ErrorIs(t, err, ErrColumnFull)
+
+	// Neighbouring columns are unaffected
+	row, err := board.DropColumn(0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, row)
+
+	_, err = board.DropColumn(3)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+	_, err = board.DropColumn(-1)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+}
+
+func TestBoard_Validate(t *testing.T) {
+	const (
+		E = MarkEmpty
+		X = MarkX
+		O = MarkO
+		T = MarkTriangle
+	)
+
+	tests := []struct {
+		name   string
+		cells  []Mark
+		turn   Mark
+		status Status
+		valid  bool
+	}{
+		{"empty pending", make([]Mark, 9), MarkX, StatusPending, true},
+		{"O to move", []Mark{X, E, E, E, E, E, E, E, E}, MarkO, StatusInProgress, true},
+		{"X won", []Mark{X, X, X, O, O, E, E, E, E}, MarkO, StatusXWon, true},
+		{"X lost misère", []Mark{X, X, X, O, O, E, E, E, E}, MarkO, StatusOWon, true},
+		{"full draw", []Mark{X, O, X, X, O, O, O, X, X}, MarkX, StatusDraw, true},
+		{"three players", []Mark{X, O, T, X, E, E, E, E, E}, MarkO, StatusInProgress, true},
+		{"forfeit without a line", []Mark{X, O, E, E, E, E, E, E, E}, MarkX, StatusOWon, true},
+
+		{"five X and no O", []Mark{X, X, E, X, E, X, E, E, X}, MarkO, StatusInProgress, false},
+		{"O ahead of X", []Mark{O, O, X, E, E, E, E, E, E}, MarkX, StatusInProgress, false},
+		{"triangle ahead of O", []Mark{X, T, T, X, E, E, E, E, E}, MarkO, StatusInProgress, false},
+		{"wrong player to move", []Mark{X, O, E, E, E, E, E, E, E}, MarkO, StatusInProgress, false},
+		{"marks before the start", []Mark{X, E, E, E, E, E, E, E, E}, MarkO, StatusPending, false},
+		{"both completed lines", []Mark{X, X, X, O, O, O, X, O, E}, MarkX, StatusXWon, false},
+		{"line by the player who did not move last", []Mark{X, X, X, O, O, E, O, E, E}, MarkX, StatusXWon, false},
+		{"line in a game in progress", []Mark{X, X, X, O, O, E, E, E, E}, MarkO, StatusInProgress, false},
+		{"line in a draw", []Mark{X, X, X, O, O, E, E, E, E}, MarkO, StatusDraw, false},
+		{"unknown mark", []Mark{Mark(7), E, E, E, E, E, E, E, E}, MarkO, StatusInProgress, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewBoard(3, 3)
+			require.NoError(t, err)
+			copy(board.Cells, tt.cells)
+			err = board.Validate(tt.turn, tt.status)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrIllegalBoard)
+			}
+		})
+	}
+}
+
+func TestBoard_EmptyCells(t *testing.T) {
+	board, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	assert.Len(t, board.EmptyCells(), 9)
+
+	board.Set(0, 0, MarkX)
+	board.Set(1, 1, MarkO)
+	board.Set(2, 2, MarkX)
+	assert.Equal(t, []Position{{0, 1, 0}, {0, 2, 0}, {1, 0, 0}, {1, 2, 0}, {2, 0, 0}, {2, 1, 0}}, board.EmptyCells())
+}
+
 func TestMark_Opponent(t *testing.T) {
 	assert.Equal(t, MarkO, MarkX.Opponent())
 	assert.Equal(t, MarkX, MarkO.Opponent())
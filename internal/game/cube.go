@@ -0,0 +1,109 @@
+package game
+
+// cubeDirections are the 13 directions a line can run in through a cube, as
+// {dLayer, dRow, dCol}: the 4 within a layer, the vertical through the
+// layers, the 4 diagonals of the vertical planes and the 4 space diagonals
+var cubeDirections = [][3]int{
+	{0, 0, 1}, {0, 1, 0}, {0, 1, 1}, {0, 1, -1},
+	{1, 0, 0},
+	{1, 0, 1}, {1, 0, -1}, {1, 1, 0}, {1, -1, 0},
+	{1, 1, 1}, {1, 1, -1}, {1, -1, 1}, {1, -1, -1},
+}
+
+// NewCubeBoard creates an empty size×size×size board for 3D play
+func NewCubeBoard(size, winLength int) (*Board, error) {
+	board, err := NewBoard(size, winLength)
+	if err != nil {
+		return nil, err
+	}
+	board.Depth = size
+	board.Cells = make([]Mark, size*size*size)
+	return board, nil
+}
+
+// IsCube reports whether the board has more than one layer
+func (b *Board) IsCube() bool {
+	return b.Depth > 1
+}
+
+// layers returns the number of layers, treating an unset Depth as flat
+func (b *Board) layers() int {
+	if b.Depth < 1 {
+		return 1
+	}
+	return b.Depth
+}
+
+// isValidCell checks if the position is within bounds of every layer
+func (b *Board) isValidCell(row, col, layer int) bool {
+	return b.isValidPosition(row, col) && layer >= 0 && layer < b.layers()
+}
+
+// GetAt returns the mark at the given position of the given layer.
+// On a flat board only layer 0 exists.
+func (b *Board) GetAt(row, col, layer int) (Mark, error) {
+	if !b.isValidCell(row, col, layer) {
+		return MarkEmpty, ErrInvalidPosition
+	}
+	return b.Cells[(layer*b.Rows+row)*b.Cols+col], nil
+}
+
+// SetAt places a mark at the given position of the given layer
+func (b *Board) SetAt(row, col, layer int, mark Mark) error {
+	if !b.isValidCell(row, col, layer) {
+		return ErrInvalidPosition
+	}
+	idx := (layer*b.Rows+row)*b.Cols + col
+	if b.Cells[idx] != MarkEmpty {
+		return ErrCellOccupied
+	}
+	b.Cells[idx] = mark
+	b.updateHash(idx, mark)
+	return nil
+}
+
+// CheckWinnerAt checks if there's a winner after a move at (row, col, layer),
+// looking along all 13 directions of a cube. On a flat board it is CheckWinner.
+func (b *Board) CheckWinnerAt(row, col, layer int) Mark {
+	if !b.IsCube() {
+		if layer != 0 {
+			return MarkEmpty
+		}
+		return b.CheckWinner(row, col)
+	}
+
+	mark, err := b.GetAt(row, col, layer)
+	if err != nil || mark == MarkEmpty {
+		return MarkEmpty
+	}
+
+	for _, dir := range cubeDirections {
+		count := 1 +
+			b.countInDirectionAt(row, col, layer, dir, mark) +
+			b.countInDirectionAt(row, col, layer, [3]int{-dir[0], -dir[1], -dir[2]}, mark)
+		if count >= b.WinLength {
+			return mark
+		}
+	}
+
+	return MarkEmpty
+}
+
+// countInDirectionAt counts consecutive marks from (row, col, layer) in a
+// {dLayer, dRow, dCol} direction
+func (b *Board) countInDirectionAt(row, col, layer int, dir [3]int, mark Mark) int {
+	count := 0
+	l, r, c := layer+dir[0], row+dir[1], col+dir[2]
+
+	for b.isValidCell(r, c, l) {
+		if m, _ := b.GetAt(r, c, l); m != mark {
+			break
+		}
+		count++
+		l += dir[0]
+		r += dir[1]
+		c += dir[2]
+	}
+
+	return count
+}
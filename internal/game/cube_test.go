@@ -0,0 +1,137 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCubeBoard_SpaceDiagonal(t *testing.T) {
+	board, err := NewCubeBoard(3, 3)
+	require.NoError(t, err)
+	assert.Len(t, board.Cells, 27)
+
+	// (0,0,0) -> (1,1,1) -> (2,2,2) runs corner to corner through the cube
+	require.NoError(t, board.SetAt(0, 0, 0, MarkX))
+	require.NoError(t, board.SetAt(1, 1, 1, MarkX))
+	assert.Equal(t, MarkEmpty, board.CheckWinnerAt(1, 1, 1))
+	require.NoError(t, board.SetAt(2, 2, 2, MarkX))
+	assert.Equal(t, MarkX, board.CheckWinnerAt(2, 2, 2))
+	assert.Equal(t, MarkX, board.CheckWinnerAt(1, 1, 1))
+
+	// The other three space diagonals
+	for _, line := range [][3][3]int{
+		{{0, 2, 0}, {1, 1, 1}, {2, 0, 2}},
+		{{2, 0, 0}, {1, 1, 1}, {0, 2, 2}},
+		{{0, 0, 2}, {1, 1, 1}, {2, 2, 0}},
+	} {
+		board, _ := NewCubeBoard(3, 3)
+		for _, cell := range line {
+			require.NoError(t, board.SetAt(cell[0], cell[1], cell[2], MarkO))
+		}
+		assert.Equal(t, MarkO, board.CheckWinnerAt(line[0][0], line[0][1], line[0][2]), "line %v", line)
+	}
+}
+
+func TestCubeBoard_LinesThroughLayers(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells [3][3]int // {row, col, layer}
+	}{
+		{"vertical", [3][3]int{{1, 2, 0}, {1, 2, 1}, {1, 2, 2}}},
+		{"row diagonal", [3][3]int{{0, 0, 0}, {0, 1, 1}, {0, 2, 2}}},
+		{"column diagonal", [3][3]int{{2, 1, 0}, {1, 1, 1}, {0, 1, 2}}},
+		{"within a layer", [3][3]int{{0, 0, 2}, {1, 1, 2}, {2, 2, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, err := NewCubeBoard(3, 3)
+			require.NoError(t, err)
+			for i, cell := range tt.cells {
+				require.NoError(t, board.SetAt(cell[0], cell[1], cell[2], MarkX))
+				if i < 2 {
+					assert.Equal(t, MarkEmpty, board.CheckWinnerAt(cell[0], cell[1], cell[2]))
+				}
+			}
+			last := tt.cells[2]
+			assert.Equal(t, MarkX, board.CheckWinnerAt(last[0], last[1], last[2]))
+		})
+	}
+}
+
+func TestCubeBoard_NoWinAcrossBrokenLine(t *testing.T) {
+	board, err := NewCubeBoard(4, 3)
+	require.NoError(t, err)
+
+	require.NoError(t, board.SetAt(0, 0, 0, MarkX))
+	require.NoError(t, board.SetAt(1, 1, 1, MarkO))
+	require.NoError(t, board.SetAt(2, 2, 2, MarkX))
+	require.NoError(t, board.SetAt(3, 3, 3, MarkX))
+	assert.Equal(t, MarkEmpty, board.CheckWinnerAt(3, 3, 3))
+}
+
+func TestCubeBoard_Bounds(t *testing.T) {
+	board, err := NewCubeBoard(3, 3)
+	require.NoError(t, err)
+	assert.ErrorIs(t, board.SetAt(0, 0, 3, MarkX), ErrInvalidPosition)
+	assert.ErrorIs(t, board.SetAt(0, 0, -1, MarkX), ErrInvalidPosition)
+	require.NoError(t, board.SetAt(0, 0, 2, MarkX))
+	assert.ErrorIs(t, board.SetAt(0, 0, 2, MarkO), ErrCellOccupied)
+
+	// A flat board only has layer 0
+	flat, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	assert.ErrorIs(t, flat.SetAt(0, 0, 1, MarkX), ErrInvalidPosition)
+	require.NoError(t, flat.SetAt(1, 2, 0, MarkX))
+	mark, _ := flat.Get(1, 2)
+	assert.Equal(t, MarkX, mark)
+}
+
+func TestCubeBoard_EmptyCells(t *testing.T) {
+	board, err := NewCubeBoard(3, 3)
+	require.NoError(t, err)
+	for i := range board.Cells {
+		board.Cells[i] = MarkX
+	}
+	board.Cells[13] = MarkEmpty // centre of the cube
+	board.Cells[26] = MarkEmpty
+
+	assert.Equal(t, []Position{{Row: 1, Col: 1, Layer: 1}, {Row: 2, Col: 2, Layer: 2}}, board.EmptyCells())
+}
+
+func TestGame_3D(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, With3D())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	assert.Len(t, g.AvailableMoves(), 27)
+
+	// X climbs the space diagonal while O stays on the bottom layer
+	mustMove(t, g, "player-1", 0, 0, OnLayer(0))
+	mustMove(t, g, "player-2", 0, 1, OnLayer(0))
+	mustMove(t, g, "player-1", 1, 1, OnLayer(1))
+	mustMove(t, g, "player-2", 0, 2, OnLayer(0))
+	snapshot := mustMove(t, g, "player-1", 2, 2, OnLayer(2))
+
+	assert.Equal(t, StatusXWon, snapshot.Status)
+	assert.True(t, snapshot.Board.IsCube())
+}
+
+func TestGame_3D_Validation(t *testing.T) {
+	_, err := NewGame("game-1", "player-1", 3, 3, With3D(), WithMode(ModeGravity))
+	assert.ErrorIs(t, err, ErrGravityOnCube)
+
+	g, err := NewGame("game-1", "player-1", 3, 3, With3D())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	_, err = g.MakeMove("player-1", 0, 0, OnLayer(3))
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+
+	// Flat games are unchanged and reject other layers
+	flat, err := NewGame("game-2", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, flat.Join("player-2"))
+	_, err = flat.MakeMove("player-1", 0, 0, OnLayer(1))
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+	assert.False(t, flat.GetSnapshot().Board.IsCube())
+}
@@ -0,0 +1,330 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidExport is returned when exported game JSON cannot be restored
+var ErrInvalidExport = errors.New("invalid game export")
+
+// gameJSON is the exported form of a game. Marks are "X", "O", "△" or "" and
+// cells are listed row by row, layer by layer on a 3D board.
+type gameJSON struct {
+	ID              string     `json:"id"`
+	PlayerX         string     `json:"player_x"`
+	PlayerO         string     `json:"player_o"`
+	PlayerTriangle  string     `json:"player_triangle,omitempty"`
+	Creator         string     `json:"creator,omitempty"`
+	NumPlayers      int        `json:"num_players,omitempty"`
+	BoardSize       int        `json:"board_size"`
+	Rows            int        `json:"rows,omitempty"`
+	Cols            int        `json:"cols,omitempty"`
+	WinLength       int        `json:"win_length"`
+	Depth           int        `json:"depth"`
+	Cells           []string   `json:"cells"`
+	Mode            string     `json:"mode"`
+	Misere          bool       `json:"misere"`
+	Turn            string     `json:"turn"`
+	Status          string     `json:"status"`
+	DrawOffer       string     `json:"draw_offer"`
+	AbandonedBy     string     `json:"abandoned_by"`
+	PlayersOnlyChat bool       `json:"players_only_chat"`
+	EarlyDraw       bool       `json:"early_draw,omitempty"`
+	Hints           bool       `json:"hints,omitempty"`
+	ReadyCheck      bool       `json:"ready_check,omitempty"`
+	Ready           []string   `json:"ready,omitempty"` // Marks of the players who have called Start
+	RandomStart     bool       `json:"random_start,omitempty"`
+	FromPosition    bool       `json:"from_position,omitempty"`
+	Seed            uint64     `json:"seed"`
+	JoinCode        string     `json:"join_code,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	StartedAt       time.Time  `json:"started_at,omitzero"`
+	Version         int64      `json:"version,omitempty"`
+	Moves           []moveJSON `json:"moves"`
+}
+
+// moveJSON is the exported form of a Move
+type moveJSON struct {
+	Mark  string    `json:"mark"`
+	Row   int       `json:"row"`
+	Col   int       `json:"col"`
+	Layer int       `json:"layer"`
+	At    time.Time `json:"at"`
+}
+
+// MarshalJSON exports the game's full state, for reproducing bug reports
+func (g *Game) MarshalJSON() ([]byte, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	cells := make([]string, len(g.Board.Cells))
+	for i, cell := range g.Board.Cells {
+		cells[i] = markToJSON(cell)
+	}
+	var ready []string
+	for _, mark := range seatMarks {
+		if g.ready[mark] {
+			ready = append(ready, markToJSON(mark))
+		}
+	}
+	moves := make([]moveJSON, len(g.moves))
+	for i, move := range g.moves {
+		moves[i] = moveJSON{Mark: markToJSON(move.Mark), Row: move.Row, Col: move.Col, Layer: move.Layer, At: move.At}
+	}
+	return json.Marshal(gameJSON{
+		ID:              g.ID,
+		PlayerX:         g.PlayerX,
+		PlayerO:         g.PlayerO,
+		PlayerTriangle:  g.PlayerTriangle,
+		Creator:         g.Creator,
+		NumPlayers:      g.NumPlayers,
+		BoardSize:       g.Board.Size,
+		Rows:            g.Board.Rows,
+		Cols:            g.Board.Cols,
+		WinLength:       g.Board.WinLength,
+		Depth:           g.Board.layers(),
+		Cells:           cells,
+		Mode:            g.Mode.String(),
+		Misere:          g.Misere,
+		Turn:            markToJSON(g.Turn),
+		Status:          g.Status.String(),
+		DrawOffer:       markToJSON(g.DrawOffer),
+		AbandonedBy:     markToJSON(g.AbandonedBy),
+		PlayersOnlyChat: g.PlayersOnlyChat,
+		EarlyDraw:       g.EarlyDraw,
+		Hints:           g.Hints,
+		ReadyCheck:      g.ReadyCheck,
+		Ready:           ready,
+		RandomStart:     g.RandomStart,
+		FromPosition:    g.FromPosition,
+		Seed:            g.Seed,
+		JoinCode:        g.JoinCode,
+		CreatedAt:       g.CreatedAt,
+		UpdatedAt:       g.UpdatedAt,
+		StartedAt:       g.StartedAt,
+		Version:         g.Version,
+		Moves:           moves,
+	})
+}
+
+// UnmarshalJSON restores a game exported by MarshalJSON and marks it
+// Imported. The board must be a
+// valid size with exactly one cell per square, and every move on the board. A restored finished game is
+// treated as already recorded, so its result is not counted again. A game exported in progress takes
+// the status its board shows (see RecomputeStatus).
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var in gameJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	if in.ID == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidExport)
+	}
+
+	// Exports without a player count predate three-player games
+	numPlayers := in.NumPlayers
+	if numPlayers == 0 {
+		numPlayers = 2
+	}
+	if numPlayers != 2 && numPlayers != 3 {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, ErrInvalidPlayerCount)
+	}
+	if numPlayers == 2 && in.PlayerTriangle != "" {
+		return fmt.Errorf("%w: player_triangle needs num_players 3", ErrInvalidExport)
+	}
+	if numPlayers != 2 && in.Misere {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, ErrMisereMultiplayer)
+	}
+
+	// Exports without rows and cols predate rectangular boards and are square
+	rows, cols := in.Rows, in.Cols
+	if rows == 0 && cols == 0 {
+		rows, cols = in.BoardSize, in.BoardSize
+	}
+	if max(rows, cols) != in.BoardSize {
+		return fmt.Errorf("%w: board_size must be the larger of rows and cols", ErrInvalidExport)
+	}
+
+	// Exports without a depth predate 3D boards and are flat
+	var board *Board
+	var err error
+	switch {
+	case in.Depth == 0 || in.Depth == 1:
+		board, err = NewRectBoard(rows, cols, in.WinLength)
+	case in.Depth == in.BoardSize && rows == cols:
+		board, err = NewCubeBoard(in.BoardSize, in.WinLength)
+	default:
+		return fmt.Errorf("%w: depth must be 1, or board_size on a square board", ErrInvalidExport)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	if len(in.Cells) != len(board.Cells) {
+		return fmt.Errorf("%w: board of %dx%d and depth %d needs %d cells, got %d",
+			ErrInvalidExport, board.Rows, board.Cols, board.layers(), len(board.Cells), len(in.Cells))
+	}
+	for i, cell := range in.Cells {
+		mark, ok := markFromJSON(cell)
+		if !ok || !markInPlay(mark, numPlayers) {
+			return fmt.Errorf("%w: unknown mark %q in cell %d", ErrInvalidExport, cell, i)
+		}
+		board.Cells[i] = mark
+	}
+
+	// Exports without moves predate move history and restore with none
+	var moves []Move
+	for i, move := range in.Moves {
+		mark, ok := markFromJSON(move.Mark)
+		if !ok || mark == MarkEmpty || !markInPlay(mark, numPlayers) {
+			return fmt.Errorf("%w: move %d mark must be a player's mark", ErrInvalidExport, i)
+		}
+		if !board.isValidCell(move.Row, move.Col, move.Layer) {
+			return fmt.Errorf("%w: move %d is off the board", ErrInvalidExport, i)
+		}
+		moves = append(moves, Move{Mark: mark, Row: move.Row, Col: move.Col, Layer: move.Layer, At: move.At})
+	}
+	thinkTime := make(map[Mark]time.Duration)
+	for i, move := range moves {
+		thinkTime[move.Mark] += moveThinkTime(in.StartedAt, moves, i)
+	}
+
+	mode, ok := modeFromString(in.Mode)
+	if !ok {
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidExport, in.Mode)
+	}
+	if board.IsCube() && mode == ModeGravity {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, ErrGravityOnCube)
+	}
+	status, ok := statusFromString(in.Status)
+	if !ok || (status == StatusTriangleWon && numPlayers != 3) {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidExport, in.Status)
+	}
+	turn, ok := markFromJSON(in.Turn)
+	if !ok || turn == MarkEmpty || !markInPlay(turn, numPlayers) {
+		return fmt.Errorf("%w: turn must be a player's mark", ErrInvalidExport)
+	}
+	drawOffer, ok := markFromJSON(in.DrawOffer)
+	if !ok {
+		return fmt.Errorf("%w: unknown draw offer %q", ErrInvalidExport, in.DrawOffer)
+	}
+	abandonedBy, ok := markFromJSON(in.AbandonedBy)
+	if !ok || !markInPlay(abandonedBy, numPlayers) || (abandonedBy != MarkEmpty) != (status == StatusAbandoned) {
+		return fmt.Errorf("%w: abandoned_by must name the player of an abandoned game", ErrInvalidExport)
+	}
+
+	if status == StatusReady && !in.ReadyCheck {
+		return fmt.Errorf("%w: a READY game needs ready_check", ErrInvalidExport)
+	}
+	var ready map[Mark]bool
+	for _, cell := range in.Ready {
+		mark, ok := markFromJSON(cell)
+		if !ok || mark == MarkEmpty || !markInPlay(mark, numPlayers) || status != StatusReady {
+			return fmt.Errorf("%w: ready must list player marks of a READY game", ErrInvalidExport)
+		}
+		if ready == nil {
+			ready = make(map[Mark]bool)
+		}
+		ready[mark] = true
+	}
+
+	// A game exported in progress resumes as its board stands, so one whose
+	// board already shows a result comes back finished
+	if status == StatusInProgress {
+		status = resumedStatus(board, numPlayers, in.Misere, in.EarlyDraw)
+	}
+	// A corrupted record may hold a board no game could reach
+	if err := board.Validate(turn, status); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.ID = in.ID
+	g.PlayerX = in.PlayerX
+	g.PlayerO = in.PlayerO
+	g.PlayerTriangle = in.PlayerTriangle
+	g.Creator = in.Creator
+	g.NumPlayers = numPlayers
+	g.Board = board
+	g.Mode = mode
+	g.Misere = in.Misere
+	g.Turn = turn
+	g.Status = status
+	g.DrawOffer = drawOffer
+	g.AbandonedBy = abandonedBy
+	g.PlayersOnlyChat = in.PlayersOnlyChat
+	g.EarlyDraw = in.EarlyDraw
+	g.Hints = in.Hints
+	g.ReadyCheck = in.ReadyCheck
+	g.ready = ready
+	g.RandomStart = in.RandomStart
+	g.FromPosition = in.FromPosition
+	g.Imported = true
+	g.Seed = in.Seed
+	g.rng = newRand(in.Seed, 0)
+	g.JoinCode = in.JoinCode
+	g.CreatedAt = in.CreatedAt
+	g.UpdatedAt = in.UpdatedAt
+	g.StartedAt = in.StartedAt
+	// Exports from before versions start the count afresh
+	g.Version = max(in.Version, 1)
+	g.moves = moves
+	g.thinkTime = thinkTime
+	g.resultRecorded = g.Status.IsFinished()
+	g.lastNonce = nil
+	return nil
+}
+
+// markToJSON returns the exported form of a mark
+func markToJSON(m Mark) string {
+	if m == MarkEmpty {
+		return ""
+	}
+	return m.String()
+}
+
+// markFromJSON parses a mark exported by markToJSON
+func markFromJSON(s string) (Mark, bool) {
+	switch s {
+	case "":
+		return MarkEmpty, true
+	case "X":
+		return MarkX, true
+	case "O":
+		return MarkO, true
+	case "△":
+		return MarkTriangle, true
+	default:
+		return MarkEmpty, false
+	}
+}
+
+// markInPlay reports whether mark can appear in a game of numPlayers players
+func markInPlay(mark Mark, numPlayers int) bool {
+	return mark != MarkTriangle || numPlayers == 3
+}
+
+// modeFromString parses a Mode's String form
+func modeFromString(s string) (Mode, bool) {
+	for _, mode := range []Mode{ModeClassic, ModeGravity} {
+		if mode.String() == s {
+			return mode, true
+		}
+	}
+	return ModeClassic, false
+}
+
+// statusFromString parses a Status's String form
+func statusFromString(s string) (Status, bool) {
+	for _, status := range []Status{StatusPending, StatusInProgress, StatusXWon, StatusOWon, StatusTriangleWon, StatusDraw, StatusAbandoned, StatusCancelled, StatusReady} {
+		if status.String() == s {
+			return status, true
+		}
+	}
+	return StatusPending, false
+}
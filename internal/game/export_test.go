@@ -0,0 +1,186 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGame_JSONRoundTrip(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 4, 3, WithMode(ModeGravity), WithMisere(), WithPlayersOnlyChat())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+	mustMove(t, g, "alice", 3, 1)
+	mustMove(t, g, "bob", 3, 2)
+	require.NoError(t, g.OfferDraw("alice"))
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+
+	want, got := g.GetSnapshot(), restored.GetSnapshot()
+	assert.Equal(t, want.Board, got.Board)
+	assert.True(t, want.CreatedAt.Equal(got.CreatedAt))
+	assert.True(t, want.UpdatedAt.Equal(got.UpdatedAt))
+	assert.True(t, want.StartedAt.Equal(got.StartedAt))
+	// Restored think times come from wall clock times, without the monotonic readings
+	for _, player := range []string{"alice", "bob"} {
+		assert.InDelta(t, want.ThinkTime(player), got.ThinkTime(player), float64(time.Millisecond))
+	}
+	want.CreatedAt, want.UpdatedAt, want.StartedAt = got.CreatedAt, got.UpdatedAt, got.StartedAt
+	want.thinkTime = got.thinkTime
+	assert.False(t, want.Imported)
+	assert.True(t, got.Imported)
+	want.Imported = true
+	assert.Equal(t, want, got)
+
+	wantMoves, gotMoves := g.Moves(), restored.Moves()
+	require.Len(t, gotMoves, 2)
+	for i := range wantMoves {
+		assert.True(t, wantMoves[i].At.Equal(gotMoves[i].At))
+		wantMoves[i].At, gotMoves[i].At = time.Time{}, time.Time{}
+	}
+	assert.Equal(t, wantMoves, gotMoves)
+
+	// The restored game plays on from where the export left off
+	snapshot := mustMove(t, &restored, "alice", 2, 1)
+	assert.Equal(t, MarkEmpty, snapshot.DrawOffer)
+	assert.Equal(t, MarkO, snapshot.Turn)
+}
+
+func TestGame_JSONRoundTrip_3D(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 3, 3, With3D())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+	mustMove(t, g, "alice", 1, 1, OnLayer(2))
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, g.GetSnapshot().Board, restored.GetSnapshot().Board)
+	mark, err := restored.GetSnapshot().Board.GetAt(1, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, MarkX, mark)
+}
+
+func TestGame_JSONRoundTrip_Rectangular(t *testing.T) {
+	g, err := NewRectGame("game-1", "alice", 3, 4, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+	mustMove(t, g, "alice", 2, 3)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, g.GetSnapshot().Board, restored.GetSnapshot().Board)
+	assert.Equal(t, 3, restored.GetSnapshot().Board.Rows)
+}
+
+func TestGame_MarshalJSON_Format(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+	mustMove(t, g, "alice", 0, 0)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "game-1", fields["id"])
+	assert.Equal(t, []any{"X", "", "", "", "", "", "", "", ""}, fields["cells"])
+	assert.Equal(t, "O", fields["turn"])
+	assert.Equal(t, "IN_PROGRESS", fields["status"])
+	assert.Equal(t, "CLASSIC", fields["mode"])
+}
+
+func TestGame_UnmarshalJSON_FinishedGameIsRecorded(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+	require.NoError(t, g.OfferDraw("alice"))
+	require.NoError(t, g.RespondDraw("bob", true))
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, StatusDraw, restored.GetStatus())
+	assert.False(t, restored.MarkResultRecorded())
+}
+
+func TestGame_UnmarshalJSON_StatusFromBoard(t *testing.T) {
+	tests := []struct {
+		name   string
+		cells  string
+		turn   string
+		misere bool
+		want   Status
+	}{
+		// X X X / O O . / . . .
+		{"three in a row", `"X","X","X","O","O","","","",""`, "O", false, StatusXWon},
+		{"three in a row in misère", `"X","X","X","O","O","","","",""`, "O", true, StatusOWon},
+		// X O X / X O O / O X .
+		{"almost full", `"X","O","X","X","O","O","O","X",""`, "X", false, StatusInProgress},
+		// X O X / X O O / O X X
+		{"full", `"X","O","X","X","O","O","O","X","X"`, "O", false, StatusDraw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := fmt.Sprintf(`{"id":"g","player_x":"alice","player_o":"bob","board_size":3,"win_length":3,"cells":[%s],"mode":"CLASSIC","misere":%t,"turn":%q,"status":"IN_PROGRESS"}`,
+				tt.cells, tt.misere, tt.turn)
+			var g Game
+			require.NoError(t, json.Unmarshal([]byte(data), &g))
+			assert.Equal(t, tt.want, g.GetStatus())
+
+			// A result read off the board is not counted as if it had been played
+			assert.False(t, g.MarkResultRecorded())
+		})
+	}
+}
+
+func TestGame_UnmarshalJSON_Invalid(t *testing.T) {
+	valid := `{"id":"g","board_size":3,"win_length":3,"cells":["","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`
+	var g Game
+	require.NoError(t, json.Unmarshal([]byte(valid), &g))
+
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"malformed", `{"id":`},
+		{"missing id", `{"board_size":3,"win_length":3,"cells":["","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"too few cells", `{"id":"g","board_size":3,"win_length":3,"cells":["","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"too many cells", `{"id":"g","board_size":3,"win_length":3,"cells":["","","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"bad board size", `{"id":"g","board_size":2,"win_length":3,"cells":["","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"bad mark", `{"id":"g","board_size":3,"win_length":3,"cells":["Z","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"bad mode", `{"id":"g","board_size":3,"win_length":3,"cells":["","","","","","","","",""],"mode":"SIDEWAYS","turn":"X","status":"PENDING"}`},
+		{"bad status", `{"id":"g","board_size":3,"win_length":3,"cells":["","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PAUSED"}`},
+		{"bad depth", `{"id":"g","board_size":3,"win_length":3,"depth":2,"cells":["","","","","","","","","","","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"flat cells for a cube", `{"id":"g","board_size":3,"win_length":3,"depth":3,"cells":["","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"rows larger than board size", `{"id":"g","board_size":3,"rows":4,"cols":3,"win_length":3,"cells":["","","","","","","","","","","",""],"mode":"CLASSIC","turn":"X","status":"PENDING"}`},
+		{"move off the board", `{"id":"g","board_size":3,"win_length":3,"cells":["X","","","","","","","",""],"mode":"CLASSIC","turn":"O","status":"IN_PROGRESS","moves":[{"mark":"X","row":3,"col":0}]}`},
+		{"move without a mark", `{"id":"g","board_size":3,"win_length":3,"cells":["X","","","","","","","",""],"mode":"CLASSIC","turn":"O","status":"IN_PROGRESS","moves":[{"mark":"","row":0,"col":0}]}`},
+		{"empty turn", `{"id":"g","board_size":3,"win_length":3,"cells":["","","","","","","","",""],"mode":"CLASSIC","turn":"","status":"PENDING"}`},
+		{"impossible mark counts", `{"id":"g","board_size":3,"win_length":3,"cells":["X","X","","X","","X","","","X"],"mode":"CLASSIC","turn":"O","status":"IN_PROGRESS"}`},
+		{"marks in a pending game", `{"id":"g","board_size":3,"win_length":3,"cells":["X","","","","","","","",""],"mode":"CLASSIC","turn":"O","status":"PENDING"}`},
+		{"line in a drawn game", `{"id":"g","board_size":3,"win_length":3,"cells":["X","X","X","O","O","","","",""],"mode":"CLASSIC","turn":"O","status":"DRAW"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g Game
+			assert.ErrorIs(t, g.UnmarshalJSON([]byte(tt.json)), ErrInvalidExport)
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package game
 
 import (
+	"crypto/subtle"
+	"maps"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
@@ -10,61 +13,850 @@ type Game struct {
 	mu sync.RWMutex
 
 	ID        string
-	PlayerX   string // First player (creator)
-	PlayerO   string // Second player (joiner)
+	PlayerX   string // Moves first; the creator unless they chose O or a random start drew O
+	PlayerO   string // Moves second; the joiner unless the creator chose O
 	Board     *Board
+	Mode      Mode
+	Misere    bool // Completing a line loses instead of wins
 	Turn      Mark
 	Status    Status
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// PlayerTriangle moves third in a three-player game (empty otherwise)
+	PlayerTriangle string
+
+	// Creator is the player who created the game, in whichever seat they hold
+	Creator string
+
+	// NumPlayers is 2, or 3 when the game has a PlayerTriangle seat
+	NumPlayers int
+
+	// DrawOffer is the mark of the player with an outstanding draw offer (MarkEmpty if none)
+	DrawOffer Mark
+
+	// PlayersOnlyChat restricts chat to the two players, excluding spectators
+	PlayersOnlyChat bool
+
+	// AbandonedBy is the mark of the player who abandoned the game (MarkEmpty if none)
+	AbandonedBy Mark
+
+	// JoinCode must be presented to join a private game (empty for public games)
+	JoinCode string
+
+	// EarlyDraw ends the game as a draw as soon as no player can complete a line
+	EarlyDraw bool
+
+	// Hints asks the server to suggest a move to the player on turn
+	Hints bool
+
+	// ReadyCheck holds a full game in StatusReady until every player calls Start
+	ReadyCheck bool
+
+	// RandomStart shuffles the seats when the last player joins, so the creator is not always X
+	RandomStart bool
+
+	// FromPosition marks a game set up from a position rather than an empty board
+	FromPosition bool
+
+	// Imported marks a game restored from an export rather than played here from its start
+	Imported bool
+
+	// Seed drives the game's random decisions, such as the random start, so
+	// games created with the same seed make the same ones
+	Seed uint64
+
+	// Version starts at 1 and goes up with every change to the game's state
+	Version int64
+
+	// StartedAt is when play began: the last join, or the last Start with a ready check (zero until then)
+	StartedAt time.Time
+
+	// moves lists every move played, in order
+	moves []Move
+
+	// thinkTime totals each player's think time over their moves
+	thinkTime map[Mark]time.Duration
+
+	// ready holds the marks of the players who have called Start
+	ready map[Mark]bool
+
+	// resultRecorded is set once the finished game's result has been counted in stats
+	resultRecorded bool
+
+	// lastNonce holds the last accepted move nonce per player
+	lastNonce map[string]uint64
+
+	// admission bounds the moves waiting on mu; nil means unlimited
+	admission chan struct{}
+
+	// maxMoves caps the moves played; 0 means one per cell
+	maxMoves int
+
+	// rng is the game's own source of randomness, seeded from Seed
+	rng *rand.Rand
+}
+
+// Move is one mark placed during a game
+type Move struct {
+	Mark  Mark
+	Row   int
+	Col   int
+	Layer int
+	At    time.Time
+}
+
+// Option configures optional game settings at creation time
+type Option func(*Game)
+
+// WithMode selects the placement rules for the game (classic by default)
+func WithMode(mode Mode) Option {
+	return func(g *Game) {
+		g.Mode = mode
+	}
+}
+
+// WithMisere makes completing a line of WinLength lose the game
+func WithMisere() Option {
+	return func(g *Game) {
+		g.Misere = true
+	}
+}
+
+// With3D plays on a size×size×size cube instead of a flat board. Moves name
+// their layer with OnLayer, and lines may run in any of the cube's 13
+// directions. Gravity mode is not supported on a cube.
+func With3D() Option {
+	return func(g *Game) {
+		g.Board, _ = NewCubeBoard(g.Board.Size, g.Board.WinLength)
+	}
+}
+
+// WithEarlyDraw declares a draw as soon as no line can be completed by any
+// player, instead of playing on until the board is full
+func WithEarlyDraw() Option {
+	return func(g *Game) {
+		g.EarlyDraw = true
+	}
+}
+
+// WithHints marks the game as one where the player on turn is offered a
+// suggested move. The game itself does not compute hints.
+func WithHints() Option {
+	return func(g *Game) {
+		g.Hints = true
+	}
+}
+
+// WithReadyCheck keeps the game in StatusReady once every seat is filled,
+// until each player has called Start, instead of starting it on the last join
+func WithReadyCheck() Option {
+	return func(g *Game) {
+		g.ReadyCheck = true
+	}
+}
+
+// WithRandomStart draws the players' marks, and so who moves first, at random
+// once every seat is filled instead of keeping the creator's mark
+func WithRandomStart() Option {
+	return func(g *Game) {
+		g.RandomStart = true
+	}
+}
+
+// WithSeed seeds the game's randomness, which is otherwise seeded at
+// random, so that a test or tournament can reproduce the game's random
+// decisions
+func WithSeed(seed uint64) Option {
+	return func(g *Game) {
+		g.Seed = seed
+	}
+}
+
+// WithPlayersOnlyChat restricts the game's chat to its two players
+func WithPlayersOnlyChat() Option {
+	return func(g *Game) {
+		g.PlayersOnlyChat = true
+	}
+}
+
+// WithPlayers sets the number of players: 2 (the default) or 3. A
+// three-player game adds MarkTriangle, moving after O, and starts once every
+// seat is filled. Misère mode, draw offers and forfeits need two players.
+func WithPlayers(n int) Option {
+	return func(g *Game) {
+		g.NumPlayers = n
+	}
+}
+
+// WithJoinCode makes the game private: it can only be joined by presenting
+// code with UsingJoinCode
+func WithJoinCode(code string) Option {
+	return func(g *Game) {
+		g.JoinCode = code
+	}
+}
+
+// WithMoveAdmissionLimit caps the number of move attempts that may be in flight
+// (holding or waiting for the game lock) at once. Attempts beyond the limit fail
+// fast with ErrTooManyMovesInFlight instead of queuing. Zero means unlimited.
+func WithMoveAdmissionLimit(limit int) Option {
+	return func(g *Game) {
+		if limit > 0 {
+			g.admission = make(chan struct{}, limit)
+		} else {
+			g.admission = nil
+		}
+	}
+}
+
+// WithMaxMoves caps the moves the game accepts at limit, below the one move
+// per cell no game can exceed anyway: a move reaching the cap without
+// winning ends the game as a draw. Zero means one move per cell.
+func WithMaxMoves(limit int) Option {
+	return func(g *Game) {
+		g.maxMoves = max(limit, 0)
+	}
+}
+
+// WithCreatorMark seats the creator as X (the default) or O. X always moves
+// first, so a creator playing O waits for the joiner's opening move.
+func WithCreatorMark(mark Mark) Option {
+	return func(g *Game) {
+		if mark == MarkO && g.PlayerO == "" {
+			g.PlayerX, g.PlayerO = "", g.PlayerX
+		}
+	}
 }
 
 // NewGame creates a new game with the specified configuration
-func NewGame(id, creatorID string, boardSize, winLength int) (*Game, error) {
-	board, err := NewBoard(boardSize, winLength)
+func NewGame(id, creatorID string, boardSize, winLength int, opts ...Option) (*Game, error) {
+	return NewRectGame(id, creatorID, boardSize, boardSize, winLength, opts...)
+}
+
+// NewRectGame creates a new game on a board of rows by cols cells. Lines
+// still run in all four directions, clipped to the board; a 3D game needs a
+// square board.
+func NewRectGame(id, creatorID string, rows, cols, winLength int, opts ...Option) (*Game, error) {
+	board, err := NewRectBoard(rows, cols, winLength)
 	if err != nil {
 		return nil, err
 	}
 
 	now := time.Now()
-	return &Game{
-		ID:        id,
-		PlayerX:   creatorID,
-		Board:     board,
-		Turn:      MarkX, // X always goes first
-		Status:    StatusPending,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}, nil
-}
-
-// Join adds a second player to the game
-func (g *Game) Join(playerID string) error {
+	g := &Game{
+		ID:         id,
+		PlayerX:    creatorID,
+		Creator:    creatorID,
+		NumPlayers: 2,
+		Board:      board,
+		Mode:       ModeClassic,
+		Turn:       MarkX, // X always goes first
+		Status:     StatusPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Version:    1,
+		Seed:       rand.Uint64(),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.rng = newRand(g.Seed, 0)
+	if g.Board.IsCube() && g.Mode == ModeGravity {
+		return nil, ErrGravityOnCube
+	}
+	if g.Board.IsCube() && rows != cols {
+		return nil, ErrCubeNotSquare
+	}
+	if g.NumPlayers != 2 && g.NumPlayers != 3 {
+		return nil, ErrInvalidPlayerCount
+	}
+	if g.NumPlayers != 2 && g.Misere {
+		return nil, ErrMisereMultiplayer
+	}
+	return g, nil
+}
+
+// JoinOption configures optional checks for joining a game
+type JoinOption func(*joinConfig)
+
+type joinConfig struct {
+	joinCode  string
+	normalize func(string) string
+}
+
+// UsingJoinCode presents the code needed to join a private game
+func UsingJoinCode(code string) JoinOption {
+	return func(c *joinConfig) {
+		c.joinCode = code
+	}
+}
+
+// ComparingIDs makes Join treat a player whose ID normalizes to the same
+// value as a seated player's as that player, so one user cannot take two
+// seats under IDs that differ only in, say, case or surrounding whitespace
+func ComparingIDs(normalize func(string) string) JoinOption {
+	return func(c *joinConfig) {
+		c.normalize = normalize
+	}
+}
+
+// Join seats a player in the first open seat in turn order. The game starts
+// once every seat is filled, or with a ready check waits in StatusReady for
+// the players to start it; with a random start the seats are shuffled first.
+// A private game also needs its join code (see UsingJoinCode).
+func (g *Game) Join(playerID string, opts ...JoinOption) error {
+	cfg := joinConfig{normalize: func(id string) string { return id }}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	if g.Status != StatusPending {
 		return ErrGameAlreadyStarted
 	}
-	if g.PlayerX == playerID {
-		return ErrCannotJoinOwnGame
+	joiner := cfg.normalize(playerID)
+	for _, seat := range g.seats() {
+		if seat != "" && cfg.normalize(seat) == joiner {
+			return ErrCannotJoinOwnGame
+		}
+	}
+	if g.JoinCode != "" && subtle.ConstantTimeCompare([]byte(cfg.joinCode), []byte(g.JoinCode)) != 1 {
+		return ErrWrongJoinCode
 	}
 
-	g.PlayerO = playerID
-	g.Status = StatusInProgress
-	g.UpdatedAt = time.Now()
+	switch {
+	case g.PlayerX == "":
+		g.PlayerX = playerID
+	case g.PlayerO == "":
+		g.PlayerO = playerID
+	default:
+		g.PlayerTriangle = playerID
+	}
+	open := 0
+	for _, seat := range g.seats() {
+		if seat == "" {
+			open++
+		}
+	}
+	if open == 0 && g.RandomStart {
+		players := g.seats()
+		g.rng.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+		g.PlayerX, g.PlayerO = players[0], players[1]
+		if g.NumPlayers == 3 {
+			g.PlayerTriangle = players[2]
+		}
+	}
+	g.touch()
+	switch {
+	case open > 0:
+	case g.ReadyCheck:
+		g.Status = StatusReady
+	default:
+		g.begin()
+	}
+	return nil
+}
+
+// Start records that playerID is ready to play a game in StatusReady. The
+// game starts once every player has called it; calling it again before then
+// changes nothing.
+func (g *Game) Start(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != StatusReady {
+		return ErrGameNotReady
+	}
+	mark := g.getPlayerMark(playerID)
+	if mark == MarkEmpty {
+		return ErrPlayerNotInGame
+	}
+
+	if g.ready == nil {
+		g.ready = make(map[Mark]bool)
+	}
+	g.ready[mark] = true
+	g.touch()
+	if len(g.ready) == g.NumPlayers {
+		g.begin()
+	}
+	return nil
+}
+
+// begin starts a game whose players are all in place (must hold g.mu). A
+// game set up from a decided position ends at once with its result.
+func (g *Game) begin() {
+	g.recomputeStatus()
+	g.StartedAt = g.UpdatedAt
+}
+
+// MoveOption configures optional checks for a single move
+type MoveOption func(*moveConfig)
+
+type moveConfig struct {
+	nonce uint64
+	layer int
+
+	// expectedMoves is checked against the move count when checkMoves is set
+	expectedMoves int
+	checkMoves    bool
+}
+
+// WithNonce attaches a replay-protection nonce to a move. Once a player has
+// used nonces in a game, each move must carry a strictly larger one.
+// A zero nonce means none was supplied, which is rejected as stale once the
+// player has used nonces.
+func WithNonce(nonce uint64) MoveOption {
+	return func(c *moveConfig) {
+		c.nonce = nonce
+	}
+}
+
+// ExpectMoveCount rejects the move with ErrMoveCountMismatch unless exactly
+// count moves have been played, so a client acting on a stale view of the
+// game, such as a second tab, cannot move twice
+func ExpectMoveCount(count int) MoveOption {
+	return func(c *moveConfig) {
+		c.expectedMoves = count
+		c.checkMoves = true
+	}
+}
+
+// OnLayer places a move on the given layer of a 3D game. Flat boards only
+// have layer 0.
+func OnLayer(layer int) MoveOption {
+	return func(c *moveConfig) {
+		c.layer = layer
+	}
+}
+
+// MakeMove attempts to place a mark at the given position.
+// In gravity mode the position must be the lowest empty cell of its column.
+// The returned snapshot is taken under the same lock as the move, so it
+// reflects exactly this move and the outcome it produced.
+func (g *Game) MakeMove(playerID string, row, col int, opts ...MoveOption) (GameSnapshot, error) {
+	if !g.admit() {
+		return GameSnapshot{}, ErrTooManyMovesInFlight
+	}
+	defer g.release()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cfg := newMoveConfig(opts)
+	if err := g.checkMove(playerID, row, col, cfg); err != nil {
+		return GameSnapshot{}, err
+	}
+
+	if err := g.place(playerID, row, col, cfg); err != nil {
+		return GameSnapshot{}, err
+	}
+	return g.snapshot(), nil
+}
+
+// DropMove drops the player's mark into a column of a gravity game and
+// returns the row it landed on along with the post-move snapshot
+func (g *Game) DropMove(playerID string, col int, opts ...MoveOption) (int, GameSnapshot, error) {
+	if !g.admit() {
+		return 0, GameSnapshot{}, ErrTooManyMovesInFlight
+	}
+	defer g.release()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Mode != ModeGravity {
+		return 0, GameSnapshot{}, ErrNotGravityGame
+	}
+
+	// Turn and state checks come first so a full column is only reported to the player to move
+	cfg := newMoveConfig(opts)
+	if err := g.checkCanMove(playerID, cfg); err != nil {
+		return 0, GameSnapshot{}, err
+	}
+
+	row, err := g.Board.DropColumn(col)
+	if err != nil {
+		return 0, GameSnapshot{}, err
+	}
+
+	if err := g.place(playerID, row, col, cfg); err != nil {
+		return 0, GameSnapshot{}, err
+	}
+	return row, g.snapshot(), nil
+}
+
+// PreviewMove returns the snapshot MakeMove would return for the move, after
+// the same checks and with the same errors, without changing the game: the
+// move is played on a clone of the board. The preview keeps the game's
+// version and timestamps, so it is never mistaken for a newer state.
+func (g *Game) PreviewMove(playerID string, row, col int, opts ...MoveOption) (GameSnapshot, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	cfg := newMoveConfig(opts)
+	if err := g.checkMove(playerID, row, col, cfg); err != nil {
+		return GameSnapshot{}, err
+	}
+
+	preview := g.snapshot()
+	mark := g.getPlayerMark(playerID)
+	if err := preview.Board.SetAt(row, col, cfg.layer, mark); err != nil {
+		return GameSnapshot{}, err
+	}
+	preview.MoveCount++
+	if preview.DrawOffer == mark {
+		preview.DrawOffer = MarkEmpty
+	}
+	preview.Status = g.resultAfter(preview.Board, row, col, cfg.layer, preview.MoveCount)
+	if preview.Status == StatusInProgress {
+		preview.Turn = preview.Turn.Next(g.NumPlayers)
+	} else {
+		preview.DrawOffer = MarkEmpty
+	}
+	return preview, nil
+}
+
+// CheckMove reports whether MakeMove would accept the move, returning the
+// error it would fail with, without changing the game. Only the in-flight
+// move limit of MakeMove is not checked.
+func (g *Game) CheckMove(playerID string, row, col int, opts ...MoveOption) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.checkMove(playerID, row, col, newMoveConfig(opts))
+}
+
+// AvailableMoves returns the positions the player to move may mark: every
+// empty cell, or in gravity mode the landing cell of each open column.
+// Games that are not in progress have no available moves.
+func (g *Game) AvailableMoves() []Position {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.Status != StatusInProgress {
+		return nil
+	}
+	if g.Mode == ModeGravity {
+		return g.Board.landingCells()
+	}
+	return g.Board.EmptyCells()
+}
+
+// OfferDraw records the player's offer to end the game in a draw. The offer
+// stands until the opponent responds or the offering player moves.
+func (g *Game) OfferDraw(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != StatusInProgress {
+		return ErrGameNotInProgress
+	}
+	mark := g.getPlayerMark(playerID)
+	if mark == MarkEmpty {
+		return ErrPlayerNotInGame
+	}
+	if g.NumPlayers != 2 {
+		return ErrTwoPlayerOnly
+	}
+	if g.DrawOffer != MarkEmpty {
+		return ErrDrawOfferPending
+	}
+
+	g.DrawOffer = mark
+	g.touch()
 	return nil
 }
 
-// MakeMove attempts to place a mark at the given position
-func (g *Game) MakeMove(playerID string, row, col int) error {
+// RespondDraw accepts or declines the opponent's draw offer. Accepting ends
+// the game in a draw; either way the offer is cleared.
+func (g *Game) RespondDraw(playerID string, accept bool) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if g.Status != StatusInProgress {
+		return ErrGameNotInProgress
+	}
+	mark := g.getPlayerMark(playerID)
+	if mark == MarkEmpty {
+		return ErrPlayerNotInGame
+	}
+	if g.NumPlayers != 2 {
+		return ErrTwoPlayerOnly
+	}
+	if g.DrawOffer != mark.Opponent() {
+		return ErrNoDrawOffer
+	}
+
+	g.DrawOffer = MarkEmpty
+	if accept {
+		g.Status = StatusDraw
+	}
+	g.touch()
+	return nil
+}
+
+// Abandon ends an in-progress game because the player left it. The game has
+// no winner; AbandonedBy records who left.
+func (g *Game) Abandon(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != StatusInProgress {
+		return ErrGameNotInProgress
+	}
+	mark := g.getPlayerMark(playerID)
+	if mark == MarkEmpty {
+		return ErrPlayerNotInGame
+	}
+
+	g.Status = StatusAbandoned
+	g.AbandonedBy = mark
+	g.DrawOffer = MarkEmpty
+	g.touch()
+	return nil
+}
+
+// Leave takes playerID out of the game. The creator leaving a pending or
+// ready game cancels it, while anyone else only gives up their seat, which
+// sends a ready game back to pending. An in-progress two-player game is
+// forfeited, so the opponent wins. A game without a Creator, restored from an
+// older export, is cancelled by any of its players.
+func (g *Game) Leave(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	mark := g.getPlayerMark(playerID)
+	switch g.Status {
+	case StatusPending, StatusReady:
+		if mark == MarkEmpty {
+			return ErrPlayerNotInGame
+		}
+		if g.Creator == "" || playerID == g.Creator {
+			g.Status = StatusCancelled
+			break
+		}
+		switch mark {
+		case MarkX:
+			g.PlayerX = ""
+		case MarkO:
+			g.PlayerO = ""
+		default:
+			g.PlayerTriangle = ""
+		}
+		g.Status = StatusPending
+		g.ready = nil
+	case StatusInProgress:
+		if mark == MarkEmpty {
+			return ErrPlayerNotInGame
+		}
+		if g.NumPlayers != 2 {
+			return ErrTwoPlayerOnly
+		}
+		if mark == MarkX {
+			g.Status = StatusOWon
+		} else {
+			g.Status = StatusXWon
+		}
+		g.DrawOffer = MarkEmpty
+	default:
+		return ErrGameNotInProgress
+	}
+
+	g.touch()
+	return nil
+}
+
+// Expire cancels a pending or ready game that has not changed since before
+// cutoff and reports whether it did. The check and the change happen under
+// the game lock, so a game cannot expire after a join or Start has started it.
+func (g *Game) Expire(cutoff time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if (g.Status != StatusPending && g.Status != StatusReady) || !g.UpdatedAt.Before(cutoff) {
+		return false
+	}
+	g.Status = StatusCancelled
+	g.touch()
+	return true
+}
+
+// TimeOut abandons an in-progress game that started before cutoff, blaming
+// the player on turn, and reports whether it did. It ends games stuck by a
+// bug or by a player who never moves. A ready game created before cutoff is
+// abandoned too, blaming the first player in turn order who has not called
+// Start.
+func (g *Game) TimeOut(cutoff time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// Ready games, and games imported from exports without started_at,
+	// count from creation
+	start := g.StartedAt
+	if start.IsZero() {
+		start = g.CreatedAt
+	}
+	if !start.Before(cutoff) {
+		return false
+	}
+	switch g.Status {
+	case StatusInProgress:
+		g.AbandonedBy = g.Turn
+	case StatusReady:
+		for _, mark := range seatMarks[:g.NumPlayers] {
+			if !g.ready[mark] {
+				g.AbandonedBy = mark
+				break
+			}
+		}
+	default:
+		return false
+	}
+	g.Status = StatusAbandoned
+	g.DrawOffer = MarkEmpty
+	g.touch()
+	return true
+}
+
+// RecomputeStatus derives the game's status from its board alone and
+// returns it: a completed line wins for its owner (loses in misère), a full
+// board is a draw, as is with early draws one nobody can complete a line on,
+// and anything else is in progress. It keeps a board set up outside play
+// consistent with its status. Statuses the board cannot show, such as
+// pending, abandoned or a draw by agreement, are replaced, so only call it
+// on a game whose board tells the whole story.
+func (g *Game) RecomputeStatus() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.recomputeStatus() {
+		g.touch()
+	}
+	return g.Status
+}
+
+// recomputeStatus does the work of RecomputeStatus without touching the
+// game, reporting whether the status changed (must hold g.mu)
+func (g *Game) recomputeStatus() bool {
+	status := g.statusFromBoard()
+	if status == g.Status {
+		return false
+	}
+	g.Status = status
+	if status.IsFinished() {
+		g.DrawOffer = MarkEmpty
+	}
+	g.AbandonedBy = MarkEmpty
+	return true
+}
+
+// resumedStatus returns the status RecomputeStatus gives a game in progress
+// on board, for checking a board set up outside play before any game has it
+func resumedStatus(board *Board, numPlayers int, misere, earlyDraw bool) Status {
+	g := &Game{Board: board, NumPlayers: numPlayers, Misere: misere, EarlyDraw: earlyDraw, Status: StatusInProgress}
+	return g.RecomputeStatus()
+}
+
+// statusFromBoard returns the status the board shows (must hold g.mu); see
+// RecomputeStatus
+func (g *Game) statusFromBoard() Status {
+	for layer := 0; layer < g.Board.layers(); layer++ {
+		for row := 0; row < g.Board.Rows; row++ {
+			for col := 0; col < g.Board.Cols; col++ {
+				winner := g.Board.CheckWinnerAt(row, col, layer)
+				if winner == MarkEmpty {
+					continue
+				}
+				if g.Misere {
+					winner = winner.Opponent()
+				}
+				return wonBy(winner)
+			}
+		}
+	}
+	if g.Board.IsFull() || (g.EarlyDraw && !g.winPossible(g.Board)) {
+		return StatusDraw
+	}
+	return StatusInProgress
+}
+
+// MarkResultRecorded claims the right to record this game's result. It
+// returns true exactly once, and only after the game has finished, so a
+// result is never counted twice however many callers observe the ending.
+func (g *Game) MarkResultRecorded() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.Status.IsFinished() || g.resultRecorded {
+		return false
+	}
+	g.resultRecorded = true
+	return true
+}
+
+// MoveCount returns the number of moves played so far
+func (g *Game) MoveCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.moves)
+}
+
+// Moves returns the moves played so far, oldest first
+func (g *Game) Moves() []Move {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	moves := make([]Move, len(g.moves))
+	copy(moves, g.moves)
+	return moves
+}
+
+// admit reserves an in-flight move slot without blocking
+func (g *Game) admit() bool {
+	if g.admission == nil {
+		return true
+	}
+	select {
+	case g.admission <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot reserved by admit
+func (g *Game) release() {
+	if g.admission != nil {
+		<-g.admission
+	}
+}
+
+// newMoveConfig applies move options
+func newMoveConfig(opts []MoveOption) moveConfig {
+	var cfg moveConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// checkCanMove validates that the player may move now (must hold g.mu)
+func (g *Game) checkCanMove(playerID string, cfg moveConfig) error {
 	// Validate game state
 	if g.Status != StatusInProgress {
 		return ErrGameNotInProgress
 	}
+	limit := len(g.Board.Cells)
+	if g.maxMoves > 0 {
+		limit = min(limit, g.maxMoves)
+	}
+	if len(g.moves) >= limit {
+		return ErrMoveLimitReached
+	}
 
 	// Validate player
 	playerMark := g.getPlayerMark(playerID)
@@ -72,47 +864,175 @@ func (g *Game) MakeMove(playerID string, row, col int) error {
 		return ErrPlayerNotInGame
 	}
 
+	// Reject replayed or reordered requests before anything else about the
+	// move, including ones without a nonce from a player who has used them
+	if last := g.lastNonce[playerID]; last != 0 && cfg.nonce <= last {
+		return ErrStaleNonce
+	}
+
+	// A stale view is reported as such rather than as the turn it implies
+	if cfg.checkMoves && cfg.expectedMoves != len(g.moves) {
+		return ErrMoveCountMismatch
+	}
+
 	// Validate turn
 	if g.Turn != playerMark {
 		return ErrNotYourTurn
 	}
 
+	return nil
+}
+
+// checkMove validates a move at (row, col) in the order MakeMove applies
+// it: state, player and turn, then the landing cell in gravity mode, then
+// bounds and occupancy (must hold g.mu)
+func (g *Game) checkMove(playerID string, row, col int, cfg moveConfig) error {
+	if err := g.checkCanMove(playerID, cfg); err != nil {
+		return err
+	}
+	if g.Mode == ModeGravity {
+		if err := g.checkLanding(row, col); err != nil {
+			return err
+		}
+	}
+	mark, err := g.Board.GetAt(row, col, cfg.layer)
+	if err != nil {
+		return err
+	}
+	if mark != MarkEmpty {
+		return ErrCellOccupied
+	}
+	return nil
+}
+
+// checkLanding validates that (row, col) is where a mark dropped into col would land
+func (g *Game) checkLanding(row, col int) error {
+	if !g.Board.isValidPosition(row, col) {
+		return ErrInvalidPosition
+	}
+	if g.Board.Cells[row*g.Board.Cols+col] != MarkEmpty {
+		return ErrCellOccupied
+	}
+	if landing, _ := g.Board.DropColumn(col); row != landing {
+		return ErrNotLowestEmptyRow
+	}
+	return nil
+}
+
+// place applies a validated move, then updates status and turn (must hold g.mu)
+func (g *Game) place(playerID string, row, col int, cfg moveConfig) error {
+	playerMark := g.getPlayerMark(playerID)
+
 	// Make the move
-	if err := g.Board.Set(row, col, playerMark); err != nil {
+	if err := g.Board.SetAt(row, col, cfg.layer, playerMark); err != nil {
 		return err
 	}
 
-	g.UpdatedAt = time.Now()
+	// Moving withdraws the mover's own draw offer
+	if g.DrawOffer == playerMark {
+		g.DrawOffer = MarkEmpty
+	}
 
-	// Check for winner
-	winner := g.Board.CheckWinner(row, col)
-	if winner != MarkEmpty {
-		if winner == MarkX {
-			g.Status = StatusXWon
-		} else {
-			g.Status = StatusOWon
+	if cfg.nonce != 0 {
+		if g.lastNonce == nil {
+			g.lastNonce = make(map[string]uint64)
 		}
-		return nil
+		g.lastNonce[playerID] = cfg.nonce
 	}
 
-	// Check for draw
-	if g.Board.IsFull() {
-		g.Status = StatusDraw
+	g.touch()
+	g.moves = append(g.moves, Move{Mark: playerMark, Row: row, Col: col, Layer: cfg.layer, At: g.UpdatedAt})
+	if g.thinkTime == nil {
+		g.thinkTime = make(map[Mark]time.Duration)
+	}
+	g.thinkTime[playerMark] += moveThinkTime(g.StartedAt, g.moves, len(g.moves)-1)
+
+	if status := g.resultAfter(g.Board, row, col, cfg.layer, len(g.moves)); status != StatusInProgress {
+		g.Status = status
+		g.DrawOffer = MarkEmpty
 		return nil
 	}
 
-	// Switch turn
-	g.Turn = g.Turn.Opponent()
+	// Pass the turn on
+	g.Turn = g.Turn.Next(g.NumPlayers)
 	return nil
 }
 
+// resultAfter returns the status of the game once a move at (row, col, layer)
+// is on board as the moves-th move: won if the move completed a line, drawn
+// if the board is full, the move reached the move cap or with early draws
+// nobody can win on it, and otherwise still in progress (must hold g.mu)
+func (g *Game) resultAfter(board *Board, row, col, layer, moves int) Status {
+	// In misère games the player who completed the line loses
+	winner := board.CheckWinnerAt(row, col, layer)
+	if winner != MarkEmpty && g.Misere {
+		winner = winner.Opponent()
+	}
+	if winner != MarkEmpty {
+		return wonBy(winner)
+	}
+	if board.IsFull() || (g.maxMoves > 0 && moves >= g.maxMoves) || (g.EarlyDraw && !g.winPossible(board)) {
+		return StatusDraw
+	}
+	return StatusInProgress
+}
+
+// winPossible reports whether any player could still complete a line on
+// board (must hold g.mu)
+func (g *Game) winPossible(board *Board) bool {
+	marks := []Mark{MarkX, MarkO}
+	if g.NumPlayers == 3 {
+		marks = append(marks, MarkTriangle)
+	}
+	for _, mark := range marks {
+		if board.WinPossible(mark) {
+			return true
+		}
+	}
+	return false
+}
+
+// seatMarks are the marks of the seats, in the order of seats and Players
+var seatMarks = []Mark{MarkX, MarkO, MarkTriangle}
+
+// touch records a change to the game's state (must hold g.mu)
+func (g *Game) touch() {
+	g.UpdatedAt = time.Now()
+	g.Version++
+}
+
+// seats lists the player in each seat in turn order, "" for an open seat (must hold g.mu)
+func (g *Game) seats() []string {
+	if g.NumPlayers == 3 {
+		return []string{g.PlayerX, g.PlayerO, g.PlayerTriangle}
+	}
+	return []string{g.PlayerX, g.PlayerO}
+}
+
+// readyPlayers lists the players who have called Start, in turn order (must hold g.mu)
+func (g *Game) readyPlayers() []string {
+	var players []string
+	for i, player := range g.seats() {
+		if g.ready[seatMarks[i]] {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
 // getPlayerMark returns the mark for the given player ID
 func (g *Game) getPlayerMark(playerID string) Mark {
+	// An empty ID must not match the open seat of a pending game
+	if playerID == "" {
+		return MarkEmpty
+	}
 	switch playerID {
 	case g.PlayerX:
 		return MarkX
 	case g.PlayerO:
 		return MarkO
+	case g.PlayerTriangle:
+		return MarkTriangle
 	default:
 		return MarkEmpty
 	}
@@ -132,33 +1052,97 @@ func (g *Game) GetStatus() Status {
 	return g.Status
 }
 
+// GetVersion returns the current game version (thread-safe)
+func (g *Game) GetVersion() int64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Version
+}
+
 // GetSnapshot returns a snapshot of the game state
 func (g *Game) GetSnapshot() GameSnapshot {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
+	return g.snapshot()
+}
 
+// snapshot copies the game state (must hold g.mu)
+func (g *Game) snapshot() GameSnapshot {
 	return GameSnapshot{
-		ID:        g.ID,
-		PlayerX:   g.PlayerX,
-		PlayerO:   g.PlayerO,
-		Board:     g.Board.Clone(),
-		Turn:      g.Turn,
-		Status:    g.Status,
-		CreatedAt: g.CreatedAt,
-		UpdatedAt: g.UpdatedAt,
+		ID:              g.ID,
+		PlayerX:         g.PlayerX,
+		PlayerO:         g.PlayerO,
+		PlayerTriangle:  g.PlayerTriangle,
+		NumPlayers:      g.NumPlayers,
+		Board:           g.Board.Clone(),
+		Mode:            g.Mode,
+		Misere:          g.Misere,
+		EarlyDraw:       g.EarlyDraw,
+		Hints:           g.Hints,
+		ReadyCheck:      g.ReadyCheck,
+		RandomStart:     g.RandomStart,
+		FromPosition:    g.FromPosition,
+		Imported:        g.Imported,
+		Seed:            g.Seed,
+		ReadyPlayers:    g.readyPlayers(),
+		MoveCount:       len(g.moves),
+		thinkTime:       maps.Clone(g.thinkTime),
+		DrawOffer:       g.DrawOffer,
+		PlayersOnlyChat: g.PlayersOnlyChat,
+		AbandonedBy:     g.AbandonedBy,
+		Private:         g.JoinCode != "",
+		Turn:            g.Turn,
+		Status:          g.Status,
+		CreatedAt:       g.CreatedAt,
+		UpdatedAt:       g.UpdatedAt,
+		StartedAt:       g.StartedAt,
+		Version:         g.Version,
 	}
 }
 
 // GameSnapshot is an immutable snapshot of game state
 type GameSnapshot struct {
-	ID        string
-	PlayerX   string
-	PlayerO   string
-	Board     *Board
-	Turn      Mark
-	Status    Status
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              string
+	PlayerX         string
+	PlayerO         string
+	PlayerTriangle  string
+	NumPlayers      int
+	Board           *Board
+	Mode            Mode
+	Misere          bool
+	EarlyDraw       bool
+	Hints           bool
+	ReadyCheck      bool
+	ReadyPlayers    []string // Players who have called Start, in turn order
+	RandomStart     bool
+	FromPosition    bool // Set up from a position rather than an empty board
+	Imported        bool // Restored from an export
+	Seed            uint64
+	MoveCount       int // Moves played so far
+	DrawOffer       Mark
+	PlayersOnlyChat bool
+	AbandonedBy     Mark
+	Private         bool // Joining needs the game's JoinCode
+	Turn            Mark
+	Status          Status
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	StartedAt       time.Time // Zero until play begins
+	Version         int64
+
+	thinkTime map[Mark]time.Duration
+}
+
+// Rand returns a source of randomness for decisions about this position,
+// derived from the game's seed and move count, so the same moves in games
+// with the same seed lead to the same decisions
+func (s *GameSnapshot) Rand() *rand.Rand {
+	return newRand(s.Seed, uint64(s.MoveCount)+1)
+}
+
+// newRand returns the stream of random numbers numbered stream of a seed
+func newRand(seed, stream uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, stream))
 }
 
 // GetWinner returns the winner's player ID, or empty string if no winner
@@ -168,13 +1152,19 @@ func (s *GameSnapshot) GetWinner() string {
 		return s.PlayerX
 	case StatusOWon:
 		return s.PlayerO
+	case StatusTriangleWon:
+		return s.PlayerTriangle
 	default:
 		return ""
 	}
 }
 
-// GetLoser returns the loser's player ID, or empty string if no loser
+// GetLoser returns the loser's player ID in a two-player game, or empty
+// string if no loser; see GetLosers for three-player games
 func (s *GameSnapshot) GetLoser() string {
+	if s.NumPlayers == 3 {
+		return ""
+	}
 	switch s.Status {
 	case StatusXWon:
 		return s.PlayerO
@@ -185,6 +1175,87 @@ func (s *GameSnapshot) GetLoser() string {
 	}
 }
 
+// GetLosers returns every player but the winner, in turn order, or nil if
+// the game has no winner
+func (s *GameSnapshot) GetLosers() []string {
+	winner := s.GetWinner()
+	if winner == "" {
+		return nil
+	}
+	var losers []string
+	for _, player := range s.Players() {
+		if player != winner {
+			losers = append(losers, player)
+		}
+	}
+	return losers
+}
+
+// Players lists the player in each seat in turn order (X, O, then the
+// triangle in a three-player game), "" for a seat still open
+func (s *GameSnapshot) Players() []string {
+	if s.NumPlayers == 3 {
+		return []string{s.PlayerX, s.PlayerO, s.PlayerTriangle}
+	}
+	return []string{s.PlayerX, s.PlayerO}
+}
+
+// PlayerOnTurn returns the ID of the player whose turn it is, or empty
+// string unless the game is in progress
+func (s *GameSnapshot) PlayerOnTurn() string {
+	if s.Status != StatusInProgress {
+		return ""
+	}
+	for i, player := range s.Players() {
+		if seatMarks[i] == s.Turn {
+			return player
+		}
+	}
+	return ""
+}
+
+// GetAbandoner returns the ID of the player who abandoned the game, or empty string
+func (s *GameSnapshot) GetAbandoner() string {
+	switch {
+	case s.Status != StatusAbandoned:
+		return ""
+	case s.AbandonedBy == MarkX:
+		return s.PlayerX
+	case s.AbandonedBy == MarkO:
+		return s.PlayerO
+	case s.AbandonedBy == MarkTriangle:
+		return s.PlayerTriangle
+	default:
+		return ""
+	}
+}
+
+// ThinkTime returns a player's total think time so far: the time from the
+// previous move, or from the start of the game for the opening move, to each
+// of their moves. It is zero for anyone not seated in the game.
+func (s *GameSnapshot) ThinkTime(playerID string) time.Duration {
+	for i, player := range s.Players() {
+		if player != "" && player == playerID {
+			return s.thinkTime[seatMarks[i]]
+		}
+	}
+	return 0
+}
+
+// moveThinkTime returns how long the player of moves[i] thought about it,
+// counting the opening move from startedAt. It is zero when the start is
+// unknown, as for games imported from exports without started_at.
+func moveThinkTime(startedAt time.Time, moves []Move, i int) time.Duration {
+	start := startedAt
+	if i > 0 {
+		start = moves[i-1].At
+	}
+	if start.IsZero() {
+		return 0
+	}
+	return max(moves[i].At.Sub(start), 0)
+}
+
 // IsDraw returns true if the game ended in a draw
 func (s *GameSnapshot) IsDraw() bool {
 	return s.Status == StatusDraw
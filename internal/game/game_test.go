@@ -1,7 +1,11 @@
 package game
 
 import (
+	"encoding/json"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,13 +47,64 @@ func TestGame_Join_CannotJoinOwnGame(t *testing.T) {
 	assert.ErrorIs(t, err, ErrCannotJoinOwnGame)
 }
 
+func TestGame_Join_ComparingIDs(t *testing.T) {
+	fold := ComparingIDs(func(id string) string { return strings.ToLower(strings.TrimSpace(id)) })
+
+	// Without normalization look-alike IDs are different players
+	g, err := NewGame("game-1", "Alice", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join(" alice"))
+
+	for _, id := range []string{"Alice", "alice", " ALICE ", "\talice\n"} {
+		g, err = NewGame("game-2", "Alice", 3, 3)
+		require.NoError(t, err)
+		assert.ErrorIs(t, g.Join(id, fold), ErrCannotJoinOwnGame, "%q", id)
+		assert.Equal(t, StatusPending, g.GetStatus())
+	}
+
+	// The creator's seat is checked wherever it is, and other players still join
+	g, err = NewGame("game-3", "Alice", 3, 3, WithCreatorMark(MarkO))
+	require.NoError(t, err)
+	assert.ErrorIs(t, g.Join("alice ", fold), ErrCannotJoinOwnGame)
+	require.NoError(t, g.Join("bob", fold))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, "bob", snapshot.PlayerX)
+	assert.Equal(t, "Alice", snapshot.PlayerO)
+
+	// Every seat of a three-player game is checked
+	g, err = NewGame("game-4", "alice", 3, 3, WithPlayers(3))
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob", fold))
+	assert.ErrorIs(t, g.Join("BOB", fold), ErrCannotJoinOwnGame)
+	assert.ErrorIs(t, g.Join("Alice", fold), ErrCannotJoinOwnGame)
+}
+
+func TestGame_Join_Private(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithJoinCode("ABCD2345"))
+	require.NoError(t, err)
+	assert.True(t, g.GetSnapshot().Private)
+
+	assert.ErrorIs(t, g.Join("player-2"), ErrWrongJoinCode)
+	assert.ErrorIs(t, g.Join("player-2", UsingJoinCode("ABCD2346")), ErrWrongJoinCode)
+	assert.Equal(t, StatusPending, g.GetStatus())
+
+	require.NoError(t, g.Join("player-2", UsingJoinCode("ABCD2345")))
+	assert.Equal(t, "player-2", g.PlayerO)
+
+	// Public games ignore any code presented
+	public, err := NewGame("game-2", "player-1", 3, 3)
+	require.NoError(t, err)
+	assert.False(t, public.GetSnapshot().Private)
+	require.NoError(t, public.Join("player-2", UsingJoinCode("ABCD2345")))
+}
+
 func TestGame_MakeMove(t *testing.T) {
 	g, err := NewGame("game-1", "player-1", 3, 3)
 	require.NoError(t, err)
 	g.Join("player-2")
 
 	// Player X makes a move
-	err = g.MakeMove("player-1", 0, 0)
+	_, err = g.MakeMove("player-1", 0, 0)
 	require.NoError(t, err)
 
 	mark, _ := g.Board.Get(0, 0)
@@ -57,7 +112,7 @@ func TestGame_MakeMove(t *testing.T) {
 	assert.Equal(t, MarkO, g.Turn)
 
 	// Player O makes a move
-	err = g.MakeMove("player-2", 1, 1)
+	_, err = g.MakeMove("player-2", 1, 1)
 	require.NoError(t, err)
 
 	mark, _ = g.Board.Get(1, 1)
@@ -71,7 +126,7 @@ func TestGame_MakeMove_NotYourTurn(t *testing.T) {
 	g.Join("player-2")
 
 	// Player O tries to move first
-	err = g.MakeMove("player-2", 0, 0)
+	_, err = g.MakeMove("player-2", 0, 0)
 	assert.ErrorIs(t, err, ErrNotYourTurn)
 }
 
@@ -80,7 +135,7 @@ func TestGame_MakeMove_PlayerNotInGame(t *testing.T) {
 	require.NoError(t, err)
 	g.Join("player-2")
 
-	err = g.MakeMove("player-3", 0, 0)
+	_, err = g.MakeMove("player-3", 0, 0)
 	assert.ErrorIs(t, err, ErrPlayerNotInGame)
 }
 
@@ -89,7 +144,7 @@ func TestGame_MakeMove_GameNotInProgress(t *testing.T) {
 	require.NoError(t, err)
 
 	// Game is still pending
-	err = g.MakeMove("player-1", 0, 0)
+	_, err = g.MakeMove("player-1", 0, 0)
 	assert.ErrorIs(t, err, ErrGameNotInProgress)
 }
 
@@ -115,13 +170,117 @@ func TestGame_MakeMove_WinCondition(t *testing.T) {
 	}
 
 	for _, m := range moves {
-		err := g.MakeMove(m.player, m.row, m.col)
+		_, err := g.MakeMove(m.player, m.row, m.col)
 		require.NoError(t, err)
 	}
 
 	assert.Equal(t, StatusXWon, g.Status)
 }
 
+func TestGame_PreviewMove(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// X X .
+	// O O .
+	// . . .
+	moves := []struct {
+		player   string
+		row, col int
+	}{
+		{"player-1", 0, 0},
+		{"player-2", 1, 0},
+		{"player-1", 0, 1},
+		{"player-2", 1, 1},
+	}
+	for _, m := range moves {
+		_, err := g.MakeMove(m.player, m.row, m.col)
+		require.NoError(t, err)
+	}
+	before := g.GetSnapshot()
+
+	// A winning move is previewed as a win, as often as asked
+	for i := 0; i < 2; i++ {
+		preview, err := g.PreviewMove("player-1", 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, StatusXWon, preview.Status)
+		assert.Equal(t, 5, preview.MoveCount)
+		mark, _ := preview.Board.Get(0, 2)
+		assert.Equal(t, MarkX, mark)
+	}
+
+	// Any other move passes the turn on
+	preview, err := g.PreviewMove("player-1", 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInProgress, preview.Status)
+	assert.Equal(t, MarkO, preview.Turn)
+
+	// Invalid moves fail as they would if played
+	_, err = g.PreviewMove("player-2", 2, 2)
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	_, err = g.PreviewMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrCellOccupied)
+	_, err = g.PreviewMove("player-1", 3, 0)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+	_, err = g.PreviewMove("player-3", 2, 2)
+	assert.ErrorIs(t, err, ErrPlayerNotInGame)
+
+	// None of it touched the game
+	after := g.GetSnapshot()
+	assert.Equal(t, before.Board.Cells, after.Board.Cells)
+	assert.Equal(t, before.Version, after.Version)
+	assert.Equal(t, StatusInProgress, after.Status)
+	assert.Equal(t, MarkX, after.Turn)
+	assert.Equal(t, 4, after.MoveCount)
+}
+
+func TestGame_PreviewMove_Gravity(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 4, 3, WithMode(ModeGravity))
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	_, err = g.PreviewMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrNotLowestEmptyRow)
+	_, err = g.PreviewMove("player-1", 3, 0)
+	assert.NoError(t, err)
+}
+
+func TestGame_CheckMove(t *testing.T) {
+	for _, mode := range []Mode{ModeClassic, ModeGravity} {
+		t.Run(mode.String(), func(t *testing.T) {
+			g, err := NewGame("game-1", "player-1", 4, 3, WithMode(mode))
+			require.NoError(t, err)
+			g.Join("player-2")
+
+			// At every turn, each candidate CheckMove rejects fails MakeMove
+			// with the same error, and the one played is accepted
+			players := []string{"player-1", "player-2", "player-3"}
+			for turn := 0; g.GetSnapshot().Status == StatusInProgress; turn++ {
+				for _, player := range players {
+					for row := -1; row <= 4; row++ {
+						for col := -1; col <= 4; col++ {
+							if err := g.CheckMove(player, row, col); err != nil {
+								_, moveErr := g.MakeMove(player, row, col)
+								assert.Equal(t, err, moveErr, "%s at (%d, %d)", player, row, col)
+							}
+						}
+					}
+				}
+
+				mover := players[turn%2]
+				moves := g.AvailableMoves()
+				require.NotEmpty(t, moves)
+				require.NoError(t, g.CheckMove(mover, moves[0].Row, moves[0].Col))
+				_, err := g.MakeMove(mover, moves[0].Row, moves[0].Col)
+				require.NoError(t, err)
+			}
+
+			assert.ErrorIs(t, g.CheckMove("player-1", 0, 0), ErrGameNotInProgress)
+		})
+	}
+}
+
 func TestGame_MakeMove_DrawCondition(t *testing.T) {
 	g, err := NewGame("game-1", "player-1", 3, 3)
 	require.NoError(t, err)
@@ -148,13 +307,44 @@ func TestGame_MakeMove_DrawCondition(t *testing.T) {
 	}
 
 	for _, m := range moves {
-		err := g.MakeMove(m.player, m.row, m.col)
+		_, err := g.MakeMove(m.player, m.row, m.col)
 		require.NoError(t, err)
 	}
 
 	assert.Equal(t, StatusDraw, g.Status)
 }
 
+func TestGame_MakeMove_EarlyDraw(t *testing.T) {
+	// X O X
+	// O X .
+	// O X O  <- nobody can complete a line with a cell still empty
+	moves := [][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {2, 0}, {2, 1}, {2, 2}}
+	play := func(opts ...Option) GameSnapshot {
+		g, err := NewGame("game-1", "player-1", 3, 3, opts...)
+		require.NoError(t, err)
+		require.NoError(t, g.Join("player-2"))
+		var snapshot GameSnapshot
+		for i, move := range moves {
+			player := "player-1"
+			if i%2 == 1 {
+				player = "player-2"
+			}
+			snapshot = mustMove(t, g, player, move[0], move[1])
+			if i < len(moves)-1 {
+				require.Equal(t, StatusInProgress, snapshot.Status, "move %d", i+1)
+			}
+		}
+		return snapshot
+	}
+
+	snapshot := play(WithEarlyDraw())
+	assert.Equal(t, StatusDraw, snapshot.Status)
+	assert.Len(t, snapshot.Board.EmptyCells(), 1)
+
+	// Without the option play goes on until the board is full
+	assert.Equal(t, StatusInProgress, play().Status)
+}
+
 func TestGame_GetSnapshot(t *testing.T) {
 	g, err := NewGame("game-1", "player-1", 3, 3)
 	require.NoError(t, err)
@@ -193,3 +383,905 @@ func TestGameSnapshot_GetWinnerLoser(t *testing.T) {
 	assert.Equal(t, "player-2", snapshot.GetLoser())
 	assert.False(t, snapshot.IsDraw())
 }
+
+func TestGame_DropMove_Gravity(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 4, 3, WithMode(ModeGravity))
+	require.NoError(t, err)
+	assert.Equal(t, ModeGravity, g.Mode)
+	g.Join("player-2")
+
+	// Marks stack up from the bottom row
+	players := []string{"player-1", "player-2"}
+	for i := 0; i < 4; i++ {
+		row, _, err := g.DropMove(players[i%2], 1)
+		require.NoError(t, err)
+		assert.Equal(t, 3-i, row)
+	}
+
+	// Column 1 is now full
+	_, _, err = g.DropMove("player-1", 1)
+	assert.ErrorIs(t, err, ErrColumnFull)
+
+	_, _, err = g.DropMove("player-1", 4)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+
+	// Turn checks still apply
+	_, _, err = g.DropMove("player-2", 0)
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+}
+
+func TestGame_DropMove_Win(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 4, 3, WithMode(ModeGravity))
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// X builds a vertical line in column 0 while O plays column 1
+	for i := 0; i < 2; i++ {
+		_, _, err := g.DropMove("player-1", 0)
+		require.NoError(t, err)
+		_, _, err = g.DropMove("player-2", 1)
+		require.NoError(t, err)
+	}
+	row, _, err := g.DropMove("player-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, row)
+	assert.Equal(t, StatusXWon, g.Status)
+}
+
+func TestGame_MakeMove_Gravity(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithMode(ModeGravity))
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// Floating marks are rejected
+	_, err = g.MakeMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrNotLowestEmptyRow)
+
+	// The landing cell is accepted
+	mustMove(t, g, "player-1", 2, 0)
+
+	_, err = g.MakeMove("player-2", 2, 0)
+	assert.ErrorIs(t, err, ErrCellOccupied)
+}
+
+func TestGame_DropMove_ClassicMode(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, ModeClassic, g.Mode)
+	g.Join("player-2")
+
+	_, _, err = g.DropMove("player-1", 0)
+	assert.ErrorIs(t, err, ErrNotGravityGame)
+}
+
+func TestGame_MakeMove_Nonce(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// Increasing nonces are accepted; each player has their own sequence
+	mustMove(t, g, "player-1", 0, 0, WithNonce(1))
+	mustMove(t, g, "player-2", 1, 1, WithNonce(1))
+	mustMove(t, g, "player-1", 0, 1, WithNonce(5))
+
+	// Replaying the last nonce is rejected before the turn check
+	_, err = g.MakeMove("player-1", 0, 2, WithNonce(5))
+	assert.ErrorIs(t, err, ErrStaleNonce)
+
+	// A stale nonce is rejected even on the player's turn
+	_, err = g.MakeMove("player-2", 2, 2, WithNonce(1))
+	assert.ErrorIs(t, err, ErrStaleNonce)
+
+	// A failed move does not consume the nonce
+	_, err = g.MakeMove("player-2", 0, 0, WithNonce(2))
+	assert.ErrorIs(t, err, ErrCellOccupied)
+	mustMove(t, g, "player-2", 2, 2, WithNonce(2))
+
+	// Once a player has used nonces, a move without one could be a replay
+	_, err = g.MakeMove("player-1", 1, 0)
+	assert.ErrorIs(t, err, ErrStaleNonce)
+	mustMove(t, g, "player-1", 1, 0, WithNonce(6))
+}
+
+func TestGame_MakeMove_WithoutNonce(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// A player who never sends nonces is not held to them, even when the
+	// opponent does
+	mustMove(t, g, "player-1", 0, 0)
+	mustMove(t, g, "player-2", 1, 1, WithNonce(1))
+	mustMove(t, g, "player-1", 0, 1)
+}
+
+func TestGame_MakeMove_ExpectMoveCount(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	mustMove(t, g, "player-1", 0, 0, ExpectMoveCount(0))
+	assert.Equal(t, 1, g.MoveCount())
+
+	// A second tab that still thinks no moves were played is turned away
+	_, err = g.MakeMove("player-1", 0, 1, ExpectMoveCount(0))
+	assert.ErrorIs(t, err, ErrMoveCountMismatch)
+	_, err = g.MakeMove("player-2", 1, 1, ExpectMoveCount(0))
+	assert.ErrorIs(t, err, ErrMoveCountMismatch)
+	assert.Equal(t, 1, g.MoveCount())
+
+	snapshot := mustMove(t, g, "player-2", 1, 1, ExpectMoveCount(1))
+	assert.Equal(t, 2, snapshot.MoveCount)
+
+	// Moves without an expectation are unaffected
+	mustMove(t, g, "player-1", 0, 1)
+	assert.Equal(t, 3, g.MoveCount())
+}
+
+func TestGame_MakeMove_Misere(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithMisere())
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// X completes the top row and therefore loses
+	moves := []struct {
+		player   string
+		row, col int
+	}{
+		{"player-1", 0, 0},
+		{"player-2", 1, 0},
+		{"player-1", 0, 1},
+		{"player-2", 2, 2},
+		{"player-1", 0, 2},
+	}
+	for _, m := range moves {
+		mustMove(t, g, m.player, m.row, m.col)
+	}
+
+	assert.Equal(t, StatusOWon, g.Status)
+	snapshot := g.GetSnapshot()
+	assert.True(t, snapshot.Misere)
+	assert.Equal(t, "player-2", snapshot.GetWinner())
+	assert.Equal(t, "player-1", snapshot.GetLoser())
+}
+
+func TestGame_MakeMove_MisereDraw(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithMisere())
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// X O X
+	// X O O
+	// O X X
+	moves := []struct {
+		player   string
+		row, col int
+	}{
+		{"player-1", 0, 0},
+		{"player-2", 0, 1},
+		{"player-1", 0, 2},
+		{"player-2", 1, 1},
+		{"player-1", 1, 0},
+		{"player-2", 2, 0},
+		{"player-1", 2, 1},
+		{"player-2", 1, 2},
+		{"player-1", 2, 2},
+	}
+	for _, m := range moves {
+		mustMove(t, g, m.player, m.row, m.col)
+	}
+
+	assert.Equal(t, StatusDraw, g.Status)
+}
+
+func TestGame_MakeMove_AdmissionLimit(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithMoveAdmissionLimit(1))
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// Hold the game lock so the admitted attempt stays in flight
+	g.mu.Lock()
+
+	const attempts = 50
+	results := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		go func() {
+			_, err := g.MakeMove("player-1", 0, 0)
+			results <- err
+		}()
+	}
+
+	// Every attempt beyond the limit is shed without waiting for the lock
+	for i := 0; i < attempts-1; i++ {
+		assert.ErrorIs(t, <-results, ErrTooManyMovesInFlight)
+	}
+
+	g.mu.Unlock()
+	require.NoError(t, <-results)
+
+	// The slot is released once the move completes
+	mustMove(t, g, "player-2", 1, 1)
+}
+
+func TestGame_CreatorPlaysO(t *testing.T) {
+	g, err := NewGame("game-1", "creator", 3, 3, WithCreatorMark(MarkO))
+	require.NoError(t, err)
+	assert.Empty(t, g.PlayerX)
+	assert.Equal(t, "creator", g.PlayerO)
+
+	assert.ErrorIs(t, g.Join("creator"), ErrCannotJoinOwnGame)
+	require.NoError(t, g.Join("joiner"))
+	assert.Equal(t, "joiner", g.PlayerX)
+	assert.Equal(t, MarkO, g.GetPlayerMark("creator"))
+
+	// The joiner plays X and still moves first
+	_, err = g.MakeMove("creator", 0, 0)
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	mustMove(t, g, "joiner", 0, 0)
+	mustMove(t, g, "creator", 1, 1)
+}
+
+func TestGame_CreatorPlaysX_Default(t *testing.T) {
+	g, err := NewGame("game-1", "creator", 3, 3, WithCreatorMark(MarkX))
+	require.NoError(t, err)
+	assert.Equal(t, "creator", g.PlayerX)
+	assert.Empty(t, g.PlayerO)
+
+	// An empty player ID never matches the open seat
+	assert.Equal(t, MarkEmpty, g.GetPlayerMark(""))
+}
+
+func TestGame_DrawByAgreement(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, g.OfferDraw("player-1"), ErrGameNotInProgress)
+	g.Join("player-2")
+
+	// Nothing to respond to yet, and outsiders cannot offer
+	assert.ErrorIs(t, g.RespondDraw("player-2", true), ErrNoDrawOffer)
+	assert.ErrorIs(t, g.OfferDraw("player-3"), ErrPlayerNotInGame)
+
+	// Either player may offer, even off turn, but only one offer stands at a time
+	require.NoError(t, g.OfferDraw("player-2"))
+	assert.Equal(t, MarkO, g.GetSnapshot().DrawOffer)
+	assert.ErrorIs(t, g.OfferDraw("player-1"), ErrDrawOfferPending)
+
+	// The offering player cannot accept their own offer
+	assert.ErrorIs(t, g.RespondDraw("player-2", true), ErrNoDrawOffer)
+
+	// Declining clears the offer and play continues
+	require.NoError(t, g.RespondDraw("player-1", false))
+	assert.Equal(t, MarkEmpty, g.GetSnapshot().DrawOffer)
+	assert.Equal(t, StatusInProgress, g.GetStatus())
+
+	// Accepting ends the game in a draw
+	require.NoError(t, g.OfferDraw("player-1"))
+	require.NoError(t, g.RespondDraw("player-2", true))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, StatusDraw, snapshot.Status)
+	assert.Equal(t, MarkEmpty, snapshot.DrawOffer)
+	_, err = g.MakeMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrGameNotInProgress)
+}
+
+func TestGame_DrawOffer_ClearedByOfferersMove(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// The opponent moving leaves the offer in place
+	mustMove(t, g, "player-1", 0, 0)
+	require.NoError(t, g.OfferDraw("player-1"))
+	mustMove(t, g, "player-2", 1, 1)
+	assert.Equal(t, MarkX, g.GetSnapshot().DrawOffer)
+
+	// The offering player moving withdraws it
+	mustMove(t, g, "player-1", 2, 2)
+	assert.Equal(t, MarkEmpty, g.GetSnapshot().DrawOffer)
+	assert.ErrorIs(t, g.RespondDraw("player-2", true), ErrNoDrawOffer)
+}
+
+func TestGame_AvailableMoves(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	assert.Empty(t, g.AvailableMoves())
+
+	g.Join("player-2")
+	mustMove(t, g, "player-1", 1, 1)
+	moves := g.AvailableMoves()
+	assert.Len(t, moves, 8)
+	assert.NotContains(t, moves, Position{Row: 1, Col: 1})
+}
+
+func TestGame_AvailableMoves_Gravity(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithMode(ModeGravity))
+	require.NoError(t, err)
+	g.Join("player-2")
+
+	// Only the bottom row is reachable at first
+	assert.Equal(t, []Position{{2, 0, 0}, {2, 1, 0}, {2, 2, 0}}, g.AvailableMoves())
+
+	// Filling a column removes it; others offer their landing cell
+	for i := 0; i < 3; i++ {
+		player := []string{"player-1", "player-2"}[i%2]
+		_, _, err := g.DropMove(player, 0)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []Position{{2, 1, 0}, {2, 2, 0}}, g.AvailableMoves())
+}
+
+// mustMove makes a move that the test expects to succeed
+func mustMove(t *testing.T, g *Game, playerID string, row, col int, opts ...MoveOption) GameSnapshot {
+	t.Helper()
+	snapshot, err := g.MakeMove(playerID, row, col, opts...)
+	require.NoError(t, err)
+	return snapshot
+}
+
+func TestGame_MakeMove_ConcurrentSnapshots(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 5, 4)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+
+	// Both players, several goroutines each, race to fill every cell
+	var mu sync.Mutex
+	var snapshots []GameSnapshot
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		player := []string{"player-1", "player-2"}[i%2]
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			for n := 0; !g.GetStatus().IsFinished(); n++ {
+				cell := (n + offset) % 25
+				snapshot, err := g.MakeMove(player, cell/5, cell%5)
+				if err == nil {
+					mu.Lock()
+					snapshots = append(snapshots, snapshot)
+					mu.Unlock()
+				}
+			}
+		}(i * 3)
+	}
+	wg.Wait()
+
+	// Each accepted move saw a board with exactly one more mark than the
+	// previous one, and only the final move saw the game finish
+	final := g.GetSnapshot()
+	require.True(t, final.Status.IsFinished())
+	seen := make(map[int]bool)
+	finished := 0
+	for _, snapshot := range snapshots {
+		marks := len(snapshot.Board.Cells) - len(snapshot.Board.EmptyCells())
+		assert.False(t, seen[marks], "two moves observed %d marks", marks)
+		seen[marks] = true
+		if snapshot.Status.IsFinished() {
+			finished++
+		}
+	}
+	assert.Equal(t, 1, finished)
+	assert.Len(t, snapshots, len(final.Board.Cells)-len(final.Board.EmptyCells()))
+}
+
+func TestGame_MarkResultRecorded(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+
+	// Nothing to record while the game is in progress
+	assert.False(t, g.MarkResultRecorded())
+
+	for _, m := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}} {
+		player := "player-1"
+		if g.GetSnapshot().Turn == MarkO {
+			player = "player-2"
+		}
+		mustMove(t, g, player, m[0], m[1])
+	}
+	require.Equal(t, StatusXWon, g.GetStatus())
+
+	// The finishing path may run twice; only the first records
+	assert.True(t, g.MarkResultRecorded())
+	assert.False(t, g.MarkResultRecorded())
+
+	// Retrying the finishing move is rejected without reopening the result
+	_, err = g.MakeMove("player-1", 0, 2)
+	assert.ErrorIs(t, err, ErrGameNotInProgress)
+	assert.False(t, g.MarkResultRecorded())
+}
+
+func TestGame_Moves(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithMode(ModeGravity))
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	assert.Empty(t, g.Moves())
+
+	mustMove(t, g, "player-1", 2, 1)
+	_, _, err = g.DropMove("player-2", 1)
+	require.NoError(t, err)
+	_, err = g.MakeMove("player-1", 0, 0)
+	require.Error(t, err)
+
+	// Only accepted moves are recorded, drops included
+	moves := g.Moves()
+	require.Len(t, moves, 2)
+	assert.Equal(t, Move{Mark: MarkX, Row: 2, Col: 1, At: moves[0].At}, moves[0])
+	assert.Equal(t, Move{Mark: MarkO, Row: 1, Col: 1, At: moves[1].At}, moves[1])
+	assert.False(t, moves[1].At.Before(moves[0].At))
+
+	// The returned slice is a copy
+	moves[0].Row = 99
+	assert.Equal(t, 2, g.Moves()[0].Row)
+}
+
+func TestGame_Abandon(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+
+	// Only an in-progress game can be abandoned, and only by one of its players
+	assert.ErrorIs(t, g.Abandon("player-1"), ErrGameNotInProgress)
+	require.NoError(t, g.Join("player-2"))
+	assert.ErrorIs(t, g.Abandon("spectator"), ErrPlayerNotInGame)
+
+	require.NoError(t, g.OfferDraw("player-1"))
+	require.NoError(t, g.Abandon("player-2"))
+
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, StatusAbandoned, snapshot.Status)
+	assert.True(t, snapshot.Status.IsFinished())
+	assert.Equal(t, MarkO, snapshot.AbandonedBy)
+	assert.Equal(t, "player-2", snapshot.GetAbandoner())
+	assert.Equal(t, MarkEmpty, snapshot.DrawOffer)
+	assert.Empty(t, snapshot.GetWinner())
+	assert.Empty(t, snapshot.GetLoser())
+	assert.False(t, snapshot.IsDraw())
+
+	_, err = g.MakeMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrGameNotInProgress)
+	assert.ErrorIs(t, g.Abandon("player-1"), ErrGameNotInProgress)
+	assert.True(t, g.MarkResultRecorded())
+}
+
+func TestGame_Leave(t *testing.T) {
+	// The creator leaving a pending game cancels it
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	assert.ErrorIs(t, g.Leave("player-2"), ErrPlayerNotInGame)
+	require.NoError(t, g.Leave("player-1"))
+	assert.Equal(t, StatusCancelled, g.GetStatus())
+	assert.True(t, g.GetStatus().IsFinished())
+	assert.ErrorIs(t, g.Join("player-2"), ErrGameAlreadyStarted)
+	assert.ErrorIs(t, g.Leave("player-1"), ErrGameNotInProgress)
+
+	// Anyone else leaving a pending game only gives up their seat
+	g, err = NewGame("game-1", "player-1", 3, 3, WithPlayers(3), WithCreatorMark(MarkO))
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	require.NoError(t, g.Leave("player-2"))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, StatusPending, snapshot.Status)
+	assert.Equal(t, []string{"", "player-1", ""}, []string{snapshot.PlayerX, snapshot.PlayerO, snapshot.PlayerTriangle})
+	assert.ErrorIs(t, g.Leave("player-2"), ErrPlayerNotInGame)
+
+	// Leaving an in-progress game forfeits it to the opponent
+	g, err = NewGame("game-2", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	assert.ErrorIs(t, g.Leave("spectator"), ErrPlayerNotInGame)
+	require.NoError(t, g.OfferDraw("player-2"))
+	require.NoError(t, g.Leave("player-2"))
+
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusXWon, snapshot.Status)
+	assert.Equal(t, "player-1", snapshot.GetWinner())
+	assert.Equal(t, "player-2", snapshot.GetLoser())
+	assert.Equal(t, MarkEmpty, snapshot.DrawOffer)
+	assert.ErrorIs(t, g.Leave("player-1"), ErrGameNotInProgress)
+
+	// Even before X's first move
+	g, err = NewGame("game-3", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	require.NoError(t, g.Leave("player-1"))
+	assert.Equal(t, StatusOWon, g.GetStatus())
+}
+
+func TestGame_RecomputeStatus(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	version := g.GetSnapshot().Version
+
+	// A board set up outside play: X O X / X O O / O X .
+	for i, mark := range []Mark{MarkX, MarkO, MarkX, MarkX, MarkO, MarkO, MarkO, MarkX} {
+		require.NoError(t, g.Board.Set(i/3, i%3, mark))
+	}
+	assert.Equal(t, StatusInProgress, g.RecomputeStatus())
+	assert.Equal(t, version, g.GetSnapshot().Version, "an unchanged status is not a change")
+
+	require.NoError(t, g.Board.Set(2, 2, MarkX))
+	assert.Equal(t, StatusDraw, g.RecomputeStatus())
+	assert.Greater(t, g.GetSnapshot().Version, version)
+
+	// O O O down the middle column wins, wherever the line is
+	g, err = NewGame("game-2", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	for row := 0; row < 3; row++ {
+		require.NoError(t, g.Board.Set(row, 1, MarkO))
+	}
+	assert.Equal(t, StatusOWon, g.RecomputeStatus())
+
+	// Lines count in every direction of a cube too
+	cube, err := NewGame("game-3", "player-1", 3, 3, With3D())
+	require.NoError(t, err)
+	require.NoError(t, cube.Join("player-2"))
+	for layer := 0; layer < 3; layer++ {
+		require.NoError(t, cube.Board.SetAt(layer, layer, layer, MarkX))
+	}
+	assert.Equal(t, StatusXWon, cube.RecomputeStatus())
+}
+
+func TestGame_Version(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), g.GetSnapshot().Version)
+
+	require.NoError(t, g.Join("player-2"))
+	assert.Equal(t, int64(2), g.GetSnapshot().Version)
+
+	mustMove(t, g, "player-1", 0, 0)
+	assert.Equal(t, int64(3), g.GetSnapshot().Version)
+	mustMove(t, g, "player-2", 1, 1)
+	assert.Equal(t, int64(4), g.GetSnapshot().Version)
+
+	// A rejected move changes nothing
+	_, err = g.MakeMove("player-2", 2, 2)
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	assert.Equal(t, int64(4), g.GetSnapshot().Version)
+
+	require.NoError(t, g.OfferDraw("player-1"))
+	assert.Equal(t, int64(5), g.GetSnapshot().Version)
+
+	// The version survives an export
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, int64(5), restored.GetSnapshot().Version)
+}
+
+func TestGame_Join_RandomStart(t *testing.T) {
+	// Without a random start the creator keeps X
+	g, err := NewGame("game", "creator", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("joiner"))
+	assert.Equal(t, "creator", g.GetSnapshot().PlayerX)
+
+	const games = 1000
+	creatorX := 0
+	for i := 0; i < games; i++ {
+		g, err := NewGame("game", "creator", 3, 3, WithRandomStart())
+		require.NoError(t, err)
+		assert.Equal(t, "creator", g.GetSnapshot().PlayerX, "marks are drawn on join")
+		require.NoError(t, g.Join("joiner"))
+
+		snapshot := g.GetSnapshot()
+		assert.ElementsMatch(t, []string{"creator", "joiner"}, snapshot.Players())
+		assert.Equal(t, MarkX, snapshot.Turn)
+		if snapshot.PlayerX == "creator" {
+			creatorX++
+		}
+	}
+	// Outside 400-600 by chance less than once in a billion runs
+	assert.InDelta(t, games/2, creatorX, 100)
+
+	// Three players are shuffled only once the last seat is filled
+	g, err = NewGame("game", "creator", 3, 3, WithPlayers(3), WithRandomStart())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("second"))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, []string{"creator", "second", ""}, snapshot.Players())
+	require.NoError(t, g.Join("third"))
+	snapshot = g.GetSnapshot()
+	assert.ElementsMatch(t, []string{"creator", "second", "third"}, snapshot.Players())
+}
+
+func TestGame_Seed(t *testing.T) {
+	// The same seed draws the same marks, whoever the players are
+	draw := func(seed uint64, creator, joiner string) []string {
+		g, err := NewGame("game", creator, 3, 3, WithRandomStart(), WithSeed(seed))
+		require.NoError(t, err)
+		require.NoError(t, g.Join(joiner))
+		snapshot := g.GetSnapshot()
+		return snapshot.Players()
+	}
+	creatorX := 0
+	for seed := uint64(0); seed < 50; seed++ {
+		first := draw(seed, "a", "b")
+		assert.Equal(t, first, draw(seed, "a", "b"))
+		if first[0] == "a" {
+			creatorX++
+			assert.Equal(t, []string{"c", "d"}, draw(seed, "c", "d"))
+		}
+	}
+	assert.NotZero(t, creatorX)
+	assert.Less(t, creatorX, 50)
+
+	// Unseeded games still get a seed, which snapshots and exports carry
+	g, err := NewGame("game", "a", 3, 3)
+	require.NoError(t, err)
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, g.Seed, snapshot.Seed)
+	assert.Equal(t, snapshot.Rand().Uint64(), snapshot.Rand().Uint64())
+
+	g, err = NewGame("game", "a", 3, 3, WithSeed(42))
+	require.NoError(t, err)
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, uint64(42), restored.GetSnapshot().Seed)
+}
+
+func TestGame_Start(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithReadyCheck())
+	require.NoError(t, err)
+	assert.ErrorIs(t, g.Start("player-1"), ErrGameNotReady)
+
+	// A full game waits for its players instead of starting
+	require.NoError(t, g.Join("player-2"))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, StatusReady, snapshot.Status)
+	assert.False(t, snapshot.Status.IsFinished())
+	assert.True(t, snapshot.StartedAt.IsZero())
+	_, err = g.MakeMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrGameNotInProgress)
+	assert.ErrorIs(t, g.Join("player-3"), ErrGameAlreadyStarted)
+
+	assert.ErrorIs(t, g.Start("spectator"), ErrPlayerNotInGame)
+	require.NoError(t, g.Start("player-2"))
+	require.NoError(t, g.Start("player-2"))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusReady, snapshot.Status)
+	assert.Equal(t, []string{"player-2"}, snapshot.ReadyPlayers)
+
+	// The last player to start it starts the game
+	require.NoError(t, g.Start("player-1"))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusInProgress, snapshot.Status)
+	assert.Equal(t, []string{"player-1", "player-2"}, snapshot.ReadyPlayers)
+	assert.False(t, snapshot.StartedAt.IsZero())
+	assert.ErrorIs(t, g.Start("player-1"), ErrGameNotReady)
+	mustMove(t, g, "player-1", 0, 0)
+
+	// The joiner leaving a game that has not started frees the seat and the
+	// game waits for players again; the creator leaving cancels it
+	g, err = NewGame("game-2", "player-1", 3, 3, WithReadyCheck())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	require.NoError(t, g.Start("player-1"))
+	require.NoError(t, g.Leave("player-2"))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusPending, snapshot.Status)
+	assert.Empty(t, snapshot.PlayerO)
+	assert.Empty(t, snapshot.ReadyPlayers)
+	require.NoError(t, g.Join("player-3"))
+	assert.Equal(t, StatusReady, g.GetStatus())
+	require.NoError(t, g.Leave("player-1"))
+	assert.Equal(t, StatusCancelled, g.GetStatus())
+
+	// The ready check survives an export
+	g, err = NewGame("game-3", "player-1", 3, 3, WithReadyCheck())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	require.NoError(t, g.Start("player-2"))
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	var restored Game
+	require.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, []string{"player-2"}, restored.GetSnapshot().ReadyPlayers)
+	require.NoError(t, restored.Start("player-1"))
+	assert.Equal(t, StatusInProgress, restored.GetStatus())
+}
+
+func TestGame_ThreePlayers(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 5, 3, WithPlayers(3))
+	require.NoError(t, err)
+
+	// The game waits until every seat is filled
+	require.NoError(t, g.Join("bob"))
+	assert.Equal(t, StatusPending, g.GetStatus())
+	assert.ErrorIs(t, g.Join("bob"), ErrCannotJoinOwnGame)
+	require.NoError(t, g.Join("carol"))
+	assert.Equal(t, StatusInProgress, g.GetStatus())
+	assert.Equal(t, MarkTriangle, g.GetPlayerMark("carol"))
+	assert.ErrorIs(t, g.Join("dave"), ErrGameAlreadyStarted)
+
+	// Turns rotate X, O, triangle
+	mustMove(t, g, "alice", 0, 0)
+	_, err = g.MakeMove("carol", 4, 4)
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	mustMove(t, g, "bob", 1, 0)
+	snapshot := mustMove(t, g, "carol", 4, 4)
+	assert.Equal(t, MarkX, snapshot.Turn)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, snapshot.Players())
+
+	// The triangle completes a line of three
+	mustMove(t, g, "alice", 0, 1)
+	mustMove(t, g, "bob", 1, 1)
+	mustMove(t, g, "carol", 3, 4)
+	mustMove(t, g, "alice", 2, 0)
+	mustMove(t, g, "bob", 2, 2)
+	snapshot = mustMove(t, g, "carol", 2, 4)
+
+	assert.Equal(t, StatusTriangleWon, snapshot.Status)
+	assert.True(t, snapshot.Status.IsFinished())
+	assert.Equal(t, "carol", snapshot.GetWinner())
+	assert.Empty(t, snapshot.GetLoser())
+	assert.Equal(t, []string{"alice", "bob"}, snapshot.GetLosers())
+}
+
+func TestGame_ThreePlayers_Restrictions(t *testing.T) {
+	_, err := NewGame("game-1", "alice", 5, 3, WithPlayers(4))
+	assert.ErrorIs(t, err, ErrInvalidPlayerCount)
+	_, err = NewGame("game-1", "alice", 5, 3, WithPlayers(3), WithMisere())
+	assert.ErrorIs(t, err, ErrMisereMultiplayer)
+
+	g, err := NewGame("game-1", "alice", 5, 3, WithPlayers(3))
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+	require.NoError(t, g.Join("carol"))
+
+	// Draw offers and forfeits only make sense between two players
+	assert.ErrorIs(t, g.OfferDraw("alice"), ErrTwoPlayerOnly)
+	assert.ErrorIs(t, g.Leave("bob"), ErrTwoPlayerOnly)
+
+	require.NoError(t, g.Abandon("carol"))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, "carol", snapshot.GetAbandoner())
+}
+
+func TestMark_Next(t *testing.T) {
+	assert.Equal(t, MarkO, MarkX.Next(2))
+	assert.Equal(t, MarkX, MarkO.Next(2))
+	assert.Equal(t, MarkO, MarkX.Next(3))
+	assert.Equal(t, MarkTriangle, MarkO.Next(3))
+	assert.Equal(t, MarkX, MarkTriangle.Next(3))
+}
+
+func TestGameSnapshot_ThinkTime(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("bob"))
+
+	// Each move adds the time since the previous move, or since the start
+	time.Sleep(20 * time.Millisecond)
+	snapshot := mustMove(t, g, "alice", 0, 0)
+	assert.GreaterOrEqual(t, snapshot.ThinkTime("alice"), 20*time.Millisecond)
+	assert.Zero(t, snapshot.ThinkTime("bob"))
+	time.Sleep(10 * time.Millisecond)
+	snapshot = mustMove(t, g, "bob", 1, 1)
+	assert.GreaterOrEqual(t, snapshot.ThinkTime("bob"), 10*time.Millisecond)
+	assert.Zero(t, snapshot.ThinkTime("carol"))
+	assert.Zero(t, snapshot.ThinkTime(""))
+
+	// Restored games total the move times of the export exactly
+	export := `{"id":"g","player_x":"alice","player_o":"bob","board_size":3,"win_length":3,` +
+		`"cells":["X","X","","O","","","","",""],"mode":"CLASSIC","turn":"O","status":"IN_PROGRESS",` +
+		`"started_at":"2024-01-01T12:00:00Z","moves":[` +
+		`{"mark":"X","row":0,"col":0,"at":"2024-01-01T12:00:05Z"},` +
+		`{"mark":"O","row":1,"col":0,"at":"2024-01-01T12:00:07Z"},` +
+		`{"mark":"X","row":0,"col":1,"at":"2024-01-01T12:00:10Z"}]}`
+	var restored Game
+	require.NoError(t, json.Unmarshal([]byte(export), &restored))
+	snapshot = restored.GetSnapshot()
+	assert.Equal(t, 8*time.Second, snapshot.ThinkTime("alice"))
+	assert.Equal(t, 2*time.Second, snapshot.ThinkTime("bob"))
+
+	// Without started_at the opening move's think time is unknown
+	require.NoError(t, json.Unmarshal([]byte(strings.Replace(export, `"started_at":"2024-01-01T12:00:00Z",`, "", 1)), &restored))
+	snapshot = restored.GetSnapshot()
+	assert.Equal(t, 3*time.Second, snapshot.ThinkTime("alice"))
+}
+
+func TestGame_MoveLimit(t *testing.T) {
+	// A game can never take more moves than its board has cells, even if a
+	// bug left it in progress on a full move list
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	for i := 0; i < 9; i++ {
+		g.moves = append(g.moves, Move{Mark: MarkX})
+	}
+	_, err = g.MakeMove("player-1", 0, 0)
+	assert.ErrorIs(t, err, ErrMoveLimitReached)
+	assert.True(t, g.GetSnapshot().Board.IsEmpty())
+
+	// A configured cap applies below that, and the move reaching it draws
+	g, err = NewGame("game-2", "player-1", 3, 3, WithMaxMoves(2))
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	mustMove(t, g, "player-1", 0, 0)
+	preview, err := g.PreviewMove("player-2", 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDraw, preview.Status)
+	mustMove(t, g, "player-2", 1, 1)
+	assert.Equal(t, StatusDraw, g.GetSnapshot().Status)
+	_, err = g.MakeMove("player-1", 2, 2)
+	assert.ErrorIs(t, err, ErrGameNotInProgress)
+	assert.Equal(t, 2, g.MoveCount())
+}
+
+func TestGame_TimeOut(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3)
+	require.NoError(t, err)
+	assert.False(t, g.TimeOut(time.Now().Add(time.Hour)), "pending games are left to expire")
+
+	require.NoError(t, g.Join("player-2"))
+	require.NoError(t, g.OfferDraw("player-1"))
+	assert.False(t, g.TimeOut(g.GetSnapshot().StartedAt), "started at the cutoff")
+
+	// The player on turn is blamed
+	mustMove(t, g, "player-1", 0, 0)
+	assert.True(t, g.TimeOut(time.Now().Add(time.Second)))
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, StatusAbandoned, snapshot.Status)
+	assert.Equal(t, "player-2", snapshot.GetAbandoner())
+	assert.Equal(t, MarkEmpty, snapshot.DrawOffer)
+	assert.False(t, g.TimeOut(time.Now().Add(time.Second)))
+
+	// A ready game blames the first player who has not started it
+	g, err = NewGame("game-2", "player-1", 3, 3, WithReadyCheck())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	require.NoError(t, g.Start("player-1"))
+	assert.True(t, g.TimeOut(time.Now().Add(time.Second)))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusAbandoned, snapshot.Status)
+	assert.Equal(t, "player-2", snapshot.GetAbandoner())
+}
+
+func TestGame_Expire(t *testing.T) {
+	g, err := NewGame("game-1", "player-1", 3, 3, WithReadyCheck())
+	require.NoError(t, err)
+	assert.False(t, g.Expire(g.GetSnapshot().UpdatedAt), "unchanged since the cutoff")
+	assert.True(t, g.Expire(time.Now().Add(time.Second)))
+	assert.Equal(t, StatusCancelled, g.GetStatus())
+
+	// A ready game whose players never start it expires too
+	g, err = NewGame("game-2", "player-1", 3, 3, WithReadyCheck())
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	assert.True(t, g.Expire(time.Now().Add(time.Second)))
+	assert.Equal(t, StatusCancelled, g.GetStatus())
+
+	// An in-progress game does not
+	g, err = NewGame("game-3", "player-1", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("player-2"))
+	assert.False(t, g.Expire(time.Now().Add(time.Second)))
+}
+
+func TestGameSnapshot_PlayerOnTurn(t *testing.T) {
+	g, err := NewGame("game-1", "alice", 3, 3, WithPlayers(3))
+	require.NoError(t, err)
+	snapshot := g.GetSnapshot()
+	assert.Empty(t, snapshot.PlayerOnTurn(), "pending")
+
+	require.NoError(t, g.Join("bob"))
+	require.NoError(t, g.Join("carol"))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, "alice", snapshot.PlayerOnTurn())
+	snapshot = mustMove(t, g, "alice", 0, 0)
+	assert.Equal(t, "bob", snapshot.PlayerOnTurn())
+	snapshot = mustMove(t, g, "bob", 1, 0)
+	assert.Equal(t, "carol", snapshot.PlayerOnTurn())
+	snapshot = mustMove(t, g, "carol", 2, 0)
+	assert.Equal(t, "alice", snapshot.PlayerOnTurn())
+
+	require.NoError(t, g.Abandon("alice"))
+	snapshot = g.GetSnapshot()
+	assert.Empty(t, snapshot.PlayerOnTurn(), "finished")
+}
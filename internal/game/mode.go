@@ -0,0 +1,22 @@
+package game
+
+// Mode selects how marks are placed on the board
+type Mode int
+
+const (
+	// ModeClassic lets players mark any empty cell
+	ModeClassic Mode = iota
+	// ModeGravity drops marks into a column, landing on the lowest empty row (Connect-4 style)
+	ModeGravity
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeClassic:
+		return "CLASSIC"
+	case ModeGravity:
+		return "GRAVITY"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,61 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// MaxNotationSize is the widest board algebraic notation can address (columns a-z)
+const MaxNotationSize = 26
+
+var (
+	ErrInvalidNotation = errors.New("invalid cell: expected a column letter followed by a row number, e.g. \"a1\"")
+	ErrNotationTooWide = fmt.Errorf("algebraic notation supports boards up to %d columns", MaxNotationSize)
+)
+
+// ParseCell converts chess-like algebraic notation to a (row, col) position on
+// a board of the given size. Columns are letters from "a" on the left; rows are
+// numbered from 1 at the bottom, so on a 3x3 board "a1" is the bottom-left
+// cell (2, 0) and "c3" the top-right cell (0, 2). Letters may be either case.
+func ParseCell(cell string, size int) (int, int, error) {
+	return ParseRectCell(cell, size, size)
+}
+
+// ParseRectCell is ParseCell for a board of rows by cols cells; row 1 is
+// still the bottom row
+func ParseRectCell(cell string, rows, cols int) (int, int, error) {
+	if cols > MaxNotationSize {
+		return 0, 0, ErrNotationTooWide
+	}
+	if len(cell) < 2 {
+		return 0, 0, ErrInvalidNotation
+	}
+
+	letter := cell[0] | 0x20 // lower-case
+	if letter < 'a' || letter > 'z' {
+		return 0, 0, ErrInvalidNotation
+	}
+	rank, err := strconv.Atoi(cell[1:])
+	if err != nil || cell[1] < '1' || cell[1] > '9' {
+		return 0, 0, ErrInvalidNotation
+	}
+
+	row, col := rows-rank, int(letter-'a')
+	if row < 0 || row >= rows || col >= cols {
+		return 0, 0, ErrInvalidPosition
+	}
+	return row, col, nil
+}
+
+// FormatCell converts a (row, col) position on a board of the given size to
+// algebraic notation; it is the inverse of ParseCell
+func FormatCell(row, col, size int) (string, error) {
+	if size > MaxNotationSize {
+		return "", ErrNotationTooWide
+	}
+	if row < 0 || row >= size || col < 0 || col >= size {
+		return "", ErrInvalidPosition
+	}
+	return fmt.Sprintf("%c%d", 'a'+col, size-row), nil
+}
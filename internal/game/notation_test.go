@@ -0,0 +1,63 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCell(t *testing.T) {
+	tests := []struct {
+		cell     string
+		size     int
+		row, col int
+	}{
+		{"a1", 3, 2, 0},
+		{"c3", 3, 0, 2},
+		{"b2", 3, 1, 1},
+		{"C1", 3, 2, 2},
+		{"a10", 10, 0, 0},
+		{"t1", 20, 19, 19},
+	}
+	for _, tt := range tests {
+		row, col, err := ParseCell(tt.cell, tt.size)
+		require.NoError(t, err, tt.cell)
+		assert.Equal(t, [2]int{tt.row, tt.col}, [2]int{row, col}, tt.cell)
+	}
+}
+
+func TestParseCell_Errors(t *testing.T) {
+	for _, cell := range []string{"", "a", "1a", "aa", "a0", "a01", "a-1", "a1x", "?1"} {
+		_, _, err := ParseCell(cell, 3)
+		assert.ErrorIs(t, err, ErrInvalidNotation, cell)
+	}
+
+	// Well-formed but off the board
+	for _, cell := range []string{"d1", "a4", "z9"} {
+		_, _, err := ParseCell(cell, 3)
+		assert.ErrorIs(t, err, ErrInvalidPosition, cell)
+	}
+
+	_, _, err := ParseCell("a1", 27)
+	assert.ErrorIs(t, err, ErrNotationTooWide)
+	_, err = FormatCell(0, 0, 27)
+	assert.ErrorIs(t, err, ErrNotationTooWide)
+}
+
+func TestCellNotation_RoundTrip(t *testing.T) {
+	for _, size := range []int{3, 9, 10, MaxNotationSize} {
+		for row := 0; row < size; row++ {
+			for col := 0; col < size; col++ {
+				cell, err := FormatCell(row, col, size)
+				require.NoError(t, err)
+				gotRow, gotCol, err := ParseCell(cell, size)
+				require.NoError(t, err, cell)
+				assert.Equal(t, [2]int{row, col}, [2]int{gotRow, gotCol}, cell)
+			}
+		}
+	}
+
+	_, err := FormatCell(3, 0, 3)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+}
@@ -0,0 +1,58 @@
+package game
+
+import "time"
+
+// Pacing controls how delays are spread across the moves of a replay
+type Pacing int
+
+const (
+	// PacingUniform waits the same base delay before every move
+	PacingUniform Pacing = iota
+	// PacingEmphasizeEnding speeds through the opening and slows down toward the final move
+	PacingEmphasizeEnding
+)
+
+func (p Pacing) String() string {
+	switch p {
+	case PacingUniform:
+		return "UNIFORM"
+	case PacingEmphasizeEnding:
+		return "EMPHASIZE_ENDING"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+const (
+	// openingPaceFactor scales the base delay for the first move when emphasizing the ending
+	openingPaceFactor = 0.5
+	// endingPaceFactor scales the base delay for the final move when emphasizing the ending
+	endingPaceFactor = 3.0
+)
+
+// ReplayDelays returns the delay to wait before each of moveCount replayed moves.
+// With PacingEmphasizeEnding the delay grows quadratically from half the base
+// delay on the first move to three times the base delay on the final move, so
+// most of the slowdown is concentrated on the decisive moves.
+func ReplayDelays(moveCount int, base time.Duration, pacing Pacing) []time.Duration {
+	if moveCount <= 0 {
+		return nil
+	}
+
+	delays := make([]time.Duration, moveCount)
+	for i := range delays {
+		if pacing != PacingEmphasizeEnding {
+			delays[i] = base
+			continue
+		}
+
+		// Position of the move in the game, from 0 (first) to 1 (final)
+		progress := 1.0
+		if moveCount > 1 {
+			progress = float64(i) / float64(moveCount-1)
+		}
+		factor := openingPaceFactor + (endingPaceFactor-openingPaceFactor)*progress*progress
+		delays[i] = time.Duration(float64(base) * factor)
+	}
+	return delays
+}
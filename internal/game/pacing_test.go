@@ -0,0 +1,44 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayDelays_Uniform(t *testing.T) {
+	delays := ReplayDelays(5, 100*time.Millisecond, PacingUniform)
+	require.Len(t, delays, 5)
+	for _, d := range delays {
+		assert.Equal(t, 100*time.Millisecond, d)
+	}
+}
+
+func TestReplayDelays_EmphasizeEnding(t *testing.T) {
+	base := 100 * time.Millisecond
+	delays := ReplayDelays(9, base, PacingEmphasizeEnding)
+	require.Len(t, delays, 9)
+
+	// The opening is faster than uniform pacing and the final move slower
+	assert.Less(t, delays[0], base)
+	assert.Greater(t, delays[8], base)
+
+	// Delays never shrink as the game progresses, and the final move waits longest
+	for i := 1; i < len(delays); i++ {
+		assert.GreaterOrEqual(t, delays[i], delays[i-1])
+	}
+	for _, d := range delays[:8] {
+		assert.Greater(t, delays[8], d)
+	}
+}
+
+func TestReplayDelays_EdgeCases(t *testing.T) {
+	assert.Empty(t, ReplayDelays(0, time.Second, PacingEmphasizeEnding))
+
+	// A single move is the decisive one
+	delays := ReplayDelays(1, 100*time.Millisecond, PacingEmphasizeEnding)
+	require.Len(t, delays, 1)
+	assert.Equal(t, 300*time.Millisecond, delays[0])
+}
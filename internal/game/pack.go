@@ -0,0 +1,67 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPacking is returned when packed board data cannot be unpacked
+var ErrInvalidPacking = errors.New("invalid packed board")
+
+// cellsPerByte is how many 2-bit cells Pack fits in a byte
+const cellsPerByte = 4
+
+// Pack encodes the board's cells at 2 bits each, four to a byte, in the order
+// of Cells (row-major, layer by layer on a cube). Cell i sits in byte i/4 at
+// bit offset 2*(i%4), counting from the least significant bit: 0 empty, 1 X,
+// 2 O, 3 △. Unused bits of the last byte are zero.
+func (b *Board) Pack() []byte {
+	data := make([]byte, (len(b.Cells)+cellsPerByte-1)/cellsPerByte)
+	for i, cell := range b.Cells {
+		data[i/cellsPerByte] |= byte(cell) << (2 * (i % cellsPerByte))
+	}
+	return data
+}
+
+// UnpackBoard decodes a flat size×size board packed by Pack
+func UnpackBoard(size, winLength int, data []byte) (*Board, error) {
+	board, err := NewBoard(size, winLength)
+	if err != nil {
+		return nil, err
+	}
+	return board, board.unpack(data)
+}
+
+// UnpackRectBoard decodes a flat rows×cols board packed by Pack
+func UnpackRectBoard(rows, cols, winLength int, data []byte) (*Board, error) {
+	board, err := NewRectBoard(rows, cols, winLength)
+	if err != nil {
+		return nil, err
+	}
+	return board, board.unpack(data)
+}
+
+// UnpackCubeBoard decodes a size×size×size board packed by Pack
+func UnpackCubeBoard(size, winLength int, data []byte) (*Board, error) {
+	board, err := NewCubeBoard(size, winLength)
+	if err != nil {
+		return nil, err
+	}
+	return board, board.unpack(data)
+}
+
+// unpack fills the board's cells from packed data, which must be exactly as
+// long as Pack makes it with zero padding
+func (b *Board) unpack(data []byte) error {
+	if want := (len(b.Cells) + cellsPerByte - 1) / cellsPerByte; len(data) != want {
+		return fmt.Errorf("%w: %d cells need %d bytes, got %d", ErrInvalidPacking, len(b.Cells), want, len(data))
+	}
+	// Every 2-bit value is a mark, △ included
+	for i := range b.Cells {
+		b.Cells[i] = Mark(data[i/cellsPerByte] >> (2 * (i % cellsPerByte)) & 0b11)
+	}
+	if tail := len(b.Cells) % cellsPerByte; tail != 0 && data[len(data)-1]>>(2*tail) != 0 {
+		return fmt.Errorf("%w: padding bits are set", ErrInvalidPacking)
+	}
+	return nil
+}
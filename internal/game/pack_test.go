@@ -0,0 +1,101 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoard_Pack(t *testing.T) {
+	board, err := NewBoard(3, 3)
+	require.NoError(t, err)
+	require.NoError(t, board.Set(0, 0, MarkX))
+	require.NoError(t, board.Set(0, 1, MarkO))
+	require.NoError(t, board.Set(2, 2, MarkO))
+
+	// Cells 0-3 in the first byte from the low bits up, cell 8 alone in the last
+	assert.Equal(t, []byte{0b00_00_10_01, 0b00000000, 0b00000010}, board.Pack())
+}
+
+func TestBoard_PackRoundTrip(t *testing.T) {
+	marks := []Mark{MarkEmpty, MarkX, MarkO}
+	for _, size := range []int{3, 4, 5, 19} {
+		board, err := NewBoard(size, 3)
+		require.NoError(t, err)
+		for i := range board.Cells {
+			board.Cells[i] = marks[(i*7)%3]
+		}
+
+		data := board.Pack()
+		assert.Len(t, data, (size*size+3)/4)
+		unpacked, err := UnpackBoard(size, 3, data)
+		require.NoError(t, err)
+		assert.Equal(t, board, unpacked, "size %d", size)
+	}
+
+	cube, err := NewCubeBoard(3, 3)
+	require.NoError(t, err)
+	require.NoError(t, cube.SetAt(1, 1, 1, MarkX))
+	require.NoError(t, cube.SetAt(2, 0, 2, MarkO))
+	unpacked, err := UnpackCubeBoard(3, 3, cube.Pack())
+	require.NoError(t, err)
+	assert.Equal(t, cube, unpacked)
+}
+
+func TestBoard_PackRoundTrip_Rect(t *testing.T) {
+	board, err := NewRectBoard(3, 5, 3)
+	require.NoError(t, err)
+	require.NoError(t, board.Set(0, 4, MarkX))
+	require.NoError(t, board.Set(2, 0, MarkO))
+
+	data := board.Pack()
+	assert.Len(t, data, 4)
+	unpacked, err := UnpackRectBoard(3, 5, 3, data)
+	require.NoError(t, err)
+	assert.Equal(t, board, unpacked)
+
+	// The same bytes read as a 5x3 board put the marks elsewhere
+	transposed, err := UnpackRectBoard(5, 3, 3, data)
+	require.NoError(t, err)
+	mark, err := transposed.Get(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, MarkX, mark)
+}
+
+func TestBoard_PackRoundTrip_ThreePlayers(t *testing.T) {
+	board, err := NewBoard(4, 3)
+	require.NoError(t, err)
+	require.NoError(t, board.Set(0, 0, MarkX))
+	require.NoError(t, board.Set(1, 1, MarkO))
+	require.NoError(t, board.Set(3, 3, MarkTriangle))
+
+	data := board.Pack()
+	assert.Equal(t, byte(0b11_00_00_00), data[3], "△ packs as 3")
+	unpacked, err := UnpackBoard(4, 3, data)
+	require.NoError(t, err)
+	assert.Equal(t, board, unpacked)
+	mark, err := unpacked.Get(3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, MarkTriangle, mark)
+}
+
+func TestUnpackBoard_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"too short", []byte{0, 0}},
+		{"too long", []byte{0, 0, 0, 0}},
+		{"padding set", []byte{0, 0, 0b100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnpackBoard(3, 3, tt.data)
+			assert.ErrorIs(t, err, ErrInvalidPacking)
+		})
+	}
+
+	_, err := UnpackBoard(2, 3, nil)
+	assert.ErrorIs(t, err, ErrInvalidBoardSize)
+}
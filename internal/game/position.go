@@ -0,0 +1,92 @@
+package game
+
+import "errors"
+
+// Errors rejecting a position given as a board layout
+var (
+	ErrWrongCellCount      = errors.New("cell count does not match the board size")
+	ErrUnreachablePosition = errors.New("mark counts cannot arise with the given player to move")
+	ErrPositionDecided     = errors.New("position already contains a completed line")
+	ErrPositionDrawn       = errors.New("position is already drawn")
+)
+
+// NewBoardFromPosition builds a board from row-major cells, checking that the
+// position could arise in a game where turn is to move: X moves first, so X
+// has as many marks as O when X is to move and one more when O is. A
+// position with a completed line is already decided and is rejected.
+func NewBoardFromPosition(size, winLength int, cells []Mark, turn Mark) (*Board, error) {
+	board, err := reachableBoard(size, winLength, cells, turn)
+	if err != nil {
+		return nil, err
+	}
+	if board.WinningLines() != nil {
+		return nil, ErrPositionDecided
+	}
+	return board, nil
+}
+
+// reachableBoard builds a board as NewBoardFromPosition does, without
+// rejecting a decided position
+func reachableBoard(size, winLength int, cells []Mark, turn Mark) (*Board, error) {
+	board, err := NewBoard(size, winLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(cells) != len(board.Cells) {
+		return nil, ErrWrongCellCount
+	}
+
+	xs, os := 0, 0
+	for i, cell := range cells {
+		switch cell {
+		case MarkX:
+			xs++
+		case MarkO:
+			os++
+		}
+		board.Cells[i] = cell
+	}
+	switch {
+	case turn == MarkX && xs == os:
+	case turn == MarkO && xs == os+1:
+	default:
+		return nil, ErrUnreachablePosition
+	}
+	return board, nil
+}
+
+// NewGameFromBoard creates a pending game that starts from a position
+// instead of an empty board, for puzzles and testing, and marks it
+// FromPosition. The creator is seated as WithCreatorMark says and the
+// opponent joins as usual; the game is a two-player classic game on a flat
+// board whatever the other options say. The position is checked as by
+// NewBoardFromPosition, and one that is already drawn is rejected too,
+// unless allowDecided is set: then the game ends with the position's result
+// as soon as it starts.
+func NewGameFromBoard(id, creatorID string, boardSize, winLength int, cells []Mark, turn Mark, allowDecided bool, opts ...Option) (*Game, error) {
+	board, err := reachableBoard(boardSize, winLength, cells, turn)
+	if err != nil {
+		return nil, err
+	}
+	g, err := NewGame(id, creatorID, boardSize, winLength, opts...)
+	if err != nil {
+		return nil, err
+	}
+	g.PlayerTriangle = ""
+	g.NumPlayers = 2
+	g.Board = board
+	g.Mode = ModeClassic
+	g.Turn = turn
+	g.FromPosition = true
+	if allowDecided {
+		return g, nil
+	}
+	switch resumedStatus(board, g.NumPlayers, g.Misere, g.EarlyDraw) {
+	case StatusInProgress:
+		return g, nil
+	case StatusDraw:
+		return nil, ErrPositionDrawn
+	default:
+		return nil, ErrPositionDecided
+	}
+}
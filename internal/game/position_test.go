@@ -0,0 +1,117 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBoardFromPosition(t *testing.T) {
+	const (
+		E = MarkEmpty
+		X = MarkX
+		O = MarkO
+	)
+
+	board, err := NewBoardFromPosition(3, 3, []Mark{
+		X, O, E,
+		E, X, E,
+		E, E, E,
+	}, MarkO)
+	require.NoError(t, err)
+	mark, _ := board.Get(1, 1)
+	assert.Equal(t, MarkX, mark)
+
+	tests := []struct {
+		name  string
+		size  int
+		cells []Mark
+		turn  Mark
+		err   error
+	}{
+		{"too few cells", 3, []Mark{X, O, E}, MarkX, ErrWrongCellCount},
+		{"bad size", 2, []Mark{E, E, E, E}, MarkX, ErrInvalidBoardSize},
+		{"X to move after X", 3, []Mark{X, E, E, E, E, E, E, E, E}, MarkX, ErrUnreachablePosition},
+		{"O moved first", 3, []Mark{O, E, E, E, E, E, E, E, E}, MarkX, ErrUnreachablePosition},
+		{"no one to move", 3, make([]Mark, 9), MarkEmpty, ErrUnreachablePosition},
+		{"already won", 3, []Mark{X, X, X, O, O, E, E, E, E}, MarkO, ErrPositionDecided},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBoardFromPosition(tt.size, 3, tt.cells, tt.turn)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestNewGameFromBoard(t *testing.T) {
+	const (
+		E = MarkEmpty
+		X = MarkX
+		O = MarkO
+	)
+
+	g, err := NewGameFromBoard("puzzle", "alice", 3, 3, []Mark{
+		X, O, E,
+		E, X, E,
+		E, E, O,
+	}, MarkX, false, WithCreatorMark(MarkO), WithPlayers(3))
+	require.NoError(t, err)
+	snapshot := g.GetSnapshot()
+	assert.Equal(t, StatusPending, snapshot.Status)
+	assert.Equal(t, "alice", snapshot.PlayerO)
+	assert.True(t, snapshot.FromPosition)
+
+	// The opponent joins to start the game
+	require.NoError(t, g.Join("bob"))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusInProgress, snapshot.Status)
+	assert.Equal(t, MarkX, snapshot.Turn)
+	assert.Equal(t, []string{"bob", "alice"}, snapshot.Players())
+	assert.False(t, snapshot.StartedAt.IsZero())
+
+	// Play continues from the position
+	_, err = g.MakeMove("alice", 0, 2)
+	assert.ErrorIs(t, err, ErrNotYourTurn)
+	_, err = g.MakeMove("bob", 1, 1)
+	assert.ErrorIs(t, err, ErrCellOccupied)
+	snapshot, err = g.MakeMove("bob", 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInProgress, snapshot.Status)
+	_, err = g.MakeMove("alice", 0, 2)
+	require.NoError(t, err)
+	snapshot, err = g.MakeMove("bob", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, StatusXWon, snapshot.Status)
+	assert.Len(t, g.Moves(), 3, "the marks of the position are not moves")
+
+	won := []Mark{X, X, X, O, O, E, E, E, E}
+	_, err = NewGameFromBoard("won", "alice", 3, 3, won, MarkO, false)
+	assert.ErrorIs(t, err, ErrPositionDecided)
+	_, err = NewGameFromBoard("uneven", "alice", 3, 3, []Mark{X, X, E, E, E, E, E, E, E}, MarkO, false)
+	assert.ErrorIs(t, err, ErrUnreachablePosition)
+	_, err = NewGameFromBoard("full", "alice", 3, 3, []Mark{
+		X, O, X,
+		X, O, O,
+		O, X, X,
+	}, MarkO, false)
+	assert.ErrorIs(t, err, ErrPositionDrawn)
+
+	// An early-draw game rejects a position no one can win any more
+	_, err = NewGameFromBoard("blocked", "alice", 3, 3, []Mark{
+		X, O, X,
+		X, O, O,
+		O, X, E,
+	}, MarkX, false, WithEarlyDraw())
+	assert.ErrorIs(t, err, ErrPositionDrawn)
+
+	// A decided position is allowed on request, and the game ends as it starts
+	g, err = NewGameFromBoard("won", "alice", 3, 3, won, MarkO, true)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, g.GetSnapshot().Status)
+	require.NoError(t, g.Join("bob"))
+	snapshot = g.GetSnapshot()
+	assert.Equal(t, StatusXWon, snapshot.Status)
+	assert.Equal(t, "alice", snapshot.GetWinner())
+}
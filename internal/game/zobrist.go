@@ -0,0 +1,52 @@
+package game
+
+// Hash returns a Zobrist hash of the board: a key for its shape and win
+// length XORed with a key for each marked cell. Like Fingerprint, boards with
+// the same shape, win length and marks hash equal, and the keys are fixed, so
+// hashes are stable across processes.
+//
+// The hash is computed on first use and then kept up to date by Set and
+// SetAt at the cost of one XOR, so boards that are never hashed pay nothing.
+// Writes straight to Cells are not tracked; call Rehash after making them.
+// Like Set, Hash is not safe for concurrent use.
+func (b *Board) Hash() uint64 {
+	if !b.hashed {
+		b.Rehash()
+	}
+	return b.hash
+}
+
+// Rehash recomputes the hash from the cells
+func (b *Board) Rehash() {
+	h := splitmix64(uint64(b.Rows)<<48 | uint64(b.Cols)<<32 | uint64(b.layers())<<16 | uint64(b.WinLength))
+	for idx, cell := range b.Cells {
+		h ^= zobristKey(idx, cell)
+	}
+	b.hash = h
+	b.hashed = true
+}
+
+// updateHash records a mark placed in an empty cell, if the hash is in use
+func (b *Board) updateHash(idx int, mark Mark) {
+	if b.hashed {
+		b.hash ^= zobristKey(idx, mark)
+	}
+}
+
+// zobristKey returns the key of a mark in the cell at idx. Empty cells
+// contribute nothing.
+func zobristKey(idx int, mark Mark) uint64 {
+	if mark == MarkEmpty {
+		return 0
+	}
+	return splitmix64(uint64(idx)<<2 | uint64(mark))
+}
+
+// splitmix64 scrambles x into a well-mixed 64-bit value, standing in for a
+// table of random keys
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
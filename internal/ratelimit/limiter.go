@@ -0,0 +1,104 @@
+// Package ratelimit provides a dependency-free keyed token-bucket rate limiter.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter holds one token bucket per key. Each bucket refills at rate tokens
+// per second up to burst. Buckets that have refilled completely carry no
+// state worth keeping, so they are dropped by a sweep that runs at most once
+// per sweep interval from Allow; idle keys therefore do not accumulate.
+type Limiter struct {
+	rate          float64
+	burst         float64
+	sweepInterval time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	// now is the clock, replaceable in tests
+	now func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New creates a limiter allowing rate requests per second per key with bursts
+// of up to burst. rate must be positive.
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+	// Sweep roughly as often as a drained bucket takes to refill
+	l.sweepInterval = l.refillTime()
+	if l.sweepInterval < time.Second {
+		l.sweepInterval = time.Second
+	}
+	l.lastSweep = l.now()
+	return l
+}
+
+// refillTime is how long an empty bucket takes to fill completely
+func (l *Limiter) refillTime() time.Duration {
+	return time.Duration(l.burst / l.rate * float64(time.Second))
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it returns
+// false and how long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.sweepInterval {
+		l.sweepLocked(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Len returns the number of buckets currently tracked
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// sweepLocked drops buckets that have refilled completely; caller must hold the lock
+func (l *Limiter) sweepLocked(now time.Time) {
+	full := l.refillTime()
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(rate float64, burst int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := New(rate, burst)
+	l.now = clock.now
+	l.lastSweep = clock.now()
+	return l, clock
+}
+
+func TestLimiter_LimitsAndRecovers(t *testing.T) {
+	l, clock := newTestLimiter(2, 3)
+
+	// The burst is available immediately
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("alice")
+		assert.True(t, ok, "request %d", i)
+	}
+
+	// Then requests are limited, with the wait until the next token
+	ok, wait := l.Allow("alice")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other keys have their own bucket
+	ok, _ = l.Allow("bob")
+	assert.True(t, ok)
+
+	// Tokens refill at the configured rate
+	clock.advance(500 * time.Millisecond)
+	ok, _ = l.Allow("alice")
+	assert.True(t, ok)
+	ok, _ = l.Allow("alice")
+	assert.False(t, ok)
+
+	// After a long pause the bucket is full again, but not beyond the burst
+	clock.advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("alice")
+		assert.True(t, ok, "request %d", i)
+	}
+	ok, _ = l.Allow("alice")
+	assert.False(t, ok)
+}
+
+func TestLimiter_SweepsIdleBuckets(t *testing.T) {
+	l, clock := newTestLimiter(1, 2)
+
+	for i := 0; i < 10; i++ {
+		l.Allow(fmt.Sprintf("user-%d", i))
+	}
+	assert.Equal(t, 10, l.Len())
+
+	// Once idle buckets have refilled, the next sweep drops them
+	clock.advance(2 * time.Second)
+	l.Allow("active")
+	assert.Equal(t, 1, l.Len())
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	l := New(1, 50)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := l.Allow("shared"); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The burst plus at most a few refilled tokens get through
+	assert.GreaterOrEqual(t, allowed, 50)
+	assert.LessOrEqual(t, allowed, 55)
+}
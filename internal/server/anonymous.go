@@ -0,0 +1,90 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"tictactoe/internal/ratelimit"
+	"tictactoe/internal/store"
+)
+
+const (
+	// DefaultAnonymousUserTTL is how long an identity from CreateAnonymousUser lives
+	DefaultAnonymousUserTTL = 24 * time.Hour
+
+	// DefaultMaxAnonymousUsers caps the anonymous identities alive at once
+	DefaultMaxAnonymousUsers = 10000
+
+	// DefaultAnonymousUserRate and DefaultAnonymousUserBurst limit how fast
+	// each caller may call CreateAnonymousUser: per second, and above that
+	DefaultAnonymousUserRate  = 0.2
+	DefaultAnonymousUserBurst = 5
+)
+
+// WithAnonymousUserLimits sets how long each identity CreateAnonymousUser
+// issues lives, after which its token stops working, and how many may be
+// alive at once; calls beyond the cap fail with ResourceExhausted until
+// older identities expire. Zero means forever, or unlimited.
+// DefaultAnonymousUserTTL and DefaultMaxAnonymousUsers apply otherwise.
+func WithAnonymousUserLimits(ttl time.Duration, maxUsers int) Option {
+	return func(s *TicTacToeServer) {
+		s.anonymousTTL = ttl
+		s.maxAnonymousUsers = maxUsers
+	}
+}
+
+// WithAnonymousUserRateLimit limits CreateAnonymousUser per caller with
+// limiter, on top of any WithRateLimit. Without it the method is limited to
+// DefaultAnonymousUserRate per second with bursts of
+// DefaultAnonymousUserBurst, since anyone may call it.
+func WithAnonymousUserRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(s *TicTacToeServer) {
+		s.anonymousLimiter = limiter
+	}
+}
+
+// anonymousUsers tracks the live identities CreateAnonymousUser issued,
+// oldest first. Every identity lives for the same TTL, so the oldest is
+// always the next to expire.
+type anonymousUsers struct {
+	mu     sync.Mutex
+	issued []issuedIdentity
+}
+
+// issuedIdentity is an anonymous identity's token ("" without auth) and
+// expiry
+type issuedIdentity struct {
+	token   string
+	expires time.Time
+}
+
+// issue records an identity issued at now with token, first forgetting the
+// identities past their TTL and revoking their tokens from tokens (nil
+// without auth). It returns when the identity expires (zero with no TTL),
+// or false when maxUsers identities are still alive (0 = unlimited).
+func (a *anonymousUsers) issue(token string, tokens *store.TokenStore, now time.Time, ttl time.Duration, maxUsers int) (time.Time, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	expired := 0
+	for expired < len(a.issued) && ttl > 0 && !now.Before(a.issued[expired].expires) {
+		if tokens != nil && a.issued[expired].token != "" {
+			tokens.Revoke(a.issued[expired].token)
+		}
+		expired++
+	}
+	a.issued = append(a.issued[:0], a.issued[expired:]...)
+
+	if maxUsers > 0 && len(a.issued) >= maxUsers {
+		return time.Time{}, false
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = now.Add(ttl)
+	}
+	// With neither a TTL nor a cap there is nothing to track
+	if ttl > 0 || maxUsers > 0 {
+		a.issued = append(a.issued, issuedIdentity{token: token, expires: expires})
+	}
+	return expires, true
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/internal/store"
+)
+
+func TestAnonymousUsers_ExpireAndCap(t *testing.T) {
+	var anonymous anonymousUsers
+	tokens := store.NewTokenStore()
+	now := time.Now()
+
+	expires, ok := anonymous.issue("token-1", tokens, now, time.Minute, 2)
+	require.True(t, ok)
+	assert.Equal(t, now.Add(time.Minute), expires)
+	tokens.AddUntil("token-1", "anon-1", expires)
+	_, ok = anonymous.issue("token-2", tokens, now.Add(time.Second), time.Minute, 2)
+	require.True(t, ok)
+
+	// The cap holds until the oldest identity expires, which revokes its token
+	_, ok = anonymous.issue("token-3", tokens, now.Add(30*time.Second), time.Minute, 2)
+	assert.False(t, ok)
+	_, ok = anonymous.issue("token-3", tokens, now.Add(time.Minute), time.Minute, 2)
+	assert.True(t, ok)
+	assert.Len(t, anonymous.issued, 2)
+	_, ok = tokens.Lookup("token-1")
+	assert.False(t, ok)
+}
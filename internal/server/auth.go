@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/store"
+)
+
+// DefaultPublicMethods are the read-only RPCs, including the standard health
+// checks, that may be called without a token
+var DefaultPublicMethods = []string{
+	pb.TicTacToeService_Ping_FullMethodName,
+	pb.TicTacToeService_CreateAnonymousUser_FullMethodName,
+	pb.TicTacToeService_ListPendingGames_FullMethodName,
+	pb.TicTacToeService_ListActiveGames_FullMethodName,
+	pb.TicTacToeService_GetGame_FullMethodName,
+	pb.TicTacToeService_BatchGetGames_FullMethodName,
+	pb.TicTacToeService_GetGameBoard_FullMethodName,
+	pb.TicTacToeService_GetGameCompact_FullMethodName,
+	pb.TicTacToeService_RenderBoard_FullMethodName,
+	pb.TicTacToeService_GetAvailableMoves_FullMethodName,
+	pb.TicTacToeService_GetUserStats_FullMethodName,
+	pb.TicTacToeService_BatchGetUserStats_FullMethodName,
+	pb.TicTacToeService_GetDisplayName_FullMethodName,
+	pb.TicTacToeService_GetGameHistory_FullMethodName,
+	pb.TicTacToeService_GetLeaderboard_FullMethodName,
+	pb.TicTacToeService_WatchLeaderboard_FullMethodName,
+	pb.TicTacToeService_GetOutcomeStats_FullMethodName,
+	pb.TicTacToeService_StreamGameUpdates_FullMethodName,
+	pb.TicTacToeService_ReplayGame_FullMethodName,
+	pb.TicTacToeService_AnalyzePosition_FullMethodName,
+	pb.TicTacToeService_GetTournament_FullMethodName,
+	pb.TicTacToeService_GetTournamentBracket_FullMethodName,
+	healthpb.Health_Check_FullMethodName,
+	healthpb.Health_Watch_FullMethodName,
+}
+
+type userIDKey struct{}
+
+// authenticator validates bearer tokens from the "authorization" metadata
+type authenticator struct {
+	tokens *store.TokenStore
+	public map[string]struct{}
+}
+
+// WithAuth requires a valid "authorization: Bearer <token>" metadata entry on
+// every RPC except the full method names in publicMethods. The token's user
+// becomes the acting player: handlers that act on behalf of a user ignore
+// req.UserId in favor of it and reject requests naming someone else.
+func WithAuth(tokens *store.TokenStore, publicMethods []string) Option {
+	return func(s *TicTacToeServer) {
+		public := make(map[string]struct{}, len(publicMethods))
+		for _, method := range publicMethods {
+			public[method] = struct{}{}
+		}
+		s.auth = &authenticator{tokens: tokens, public: public}
+	}
+}
+
+// tokenBytes is the amount of randomness in an issued token
+const tokenBytes = 32
+
+// newToken returns a random bearer token
+func newToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// WithAdmins names the users allowed to call administrative RPCs such as
+// ResetUserStats. It only matters with WithAuth; without auth there is no
+// identity to check and administrative RPCs are open like every other.
+func WithAdmins(userIDs []string) Option {
+	return func(s *TicTacToeServer) {
+		s.admins = make(map[string]struct{}, len(userIDs))
+		for _, userID := range userIDs {
+			s.admins[userID] = struct{}{}
+		}
+	}
+}
+
+// UserIDFromContext returns the authenticated user, if the request carried a valid token
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(string)
+	return userID, ok
+}
+
+// authenticate resolves the caller's token and returns a context carrying their
+// user ID. Public methods pass through when no token is sent.
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	_, public := a.public[method]
+
+	token, found := bearerToken(ctx)
+	if !found {
+		if public {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	userID, ok := a.tokens.Lookup(token)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	return context.WithValue(ctx, userIDKey{}, userID), nil
+}
+
+// bearerToken extracts the token from the incoming authorization metadata
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", false
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// unaryInterceptor authenticates unary RPCs
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor authenticates streaming RPCs
+func (a *authenticator) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authedStream carries the authenticated context into stream handlers
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context {
+	return s.ctx
+}
+
+// actingUser returns the user a request acts on behalf of. With auth enabled
+// this is the token's user; a differing req.UserId is rejected rather than trusted.
+func (s *TicTacToeServer) actingUser(ctx context.Context, requested string) (string, error) {
+	if s.auth == nil {
+		if requested == "" {
+			return "", requiredFieldError("user_id")
+		}
+		return requested, nil
+	}
+
+	userID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if requested != "" && requested != userID {
+		return "", status.Error(codes.PermissionDenied, "user_id does not match the authenticated user")
+	}
+	return userID, nil
+}
+
+// requireAdmin rejects callers that are not admins when auth is enabled
+func (s *TicTacToeServer) requireAdmin(ctx context.Context) error {
+	if s.auth == nil {
+		return nil
+	}
+
+	userID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	if _, ok := s.admins[userID]; !ok {
+		return status.Error(codes.PermissionDenied, "admin privileges required")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"slices"
+
+	"google.golang.org/grpc"
+	// Registers the gzip compressor, so a client that sends a call
+	// gzip-compressed gets its responses gzip-compressed too
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// WithStreamCompression makes streaming RPCs such as StreamGameUpdates send
+// gzip-compressed messages to every client that accepts gzip, rather than
+// only to clients that compressed their own request. Repeated updates of
+// large boards shrink well, at some CPU cost per message.
+func WithStreamCompression(enabled bool) Option {
+	return func(s *TicTacToeServer) {
+		s.compressStreams = enabled
+	}
+}
+
+// compressStreamInterceptor selects gzip for the messages of a stream whose
+// client advertises gzip in grpc-accept-encoding
+func compressStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if accepted, err := grpc.ClientSupportedCompressors(ss.Context()); err == nil && slices.Contains(accepted, gzip.Name) {
+		// Failure only means the stream keeps the default compressor
+		_ = grpc.SetSendCompressor(ss.Context(), gzip.Name)
+	}
+	return handler(srv, ss)
+}
@@ -1,8 +1,14 @@
 package server
 
 import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
 	pb "tictactoe/api/gen/tictactoe"
 	"tictactoe/internal/game"
+	"tictactoe/internal/store"
 )
 
 // gameToProto converts a GameSnapshot to protobuf Game message
@@ -12,20 +18,60 @@ func gameToProto(snapshot game.GameSnapshot) *pb.Game {
 		board[i] = markToProto(cell)
 	}
 
+	dimensions := int32(2)
+	if snapshot.Board.IsCube() {
+		dimensions = 3
+	}
+	var startedAt int64
+	if !snapshot.StartedAt.IsZero() {
+		startedAt = snapshot.StartedAt.Unix()
+	}
+
 	return &pb.Game{
-		GameId:    snapshot.ID,
-		PlayerXId: snapshot.PlayerX,
-		PlayerOId: snapshot.PlayerO,
-		BoardSize: int32(snapshot.Board.Size),
-		WinLength: int32(snapshot.Board.WinLength),
-		Board:     board,
-		CurrentTurn: markToProto(snapshot.Turn),
-		Status:    statusToProto(snapshot.Status),
-		CreatedAt: snapshot.CreatedAt.Unix(),
-		UpdatedAt: snapshot.UpdatedAt.Unix(),
+		GameId:              snapshot.ID,
+		PlayerXId:           snapshot.PlayerX,
+		PlayerOId:           snapshot.PlayerO,
+		PlayerTriangleId:    snapshot.PlayerTriangle,
+		NumPlayers:          int32(snapshot.NumPlayers),
+		BoardSize:           int32(snapshot.Board.Size),
+		Rows:                int32(snapshot.Board.Rows),
+		Cols:                int32(snapshot.Board.Cols),
+		WinLength:           int32(snapshot.Board.WinLength),
+		Board:               board,
+		CurrentTurn:         markToProto(snapshot.Turn),
+		CurrentTurnUserId:   snapshot.PlayerOnTurn(),
+		Status:              statusToProto(snapshot.Status),
+		Mode:                modeToProto(snapshot.Mode),
+		Misere:              snapshot.Misere,
+		DrawOfferedBy:       markToProto(snapshot.DrawOffer),
+		AbandonedBy:         markToProto(snapshot.AbandonedBy),
+		PlayersOnlyChat:     snapshot.PlayersOnlyChat,
+		EarlyDrawDetection:  snapshot.EarlyDraw,
+		Hints:               snapshot.Hints,
+		MoveCount:           int32(snapshot.MoveCount),
+		ThinkTimeXMs:        snapshot.ThinkTime(snapshot.PlayerX).Milliseconds(),
+		ThinkTimeOMs:        snapshot.ThinkTime(snapshot.PlayerO).Milliseconds(),
+		ThinkTimeTriangleMs: snapshot.ThinkTime(snapshot.PlayerTriangle).Milliseconds(),
+		IsPrivate:           snapshot.Private,
+		Dimensions:          dimensions,
+		CreatedAt:           snapshot.CreatedAt.Unix(),
+		UpdatedAt:           snapshot.UpdatedAt.Unix(),
+		CreatedAtIso:        isoTimestamp(snapshot.CreatedAt),
+		UpdatedAtIso:        isoTimestamp(snapshot.UpdatedAt),
+		StartedAt:           startedAt,
+		ReadyCheck:          snapshot.ReadyCheck,
+		ReadyPlayerIds:      snapshot.ReadyPlayers,
+		RandomStart:         snapshot.RandomStart,
+		Version:             snapshot.Version,
 	}
 }
 
+// isoTimestamp formats t as RFC 3339 in UTC, to the second like the Unix
+// timestamps it accompanies
+func isoTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
 // markToProto converts a game.Mark to protobuf Mark
 func markToProto(m game.Mark) pb.Mark {
 	switch m {
@@ -35,11 +81,40 @@ func markToProto(m game.Mark) pb.Mark {
 		return pb.Mark_MARK_X
 	case game.MarkO:
 		return pb.Mark_MARK_O
+	case game.MarkTriangle:
+		return pb.Mark_MARK_TRIANGLE
 	default:
 		return pb.Mark_MARK_UNSPECIFIED
 	}
 }
 
+// cellFromProto converts a protobuf board cell to a game.Mark, treating an
+// unspecified cell as empty
+func cellFromProto(m pb.Mark) (game.Mark, bool) {
+	switch m {
+	case pb.Mark_MARK_UNSPECIFIED, pb.Mark_MARK_EMPTY:
+		return game.MarkEmpty, true
+	case pb.Mark_MARK_X:
+		return game.MarkX, true
+	case pb.Mark_MARK_O:
+		return game.MarkO, true
+	default:
+		return game.MarkEmpty, false
+	}
+}
+
+// creatorMarkFromProto converts the creator's requested protobuf Mark to a game.Mark
+func creatorMarkFromProto(m pb.Mark) (game.Mark, bool) {
+	switch m {
+	case pb.Mark_MARK_UNSPECIFIED, pb.Mark_MARK_X:
+		return game.MarkX, true
+	case pb.Mark_MARK_O:
+		return game.MarkO, true
+	default:
+		return game.MarkEmpty, false
+	}
+}
+
 // statusToProto converts a game.Status to protobuf GameStatus
 func statusToProto(s game.Status) pb.GameStatus {
 	switch s {
@@ -51,9 +126,159 @@ func statusToProto(s game.Status) pb.GameStatus {
 		return pb.GameStatus_GAME_STATUS_X_WON
 	case game.StatusOWon:
 		return pb.GameStatus_GAME_STATUS_O_WON
+	case game.StatusTriangleWon:
+		return pb.GameStatus_GAME_STATUS_TRIANGLE_WON
 	case game.StatusDraw:
 		return pb.GameStatus_GAME_STATUS_DRAW
+	case game.StatusAbandoned:
+		return pb.GameStatus_GAME_STATUS_ABANDONED
+	case game.StatusCancelled:
+		return pb.GameStatus_GAME_STATUS_CANCELLED
+	case game.StatusReady:
+		return pb.GameStatus_GAME_STATUS_READY
 	default:
 		return pb.GameStatus_GAME_STATUS_UNSPECIFIED
 	}
 }
+
+// modeToProto converts a game.Mode to protobuf GameMode
+func modeToProto(m game.Mode) pb.GameMode {
+	switch m {
+	case game.ModeClassic:
+		return pb.GameMode_GAME_MODE_CLASSIC
+	case game.ModeGravity:
+		return pb.GameMode_GAME_MODE_GRAVITY
+	default:
+		return pb.GameMode_GAME_MODE_UNSPECIFIED
+	}
+}
+
+// modeFromProto converts a protobuf GameMode to game.Mode
+func modeFromProto(m pb.GameMode) (game.Mode, bool) {
+	switch m {
+	case pb.GameMode_GAME_MODE_UNSPECIFIED, pb.GameMode_GAME_MODE_CLASSIC:
+		return game.ModeClassic, true
+	case pb.GameMode_GAME_MODE_GRAVITY:
+		return game.ModeGravity, true
+	default:
+		return game.ModeClassic, false
+	}
+}
+
+// pacingFromProto converts a protobuf ReplayPacing to game.Pacing
+func pacingFromProto(p pb.ReplayPacing) (game.Pacing, bool) {
+	switch p {
+	case pb.ReplayPacing_REPLAY_PACING_UNSPECIFIED, pb.ReplayPacing_REPLAY_PACING_UNIFORM:
+		return game.PacingUniform, true
+	case pb.ReplayPacing_REPLAY_PACING_EMPHASIZE_ENDING:
+		return game.PacingEmphasizeEnding, true
+	default:
+		return game.PacingUniform, false
+	}
+}
+
+// bracketToProto converts a store.Bracket to protobuf BoardBracket
+func bracketToProto(b store.Bracket) pb.BoardBracket {
+	switch b {
+	case store.BracketSmall:
+		return pb.BoardBracket_BOARD_BRACKET_SMALL
+	case store.BracketMedium:
+		return pb.BoardBracket_BOARD_BRACKET_MEDIUM
+	case store.BracketLarge:
+		return pb.BoardBracket_BOARD_BRACKET_LARGE
+	default:
+		return pb.BoardBracket_BOARD_BRACKET_UNSPECIFIED
+	}
+}
+
+// bracketFromProto converts a protobuf BoardBracket to store.Bracket
+func bracketFromProto(b pb.BoardBracket) (store.Bracket, bool) {
+	switch b {
+	case pb.BoardBracket_BOARD_BRACKET_UNSPECIFIED:
+		return store.BracketAll, true
+	case pb.BoardBracket_BOARD_BRACKET_SMALL:
+		return store.BracketSmall, true
+	case pb.BoardBracket_BOARD_BRACKET_MEDIUM:
+		return store.BracketMedium, true
+	case pb.BoardBracket_BOARD_BRACKET_LARGE:
+		return store.BracketLarge, true
+	default:
+		return store.BracketAll, false
+	}
+}
+
+// encodePageToken turns a list cursor into an opaque page token
+func encodePageToken(cursor store.PendingCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + "/" + cursor.GameID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePageToken parses a page token produced by encodePageToken
+func decodePageToken(token string) (store.PendingCursor, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return store.PendingCursor{}, false
+	}
+	nanos, gameID, ok := strings.Cut(string(raw), "/")
+	if !ok || gameID == "" {
+		return store.PendingCursor{}, false
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return store.PendingCursor{}, false
+	}
+	return store.PendingCursor{CreatedAt: time.Unix(0, n), GameID: gameID}, true
+}
+
+// userStatsToProto converts a user's stats, broken down by bracket
+func userStatsToProto(stats store.UserStats) *pb.GetUserStatsResponse {
+	brackets := make([]*pb.BracketStats, 0, store.NumBrackets)
+	for b := store.BracketSmall; b <= store.BracketLarge; b++ {
+		rec := stats.Bracket(b)
+		brackets = append(brackets, &pb.BracketStats{
+			Bracket:    bracketToProto(b),
+			Wins:       rec.Wins,
+			Losses:     rec.Losses,
+			Draws:      rec.Draws,
+			TotalGames: rec.TotalGames(),
+		})
+	}
+
+	return &pb.GetUserStatsResponse{
+		UserId:        stats.UserID,
+		Wins:          stats.Wins,
+		Losses:        stats.Losses,
+		Draws:         stats.Draws,
+		TotalGames:    stats.TotalGames(),
+		Brackets:      brackets,
+		CurrentStreak: stats.CurrentStreak,
+		LongestStreak: stats.LongestStreak,
+	}
+}
+
+// historyEntryToProto describes a finished game from userID's side. In a
+// three-player game the opponent is the next player after userID.
+func historyEntryToProto(snapshot game.GameSnapshot, userID string) *pb.GameHistoryEntry {
+	entry := &pb.GameHistoryEntry{
+		Game:        gameToProto(snapshot),
+		Result:      pb.GameResult_GAME_RESULT_DRAW,
+		FinishedAt:  snapshot.UpdatedAt.Unix(),
+		ThinkTimeMs: snapshot.ThinkTime(userID).Milliseconds(),
+	}
+	players := snapshot.Players()
+	for i, playerID := range players {
+		if playerID == userID {
+			entry.OpponentId = players[(i+1)%len(players)]
+			break
+		}
+	}
+	switch {
+	case snapshot.Status == game.StatusAbandoned:
+		entry.Result = pb.GameResult_GAME_RESULT_ABANDONED
+	case userID == snapshot.GetWinner():
+		entry.Result = pb.GameResult_GAME_RESULT_WIN
+	case !snapshot.IsDraw():
+		entry.Result = pb.GameResult_GAME_RESULT_LOSS
+	}
+	return entry
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of every ErrorInfo detail the server attaches
+const ErrorDomain = "tictactoe"
+
+// Reasons set in the ErrorInfo detail of errors, so clients can tell failures
+// apart without matching on messages. The metadata names the game_id or the
+// field involved.
+const (
+	ReasonFieldRequired        = "FIELD_REQUIRED"
+	ReasonGameNotFound         = "GAME_NOT_FOUND"
+	ReasonGameNotInProgress    = "GAME_NOT_IN_PROGRESS"
+	ReasonNotAPlayer           = "NOT_A_PLAYER"
+	ReasonNotYourTurn          = "NOT_YOUR_TURN"
+	ReasonInvalidPosition      = "INVALID_POSITION"
+	ReasonCellOccupied         = "CELL_OCCUPIED"
+	ReasonColumnFull           = "COLUMN_FULL"
+	ReasonNotLowestEmptyRow    = "NOT_LOWEST_EMPTY_ROW"
+	ReasonNotGravityGame       = "NOT_GRAVITY_GAME"
+	ReasonStaleNonce           = "STALE_NONCE"
+	ReasonMoveCountMismatch    = "MOVE_COUNT_MISMATCH"
+	ReasonDrawOfferPending     = "DRAW_OFFER_PENDING"
+	ReasonNoDrawOffer          = "NO_DRAW_OFFER"
+	ReasonTooManyMovesInFlight = "TOO_MANY_MOVES_IN_FLIGHT"
+	ReasonTwoPlayerOnly        = "TWO_PLAYER_ONLY"
+	ReasonGameNotReady         = "GAME_NOT_READY"
+	ReasonMoveLimitReached     = "MOVE_LIMIT_REACHED"
+)
+
+// errorWithInfo returns a status error carrying an ErrorInfo detail with the
+// given reason and metadata
+func errorWithInfo(code codes.Code, msg, reason string, metadata map[string]string) error {
+	st := status.New(code, msg)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// requiredFieldError reports a missing request field
+func requiredFieldError(field string) error {
+	return errorWithInfo(codes.InvalidArgument, field+" is required", ReasonFieldRequired,
+		map[string]string{"field": field})
+}
+
+// gameNotFoundError reports a game ID that names no stored game
+func gameNotFoundError(gameID string) error {
+	return errorWithInfo(codes.NotFound, "game not found", ReasonGameNotFound,
+		map[string]string{"game_id": gameID})
+}
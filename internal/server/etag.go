@@ -0,0 +1,18 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ETagHeader is the metadata key GetGame sets to the game's version, quoted
+// as an HTTP entity tag so the gateway can return it as the ETag header
+const ETagHeader = "etag"
+
+// setETag sends a game's version in the response's ETag header metadata
+func setETag(ctx context.Context, version int64) {
+	_ = grpc.SetHeader(ctx, metadata.Pairs(ETagHeader, strconv.Quote(strconv.FormatInt(version, 10))))
+}
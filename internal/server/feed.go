@@ -0,0 +1,63 @@
+package server
+
+import (
+	"sync"
+
+	pb "tictactoe/api/gen/tictactoe"
+)
+
+// gameFeed is one game's update streams: its subscribers (channel ->
+// streaming user, "" for anonymous spectators) and the numbered recent
+// updates for replay on reconnect. Each game has its own lock, so a
+// broadcast waiting on a slow stream holds up only that game.
+type gameFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan *pb.GameUpdate]string
+	history     updateHistory
+
+	// removed is set once the feed is dropped from the server; whoever finds
+	// it afterwards looks the game up again
+	removed bool
+}
+
+// feed returns a game's feed, or nil if it has none
+func (s *TicTacToeServer) feed(gameID string) *gameFeed {
+	s.feedsMu.RLock()
+	defer s.feedsMu.RUnlock()
+	return s.feeds[gameID]
+}
+
+// openFeed returns a game's feed locked, creating it if needed
+func (s *TicTacToeServer) openFeed(gameID string) *gameFeed {
+	for {
+		s.feedsMu.Lock()
+		feed, ok := s.feeds[gameID]
+		if !ok {
+			feed = &gameFeed{subscribers: make(map[chan *pb.GameUpdate]string)}
+			s.feeds[gameID] = feed
+		}
+		s.feedsMu.Unlock()
+
+		// Never wait for a feed under feedsMu: its holder may be blocked on
+		// a slow stream
+		feed.mu.Lock()
+		if !feed.removed {
+			return feed
+		}
+		feed.mu.Unlock()
+	}
+}
+
+// lockFeed returns a game's feed locked, or nil if it has none
+func (s *TicTacToeServer) lockFeed(gameID string) *gameFeed {
+	feed := s.feed(gameID)
+	if feed == nil {
+		return nil
+	}
+	feed.mu.Lock()
+	if feed.removed {
+		feed.mu.Unlock()
+		return nil
+	}
+	return feed
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	pb "tictactoe/api/gen/tictactoe"
+)
+
+// UpdateHistorySize is the number of recent updates kept per game for
+// replay to reconnecting subscribers
+const UpdateHistorySize = 64
+
+// updateHistory numbers a game's updates and remembers the most recent ones.
+// Update n is stored at ring[n % UpdateHistorySize].
+type updateHistory struct {
+	latest uint64
+	ring   [UpdateHistorySize]*pb.GameUpdate
+}
+
+// add assigns the next sequence number to update and records it
+func (h *updateHistory) add(update *pb.GameUpdate) {
+	h.latest++
+	update.Sequence = h.latest
+	h.ring[h.latest%UpdateHistorySize] = update
+}
+
+// since returns the updates after sequence seen, oldest first. It reports
+// false when some of them have already been overwritten, or when seen is
+// ahead of anything this game has sent.
+func (h *updateHistory) since(seen uint64) ([]*pb.GameUpdate, bool) {
+	if seen > h.latest || h.latest-seen > UpdateHistorySize {
+		return nil, false
+	}
+	missed := make([]*pb.GameUpdate, 0, h.latest-seen)
+	for seq := seen + 1; seq <= h.latest; seq++ {
+		missed = append(missed, h.ring[seq%UpdateHistorySize])
+	}
+	return missed, true
+}
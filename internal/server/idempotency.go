@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "tictactoe/api/gen/tictactoe"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long CreateGame remembers the game created
+	// for an idempotency key
+	DefaultIdempotencyTTL = 10 * time.Minute
+	// MaxIdempotencyKeyLength caps the idempotency_key of CreateGame
+	MaxIdempotencyKeyLength = 128
+)
+
+// WithIdempotencyTTL sets how long CreateGame remembers the game created for
+// each user's idempotency_key (DefaultIdempotencyTTL otherwise). A retry
+// within ttl gets the same game back instead of creating another. Zero
+// ignores idempotency keys.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.idempotencyTTL = ttl
+	}
+}
+
+// idempotencyKey scopes a client's key to the user who sent it, so users
+// cannot see each other's games by guessing keys
+type idempotencyKey struct {
+	userID string
+	key    string
+}
+
+// idempotentCreate is the game created for an idempotency key. done is
+// closed once the first request finishes; gameID stays empty if it failed.
+type idempotentCreate struct {
+	done     chan struct{}
+	gameID   string
+	joinCode string
+	warning  string
+	expires  time.Time
+}
+
+// idempotencyCache maps recent idempotency keys to the games created for them
+type idempotencyCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[idempotencyKey]*idempotentCreate
+	nextPurge time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:     ttl,
+		entries: make(map[idempotencyKey]*idempotentCreate),
+	}
+}
+
+// claim returns the entry for key. If it is new, the caller must create the
+// game and then call finish; otherwise the caller waits on the entry's done.
+func (c *idempotencyCache) claim(key idempotencyKey) (*idempotentCreate, bool) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired entries at most once per TTL so the map stays small
+	// without a sweeper goroutine
+	if now.After(c.nextPurge) {
+		for k, entry := range c.entries {
+			if !entry.expires.IsZero() && now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextPurge = now.Add(c.ttl)
+	}
+
+	if entry, ok := c.entries[key]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		return entry, false
+	}
+	entry := &idempotentCreate{done: make(chan struct{})}
+	c.entries[key] = entry
+	return entry, true
+}
+
+// finish records the response to a claimed key, or forgets the key when the
+// create failed (resp is nil) so a retry can try again
+func (c *idempotencyCache) finish(key idempotencyKey, entry *idempotentCreate, resp *pb.CreateGameResponse) {
+	c.mu.Lock()
+	if resp == nil {
+		delete(c.entries, key)
+	} else {
+		entry.gameID = resp.Game.GameId
+		entry.joinCode = resp.JoinCode
+		entry.warning = resp.Warning
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+}
+
+// forget drops key if it still maps to entry, as when its game is gone
+func (c *idempotencyCache) forget(key idempotencyKey, entry *idempotentCreate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+}
+
+// createGameOnce creates a game for the first request with an idempotency
+// key and answers later ones, including concurrent ones, with that game as
+// it stands. The retries' other fields are not compared with the first
+// request's.
+func (s *TicTacToeServer) createGameOnce(ctx context.Context, userID string, req *pb.CreateGameRequest) (*pb.CreateGameResponse, error) {
+	if len(req.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency_key must be at most %d characters", MaxIdempotencyKeyLength)
+	}
+	key := idempotencyKey{userID: userID, key: req.IdempotencyKey}
+
+	for {
+		entry, first := s.idempotency.claim(key)
+		if first {
+			resp, err := s.createGame(userID, req)
+			s.idempotency.finish(key, entry, resp)
+			return resp, err
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if entry.gameID == "" {
+			// The first request failed; this one gets its own attempt
+			continue
+		}
+		g, err := s.gameStore.Get(entry.gameID)
+		if err != nil {
+			// The game is gone, so there is nothing to return twice
+			s.idempotency.forget(key, entry)
+			continue
+		}
+		return &pb.CreateGameResponse{
+			Game:     s.gameProto(g.GetSnapshot()),
+			JoinCode: entry.joinCode,
+			Warning:  entry.warning,
+		}, nil
+	}
+}
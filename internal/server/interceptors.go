@@ -0,0 +1,36 @@
+package server
+
+import "google.golang.org/grpc"
+
+// GRPCServerOptions returns the grpc.Server options (interceptors) required by
+// the features enabled on this server. Pass them to grpc.NewServer.
+func (s *TicTacToeServer) GRPCServerOptions() []grpc.ServerOption {
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+
+	// Logging runs outermost so the request ID is set for everything below it
+	if s.logging != nil {
+		unary = append(unary, s.logging.unaryInterceptor)
+		stream = append(stream, s.logging.streamInterceptor)
+	}
+	if s.metrics != nil {
+		unary = append(unary, s.metrics.unaryInterceptor)
+		stream = append(stream, s.metrics.streamInterceptor)
+	}
+	if s.auth != nil {
+		unary = append(unary, s.auth.unaryInterceptor)
+		stream = append(stream, s.auth.streamInterceptor)
+	}
+	// Rate limiting runs after auth so callers are charged by authenticated identity
+	if s.rateLimiter != nil {
+		unary = append(unary, s.rateLimitInterceptor)
+	}
+	if s.compressStreams {
+		stream = append(stream, compressStreamInterceptor)
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
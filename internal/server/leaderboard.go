@@ -0,0 +1,149 @@
+package server
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/store"
+)
+
+// DefaultLeaderboardDebounce is how long WatchLeaderboard lets a burst of
+// results settle before recomputing the leaderboard
+const DefaultLeaderboardDebounce = 250 * time.Millisecond
+
+// WithLeaderboardDebounce sets how long WatchLeaderboard waits after a
+// change to the stats before recomputing and sending the leaderboard
+// (DefaultLeaderboardDebounce otherwise). Results recorded meanwhile are
+// covered by the same update.
+func WithLeaderboardDebounce(d time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.leaderboardDebounce = d
+	}
+}
+
+// ranking is the top of a bracket's leaderboard as of a stats version
+type ranking struct {
+	version    uint64
+	entries    []store.LeaderboardEntry
+	totalCount int
+}
+
+// topOfLeaderboard returns the first limit entries of a bracket's
+// leaderboard, at most maxListLimit, and the number of ranked users. The
+// top is ranked once per change to the stats and shared by every caller, so
+// streams watching a bracket do not each sort every user.
+func (s *TicTacToeServer) topOfLeaderboard(bracket store.Bracket, limit int) ([]store.LeaderboardEntry, int) {
+	s.rankingsMu.Lock()
+	defer s.rankingsMu.Unlock()
+
+	// Read the version first: a change made while ranking bumps it again
+	version := s.statsStore.Version()
+	cached, ok := s.rankings[bracket]
+	if !ok || cached.version != version {
+		entries, totalCount := s.statsStore.Leaderboard(bracket, s.tieBreak, s.maxListLimit, 0)
+		cached = ranking{version: version, entries: entries, totalCount: totalCount}
+		if s.rankings == nil {
+			s.rankings = make(map[store.Bracket]ranking)
+		}
+		s.rankings[bracket] = cached
+	}
+	return cached.entries[:min(limit, len(cached.entries))], cached.totalCount
+}
+
+// leaderboardEntries converts a page of the leaderboard to protobuf, with
+// the users' display names
+func (s *TicTacToeServer) leaderboardEntries(entries []store.LeaderboardEntry) []*pb.LeaderboardEntry {
+	pbEntries := make([]*pb.LeaderboardEntry, len(entries))
+	for i, e := range entries {
+		pbEntries[i] = &pb.LeaderboardEntry{
+			Rank:        int32(e.Rank),
+			UserId:      e.UserID,
+			DisplayName: s.names.DisplayName(e.UserID),
+			Wins:        e.Record.Wins,
+			Losses:      e.Record.Losses,
+			Draws:       e.Record.Draws,
+			TotalGames:  e.Record.TotalGames(),
+		}
+	}
+	return pbEntries
+}
+
+// WatchLeaderboard streams the top limit entries of the leaderboard, first
+// as they stand and then each time they change: a new user among them, a
+// change of order, or a new result for one of them. Results below the top
+// entries send nothing.
+func (s *TicTacToeServer) WatchLeaderboard(req *pb.WatchLeaderboardRequest, stream pb.TicTacToeService_WatchLeaderboardServer) error {
+	bracket, ok := bracketFromProto(req.Bracket)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown bracket %v", req.Bracket)
+	}
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > s.maxListLimit {
+		limit = s.maxListLimit
+	}
+
+	// Watch before the first read so no change falls between the two
+	changes, stop := s.statsStore.Watch()
+	defer stop()
+
+	var sent []*pb.LeaderboardEntry
+	send := func() error {
+		entries, totalCount := s.topOfLeaderboard(bracket, limit)
+		pbEntries := s.leaderboardEntries(entries)
+		if sent != nil && sameEntries(sent, pbEntries) {
+			return nil
+		}
+		sent = pbEntries
+		return stream.Send(&pb.LeaderboardUpdate{Entries: pbEntries, TotalCount: int32(totalCount)})
+	}
+	if err := send(); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-changes:
+		case <-s.closed:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		// Let a burst of results settle, then read them all at once
+		select {
+		case <-time.After(s.leaderboardDebounce):
+		case <-s.closed:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		select {
+		case <-changes:
+		default:
+		}
+		if err := send(); err != nil {
+			return err
+		}
+	}
+}
+
+// sameEntries reports whether two pages of the leaderboard are identical
+func sameEntries(a, b []*pb.LeaderboardEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
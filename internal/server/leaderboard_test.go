@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/internal/store"
+)
+
+func TestTopOfLeaderboard_SharedUntilChange(t *testing.T) {
+	stats := store.NewStatsStore(1)
+	s := NewTicTacToeServer(store.NewGameStore(1), stats)
+	stats.RecordGameResult("alice", "bob", false, 3)
+	stats.RecordGameResult("carol", "bob", false, 3)
+
+	// Callers share one ranking, each taking the entries it asked for
+	top, total := s.topOfLeaderboard(store.BracketAll, 1)
+	all, _ := s.topOfLeaderboard(store.BracketAll, 10)
+	require.Len(t, top, 1)
+	require.Len(t, all, 3)
+	assert.Equal(t, 3, total)
+	assert.Same(t, &top[0], &all[0])
+
+	// A change ranks again
+	stats.RecordGameResult("carol", "alice", false, 3)
+	top, _ = s.topOfLeaderboard(store.BracketAll, 1)
+	require.Len(t, top, 1)
+	assert.Equal(t, "carol", top[0].UserID)
+	assert.NotSame(t, &top[0], &all[0])
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is the metadata key carrying the request ID in both directions
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength bounds client-supplied request IDs; longer ones are replaced
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// requestLogger logs one structured line per RPC
+type requestLogger struct {
+	logger *slog.Logger
+}
+
+// WithRequestLogging logs the method, user and game IDs, duration and status
+// code of every RPC to logger. Each request is tagged with the client's
+// x-request-id metadata, or a fresh ID when absent, which is returned in the
+// response header metadata and available via RequestIDFromContext.
+// Only IDs are taken from request messages; payloads and metadata are never logged.
+func WithRequestLogging(logger *slog.Logger) Option {
+	return func(s *TicTacToeServer) {
+		s.logging = &requestLogger{logger: logger}
+	}
+}
+
+// RequestIDFromContext returns the request ID assigned by the logging
+// interceptor, or an empty string when request logging is disabled
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestID returns the incoming request ID, generating one if none was sent
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDHeader); len(ids) > 0 && ids[0] != "" && len(ids[0]) <= maxRequestIDLength {
+			return ids[0]
+		}
+	}
+	return uuid.New().String()
+}
+
+// log writes the summary line for one RPC
+func (l *requestLogger) log(ctx context.Context, method, id string, req any, start time.Time, err error) {
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("request_id", id),
+	}
+	if r, ok := req.(interface{ GetUserId() string }); ok && r.GetUserId() != "" {
+		attrs = append(attrs, slog.String("user_id", r.GetUserId()))
+	}
+	if r, ok := req.(interface{ GetGameId() string }); ok && r.GetGameId() != "" {
+		attrs = append(attrs, slog.String("game_id", r.GetGameId()))
+	}
+	attrs = append(attrs,
+		slog.Duration("duration", time.Since(start)),
+		slog.String("code", status.Code(err).String()),
+	)
+
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	l.logger.LogAttrs(ctx, level, "rpc", attrs...)
+}
+
+// unaryInterceptor tags and logs unary RPCs
+func (l *requestLogger) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	id := requestID(ctx)
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+	resp, err := handler(ctx, req)
+	l.log(ctx, info.FullMethod, id, req, start, err)
+	return resp, err
+}
+
+// streamInterceptor tags streaming RPCs and logs them once the stream ends
+func (l *requestLogger) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	id := requestID(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(RequestIDHeader, id))
+
+	ls := &loggedStream{
+		ServerStream: ss,
+		ctx:          context.WithValue(ss.Context(), requestIDKey{}, id),
+	}
+	err := handler(srv, ls)
+	l.log(ls.ctx, info.FullMethod, id, ls.req, start, err)
+	return err
+}
+
+// loggedStream carries the request ID context and remembers the first
+// received message so its IDs can be logged
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req any
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggedStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// latencyBuckets are latency buckets in seconds suited to in-memory RPCs
+var latencyBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+// serverMetrics holds the RPC and gameplay metrics recorded by the server
+type serverMetrics struct {
+	requests     *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+	gamesCreated prometheus.Counter
+	moves        prometheus.Counter
+	streamDrops  *prometheus.CounterVec
+}
+
+// WithMetrics registers per-method RPC counts, status codes and latencies
+// plus gameplay counters and gauges with reg. The RPC metrics are collected
+// by the interceptors returned from GRPCServerOptions.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(s *TicTacToeServer) {
+		s.metrics = &serverMetrics{
+			requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "tictactoe_grpc_requests_total",
+				Help: "RPCs completed, by method and status code.",
+			}, []string{"method", "code"}),
+			latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "tictactoe_grpc_request_duration_seconds",
+				Help:    "RPC latency in seconds, by method.",
+				Buckets: latencyBuckets,
+			}, []string{"method"}),
+			gamesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "tictactoe_games_created_total",
+				Help: "Games created.",
+			}),
+			moves: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "tictactoe_moves_total",
+				Help: "Moves accepted.",
+			}),
+			streamDrops: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "tictactoe_stream_dropped_updates_total",
+				Help: "Updates dropped because a stream's buffer was full, by overflow policy.",
+			}, []string{"policy"}),
+		}
+
+		reg.MustRegister(
+			s.metrics.requests,
+			s.metrics.latency,
+			s.metrics.gamesCreated,
+			s.metrics.moves,
+			s.metrics.streamDrops,
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "tictactoe_games",
+				Help: "Games currently held in the game store.",
+			}, func() float64 {
+				return float64(s.gameStore.Count())
+			}),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "tictactoe_stream_subscribers",
+				Help: "Active game update stream subscribers.",
+			}, func() float64 {
+				return float64(s.SubscriberCount())
+			}),
+			newShardCollector("tictactoe_game_store_shard_games",
+				"Games held in each game store shard, by shard index.", func() []int {
+					return s.gameStore.ShardSizes()
+				}),
+			newShardCollector("tictactoe_stats_store_shard_users",
+				"Users tracked in each stats store shard, by shard index.", func() []int {
+					return s.statsStore.ShardSizes()
+				}),
+		)
+	}
+}
+
+// shardCollector reports one gauge per store shard, labeled with the shard
+// index and read from sizes on every scrape
+type shardCollector struct {
+	desc  *prometheus.Desc
+	sizes func() []int
+}
+
+func newShardCollector(name, help string, sizes func() []int) *shardCollector {
+	return &shardCollector{
+		desc:  prometheus.NewDesc(name, help, []string{"shard"}, nil),
+		sizes: sizes,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *shardCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *shardCollector) Collect(ch chan<- prometheus.Metric) {
+	for i, size := range c.sizes() {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(size), strconv.Itoa(i))
+	}
+}
+
+// observe records the outcome of one RPC
+func (m *serverMetrics) observe(method string, start time.Time, err error) {
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// unaryInterceptor records metrics for unary RPCs
+func (m *serverMetrics) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observe(info.FullMethod, start, err)
+	return resp, err
+}
+
+// streamInterceptor records metrics for streaming RPCs once the stream ends
+func (m *serverMetrics) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observe(info.FullMethod, start, err)
+	return err
+}
+
+// recordGameCreated counts a newly created game
+func (m *serverMetrics) recordGameCreated() {
+	if m != nil {
+		m.gamesCreated.Inc()
+	}
+}
+
+// recordMove counts an accepted move
+func (m *serverMetrics) recordMove() {
+	if m != nil {
+		m.moves.Inc()
+	}
+}
+
+// recordStreamDrop counts an update dropped for a full stream buffer
+func (m *serverMetrics) recordStreamDrop(policy OverflowPolicy) {
+	if m != nil {
+		m.streamDrops.WithLabelValues(policy.String()).Inc()
+	}
+}
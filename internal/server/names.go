@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/game"
+	"tictactoe/internal/store"
+)
+
+// MaxDisplayNameLength is the longest display name accepted, in characters
+const MaxDisplayNameLength = 32
+
+// WithNameStore sets the store of users' display names (an empty one
+// otherwise), for example to share it between servers
+func WithNameStore(names *store.NameStore) Option {
+	return func(s *TicTacToeServer) {
+		s.names = names
+	}
+}
+
+// sanitizeDisplayName trims a display name and collapses runs of whitespace
+// to one space. Names with control or formatting characters, which could
+// garble or disguise what other players see, are rejected.
+func sanitizeDisplayName(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", status.Error(codes.InvalidArgument, "display_name must be valid UTF-8")
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if unicode.IsControl(r) || unicode.In(r, unicode.Cf) {
+			return "", status.Error(codes.InvalidArgument, "display_name must not contain control characters")
+		}
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		return "", status.Errorf(codes.InvalidArgument, "display_name must be at most %d characters", MaxDisplayNameLength)
+	}
+	return name, nil
+}
+
+// gameProto converts a snapshot to protobuf with the players' display names
+func (s *TicTacToeServer) gameProto(snapshot game.GameSnapshot) *pb.Game {
+	return s.withDisplayNames(gameToProto(snapshot))
+}
+
+// withDisplayNames fills in the display names of a game's players
+func (s *TicTacToeServer) withDisplayNames(g *pb.Game) *pb.Game {
+	g.PlayerXName = s.names.DisplayName(g.PlayerXId)
+	g.PlayerOName = s.names.DisplayName(g.PlayerOId)
+	g.PlayerTriangleName = s.names.DisplayName(g.PlayerTriangleId)
+	return g
+}
+
+// SetDisplayName sets the name shown for a user in games and the
+// leaderboard. An empty name clears it, so the user ID is shown again.
+func (s *TicTacToeServer) SetDisplayName(ctx context.Context, req *pb.SetDisplayNameRequest) (*pb.SetDisplayNameResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	name, err := sanitizeDisplayName(req.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.names.Set(userID, name)
+
+	return &pb.SetDisplayNameResponse{
+		UserId:      userID,
+		DisplayName: s.names.DisplayName(userID),
+	}, nil
+}
+
+// GetDisplayName retrieves the name shown for a user, which is their ID
+// until they set one
+func (s *TicTacToeServer) GetDisplayName(ctx context.Context, req *pb.GetDisplayNameRequest) (*pb.GetDisplayNameResponse, error) {
+	if req.UserId == "" {
+		return nil, requiredFieldError("user_id")
+	}
+
+	name, ok := s.names.Get(req.UserId)
+	if !ok {
+		name = req.UserId
+	}
+	return &pb.GetDisplayNameResponse{
+		UserId:      req.UserId,
+		DisplayName: name,
+		IsSet:       ok,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pb "tictactoe/api/gen/tictactoe"
+)
+
+const (
+	// DefaultStreamBuffer is how many updates a stream buffers for a slow client
+	DefaultStreamBuffer = 10
+	// DefaultStreamBlockTimeout is how long OverflowBlock waits for room
+	DefaultStreamBlockTimeout = 100 * time.Millisecond
+)
+
+// OverflowPolicy decides what happens to an update for a stream whose buffer
+// is full
+type OverflowPolicy int
+
+const (
+	OverflowDropNewest OverflowPolicy = iota // The new update is dropped
+	OverflowDropOldest                       // The oldest buffered update is dropped to make room
+	OverflowBlock                            // The sender waits for room up to a timeout, then drops the new update
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// ParseOverflowPolicy parses a policy name as returned by OverflowPolicy.String
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	for _, p := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowBlock} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return OverflowDropNewest, fmt.Errorf("unknown overflow policy %q", name)
+}
+
+// WithStreamBuffer sets how many updates each StreamGameUpdates stream
+// buffers for a slow client (DefaultStreamBuffer otherwise). size must be at
+// least 1.
+func WithStreamBuffer(size int) Option {
+	return func(s *TicTacToeServer) {
+		s.streamBuffer = size
+	}
+}
+
+// WithStreamOverflow sets what happens to an update for a stream whose buffer
+// is full (OverflowDropNewest by default). OverflowBlock waits up to
+// blockTimeout for the client to catch up; it holds up the other streams
+// and broadcasts of that client's game while it waits, but no other game. The update that ends the streams
+// of an expired or removed game is always delivered, in place of the oldest
+// buffered ones.
+func WithStreamOverflow(policy OverflowPolicy, blockTimeout time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.streamOverflow = policy
+		s.streamBlockTimeout = blockTimeout
+	}
+}
+
+// deliver sends an update to one subscriber, applying the overflow policy
+// when its buffer is full (must hold the game's feed lock)
+func (s *TicTacToeServer) deliver(ch chan *pb.GameUpdate, update *pb.GameUpdate) {
+	select {
+	case ch <- update:
+		return
+	default:
+	}
+
+	switch s.streamOverflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case <-ch:
+				s.metrics.recordStreamDrop(s.streamOverflow)
+			default:
+				// The client caught up in the meantime
+			}
+			select {
+			case ch <- update:
+				return
+			default:
+			}
+		}
+	case OverflowBlock:
+		timer := time.NewTimer(s.streamBlockTimeout)
+		defer timer.Stop()
+		select {
+		case ch <- update:
+			return
+		case <-timer.C:
+		}
+	}
+	s.metrics.recordStreamDrop(s.streamOverflow)
+}
+
+// deliverLast sends the update a stream ends on, dropping the oldest buffered
+// updates to make room whatever the overflow policy: losing the last one
+// would leave the client without the reason its stream ended (must hold
+// the game's feed lock)
+func (s *TicTacToeServer) deliverLast(ch chan *pb.GameUpdate, update *pb.GameUpdate) {
+	for {
+		select {
+		case ch <- update:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+			s.metrics.recordStreamDrop(s.streamOverflow)
+		default:
+			// The client caught up in the meantime
+		}
+	}
+}
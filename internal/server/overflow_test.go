@@ -0,0 +1,140 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/store"
+)
+
+// overflowServer returns a server with metrics, two-update stream buffers
+// and the given overflow policy
+func overflowServer(policy OverflowPolicy, blockTimeout time.Duration) *TicTacToeServer {
+	return NewTicTacToeServer(store.NewGameStore(1), store.NewStatsStore(1),
+		WithMetrics(prometheus.NewRegistry()), WithStreamBuffer(2), WithStreamOverflow(policy, blockTimeout))
+}
+
+// broadcastN broadcasts n numbered updates to a game
+func broadcastN(s *TicTacToeServer, gameID string, n int) {
+	for i := 0; i < n; i++ {
+		s.broadcastUpdate(gameID, &pb.GameUpdate{})
+	}
+}
+
+// sequences lists the sequence numbers of the updates waiting on ch
+func sequences(ch chan *pb.GameUpdate) []uint64 {
+	var seqs []uint64
+	for _, update := range drain(ch) {
+		seqs = append(seqs, update.Sequence)
+	}
+	return seqs
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, p := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowBlock} {
+		parsed, err := ParseOverflowPolicy(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	_, err := ParseOverflowPolicy("drop-everything")
+	assert.Error(t, err)
+}
+
+func TestStreamOverflow_DropNewest(t *testing.T) {
+	s := overflowServer(OverflowDropNewest, 0)
+	ch := make(chan *pb.GameUpdate, s.streamBuffer)
+	s.subscribe("game", ch, "", 0)
+
+	// Nobody reads, so the updates after the first two are dropped
+	broadcastN(s, "game", 4)
+	assert.Equal(t, []uint64{1, 2}, sequences(ch))
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.streamDrops.WithLabelValues("drop-newest")))
+}
+
+func TestStreamOverflow_FinalUpdateDelivered(t *testing.T) {
+	s := overflowServer(OverflowDropNewest, 0)
+	ch := make(chan *pb.GameUpdate, s.streamBuffer)
+	s.subscribe("game", ch, "", 0)
+
+	// The update a stream ends on displaces older ones instead of being dropped
+	broadcastN(s, "game", 2)
+	s.broadcastFinal("game", &pb.GameUpdate{Message: "Game expired, no opponent joined"})
+	updates := drain(ch)
+	if assert.Len(t, updates, 2) {
+		assert.Equal(t, uint64(2), updates[0].Sequence)
+		assert.Equal(t, "Game expired, no opponent joined", updates[1].Message)
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.streamDrops.WithLabelValues("drop-newest")))
+}
+
+func TestStreamOverflow_DropOldest(t *testing.T) {
+	s := overflowServer(OverflowDropOldest, 0)
+	ch := make(chan *pb.GameUpdate, s.streamBuffer)
+	s.subscribe("game", ch, "", 0)
+
+	// The buffer keeps the latest updates, so a lagging client still ends up current
+	broadcastN(s, "game", 4)
+	assert.Equal(t, []uint64{3, 4}, sequences(ch))
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.streamDrops.WithLabelValues("drop-oldest")))
+}
+
+func TestStreamOverflow_Block(t *testing.T) {
+	s := overflowServer(OverflowBlock, time.Second)
+	ch := make(chan *pb.GameUpdate, s.streamBuffer)
+	s.subscribe("game", ch, "", 0)
+
+	// A slow consumer that keeps within the timeout misses nothing
+	received := make(chan []uint64)
+	go func() {
+		var seqs []uint64
+		for len(seqs) < 6 {
+			time.Sleep(10 * time.Millisecond)
+			seqs = append(seqs, (<-ch).Sequence)
+		}
+		received <- seqs
+	}()
+	broadcastN(s, "game", 6)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6}, <-received)
+	assert.Zero(t, testutil.ToFloat64(s.metrics.streamDrops.WithLabelValues("block")))
+
+	// A consumer that stops reading costs one timeout per update, then the update is dropped
+	s.streamBlockTimeout = 20 * time.Millisecond
+	start := time.Now()
+	broadcastN(s, "game", 3)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, []uint64{7, 8}, sequences(ch))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.streamDrops.WithLabelValues("block")))
+}
+
+func TestStreamOverflow_BlockHoldsUpOnlyItsGame(t *testing.T) {
+	s := overflowServer(OverflowBlock, time.Second)
+	stalled := make(chan *pb.GameUpdate, s.streamBuffer)
+	s.subscribe("game-a", stalled, "", 0)
+	broadcastN(s, "game-a", 2)
+
+	// A broadcast on game A waits for its stalled stream...
+	blocked := make(chan struct{})
+	go func() {
+		defer close(blocked)
+		broadcastN(s, "game-a", 1)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// ...while game B's streams and broadcasts go on
+	ch := make(chan *pb.GameUpdate, s.streamBuffer)
+	start := time.Now()
+	s.subscribe("game-b", ch, "", 0)
+	broadcastN(s, "game-b", 1)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, []uint64{1}, sequences(ch))
+	assert.Equal(t, 2, s.SubscriberCount())
+
+	<-blocked
+	assert.Equal(t, []uint64{1, 2}, sequences(stalled))
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/game"
+	"tictactoe/internal/store"
+)
+
+// CreateGameFromPosition creates a classic game that starts from a position
+// instead of an empty board, for puzzles and testing. The position must be
+// reachable with turn to move and, unless allow_decided is set, not already
+// decided. Given both player IDs the game starts at once, which seats the
+// opponent without asking, so with auth enabled only an admin may do it.
+// Given one, that player must be the caller and the game is pending like any
+// other until the opponent joins. Either way its result never counts
+// towards player stats.
+func (s *TicTacToeServer) CreateGameFromPosition(ctx context.Context, req *pb.CreateGameFromPositionRequest) (*pb.CreateGameFromPositionResponse, error) {
+	creatorID, creatorMark, opponentID := req.PlayerXId, game.MarkX, req.PlayerOId
+	if req.PlayerXId != "" && req.PlayerOId != "" {
+		if err := s.requireAdmin(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		userID, err := s.actingUser(ctx, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+		switch userID {
+		case req.PlayerXId:
+		case req.PlayerOId:
+			creatorID, creatorMark, opponentID = req.PlayerOId, game.MarkO, ""
+		default:
+			return nil, status.Error(codes.InvalidArgument, "player_x_id or player_o_id must be the caller")
+		}
+	}
+
+	boardSize := int(req.BoardSize)
+	if boardSize == 0 {
+		boardSize = s.defaultBoardSize
+	}
+	if boardSize < 3 || boardSize > s.maxBoardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size must be between 3 and %d", s.maxBoardSize)
+	}
+	winLength := int(req.WinLength)
+	if winLength == 0 {
+		winLength = s.defaultWinLengthFor(boardSize)
+	}
+
+	cells, turn, err := positionFromProto(req.Board, req.Turn)
+	if err != nil {
+		return nil, err
+	}
+	opts := []game.Option{
+		game.WithCreatorMark(creatorMark),
+		game.WithMoveAdmissionLimit(s.moveAdmissionLimit),
+		game.WithMaxMoves(s.maxMoves),
+	}
+	// The join code keeps anyone else from taking the opponent's seat first
+	joinCode := ""
+	if opponentID != "" {
+		if joinCode, err = newJoinCode(); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate join code: %v", err)
+		}
+		opts = append(opts, game.WithJoinCode(joinCode))
+	}
+	g, err := game.NewGameFromBoard(uuid.New().String(), creatorID, boardSize, winLength, cells, turn, req.AllowDecided, opts...)
+	if err != nil {
+		return nil, positionErrorToStatus(err, boardSize, len(cells))
+	}
+
+	if err := s.gameStore.Create(g); err != nil {
+		return nil, createErrorToStatus(err)
+	}
+	if opponentID != "" {
+		if _, err := s.gameStore.Join(g.ID, opponentID, game.UsingJoinCode(joinCode), game.ComparingIDs(s.userIDPolicy.normalize)); err != nil {
+			s.gameStore.Delete(g.ID)
+			switch err {
+			case store.ErrTooManyGames:
+				return nil, status.Error(codes.ResourceExhausted, "too many active games")
+			case game.ErrCannotJoinOwnGame:
+				return nil, status.Error(codes.InvalidArgument, "player_x_id and player_o_id must differ")
+			default:
+				return nil, status.Errorf(codes.Internal, "failed to seat player_o_id: %v", err)
+			}
+		}
+	}
+	s.metrics.recordGameCreated()
+
+	snapshot := g.GetSnapshot()
+	if snapshot.Status.IsFinished() {
+		// A decided position ends as it starts
+		s.recordGameResult(g, snapshot)
+	}
+	s.updateFingerprint(snapshot)
+
+	return &pb.CreateGameFromPositionResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// positionFromProto converts the board and player to move of a position
+func positionFromProto(board []pb.Mark, turn pb.Mark) ([]game.Mark, game.Mark, error) {
+	cells := make([]game.Mark, len(board))
+	for i, cell := range board {
+		mark, ok := cellFromProto(cell)
+		if !ok {
+			return nil, game.MarkEmpty, status.Errorf(codes.InvalidArgument, "unknown mark %v in cell %d", cell, i)
+		}
+		cells[i] = mark
+	}
+	switch turn {
+	case pb.Mark_MARK_X:
+		return cells, game.MarkX, nil
+	case pb.Mark_MARK_O:
+		return cells, game.MarkO, nil
+	default:
+		return nil, game.MarkEmpty, status.Error(codes.InvalidArgument, "turn must be MARK_X or MARK_O")
+	}
+}
+
+// positionErrorToStatus converts an error rejecting a position to a gRPC status
+func positionErrorToStatus(err error, boardSize, numCells int) error {
+	switch err {
+	case game.ErrInvalidWinLength:
+		return status.Errorf(codes.InvalidArgument, "win_length must be between 3 and board_size (%d)", boardSize)
+	case game.ErrWrongCellCount:
+		return status.Errorf(codes.InvalidArgument, "board must have %d cells, got %d", boardSize*boardSize, numCells)
+	case game.ErrUnreachablePosition:
+		return status.Error(codes.InvalidArgument, "mark counts cannot arise with this player to move; X moves first")
+	case game.ErrPositionDecided:
+		return status.Error(codes.InvalidArgument, "position already contains a completed line")
+	case game.ErrPositionDrawn:
+		return status.Error(codes.InvalidArgument, "position is already drawn")
+	default:
+		return status.Errorf(codes.InvalidArgument, "invalid position: %v", err)
+	}
+}
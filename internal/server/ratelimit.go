@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"tictactoe/internal/ratelimit"
+)
+
+// WithRateLimit limits unary RPCs per caller with limiter. Callers are the
+// authenticated user when WithAuth is enabled and a token was sent, otherwise
+// the peer IP, or for calls through a proxy named by WithTrustedProxies the
+// client it forwarded them for; request user_id fields are never trusted for
+// this.
+func WithRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(s *TicTacToeServer) {
+		s.rateLimiter = limiter
+	}
+}
+
+// WithTrustedProxies names the peers, such as the REST gateway, that call on
+// behalf of other clients. A call from one of them is charged to the client
+// its x-forwarded-for metadata names, found by skipping trusted proxies from
+// the right, since anything to the left of the last one's entry came from the
+// client and may be forged. Without it every caller without a token is
+// charged as its peer IP, so all REST clients share the gateway's limit.
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(s *TicTacToeServer) {
+		s.trustedProxies = proxies
+	}
+}
+
+// rateLimitKey identifies the caller a request is charged to
+func (s *TicTacToeServer) rateLimitKey(ctx context.Context) string {
+	if userID, ok := UserIDFromContext(ctx); ok {
+		return "user:" + userID
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			addr = host
+		}
+		if s.trustedProxy(addr) {
+			addr = s.forwardedClient(ctx, addr)
+		}
+		return "ip:" + addr
+	}
+	return "anonymous"
+}
+
+// forwardedClient returns the client a call from a trusted proxy at addr was
+// made for: the rightmost x-forwarded-for entry that is not itself a trusted
+// proxy, or the leftmost if all are
+func (s *TicTacToeServer) forwardedClient(ctx context.Context, addr string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var hops []string
+	for _, value := range md.Get("x-forwarded-for") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// A garbled entry cannot be trusted to name anyone
+			return addr
+		}
+		addr = hop
+		if !s.trustedProxy(hop) {
+			break
+		}
+	}
+	return addr
+}
+
+// trustedProxy reports whether addr is in one of the trusted proxy ranges
+func (s *TicTacToeServer) trustedProxy(addr string) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitInterceptor rejects unary RPCs from callers over their rate
+func (s *TicTacToeServer) rateLimitInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if ok, wait := s.rateLimiter.Allow(s.rateLimitKey(ctx)); !ok {
+		return nil, status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %v", wait.Round(time.Millisecond))
+	}
+	return handler(ctx, req)
+}
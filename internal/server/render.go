@@ -0,0 +1,133 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/game"
+)
+
+// SVG layout, in pixels
+const (
+	svgCellSize  = 60
+	svgPadding   = 10
+	svgMarkInset = 14
+)
+
+// renderBoard renders a snapshot in the requested format and returns the
+// content with its MIME type
+func renderBoard(snapshot game.GameSnapshot, format pb.RenderFormat) (string, string, bool) {
+	switch format {
+	case pb.RenderFormat_RENDER_FORMAT_UNSPECIFIED, pb.RenderFormat_RENDER_FORMAT_ASCII:
+		return snapshotToBoardResponse(snapshot, false).BoardDisplay, "text/plain", true
+	case pb.RenderFormat_RENDER_FORMAT_UNICODE_BOX:
+		return renderUnicodeBox(snapshot.Board), "text/plain", true
+	case pb.RenderFormat_RENDER_FORMAT_SVG:
+		return renderSVG(snapshot), "image/svg+xml", true
+	default:
+		return "", "", false
+	}
+}
+
+// renderUnicodeBox draws the board with box-drawing characters
+func renderUnicodeBox(board *game.Board) string {
+	border := func(left, mid, right string) string {
+		return left + strings.Repeat("───"+mid, board.Cols-1) + "───" + right + "\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(border("┌", "┬", "┐"))
+	for row := 0; row < board.Rows; row++ {
+		for col := 0; col < board.Cols; col++ {
+			mark, _ := board.Get(row, col)
+			sb.WriteString("│ " + markToChar(mark) + " ")
+		}
+		sb.WriteString("│\n")
+		if row < board.Rows-1 {
+			sb.WriteString(border("├", "┼", "┤"))
+		}
+	}
+	sb.WriteString(border("└", "┴", "┘"))
+	return sb.String()
+}
+
+// renderSVG draws the board as a standalone SVG document. The cells of every
+// winning line of a won game are shaded and each line is struck through.
+func renderSVG(snapshot game.GameSnapshot) string {
+	board := snapshot.Board
+	width := board.Cols*svgCellSize + 2*svgPadding
+	height := board.Rows*svgCellSize + 2*svgPadding
+
+	var lines [][][2]int
+	if snapshot.Status == game.StatusXWon || snapshot.Status == game.StatusOWon || snapshot.Status == game.StatusTriangleWon {
+		lines = board.WinningLines()
+	}
+
+	// cellOrigin returns the top-left corner of a cell
+	cellOrigin := func(row, col int) (int, int) {
+		return svgPadding + col*svgCellSize, svgPadding + row*svgCellSize
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&sb, `<title>%s</title>`, getStatusString(snapshot.Status))
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#ffffff"/>`, width, height)
+
+	// Lines completed by the same move share a cell; shade it once
+	shaded := make(map[[2]int]bool)
+	for _, line := range lines {
+		for _, cell := range line {
+			if shaded[cell] {
+				continue
+			}
+			shaded[cell] = true
+			x, y := cellOrigin(cell[0], cell[1])
+			fmt.Fprintf(&sb, `<rect class="win" x="%d" y="%d" width="%d" height="%d" fill="#fff3b0"/>`, x, y, svgCellSize, svgCellSize)
+		}
+	}
+
+	// Grid
+	right, bottom := width-svgPadding, height-svgPadding
+	for col := 0; col <= board.Cols; col++ {
+		x := svgPadding + col*svgCellSize
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333333" stroke-width="2"/>`, x, svgPadding, x, bottom)
+	}
+	for row := 0; row <= board.Rows; row++ {
+		y := svgPadding + row*svgCellSize
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#333333" stroke-width="2"/>`, svgPadding, y, right, y)
+	}
+
+	// Marks
+	for row := 0; row < board.Rows; row++ {
+		for col := 0; col < board.Cols; col++ {
+			mark, _ := board.Get(row, col)
+			x, y := cellOrigin(row, col)
+			switch mark {
+			case game.MarkX:
+				x1, y1, x2, y2 := x+svgMarkInset, y+svgMarkInset, x+svgCellSize-svgMarkInset, y+svgCellSize-svgMarkInset
+				fmt.Fprintf(&sb, `<g class="x" stroke="#d33c3c" stroke-width="6" stroke-linecap="round"><line x1="%d" y1="%d" x2="%d" y2="%d"/><line x1="%d" y1="%d" x2="%d" y2="%d"/></g>`,
+					x1, y1, x2, y2, x2, y1, x1, y2)
+			case game.MarkO:
+				fmt.Fprintf(&sb, `<circle class="o" cx="%d" cy="%d" r="%d" fill="none" stroke="#3c6ed3" stroke-width="6"/>`,
+					x+svgCellSize/2, y+svgCellSize/2, svgCellSize/2-svgMarkInset)
+			case game.MarkTriangle:
+				left, right, top, bottom := x+svgMarkInset, x+svgCellSize-svgMarkInset, y+svgMarkInset, y+svgCellSize-svgMarkInset
+				fmt.Fprintf(&sb, `<polygon class="triangle" points="%d,%d %d,%d %d,%d" fill="none" stroke="#2e9e4f" stroke-width="6" stroke-linejoin="round"/>`,
+					x+svgCellSize/2, top, right, bottom, left, bottom)
+			}
+		}
+	}
+
+	// Strike through each winning line from the first cell's center to the last's
+	for _, line := range lines {
+		x1, y1 := cellOrigin(line[0][0], line[0][1])
+		x2, y2 := cellOrigin(line[len(line)-1][0], line[len(line)-1][1])
+		half := svgCellSize / 2
+		fmt.Fprintf(&sb, `<line class="strike" x1="%d" y1="%d" x2="%d" y2="%d" stroke="#e6a800" stroke-width="8" stroke-linecap="round" stroke-opacity="0.8"/>`,
+			x1+half, y1+half, x2+half, y2+half)
+	}
+
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
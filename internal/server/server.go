@@ -2,24 +2,62 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/ai"
 	"tictactoe/internal/game"
+	"tictactoe/internal/ratelimit"
 	"tictactoe/internal/store"
 )
 
 const (
-	DefaultBoardSize  = 3
-	DefaultWinLength  = 3
-	DefaultListLimit  = 50
-	MaxBoardSize      = 20
-	MaxListLimit      = 100
+	DefaultBoardSize = 3
+	DefaultWinLength = 3
+	DefaultListLimit = 50
+
+	// MaxBoardSize and MaxListLimit are the default caps on board_size and
+	// on list page sizes; see WithMaxBoardSize and WithMaxListLimit
+	MaxBoardSize = 20
+	MaxListLimit = 100
+
+	// MaxCubeSize is the largest board_size allowed for a 3D game
+	MaxCubeSize = 6
+
+	// DefaultReplayDelay is the pause before each replayed move at speed 1
+	DefaultReplayDelay = time.Second
+	// MaxReplaySpeed is the fastest playback speed ReplayGame accepts
+	MaxReplaySpeed = 1000
+
+	// MaxChatMessageLength is the longest chat message accepted, in characters
+	MaxChatMessageLength = 500
+
+	// MaxBatchGetGames is the most game IDs one BatchGetGames call may request
+	MaxBatchGetGames = 100
+
+	// MaxBatchGetUserStats is the most user IDs one BatchGetUserStats call may request
+	MaxBatchGetUserStats = 100
+
+	// AnalysisTimeout bounds the search behind one AnalyzePosition call
+	AnalysisTimeout = 2 * time.Second
+	// MaxAnalysisDepth caps the search depth on boards larger than 3x3, where
+	// AnalyzePosition and hints only estimate the position
+	MaxAnalysisDepth = 4
+	// HintTimeout bounds the search behind one hint
+	HintTimeout = time.Second
 )
 
 // TicTacToeServer implements the gRPC TicTacToeService
@@ -29,54 +67,635 @@ type TicTacToeServer struct {
 	gameStore  *store.GameStore
 	statsStore *store.StatsStore
 
-	// Subscribers for game updates (gameID -> set of channels)
-	subscribersMu sync.RWMutex
-	subscribers   map[string]map[chan *pb.GameUpdate]struct{}
+	// Users' display names
+	names *store.NameStore
+
+	// Outcomes of standard games per board configuration
+	outcomes *store.OutcomeStore
+
+	// Tournaments, advanced as their games finish
+	tournaments *store.TournamentStore
+
+	// Optional index of board fingerprints for duplicate detection (nil when disabled)
+	fingerprints *store.FingerprintIndex
+
+	// Optional metrics (nil when disabled)
+	metrics *serverMetrics
+
+	// Optional per-request logging (nil when disabled)
+	logging *requestLogger
+
+	// Optional token authentication (nil when disabled)
+	auth *authenticator
+
+	// Users allowed to call administrative RPCs when auth is enabled
+	admins map[string]struct{}
+
+	// Optional per-caller rate limiting (nil when disabled)
+	rateLimiter *ratelimit.Limiter
+
+	// Peers whose x-forwarded-for metadata names the caller to rate limit
+	trustedProxies []netip.Prefix
+
+	// Board used when a request to create a game or analyze a position leaves
+	// board_size or win_length unset
+	defaultBoardSize int
+	defaultWinLength int
+
+	// Largest board_size accepted, and largest page returned by list RPCs
+	maxBoardSize int
+	maxListLimit int
+
+	// Per-game cap on concurrent move attempts (0 = unlimited)
+	moveAdmissionLimit int
+
+	// Order of leaderboard users with equal wins
+	tieBreak store.TieBreak
+
+	// How long WatchLeaderboard lets a burst of results settle
+	leaderboardDebounce time.Duration
+
+	// Top of each bracket's leaderboard, shared by WatchLeaderboard streams
+	rankingsMu sync.Mutex
+	rankings   map[store.Bracket]ranking
+
+	// How abandoned games count in stats
+	abandonPolicy store.AbandonPolicy
+
+	// How long GetServerStats may serve a cached response (0 = always fresh)
+	serverStatsTTL   time.Duration
+	serverStatsMu    sync.Mutex
+	serverStats      *pb.GetServerStatsResponse
+	serverStatsTaken time.Time
+
+	// How long a game may wait for an opponent before it expires (0 = forever)
+	pendingGameTTL time.Duration
+
+	// How long a game may be in progress before it is abandoned (0 = forever)
+	maxGameDuration time.Duration
+
+	// Cap on the moves in each game (0 = one per cell)
+	maxMoves int
+
+	// Cap on each user's tournaments under way (0 = unlimited), and how long
+	// decided tournaments are kept (0 = forever)
+	maxTournamentsPerUser int
+	finishedTournamentTTL time.Duration
+
+	// Build version reported by Ping
+	version string
+
+	// Prefix of the IDs CreateAnonymousUser issues (anonymous users are disabled when unset)
+	anonymousPrefix string
+
+	// How long anonymous identities live and how many may (0 = forever,
+	// unlimited), how fast each caller may ask for one, and the live ones
+	anonymousTTL      time.Duration
+	maxAnonymousUsers int
+	anonymousLimiter  *ratelimit.Limiter
+	anonymous         anonymousUsers
+
+	// Which user IDs JoinGame treats as the same user
+	userIDPolicy UserIDPolicy
+
+	// How long CreateGame remembers idempotency keys, and the keys it remembers
+	// (nil when the TTL is zero)
+	idempotencyTTL time.Duration
+	idempotency    *idempotencyCache
+
+	// Updates buffered per stream, and what happens when a buffer is full
+	streamBuffer       int
+	streamOverflow     OverflowPolicy
+	streamBlockTimeout time.Duration
+
+	// Streams are gzip-compressed for every client that accepts gzip
+	compressStreams bool
+
+	// How long a spectator may stream a game before the stream ends (0 = forever)
+	spectatorStreamLifetime time.Duration
+
+	// Update streams of each game; feedsMu guards the map, each feed its own
+	// contents
+	feedsMu         sync.RWMutex
+	feeds           map[string]*gameFeed
+	subscriberCount atomic.Int64
+
+	// closed is closed by Close to end every open update stream
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Option configures optional server behavior
+type Option func(*TicTacToeServer)
+
+// WithFingerprintIndex enables tracking of board fingerprints for in-progress games
+// so that identical scripted games can be reported by ListDuplicateGames
+func WithFingerprintIndex(idx *store.FingerprintIndex) Option {
+	return func(s *TicTacToeServer) {
+		s.fingerprints = idx
+	}
+}
+
+// WithDefaultBoard sets the board size and win length used when a request
+// leaves them unset (DefaultBoardSize and DefaultWinLength otherwise). Check
+// the pair with ValidateDefaultBoard first.
+func WithDefaultBoard(boardSize, winLength int) Option {
+	return func(s *TicTacToeServer) {
+		s.defaultBoardSize = boardSize
+		s.defaultWinLength = winLength
+	}
+}
+
+// ValidateDefaultBoard checks that a default board size and win length could
+// be requested explicitly from a server capped at maxBoardSize
+func ValidateDefaultBoard(boardSize, winLength, maxBoardSize int) error {
+	if boardSize < 3 || boardSize > maxBoardSize {
+		return fmt.Errorf("board size must be between 3 and %d, got %d", maxBoardSize, boardSize)
+	}
+	if winLength < 3 || winLength > boardSize {
+		return fmt.Errorf("win length must be between 3 and the board size (%d), got %d", boardSize, winLength)
+	}
+	return nil
+}
+
+// WithMaxBoardSize sets the largest board_size CreateGame,
+// CreateGameFromPosition, AnalyzePosition and ImportGame accept (MaxBoardSize
+// by default). Larger boards cost more memory per game and more time per
+// move. Values below 3 are ignored.
+func WithMaxBoardSize(size int) Option {
+	return func(s *TicTacToeServer) {
+		if size >= 3 {
+			s.maxBoardSize = size
+		}
+	}
+}
+
+// WithMaxListLimit sets the largest page ListPendingGames, ListActiveGames,
+// GetGameHistory and GetLeaderboard return (MaxListLimit by default). Values below 1 are ignored.
+func WithMaxListLimit(limit int) Option {
+	return func(s *TicTacToeServer) {
+		if limit >= 1 {
+			s.maxListLimit = limit
+		}
+	}
+}
+
+// WithMoveAdmissionLimit caps the number of concurrent move attempts per game.
+// Attempts beyond the limit are rejected with ResourceExhausted instead of
+// queuing on the game lock. A real game has at most one legitimate pending
+// move, so a small limit is enough.
+func WithMoveAdmissionLimit(limit int) Option {
+	return func(s *TicTacToeServer) {
+		s.moveAdmissionLimit = limit
+	}
+}
+
+// WithLeaderboardTieBreak sets how GetLeaderboard orders users with equal wins
+// (most games played first by default)
+func WithLeaderboardTieBreak(tieBreak store.TieBreak) Option {
+	return func(s *TicTacToeServer) {
+		s.tieBreak = tieBreak
+	}
+}
+
+// WithAbandonPolicy sets how abandoned games count in stats (not at all by default)
+func WithAbandonPolicy(policy store.AbandonPolicy) Option {
+	return func(s *TicTacToeServer) {
+		s.abandonPolicy = policy
+	}
+}
+
+// WithServerStatsCache lets GetServerStats reuse its last response for up to
+// ttl, so frequent polling does not rescan every game
+func WithServerStatsCache(ttl time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.serverStatsTTL = ttl
+	}
+}
+
+// WithPendingGameTTL expires pending games nobody has joined within ttl, and
+// ready games whose players have not all started them within ttl of the last
+// change. An expired game is removed and its subscribers get a final
+// CANCELLED update.
+// Zero, the default, keeps pending games until they are joined or left.
+func WithPendingGameTTL(ttl time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.pendingGameTTL = ttl
+	}
+}
+
+// WithMaxGameDuration abandons games still in progress d after they
+// started, blaming the player on turn, as a safety net for games that never
+// finish. Ready games still waiting for Start d after they were created are
+// abandoned by the first player who has not started them. Subscribers get the final ABANDONED update. Zero, the default,
+// lets games run forever.
+func WithMaxGameDuration(d time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.maxGameDuration = d
+	}
+}
+
+// WithSpectatorStreamLifetime ends each StreamGameUpdates stream of a
+// spectator, anyone not seated in the game, after d with a final "Spectator
+// session expired" update, so streams left open on abandoned games do not
+// linger. Players' streams are exempt. Zero, the default, lets spectators
+// stream until the game ends.
+func WithSpectatorStreamLifetime(d time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.spectatorStreamLifetime = d
+	}
+}
+
+// WithMaxMoves caps the moves each created or imported game accepts (see
+// game.WithMaxMoves). Zero, the default, allows one move per cell, which no
+// game exceeds by normal play.
+func WithMaxMoves(limit int) Option {
+	return func(s *TicTacToeServer) {
+		s.maxMoves = limit
+	}
+}
+
+// WithVersion sets the build version Ping reports ("dev" by default)
+func WithVersion(version string) Option {
+	return func(s *TicTacToeServer) {
+		s.version = version
+	}
+}
+
+// WithAnonymousUsers enables CreateAnonymousUser, which issues IDs made of
+// prefix and a random UUID. With auth enabled each ID comes with a token
+// added to the token store, so anyone can obtain an identity; identities
+// expire and are capped (see WithAnonymousUserLimits), and each caller is
+// rate limited (see WithAnonymousUserRateLimit).
+func WithAnonymousUsers(prefix string) Option {
+	return func(s *TicTacToeServer) {
+		s.anonymousPrefix = prefix
+	}
 }
 
 // NewTicTacToeServer creates a new server instance
-func NewTicTacToeServer(gameStore *store.GameStore, statsStore *store.StatsStore) *TicTacToeServer {
-	return &TicTacToeServer{
-		gameStore:   gameStore,
-		statsStore:  statsStore,
-		subscribers: make(map[string]map[chan *pb.GameUpdate]struct{}),
+func NewTicTacToeServer(gameStore *store.GameStore, statsStore *store.StatsStore, opts ...Option) *TicTacToeServer {
+	s := &TicTacToeServer{
+		gameStore:             gameStore,
+		statsStore:            statsStore,
+		outcomes:              store.NewOutcomeStore(),
+		tournaments:           store.NewTournamentStore(),
+		defaultBoardSize:      DefaultBoardSize,
+		defaultWinLength:      DefaultWinLength,
+		maxBoardSize:          MaxBoardSize,
+		maxListLimit:          MaxListLimit,
+		streamBuffer:          DefaultStreamBuffer,
+		leaderboardDebounce:   DefaultLeaderboardDebounce,
+		idempotencyTTL:        DefaultIdempotencyTTL,
+		anonymousTTL:          DefaultAnonymousUserTTL,
+		maxAnonymousUsers:     DefaultMaxAnonymousUsers,
+		maxTournamentsPerUser: DefaultMaxTournamentsPerUser,
+		finishedTournamentTTL: DefaultFinishedTournamentTTL,
+		version:               "dev",
+		feeds:                 make(map[string]*gameFeed),
+		closed:                make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.names == nil {
+		s.names = store.NewNameStore(64)
+	}
+	if s.idempotencyTTL > 0 {
+		s.idempotency = newIdempotencyCache(s.idempotencyTTL)
+	}
+	if s.anonymousPrefix != "" && s.anonymousLimiter == nil {
+		s.anonymousLimiter = ratelimit.New(DefaultAnonymousUserRate, DefaultAnonymousUserBurst)
+	}
+	gameStore.OnEvict(s.gameEvicted)
+	if s.pendingGameTTL > 0 {
+		go s.sweepPendingGames()
+	}
+	if s.maxGameDuration > 0 {
+		go s.sweepLongGames()
+	}
+	go s.sweepTournaments()
+	return s
+}
+
+// sweepPendingGames expires idle pending games every half TTL until Close
+func (s *TicTacToeServer) sweepPendingGames() {
+	ticker := time.NewTicker(s.pendingGameTTL / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.expirePendingGames(time.Now().Add(-s.pendingGameTTL))
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// expirePendingGames removes pending and ready games idle since before
+// cutoff. Each game's subscribers get its final CANCELLED state before their
+// streams end.
+func (s *TicTacToeServer) expirePendingGames(cutoff time.Time) {
+	for _, g := range s.gameStore.ExpirePending(cutoff) {
+		snapshot := g.GetSnapshot()
+		message := "Game expired, no opponent joined"
+		if !slices.Contains(snapshot.Players(), "") {
+			message = "Game expired, not every player started it"
+		}
+		s.broadcastFinal(snapshot.ID, &pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:    s.gameProto(snapshot),
+			Message: message,
+		})
+	}
+}
+
+// gameEvicted tells the subscribers of a game the store evicted to make room
+// that it is gone, then ends their streams. A pending game arrives cancelled.
+func (s *TicTacToeServer) gameEvicted(g *game.Game) {
+	snapshot := g.GetSnapshot()
+	s.broadcastFinal(snapshot.ID, &pb.GameUpdate{
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: "Game removed to make room for new games",
+	})
+}
+
+// sweepLongGames abandons overlong games every half maximum duration until Close
+func (s *TicTacToeServer) sweepLongGames() {
+	ticker := time.NewTicker(s.maxGameDuration / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.timeOutGames(time.Now().Add(-s.maxGameDuration))
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// timeOutGames abandons games in progress since before cutoff, recording
+// their results and notifying their subscribers
+func (s *TicTacToeServer) timeOutGames(cutoff time.Time) {
+	for _, g := range s.gameStore.TimeOutInProgress(cutoff) {
+		snapshot := g.GetSnapshot()
+		// Running out of time decides no tournament match: it is replayed
+		// instead of awarded, once this game's seats are freed
+		replays, _ := s.tournaments.RecordResult(snapshot.ID, "")
+		s.recordGameResult(g, snapshot)
+		s.startMatches(replays)
+		s.updateFingerprint(snapshot)
+		s.broadcastFinal(snapshot.ID, &pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:    s.gameProto(snapshot),
+			Message: fmt.Sprintf("Game ran past the time limit. %s", s.getUpdateMessage(snapshot)),
+		})
 	}
 }
 
-// CreateGame creates a new game and waits for an opponent
+// CreateGame creates a new game and waits for an opponent. A request
+// repeating a recent idempotency_key gets the game created for it instead.
 func (s *TicTacToeServer) CreateGame(ctx context.Context, req *pb.CreateGameRequest) (*pb.CreateGameResponse, error) {
-	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
 	}
+	if req.IdempotencyKey != "" && s.idempotency != nil {
+		return s.createGameOnce(ctx, userID, req)
+	}
+	return s.createGame(userID, req)
+}
 
+// createGame validates a CreateGame request and creates the game
+func (s *TicTacToeServer) createGame(userID string, req *pb.CreateGameRequest) (*pb.CreateGameResponse, error) {
+	// A board is square unless rows and cols ask for a rectangle
 	boardSize := int(req.BoardSize)
-	if boardSize == 0 {
-		boardSize = DefaultBoardSize
+	rows, cols := int(req.Rows), int(req.Cols)
+	switch {
+	case rows == 0 && cols == 0:
+		if boardSize == 0 {
+			boardSize = s.defaultBoardSize
+		}
+		rows, cols = boardSize, boardSize
+	case rows == 0 || cols == 0:
+		return nil, status.Error(codes.InvalidArgument, "rows and cols must be set together")
+	case boardSize != 0 && boardSize != max(rows, cols):
+		return nil, status.Error(codes.InvalidArgument, "board_size must be the larger of rows and cols when both are set")
+	default:
+		boardSize = max(rows, cols)
 	}
-	if boardSize < 3 || boardSize > MaxBoardSize {
-		return nil, status.Errorf(codes.InvalidArgument, "board_size must be between 3 and %d", MaxBoardSize)
+	if min(rows, cols) < 3 || boardSize > s.maxBoardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size, rows and cols must be between 3 and %d", s.maxBoardSize)
 	}
 
 	winLength := int(req.WinLength)
 	if winLength == 0 {
-		winLength = DefaultWinLength
+		winLength = s.defaultWinLengthFor(boardSize)
 	}
 	if winLength < 3 || winLength > boardSize {
 		return nil, status.Errorf(codes.InvalidArgument, "win_length must be between 3 and board_size (%d)", boardSize)
 	}
 
+	mode, ok := modeFromProto(req.Mode)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown mode %v", req.Mode)
+	}
+
+	creatorMark, ok := creatorMarkFromProto(req.CreatorMark)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "creator_mark must be MARK_X or MARK_O")
+	}
+	if req.RandomStart && req.CreatorMark != pb.Mark_MARK_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "creator_mark cannot be combined with random_start")
+	}
+
+	switch req.Dimensions {
+	case 0, 2:
+	case 3:
+		if rows != cols {
+			return nil, status.Error(codes.InvalidArgument, "3D games need a square board")
+		}
+		if boardSize > MaxCubeSize {
+			return nil, status.Errorf(codes.InvalidArgument, "board_size must be at most %d for 3D games", MaxCubeSize)
+		}
+		if mode != game.ModeClassic {
+			return nil, status.Error(codes.InvalidArgument, "3D games only support classic mode")
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "dimensions must be 2 or 3")
+	}
+
+	numPlayers := int(req.NumPlayers)
+	switch numPlayers {
+	case 0:
+		numPlayers = 2
+	case 2:
+	case 3:
+		if req.Misere {
+			return nil, status.Error(codes.InvalidArgument, "misère is only supported in two-player games")
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "num_players must be 2 or 3")
+	}
+
+	opts := []game.Option{
+		game.WithMode(mode),
+		game.WithPlayers(numPlayers),
+		game.WithCreatorMark(creatorMark),
+		game.WithMoveAdmissionLimit(s.moveAdmissionLimit),
+		game.WithMaxMoves(s.maxMoves),
+	}
+	if req.Misere {
+		opts = append(opts, game.WithMisere())
+	}
+	if req.PlayersOnlyChat {
+		opts = append(opts, game.WithPlayersOnlyChat())
+	}
+	if req.EarlyDrawDetection {
+		opts = append(opts, game.WithEarlyDraw())
+	}
+	if req.Hints {
+		// The search only plays classic two-player tic-tac-toe on a flat board
+		if mode != game.ModeClassic || req.Dimensions == 3 || numPlayers != 2 || req.Misere {
+			return nil, status.Error(codes.InvalidArgument, "hints are only available in two-player classic 2D games that are not misère")
+		}
+		opts = append(opts, game.WithHints())
+	}
+	if req.AutoStart != nil && !*req.AutoStart {
+		opts = append(opts, game.WithReadyCheck())
+	}
+	if req.RandomStart {
+		opts = append(opts, game.WithRandomStart())
+	}
+	if req.Seed != nil {
+		opts = append(opts, game.WithSeed(*req.Seed))
+	}
+	if req.Dimensions == 3 {
+		opts = append(opts, game.With3D())
+	}
+	var joinCode string
+	if req.IsPrivate {
+		var err error
+		joinCode, err = newJoinCode()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate join code: %v", err)
+		}
+		opts = append(opts, game.WithJoinCode(joinCode))
+	}
+
 	gameID := uuid.New().String()
-	g, err := game.NewGame(gameID, req.UserId, boardSize, winLength)
+	g, err := game.NewRectGame(gameID, userID, rows, cols, winLength, opts...)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create game: %v", err)
 	}
 
 	if err := s.gameStore.Create(g); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to store game: %v", err)
+		return nil, createErrorToStatus(err)
 	}
+	s.metrics.recordGameCreated()
 
 	return &pb.CreateGameResponse{
-		Game: gameToProto(g.GetSnapshot()),
+		Game:     s.gameProto(g.GetSnapshot()),
+		JoinCode: joinCode,
+		Warning:  boardWarning(rows, cols, winLength),
+	}, nil
+}
+
+// createErrorToStatus converts an error storing a new or imported game to a gRPC status
+func createErrorToStatus(err error) error {
+	switch err {
+	case store.ErrTooManyGames:
+		return status.Error(codes.ResourceExhausted, "too many active games")
+	case store.ErrStoreFull:
+		return status.Error(codes.ResourceExhausted, "server is full of games in progress")
+	case store.ErrTooManyPending:
+		return status.Error(codes.ResourceExhausted, "too many games waiting for an opponent")
+	default:
+		return status.Errorf(codes.Internal, "failed to store game: %v", err)
+	}
+}
+
+// boardWarning returns an advisory note when a board configuration is valid
+// but unlikely to make a good game, or "" if there is nothing to flag.
+// Needing a full row on a board larger than 3x3 is easily blocked, so such
+// games almost always end in a draw.
+func boardWarning(rows, cols, winLength int) string {
+	if longest := max(rows, cols); longest > 3 && winLength == longest {
+		return fmt.Sprintf("win_length %d fills a whole row of a %dx%d board; games will almost always be drawn", winLength, rows, cols)
+	}
+	return ""
+}
+
+// defaultWinLengthFor returns the default win length for a board, shortened
+// to fit a board smaller than the default one
+func (s *TicTacToeServer) defaultWinLengthFor(boardSize int) int {
+	if s.defaultWinLength > boardSize {
+		return boardSize
+	}
+	return s.defaultWinLength
+}
+
+// joinCodeAlphabet leaves out characters that are easy to misread when a
+// join code is passed on by hand
+const joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// joinCodeLength is the number of characters in a private game's join code
+const joinCodeLength = 8
+
+// newJoinCode returns a random join code for a private game
+func newJoinCode() (string, error) {
+	buf := make([]byte, joinCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		// len(joinCodeAlphabet) divides 256, so every character is equally likely
+		buf[i] = joinCodeAlphabet[int(b)%len(joinCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// CreateAnonymousUser issues a fresh user ID, and with auth enabled a token
+// for it, so a client without an account can start playing. The identity
+// expires after the anonymous user TTL.
+func (s *TicTacToeServer) CreateAnonymousUser(ctx context.Context, req *pb.CreateAnonymousUserRequest) (*pb.CreateAnonymousUserResponse, error) {
+	if s.anonymousPrefix == "" {
+		return nil, status.Error(codes.FailedPrecondition, "anonymous users are not enabled")
+	}
+	if ok, wait := s.anonymousLimiter.Allow(s.rateLimitKey(ctx)); !ok {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many anonymous users requested, retry in %v", wait.Round(time.Millisecond))
+	}
+
+	userID := s.anonymousPrefix + uuid.New().String()
+	var token string
+	var tokens *store.TokenStore
+	if s.auth != nil {
+		var err error
+		token, err = newToken()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
+		}
+		tokens = s.auth.tokens
+	}
+	expires, ok := s.anonymous.issue(token, tokens, time.Now(), s.anonymousTTL, s.maxAnonymousUsers)
+	if !ok {
+		return nil, status.Error(codes.ResourceExhausted, "too many anonymous users; try again later")
+	}
+	if tokens != nil {
+		tokens.AddUntil(token, userID, expires)
+	}
+
+	var expiresAt int64
+	if !expires.IsZero() {
+		expiresAt = expires.Unix()
+	}
+	return &pb.CreateAnonymousUserResponse{
+		UserId:    userID,
+		Token:     token,
+		ExpiresAt: expiresAt,
 	}, nil
 }
 
@@ -86,23 +705,75 @@ func (s *TicTacToeServer) ListPendingGames(ctx context.Context, req *pb.ListPend
 	if limit <= 0 {
 		limit = DefaultListLimit
 	}
-	if limit > MaxListLimit {
-		limit = MaxListLimit
+	if limit > s.maxListLimit {
+		limit = s.maxListLimit
 	}
 
 	offset := int(req.Offset)
 	if offset < 0 {
 		offset = 0
 	}
+	if req.PageToken != "" && offset != 0 {
+		return nil, status.Error(codes.InvalidArgument, "set either page_token or offset, not both")
+	}
 
-	games, totalCount := s.gameStore.ListPending(limit, offset)
+	filter := store.PendingFilter{
+		BoardSize: int(req.BoardSize),
+		WinLength: int(req.WinLength),
+	}
+
+	// Fetch one extra game to learn whether there is a next page
+	var games []*game.GameSnapshot
+	var totalCount int
+	if req.PageToken != "" {
+		cursor, ok := decodePageToken(req.PageToken)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		games, totalCount = s.gameStore.ListPendingAfter(filter, cursor, limit+1)
+	} else {
+		games, totalCount = s.gameStore.ListPending(filter, limit+1, offset)
+	}
+
+	var nextPageToken string
+	if len(games) > limit {
+		games = games[:limit]
+		nextPageToken = encodePageToken(store.CursorFor(games[limit-1]))
+	}
 
 	pbGames := make([]*pb.Game, len(games))
 	for i, g := range games {
-		pbGames[i] = gameToProto(*g)
+		pbGames[i] = s.gameProto(*g)
 	}
 
 	return &pb.ListPendingGamesResponse{
+		Games:         pbGames,
+		TotalCount:    int32(totalCount),
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+// ListActiveGames returns in-progress games a spectator can watch
+func (s *TicTacToeServer) ListActiveGames(ctx context.Context, req *pb.ListActiveGamesRequest) (*pb.ListActiveGamesResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > s.maxListLimit {
+		limit = s.maxListLimit
+	}
+	offset := int(req.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	games, totalCount := s.gameStore.ListActive(limit, offset)
+	pbGames := make([]*pb.Game, len(games))
+	for i, g := range games {
+		pbGames[i] = s.gameProto(*g)
+	}
+
+	return &pb.ListActiveGamesResponse{
 		Games:      pbGames,
 		TotalCount: int32(totalCount),
 	}, nil
@@ -110,158 +781,867 @@ func (s *TicTacToeServer) ListPendingGames(ctx context.Context, req *pb.ListPend
 
 // JoinGame joins an existing pending game
 func (s *TicTacToeServer) JoinGame(ctx context.Context, req *pb.JoinGameRequest) (*pb.JoinGameResponse, error) {
-	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
 	}
 	if req.GameId == "" {
-		return nil, status.Error(codes.InvalidArgument, "game_id is required")
+		return nil, requiredFieldError("game_id")
 	}
 
-	g, err := s.gameStore.Get(req.GameId)
+	g, err := s.gameStore.Join(req.GameId, userID, game.UsingJoinCode(req.JoinCode), game.ComparingIDs(s.userIDPolicy.normalize))
 	if err != nil {
-		if err == store.ErrGameNotFound {
-			return nil, status.Error(codes.NotFound, "game not found")
-		}
-		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
-	}
-
-	if err := g.Join(req.UserId); err != nil {
 		switch err {
+		case store.ErrGameNotFound:
+			return nil, gameNotFoundError(req.GameId)
+		case store.ErrTooManyGames:
+			return nil, status.Error(codes.ResourceExhausted, "too many active games")
 		case game.ErrGameAlreadyStarted:
 			return nil, status.Error(codes.FailedPrecondition, "game has already started")
+		case store.ErrJoinRaceLost:
+			return nil, status.Error(codes.Aborted, "another player joined first")
 		case game.ErrCannotJoinOwnGame:
 			return nil, status.Error(codes.InvalidArgument, "cannot join your own game")
+		case game.ErrWrongJoinCode:
+			return nil, status.Error(codes.PermissionDenied, "join code does not match")
 		default:
 			return nil, status.Errorf(codes.Internal, "failed to join game: %v", err)
 		}
 	}
 
 	snapshot := g.GetSnapshot()
+	if snapshot.Status.IsFinished() {
+		// A game set up from a decided position ends as it starts
+		s.recordGameResult(g, snapshot)
+	}
 
-	// Notify subscribers that the game has started
+	// Notify subscribers that the game has started, or that a three-player
+	// game still has a seat open
+	message := s.getUpdateMessage(snapshot)
+	switch snapshot.Status {
+	case game.StatusPending:
+		message = "Player joined; waiting for more players"
+	case game.StatusReady:
+		message = "All players joined; waiting for each player to start the game"
+	case game.StatusInProgress:
+		message = "Game started! " + message
+	}
 	s.broadcastUpdate(req.GameId, &pb.GameUpdate{
-		Game:    gameToProto(snapshot),
-		Message: "Game started! Player X's turn.",
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: message,
 	})
+	s.pushHint(g, snapshot)
 
 	return &pb.JoinGameResponse{
-		Game: gameToProto(snapshot),
+		Game: s.gameProto(snapshot),
 	}, nil
 }
 
-// MakeMove makes a move in an active game
-func (s *TicTacToeServer) MakeMove(ctx context.Context, req *pb.MakeMoveRequest) (*pb.MakeMoveResponse, error) {
-	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+// StartGame marks the caller ready in a game created with auto_start false.
+// The game starts, and X may move, once every player has called it.
+func (s *TicTacToeServer) StartGame(ctx context.Context, req *pb.StartGameRequest) (*pb.StartGameResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
 	}
 	if req.GameId == "" {
-		return nil, status.Error(codes.InvalidArgument, "game_id is required")
+		return nil, requiredFieldError("game_id")
 	}
 
 	g, err := s.gameStore.Get(req.GameId)
 	if err != nil {
 		if err == store.ErrGameNotFound {
-			return nil, status.Error(codes.NotFound, "game not found")
+			return nil, gameNotFoundError(req.GameId)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
 	}
 
-	if err := g.MakeMove(req.UserId, int(req.Row), int(req.Col)); err != nil {
-		switch err {
-		case game.ErrGameNotInProgress:
-			return nil, status.Error(codes.FailedPrecondition, "game is not in progress")
-		case game.ErrPlayerNotInGame:
-			return nil, status.Error(codes.PermissionDenied, "you are not a player in this game")
-		case game.ErrNotYourTurn:
-			return nil, status.Error(codes.FailedPrecondition, "it's not your turn")
-		case game.ErrInvalidPosition:
-			return nil, status.Error(codes.InvalidArgument, "invalid position")
-		case game.ErrCellOccupied:
-			return nil, status.Error(codes.InvalidArgument, "cell is already occupied")
-		default:
-			return nil, status.Errorf(codes.Internal, "failed to make move: %v", err)
-		}
+	if err := g.Start(userID); err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
 	}
 
 	snapshot := g.GetSnapshot()
-
-	// Update stats if game is finished
 	if snapshot.Status.IsFinished() {
-		s.recordGameResult(snapshot)
+		s.recordGameResult(g, snapshot)
+	}
+	message := s.getUpdateMessage(snapshot)
+	if snapshot.Status == game.StatusInProgress {
+		message = "Game started! " + message
 	}
-
-	// Broadcast update
 	s.broadcastUpdate(req.GameId, &pb.GameUpdate{
-		Game:    gameToProto(snapshot),
-		Message: s.getUpdateMessage(snapshot),
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: message,
 	})
+	s.pushHint(g, snapshot)
 
-	return &pb.MakeMoveResponse{
-		Game: gameToProto(snapshot),
+	return &pb.StartGameResponse{
+		Game: s.gameProto(snapshot),
 	}, nil
 }
 
-// GetGame retrieves the current state of a game
-func (s *TicTacToeServer) GetGame(ctx context.Context, req *pb.GetGameRequest) (*pb.GetGameResponse, error) {
+// MakeMove makes a move in an active game
+func (s *TicTacToeServer) MakeMove(ctx context.Context, req *pb.MakeMoveRequest) (*pb.MakeMoveResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
 	if req.GameId == "" {
-		return nil, status.Error(codes.InvalidArgument, "game_id is required")
+		return nil, requiredFieldError("game_id")
+	}
+	if req.Cell != "" && (req.Row != 0 || req.Col != 0) {
+		return nil, status.Error(codes.InvalidArgument, "set either cell or row/col, not both")
 	}
 
 	g, err := s.gameStore.Get(req.GameId)
 	if err != nil {
 		if err == store.ErrGameNotFound {
-			return nil, status.Error(codes.NotFound, "game not found")
+			return nil, gameNotFoundError(req.GameId)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
 	}
 
-	return &pb.GetGameResponse{
-		Game: gameToProto(g.GetSnapshot()),
+	row, col := int(req.Row), int(req.Col)
+	if req.Cell != "" {
+		row, col, err = game.ParseRectCell(req.Cell, g.Board.Rows, g.Board.Cols)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "cell %q: %v", req.Cell, err)
+		}
+	}
+
+	opts := []game.MoveOption{game.WithNonce(req.Nonce), game.OnLayer(int(req.Layer))}
+	if req.ExpectedMoveNumber != nil {
+		opts = append(opts, game.ExpectMoveCount(int(*req.ExpectedMoveNumber)))
+	}
+	snapshot, err := g.MakeMove(userID, row, col, opts...)
+	if err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	s.afterMove(g, snapshot)
+
+	return &pb.MakeMoveResponse{
+		Game: s.gameProto(snapshot),
 	}, nil
 }
 
-// GetGameBoard retrieves the game board as a human-readable matrix
-func (s *TicTacToeServer) GetGameBoard(ctx context.Context, req *pb.GetGameBoardRequest) (*pb.GetGameBoardResponse, error) {
+// PreviewMove reports what a move would do, failing as MakeMove would for an
+// invalid one, without playing it. It is safe to call repeatedly, as a touch
+// UI does while the player settles on a cell.
+func (s *TicTacToeServer) PreviewMove(ctx context.Context, req *pb.PreviewMoveRequest) (*pb.PreviewMoveResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
 	if req.GameId == "" {
-		return nil, status.Error(codes.InvalidArgument, "game_id is required")
+		return nil, requiredFieldError("game_id")
+	}
+	if req.Cell != "" && (req.Row != 0 || req.Col != 0) {
+		return nil, status.Error(codes.InvalidArgument, "set either cell or row/col, not both")
 	}
 
 	g, err := s.gameStore.Get(req.GameId)
 	if err != nil {
 		if err == store.ErrGameNotFound {
-			return nil, status.Error(codes.NotFound, "game not found")
+			return nil, gameNotFoundError(req.GameId)
 		}
 		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
 	}
 
-	snapshot := g.GetSnapshot()
-	return snapshotToBoardResponse(snapshot), nil
+	row, col := int(req.Row), int(req.Col)
+	if req.Cell != "" {
+		row, col, err = game.ParseRectCell(req.Cell, g.Board.Rows, g.Board.Cols)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "cell %q: %v", req.Cell, err)
+		}
+	}
+
+	opts := []game.MoveOption{game.OnLayer(int(req.Layer))}
+	if req.ExpectedMoveNumber != nil {
+		opts = append(opts, game.ExpectMoveCount(int(*req.ExpectedMoveNumber)))
+	}
+	preview, err := g.PreviewMove(userID, row, col, opts...)
+	if err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	return &pb.PreviewMoveResponse{
+		Game:  s.gameProto(preview),
+		Wins:  preview.GetWinner() == userID,
+		Draws: preview.Status == game.StatusDraw,
+	}, nil
+}
+
+// IsLegalMove reports whether MakeMove would accept a move, and if not the
+// reason it would fail with, without playing it. It runs MakeMove's checks,
+// so a legal answer holds until the game next changes.
+func (s *TicTacToeServer) IsLegalMove(ctx context.Context, req *pb.IsLegalMoveRequest) (*pb.IsLegalMoveResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	err = g.CheckMove(userID, int(req.Row), int(req.Col), game.OnLayer(int(req.Layer)))
+	if err == nil {
+		return &pb.IsLegalMoveResponse{Legal: true}, nil
+	}
+	_, msg, reason, ok := moveErrorInfo(err)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "failed to check move: %v", err)
+	}
+	return &pb.IsLegalMoveResponse{
+		Reason:  reason,
+		Message: msg,
+	}, nil
+}
+
+// DropMove drops a mark into a column of a gravity game
+func (s *TicTacToeServer) DropMove(ctx context.Context, req *pb.DropMoveRequest) (*pb.DropMoveResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	row, snapshot, err := g.DropMove(userID, int(req.Col), game.WithNonce(req.Nonce))
+	if err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	s.afterMove(g, snapshot)
+
+	return &pb.DropMoveResponse{
+		Game: s.gameProto(snapshot),
+		Row:  int32(row),
+	}, nil
+}
+
+// OfferDraw offers the opponent a draw
+func (s *TicTacToeServer) OfferDraw(ctx context.Context, req *pb.OfferDrawRequest) (*pb.OfferDrawResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	if err := g.OfferDraw(userID); err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	snapshot := g.GetSnapshot()
+	s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: fmt.Sprintf("Player %s offers a draw", markToChar(snapshot.DrawOffer)),
+	})
+
+	return &pb.OfferDrawResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// RespondDraw accepts or declines the opponent's draw offer
+func (s *TicTacToeServer) RespondDraw(ctx context.Context, req *pb.RespondDrawRequest) (*pb.RespondDrawResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	responder := g.GetPlayerMark(userID)
+	if err := g.RespondDraw(userID, req.Accept); err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	snapshot := g.GetSnapshot()
+	message := fmt.Sprintf("Player %s declined the draw", markToChar(responder))
+	if req.Accept {
+		s.recordGameResult(g, snapshot)
+		s.updateFingerprint(snapshot)
+		message = fmt.Sprintf("Player %s accepted the draw. Game ended in a draw!", markToChar(responder))
+	}
+	s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: message,
+	})
+
+	return &pb.RespondDrawResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// AbandonGame ends an in-progress game because the calling player is leaving it
+func (s *TicTacToeServer) AbandonGame(ctx context.Context, req *pb.AbandonGameRequest) (*pb.AbandonGameResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	if err := g.Abandon(userID); err != nil {
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	snapshot := g.GetSnapshot()
+	s.recordGameResult(g, snapshot)
+	s.updateFingerprint(snapshot)
+	s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: s.getUpdateMessage(snapshot),
+	})
+
+	return &pb.AbandonGameResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// LeaveGame takes the caller out of a game, for clients with a single "leave"
+// action. The creator leaving a game that has not started cancels it and
+// removes it from the store, while anyone else leaving one frees their seat
+// for another player. A player leaving an in-progress game forfeits, so the
+// opponent is recorded as the winner. Unlike AbandonGame, a forfeit counts as
+// an ordinary loss. Finished games cannot be left.
+func (s *TicTacToeServer) LeaveGame(ctx context.Context, req *pb.LeaveGameRequest) (*pb.LeaveGameResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Leave(req.GameId, userID)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, moveErrorToStatus(err, req.GameId)
+	}
+
+	snapshot := g.GetSnapshot()
+	switch {
+	case snapshot.Status == game.StatusCancelled:
+		// Nobody played, so there is no result to record
+		s.broadcastFinal(snapshot.ID, &pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:    s.gameProto(snapshot),
+			Message: s.getUpdateMessage(snapshot),
+		})
+	case snapshot.Status.IsFinished():
+		s.recordGameResult(g, snapshot)
+		s.updateFingerprint(snapshot)
+		leaver := game.MarkX
+		if snapshot.Status == game.StatusXWon {
+			leaver = game.MarkO
+		}
+		s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:    s.gameProto(snapshot),
+			Message: fmt.Sprintf("Player %s left the game. %s", markToChar(leaver), s.getUpdateMessage(snapshot)),
+		})
+	default:
+		s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:    s.gameProto(snapshot),
+			Message: "A player left; waiting for more players",
+		})
+	}
+
+	return &pb.LeaveGameResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// SendChatMessage broadcasts a chat message to the game's subscribers
+func (s *TicTacToeServer) SendChatMessage(ctx context.Context, req *pb.SendChatMessageRequest) (*pb.SendChatMessageResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, requiredFieldError("text")
+	}
+	if utf8.RuneCountInString(text) > MaxChatMessageLength {
+		return nil, status.Errorf(codes.InvalidArgument, "text must be at most %d characters", MaxChatMessageLength)
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	snapshot := g.GetSnapshot()
+	if snapshot.PlayersOnlyChat && !slices.Contains(snapshot.Players(), userID) {
+		return nil, status.Error(codes.PermissionDenied, "chat in this game is limited to its players")
+	}
+
+	chat := &pb.ChatMessage{
+		Sender: userID,
+		Text:   text,
+		SentAt: time.Now().UnixMilli(),
+	}
+	s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+		Type: pb.UpdateType_UPDATE_TYPE_CHAT,
+		Chat: chat,
+	})
+
+	return &pb.SendChatMessageResponse{
+		Chat: chat,
+	}, nil
+}
+
+// moveErrorToStatus maps game move errors to gRPC status errors whose
+// ErrorInfo detail names the game
+func moveErrorToStatus(err error, gameID string) error {
+	code, msg, reason, ok := moveErrorInfo(err)
+	if !ok {
+		return status.Errorf(codes.Internal, "failed to make move: %v", err)
+	}
+	return errorWithInfo(code, msg, reason, map[string]string{"game_id": gameID})
+}
+
+// moveErrorInfo returns the status code, message and ErrorInfo reason of a
+// game move error; ok is false for errors the game package does not define
+func moveErrorInfo(err error) (code codes.Code, msg, reason string, ok bool) {
+	switch err {
+	case game.ErrGameNotInProgress:
+		code, msg, reason = codes.FailedPrecondition, "game is not in progress", ReasonGameNotInProgress
+	case game.ErrPlayerNotInGame:
+		code, msg, reason = codes.PermissionDenied, "you are not a player in this game", ReasonNotAPlayer
+	case game.ErrNotYourTurn:
+		code, msg, reason = codes.FailedPrecondition, "it's not your turn", ReasonNotYourTurn
+	case game.ErrInvalidPosition:
+		code, msg, reason = codes.InvalidArgument, "invalid position", ReasonInvalidPosition
+	case game.ErrCellOccupied:
+		code, msg, reason = codes.InvalidArgument, "cell is already occupied", ReasonCellOccupied
+	case game.ErrColumnFull:
+		code, msg, reason = codes.FailedPrecondition, "column is full; drop into another column", ReasonColumnFull
+	case game.ErrNotLowestEmptyRow:
+		code, msg, reason = codes.InvalidArgument, "gravity games only allow the lowest empty cell in a column", ReasonNotLowestEmptyRow
+	case game.ErrNotGravityGame:
+		code, msg, reason = codes.FailedPrecondition, "drop moves are only allowed in gravity games", ReasonNotGravityGame
+	case game.ErrStaleNonce:
+		code, msg, reason = codes.Aborted, "move nonce must be greater than the last accepted nonce", ReasonStaleNonce
+	case game.ErrMoveCountMismatch:
+		code, msg, reason = codes.Aborted, "the game has changed since expected_move_number; refresh and retry", ReasonMoveCountMismatch
+	case game.ErrDrawOfferPending:
+		code, msg, reason = codes.FailedPrecondition, "a draw offer is already pending", ReasonDrawOfferPending
+	case game.ErrNoDrawOffer:
+		code, msg, reason = codes.FailedPrecondition, "no pending draw offer from the opponent", ReasonNoDrawOffer
+	case game.ErrTooManyMovesInFlight:
+		code, msg, reason = codes.ResourceExhausted, "too many concurrent moves for this game", ReasonTooManyMovesInFlight
+	case game.ErrTwoPlayerOnly:
+		code, msg, reason = codes.FailedPrecondition, "only available in two-player games", ReasonTwoPlayerOnly
+	case game.ErrGameNotReady:
+		code, msg, reason = codes.FailedPrecondition, "game is not waiting for its players to start it", ReasonGameNotReady
+	case game.ErrMoveLimitReached:
+		code, msg, reason = codes.FailedPrecondition, "game has reached its move limit", ReasonMoveLimitReached
+	default:
+		return codes.Internal, "", "", false
+	}
+	return code, msg, reason, true
+}
+
+// afterMove records results, updates indexes and notifies subscribers after
+// a successful move. snapshot must be the one returned by the move itself:
+// re-reading the game could observe a later move and record its result twice.
+func (s *TicTacToeServer) afterMove(g *game.Game, snapshot game.GameSnapshot) {
+	s.metrics.recordMove()
+
+	// Update stats if game is finished
+	if snapshot.Status.IsFinished() {
+		s.recordGameResult(g, snapshot)
+	}
+	s.updateFingerprint(snapshot)
+
+	// Broadcast update
+	s.broadcastUpdate(snapshot.ID, &pb.GameUpdate{
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(snapshot),
+		Message: s.getUpdateMessage(snapshot),
+	})
+	s.pushHint(g, snapshot)
+}
+
+// pushHint suggests a move to the player on turn in an in-progress game with
+// hints enabled. The search runs in the background so the move that led here
+// is not held up; the hint is dropped if the game changes before it is sent.
+func (s *TicTacToeServer) pushHint(g *game.Game, snapshot game.GameSnapshot) {
+	if !snapshot.Hints || snapshot.Status != game.StatusInProgress {
+		return
+	}
+	userID := snapshot.PlayerX
+	if snapshot.Turn == game.MarkO {
+		userID = snapshot.PlayerO
+	}
+
+	go func() {
+		// Ties between equally good moves are broken by the game's seed
+		opts := ai.Options{Rand: snapshot.Rand()}
+		if snapshot.Board.Size > 3 {
+			opts.MaxDepth = MaxAnalysisDepth
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), HintTimeout)
+		defer cancel()
+		res, err := ai.Search(ctx, snapshot.Board, snapshot.Turn, opts)
+		if err != nil {
+			return
+		}
+		s.sendToUser(snapshot.ID, userID, &pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_HINT,
+			Game:    s.gameProto(snapshot),
+			Message: "Suggested move",
+			Hint:    &pb.Position{Row: int32(res.Move.Row), Col: int32(res.Move.Col)},
+		}, func() bool { return g.GetVersion() == snapshot.Version })
+	}()
+}
+
+// GetGame retrieves the current state of a game
+func (s *TicTacToeServer) GetGame(ctx context.Context, req *pb.GetGameRequest) (*pb.GetGameResponse, error) {
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	snapshot := g.GetSnapshot()
+	setETag(ctx, snapshot.Version)
+	if req.IfVersion == snapshot.Version {
+		return &pb.GetGameResponse{NotModified: true}, nil
+	}
+	return &pb.GetGameResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// BatchGetGames retrieves several games in one call. IDs with no game are
+// reported in missing_game_ids rather than failing the call.
+func (s *TicTacToeServer) BatchGetGames(ctx context.Context, req *pb.BatchGetGamesRequest) (*pb.BatchGetGamesResponse, error) {
+	if len(req.GameIds) == 0 {
+		return nil, requiredFieldError("game_ids")
+	}
+	if len(req.GameIds) > MaxBatchGetGames {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d game_ids may be requested", MaxBatchGetGames)
+	}
+	for _, gameID := range req.GameIds {
+		if gameID == "" {
+			return nil, status.Error(codes.InvalidArgument, "game_ids must not contain empty IDs")
+		}
+	}
+
+	found, missing := s.gameStore.GetMany(req.GameIds)
+	games := make([]*pb.Game, len(found))
+	for i, g := range found {
+		games[i] = s.gameProto(g.GetSnapshot())
+	}
+
+	return &pb.BatchGetGamesResponse{
+		Games:          games,
+		MissingGameIds: missing,
+	}, nil
+}
+
+// AnalyzePosition searches a classic-mode position for the best move. On a
+// 3x3 board the evaluation is exact; larger boards get a depth-limited search
+// whose score is a heuristic unless it finds a forced result. No game is stored.
+func (s *TicTacToeServer) AnalyzePosition(ctx context.Context, req *pb.AnalyzePositionRequest) (*pb.AnalyzePositionResponse, error) {
+	boardSize := int(req.BoardSize)
+	if boardSize == 0 {
+		boardSize = s.defaultBoardSize
+	}
+	if boardSize < 3 || boardSize > s.maxBoardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size must be between 3 and %d", s.maxBoardSize)
+	}
+	winLength := int(req.WinLength)
+	if winLength == 0 {
+		winLength = s.defaultWinLengthFor(boardSize)
+	}
+
+	cells, turn, err := positionFromProto(req.Board, req.Turn)
+	if err != nil {
+		return nil, err
+	}
+	board, err := game.NewBoardFromPosition(boardSize, winLength, cells, turn)
+	if err != nil {
+		return nil, positionErrorToStatus(err, boardSize, len(cells))
+	}
+
+	var opts ai.Options
+	if boardSize > 3 {
+		opts.MaxDepth = MaxAnalysisDepth
+	}
+	searchCtx, cancel := context.WithTimeout(ctx, AnalysisTimeout)
+	defer cancel()
+	res, err := ai.Search(searchCtx, board, turn, opts)
+	if err != nil {
+		if err == ai.ErrNoMoves {
+			return nil, status.Error(codes.FailedPrecondition, "board is full")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to analyze position: %v", err)
+	}
+
+	evaluation := pb.GameResult_GAME_RESULT_UNSPECIFIED
+	if res.Proven() {
+		switch {
+		case res.Score > 0:
+			evaluation = pb.GameResult_GAME_RESULT_WIN
+		case res.Score < 0:
+			evaluation = pb.GameResult_GAME_RESULT_LOSS
+		default:
+			evaluation = pb.GameResult_GAME_RESULT_DRAW
+		}
+	}
+
+	return &pb.AnalyzePositionResponse{
+		BestMove:   &pb.Position{Row: int32(res.Move.Row), Col: int32(res.Move.Col)},
+		Evaluation: evaluation,
+		Score:      int32(res.Score),
+		Exact:      res.Exact,
+		Depth:      int32(res.Depth),
+	}, nil
+}
+
+// GetGameCompact retrieves a game with its board packed by game.Board.Pack,
+// for clients watching large boards; decode it with game.UnpackBoard,
+// UnpackRectBoard or UnpackCubeBoard
+func (s *TicTacToeServer) GetGameCompact(ctx context.Context, req *pb.GetGameCompactRequest) (*pb.GetGameCompactResponse, error) {
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	snapshot := g.GetSnapshot()
+	dimensions := int32(2)
+	if snapshot.Board.IsCube() {
+		dimensions = 3
+	}
+	return &pb.GetGameCompactResponse{
+		GameId:      snapshot.ID,
+		BoardSize:   int32(snapshot.Board.Size),
+		WinLength:   int32(snapshot.Board.WinLength),
+		Dimensions:  dimensions,
+		PackedBoard: snapshot.Board.Pack(),
+		CurrentTurn: markToProto(snapshot.Turn),
+		Status:      statusToProto(snapshot.Status),
+		Rows:        int32(snapshot.Board.Rows),
+		Cols:        int32(snapshot.Board.Cols),
+	}, nil
+}
+
+// GetGameBoard retrieves the game board as a human-readable matrix
+func (s *TicTacToeServer) GetGameBoard(ctx context.Context, req *pb.GetGameBoardRequest) (*pb.GetGameBoardResponse, error) {
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	snapshot := g.GetSnapshot()
+	return snapshotToBoardResponse(snapshot, req.WithCoordinates), nil
+}
+
+// RenderBoard renders the game board as ASCII, Unicode box drawing or SVG
+func (s *TicTacToeServer) RenderBoard(ctx context.Context, req *pb.RenderBoardRequest) (*pb.RenderBoardResponse, error) {
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	snapshot := g.GetSnapshot()
+	if snapshot.Board.IsCube() {
+		return nil, status.Error(codes.FailedPrecondition, "3D games cannot be rendered; use GetGameBoard")
+	}
+
+	content, contentType, ok := renderBoard(snapshot, req.Format)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown format %v", req.Format)
+	}
+
+	format := req.Format
+	if format == pb.RenderFormat_RENDER_FORMAT_UNSPECIFIED {
+		format = pb.RenderFormat_RENDER_FORMAT_ASCII
+	}
+
+	return &pb.RenderBoardResponse{
+		GameId:      req.GameId,
+		Format:      format,
+		ContentType: contentType,
+		Content:     content,
+	}, nil
+}
+
+// GetAvailableMoves lists the empty cells the player to move may mark
+func (s *TicTacToeServer) GetAvailableMoves(ctx context.Context, req *pb.GetAvailableMovesRequest) (*pb.GetAvailableMovesResponse, error) {
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	available := g.AvailableMoves()
+	moves := make([]*pb.Position, len(available))
+	for i, pos := range available {
+		moves[i] = &pb.Position{Row: int32(pos.Row), Col: int32(pos.Col), Layer: int32(pos.Layer)}
+	}
+
+	return &pb.GetAvailableMovesResponse{
+		GameId: req.GameId,
+		Moves:  moves,
+	}, nil
 }
 
-// snapshotToBoardResponse converts a game snapshot to a board response
-func snapshotToBoardResponse(snapshot game.GameSnapshot) *pb.GetGameBoardResponse {
+// snapshotToBoardResponse converts a game snapshot to a board response.
+// A 3D board is shown layer by layer. With coordinates, the display has
+// column indices above the grid and row indices to its left, right-aligned so
+// two-digit indices line up.
+func snapshotToBoardResponse(snapshot game.GameSnapshot, withCoordinates bool) *pb.GetGameBoardResponse {
 	size := snapshot.Board.Size
-	rows := make([]string, size)
+	numRows, numCols := snapshot.Board.Rows, snapshot.Board.Cols
+	layers := 1
+	if snapshot.Board.IsCube() {
+		layers = snapshot.Board.Depth
+	}
+	rows := make([]string, 0, numRows*layers)
 	var displayBuilder strings.Builder
 
-	// Build separator line
-	separator := "+" + strings.Repeat("---+", size)
-
-	displayBuilder.WriteString(separator + "\n")
-
-	for row := 0; row < size; row++ {
-		var rowCells []string
-		for col := 0; col < size; col++ {
-			mark, _ := snapshot.Board.Get(row, col)
-			rowCells = append(rowCells, markToChar(mark))
+	// Build separator line, indented past the row labels when there are any
+	separator := "+" + strings.Repeat("---+", numCols)
+	var header string
+	labelWidth := 0
+	if withCoordinates {
+		labelWidth = len(fmt.Sprint(numRows - 1))
+		indent := strings.Repeat(" ", labelWidth+1)
+		separator = indent + separator
+		var headerBuilder strings.Builder
+		headerBuilder.WriteString(indent)
+		for col := 0; col < numCols; col++ {
+			fmt.Fprintf(&headerBuilder, "%3d ", col)
 		}
-		rows[row] = strings.Join(rowCells, "|")
+		header = strings.TrimRight(headerBuilder.String(), " ")
+	}
 
-		// Build display string with borders
-		displayBuilder.WriteString("| ")
-		displayBuilder.WriteString(strings.Join(rowCells, " | "))
-		displayBuilder.WriteString(" |\n")
+	for layer := 0; layer < layers; layer++ {
+		if layers > 1 {
+			if layer > 0 {
+				displayBuilder.WriteString("\n")
+			}
+			fmt.Fprintf(&displayBuilder, "Layer %d\n", layer)
+		}
+		if withCoordinates {
+			displayBuilder.WriteString(header + "\n")
+		}
 		displayBuilder.WriteString(separator + "\n")
+
+		for row := 0; row < numRows; row++ {
+			var rowCells []string
+			for col := 0; col < numCols; col++ {
+				mark, _ := snapshot.Board.GetAt(row, col, layer)
+				rowCells = append(rowCells, markToChar(mark))
+			}
+			rows = append(rows, strings.Join(rowCells, "|"))
+
+			// Build display string with borders
+			if withCoordinates {
+				fmt.Fprintf(&displayBuilder, "%*d ", labelWidth, row)
+			}
+			displayBuilder.WriteString("| ")
+			displayBuilder.WriteString(strings.Join(rowCells, " | "))
+			displayBuilder.WriteString(" |\n")
+			displayBuilder.WriteString(separator + "\n")
+		}
 	}
 
 	// Get status string
@@ -292,6 +1672,8 @@ func markToChar(m game.Mark) string {
 		return "X"
 	case game.MarkO:
 		return "O"
+	case game.MarkTriangle:
+		return "△"
 	default:
 		return " "
 	}
@@ -308,8 +1690,16 @@ func getStatusString(s game.Status) string {
 		return "Player X won!"
 	case game.StatusOWon:
 		return "Player O won!"
+	case game.StatusTriangleWon:
+		return "Player △ won!"
 	case game.StatusDraw:
 		return "Game ended in a draw"
+	case game.StatusAbandoned:
+		return "Game abandoned"
+	case game.StatusCancelled:
+		return "Game cancelled"
+	case game.StatusReady:
+		return "Waiting for players to start"
 	default:
 		return "Unknown"
 	}
@@ -318,52 +1708,380 @@ func getStatusString(s game.Status) string {
 // GetUserStats retrieves win-lose-draw statistics for a user
 func (s *TicTacToeServer) GetUserStats(ctx context.Context, req *pb.GetUserStatsRequest) (*pb.GetUserStatsResponse, error) {
 	if req.UserId == "" {
-		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+		return nil, requiredFieldError("user_id")
+	}
+
+	return userStatsToProto(s.statsStore.Get(req.UserId)), nil
+}
+
+// BatchGetUserStats retrieves stats for several users in one call, in
+// request order. Unknown users get zeroed stats, as from GetUserStats.
+func (s *TicTacToeServer) BatchGetUserStats(ctx context.Context, req *pb.BatchGetUserStatsRequest) (*pb.BatchGetUserStatsResponse, error) {
+	if len(req.UserIds) == 0 {
+		return nil, requiredFieldError("user_ids")
+	}
+	if len(req.UserIds) > MaxBatchGetUserStats {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d user_ids may be requested", MaxBatchGetUserStats)
+	}
+	for _, userID := range req.UserIds {
+		if userID == "" {
+			return nil, status.Error(codes.InvalidArgument, "user_ids must not contain empty IDs")
+		}
+	}
+
+	all := s.statsStore.GetMany(req.UserIds)
+	stats := make([]*pb.GetUserStatsResponse, len(all))
+	for i, userStats := range all {
+		stats[i] = userStatsToProto(userStats)
+	}
+	return &pb.BatchGetUserStatsResponse{
+		Stats: stats,
+	}, nil
+}
+
+// DeleteUserStats removes a user's statistics. With auth enabled users may
+// only delete their own. Deleting a user without stats succeeds.
+func (s *TicTacToeServer) DeleteUserStats(ctx context.Context, req *pb.DeleteUserStatsRequest) (*pb.DeleteUserStatsResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteUserStatsResponse{
+		UserId:  userID,
+		Deleted: s.statsStore.Delete(userID),
+	}, nil
+}
+
+// ResetUserStats zeroes a user's record while keeping their entry. With auth
+// enabled only admins may call it.
+func (s *TicTacToeServer) ResetUserStats(ctx context.Context, req *pb.ResetUserStatsRequest) (*pb.ResetUserStatsResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.UserId == "" {
+		return nil, requiredFieldError("user_id")
+	}
+
+	s.statsStore.Reset(req.UserId)
+
+	return &pb.ResetUserStatsResponse{
+		UserId: req.UserId,
+	}, nil
+}
+
+// ArchiveSeason ends a season: it returns every user's record and zeroes
+// them all. Games finishing during the call may be counted in either season.
+// With auth enabled only admins may call it.
+func (s *TicTacToeServer) ArchiveSeason(ctx context.Context, req *pb.ArchiveSeasonRequest) (*pb.ArchiveSeasonResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	archived := s.statsStore.ResetAll()
+	slices.SortFunc(archived, func(a, b store.UserStats) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	stats := make([]*pb.GetUserStatsResponse, len(archived))
+	for i, userStats := range archived {
+		stats[i] = userStatsToProto(userStats)
+	}
+
+	return &pb.ArchiveSeasonResponse{
+		Stats:      stats,
+		ArchivedAt: time.Now().Unix(),
+	}, nil
+}
+
+// ExportGame dumps a game's full state as JSON, for reproducing bug reports
+func (s *TicTacToeServer) ExportGame(ctx context.Context, req *pb.ExportGameRequest) (*pb.ExportGameResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.GameId == "" {
+		return nil, requiredFieldError("game_id")
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return nil, gameNotFoundError(req.GameId)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to export game: %v", err)
+	}
+
+	return &pb.ExportGameResponse{
+		GameJson: string(data),
+	}, nil
+}
+
+// ImportGame recreates an exported game in the store under its original ID
+func (s *TicTacToeServer) ImportGame(ctx context.Context, req *pb.ImportGameRequest) (*pb.ImportGameResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.GameJson == "" {
+		return nil, requiredFieldError("game_json")
+	}
+
+	g := &game.Game{}
+	if err := g.UnmarshalJSON([]byte(req.GameJson)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	snapshot := g.GetSnapshot()
+	if snapshot.Board.Size > s.maxBoardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size must be between 3 and %d", s.maxBoardSize)
+	}
+	if snapshot.Board.IsCube() && snapshot.Board.Size > MaxCubeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size must be at most %d for 3D games", MaxCubeSize)
+	}
+	game.WithMoveAdmissionLimit(s.moveAdmissionLimit)(g)
+	game.WithMaxMoves(s.maxMoves)(g)
+
+	if err := s.gameStore.Import(g); err != nil {
+		if err == store.ErrGameAlreadyExists {
+			return nil, status.Error(codes.AlreadyExists, "game already exists")
+		}
+		return nil, createErrorToStatus(err)
+	}
+	s.updateFingerprint(snapshot)
+
+	return &pb.ImportGameResponse{
+		Game: s.gameProto(snapshot),
+	}, nil
+}
+
+// GetGameHistory lists the finished games a user played, most recent first
+func (s *TicTacToeServer) GetGameHistory(ctx context.Context, req *pb.GetGameHistoryRequest) (*pb.GetGameHistoryResponse, error) {
+	if req.UserId == "" {
+		return nil, requiredFieldError("user_id")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > s.maxListLimit {
+		limit = s.maxListLimit
+	}
+	offset := int(req.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	games, totalCount := s.gameStore.ListFinishedByPlayer(req.UserId, limit, offset)
+
+	entries := make([]*pb.GameHistoryEntry, len(games))
+	for i, snapshot := range games {
+		entries[i] = historyEntryToProto(*snapshot, req.UserId)
+		s.withDisplayNames(entries[i].Game)
+	}
+
+	return &pb.GetGameHistoryResponse{
+		UserId:     req.UserId,
+		Games:      entries,
+		TotalCount: int32(totalCount),
+	}, nil
+}
+
+// GetLeaderboard ranks users by wins, optionally within a board-size bracket
+func (s *TicTacToeServer) GetLeaderboard(ctx context.Context, req *pb.GetLeaderboardRequest) (*pb.GetLeaderboardResponse, error) {
+	bracket, ok := bracketFromProto(req.Bracket)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown bracket %v", req.Bracket)
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > s.maxListLimit {
+		limit = s.maxListLimit
+	}
+
+	offset := int(req.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, totalCount := s.statsStore.Leaderboard(bracket, s.tieBreak, limit, offset)
+
+	return &pb.GetLeaderboardResponse{
+		Entries:    s.leaderboardEntries(entries),
+		TotalCount: int32(totalCount),
+	}, nil
+}
+
+// Ping reports the server's time and version without touching any state
+func (s *TicTacToeServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return &pb.PingResponse{
+		ServerTimeMs: time.Now().UnixMilli(),
+		Version:      s.version,
+	}, nil
+}
+
+// GetServerStats reports aggregate game, user and subscriber counts
+func (s *TicTacToeServer) GetServerStats(ctx context.Context, req *pb.GetServerStatsRequest) (*pb.GetServerStatsResponse, error) {
+	s.serverStatsMu.Lock()
+	defer s.serverStatsMu.Unlock()
+
+	now := time.Now()
+	if s.serverStats != nil && now.Sub(s.serverStatsTaken) < s.serverStatsTTL {
+		return s.serverStats, nil
+	}
+
+	counts := s.gameStore.CountByStatus()
+	s.serverStats = &pb.GetServerStatsResponse{
+		TotalGames:        int32(counts.Total()),
+		PendingGames:      int32(counts.Pending),
+		InProgressGames:   int32(counts.InProgress),
+		FinishedGames:     int32(counts.Finished),
+		Users:             int32(s.statsStore.Count()),
+		StreamSubscribers: int32(s.SubscriberCount()),
+		GeneratedAt:       now.Unix(),
 	}
+	s.serverStatsTaken = now
+	return s.serverStats, nil
+}
 
-	stats := s.statsStore.Get(req.UserId)
+// GetOutcomeStats returns the outcomes of standard games on a board configuration
+func (s *TicTacToeServer) GetOutcomeStats(ctx context.Context, req *pb.GetOutcomeStatsRequest) (*pb.GetOutcomeStatsResponse, error) {
+	boardSize := int(req.BoardSize)
+	if boardSize == 0 {
+		boardSize = s.defaultBoardSize
+	}
+	winLength := int(req.WinLength)
+	if winLength == 0 {
+		winLength = s.defaultWinLengthFor(boardSize)
+	}
+	if boardSize < 3 || boardSize > s.maxBoardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size must be between 3 and %d", s.maxBoardSize)
+	}
+	if winLength < 3 || winLength > boardSize {
+		return nil, status.Error(codes.InvalidArgument, "win_length must be between 3 and board_size")
+	}
 
-	return &pb.GetUserStatsResponse{
-		UserId:     stats.UserID,
-		Wins:       stats.Wins,
-		Losses:     stats.Losses,
-		Draws:      stats.Draws,
-		TotalGames: stats.TotalGames(),
+	outcomes := s.outcomes.Get(store.BoardConfig{BoardSize: boardSize, WinLength: winLength})
+	return &pb.GetOutcomeStatsResponse{
+		BoardSize:   int32(boardSize),
+		WinLength:   int32(winLength),
+		XWins:       outcomes.XWins,
+		OWins:       outcomes.OWins,
+		Draws:       outcomes.Draws,
+		TotalGames:  outcomes.Total(),
+		XWinPercent: outcomes.XWinPercent(),
 	}, nil
 }
 
 // StreamGameUpdates streams game state updates to connected players
 func (s *TicTacToeServer) StreamGameUpdates(req *pb.StreamGameUpdatesRequest, stream pb.TicTacToeService_StreamGameUpdatesServer) error {
 	if req.GameId == "" {
-		return status.Error(codes.InvalidArgument, "game_id is required")
+		return requiredFieldError("game_id")
+	}
+
+	select {
+	case <-s.closed:
+		return status.Error(codes.Unavailable, "server is shutting down")
+	default:
 	}
 
 	// Verify game exists
 	g, err := s.gameStore.Get(req.GameId)
 	if err != nil {
 		if err == store.ErrGameNotFound {
-			return status.Error(codes.NotFound, "game not found")
+			return gameNotFoundError(req.GameId)
 		}
 		return status.Errorf(codes.Internal, "failed to get game: %v", err)
 	}
 
+	// Spectators may stream anonymously; a named player also receives hints
+	userID := req.UserId
+	if s.auth != nil {
+		userID = ""
+		if _, ok := UserIDFromContext(stream.Context()); ok {
+			if userID, err = s.actingUser(stream.Context(), req.UserId); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Create channel for updates
-	updateCh := make(chan *pb.GameUpdate, 10)
-	s.subscribe(req.GameId, updateCh)
+	updateCh := make(chan *pb.GameUpdate, s.streamBuffer)
+	latest, missed, ok := s.subscribe(req.GameId, updateCh, userID, req.LastSeenSequence)
 	defer s.unsubscribe(req.GameId, updateCh)
 
-	// Send initial state
-	if err := stream.Send(&pb.GameUpdate{
-		Game:    gameToProto(g.GetSnapshot()),
-		Message: "Connected to game",
-	}); err != nil {
-		return err
+	// A game removed since the lookup above ended its streams before this
+	// one subscribed, so nothing would ever close it
+	if _, err := s.gameStore.Get(req.GameId); err == store.ErrGameNotFound {
+		s.forgetGame(req.GameId)
+		return gameNotFoundError(req.GameId)
+	}
+
+	// A resuming client gets what it missed; anyone else, including clients
+	// too far behind to replay, gets the current state
+	snapshot := g.GetSnapshot()
+	if req.LastSeenSequence == 0 || !ok {
+		initial := &pb.GameUpdate{
+			Type:     pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:     s.gameProto(snapshot),
+			Message:  "Connected to game",
+			Sequence: latest,
+		}
+		if req.LastSeenSequence != 0 {
+			initial.Message = "Resynchronized with game"
+			initial.Resync = true
+		}
+		if err := stream.Send(initial); err != nil {
+			return err
+		}
+		// A finished game will send nothing more
+		if snapshot.Status.IsFinished() {
+			return nil
+		}
+	}
+	for _, update := range missed {
+		if err := stream.Send(update); err != nil {
+			return err
+		}
+		if update.Game != nil && isGameFinished(update.Game.Status) {
+			return nil
+		}
+	}
+	if snapshot.Status.IsFinished() {
+		if len(missed) == 0 {
+			// The client has already seen the end
+			return nil
+		}
+		// The replay stopped short of the end, which has not been broadcast yet
+		return stream.Send(&pb.GameUpdate{
+			Type:     pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:     s.gameProto(snapshot),
+			Message:  "Game is over",
+			Sequence: latest,
+		})
+	}
+
+	// A spectator's stream ends after its lifetime; a player's never does
+	var expired <-chan time.Time
+	if s.spectatorStreamLifetime > 0 {
+		timer := time.NewTimer(s.spectatorStreamLifetime)
+		defer timer.Stop()
+		expired = timer.C
 	}
 
 	// Stream updates
 	for {
 		select {
-		case update := <-updateCh:
+		case update, open := <-updateCh:
+			if !open {
+				// The game was removed after its last update
+				return nil
+			}
 			if err := stream.Send(update); err != nil {
 				return err
 			}
@@ -371,59 +2089,358 @@ func (s *TicTacToeServer) StreamGameUpdates(req *pb.StreamGameUpdatesRequest, st
 			if update.Game != nil && isGameFinished(update.Game.Status) {
 				return nil
 			}
+		case <-expired:
+			// Someone who joined the game since connecting is a player now
+			if userID != "" && g.GetPlayerMark(userID) != game.MarkEmpty {
+				expired = nil
+				continue
+			}
+			return stream.Send(s.finalUpdate(g, "Spectator session expired"))
+		case <-s.closed:
+			return stream.Send(s.finalUpdate(g, "Server shutting down"))
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// ReplayGame streams a finished game's board after each recorded move: the
+// empty board first, then one update per move, waiting between moves
+// according to the requested speed and pacing
+func (s *TicTacToeServer) ReplayGame(req *pb.ReplayGameRequest, stream pb.TicTacToeService_ReplayGameServer) error {
+	if req.GameId == "" {
+		return requiredFieldError("game_id")
+	}
+	speed := req.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	if !(speed > 0 && speed <= MaxReplaySpeed) {
+		return status.Errorf(codes.InvalidArgument, "speed must be between 0 and %d", MaxReplaySpeed)
+	}
+	pacing, ok := pacingFromProto(req.Pacing)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown pacing %v", req.Pacing)
+	}
+
+	g, err := s.gameStore.Get(req.GameId)
+	if err != nil {
+		if err == store.ErrGameNotFound {
+			return gameNotFoundError(req.GameId)
+		}
+		return status.Errorf(codes.Internal, "failed to get game: %v", err)
+	}
+
+	// A finished game no longer changes, so its snapshot and moves agree
+	final := g.GetSnapshot()
+	if !final.Status.IsFinished() {
+		return status.Error(codes.FailedPrecondition, "game is not finished")
+	}
+	moves := g.Moves()
+	if len(moves) == 0 {
+		return status.Error(codes.FailedPrecondition, "game has no recorded history")
+	}
+
+	// Replay onto the final board with the moves taken back, which leaves
+	// the starting position of a game created from one
+	frame := final
+	frame.Board = final.Board.Clone()
+	for _, move := range moves {
+		frame.Board.Cells[(move.Layer*frame.Board.Rows+move.Row)*frame.Board.Cols+move.Col] = game.MarkEmpty
+	}
+	frame.Board.Rehash()
+	frame.Status = game.StatusInProgress
+	frame.Turn = moves[0].Mark
+	frame.DrawOffer = game.MarkEmpty
+	frame.UpdatedAt = final.CreatedAt
+	if err := stream.Send(&pb.GameUpdate{
+		Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:    s.gameProto(frame),
+		Message: fmt.Sprintf("Replaying %d moves", len(moves)),
+	}); err != nil {
+		return err
+	}
+
+	delays := game.ReplayDelays(len(moves), time.Duration(float64(DefaultReplayDelay)/speed), pacing)
+	for i, move := range moves {
+		select {
+		case <-time.After(delays[i]):
+		case <-s.closed:
+			return status.Error(codes.Unavailable, "server is shutting down")
 		case <-stream.Context().Done():
 			return stream.Context().Err()
 		}
+
+		if err := frame.Board.SetAt(move.Row, move.Col, move.Layer, move.Mark); err != nil {
+			return status.Errorf(codes.Internal, "move %d cannot be replayed: %v", i+1, err)
+		}
+		frame.Turn = move.Mark.Next(final.NumPlayers)
+		frame.UpdatedAt = move.At
+		message := fmt.Sprintf("Move %d of %d", i+1, len(moves))
+		if i == len(moves)-1 {
+			board := frame.Board
+			frame = final
+			frame.Board = board
+			message += ": " + s.getUpdateMessage(final)
+		}
+		if err := stream.Send(&pb.GameUpdate{
+			Type:    pb.UpdateType_UPDATE_TYPE_STATE,
+			Game:    s.gameProto(frame),
+			Message: message,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finalUpdate is the last update a stream sends when it ends before the game
+// does, such as when the server closes. It carries the game's current state
+// so the client can resume elsewhere.
+func (s *TicTacToeServer) finalUpdate(g *game.Game, message string) *pb.GameUpdate {
+	snapshot := g.GetSnapshot()
+
+	var latest uint64
+	if feed := s.lockFeed(snapshot.ID); feed != nil {
+		latest = feed.history.latest
+		feed.mu.Unlock()
+	}
+
+	return &pb.GameUpdate{
+		Type:     pb.UpdateType_UPDATE_TYPE_STATE,
+		Game:     s.gameProto(snapshot),
+		Message:  message,
+		Sequence: latest,
 	}
 }
 
-// subscribe adds a channel to receive updates for a game
-func (s *TicTacToeServer) subscribe(gameID string, ch chan *pb.GameUpdate) {
-	s.subscribersMu.Lock()
-	defer s.subscribersMu.Unlock()
+// Close ends every open StreamGameUpdates stream with a final "Server
+// shutting down" update and refuses new ones. Call it before
+// grpc.Server.GracefulStop, which otherwise waits for streaming clients to
+// disconnect on their own. Close is safe to call more than once.
+func (s *TicTacToeServer) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
 
-	if s.subscribers[gameID] == nil {
-		s.subscribers[gameID] = make(map[chan *pb.GameUpdate]struct{})
+// subscribe adds a channel, streaming to userID ("" for an anonymous
+// spectator), to receive updates for a game. It returns the
+// game's latest sequence number and, when lastSeen is non-zero, the updates
+// after it; ok is false if they can no longer be replayed. Both are taken
+// under the game's broadcast lock, so nothing is missed or delivered twice.
+func (s *TicTacToeServer) subscribe(gameID string, ch chan *pb.GameUpdate, userID string, lastSeen uint64) (latest uint64, missed []*pb.GameUpdate, ok bool) {
+	feed := s.openFeed(gameID)
+	defer feed.mu.Unlock()
+
+	feed.subscribers[ch] = userID
+	s.subscriberCount.Add(1)
+	if lastSeen != 0 {
+		missed, ok = feed.history.since(lastSeen)
 	}
-	s.subscribers[gameID][ch] = struct{}{}
+	return feed.history.latest, missed, ok
 }
 
-// unsubscribe removes a channel from receiving updates
+// unsubscribe removes a channel from receiving updates and closes it. Every
+// send to a subscriber happens under its game's feed lock and only while it
+// is subscribed, so a broadcast racing the end of its stream either delivers
+// first or no longer finds the channel; none can send after the close.
+// Unsubscribing a channel twice is a no-op.
 func (s *TicTacToeServer) unsubscribe(gameID string, ch chan *pb.GameUpdate) {
-	s.subscribersMu.Lock()
-	defer s.subscribersMu.Unlock()
+	feed := s.lockFeed(gameID)
+	if feed == nil {
+		return
+	}
+	defer feed.mu.Unlock()
 
-	if subs, ok := s.subscribers[gameID]; ok {
-		delete(subs, ch)
-		if len(subs) == 0 {
-			delete(s.subscribers, gameID)
-		}
+	if _, ok := feed.subscribers[ch]; !ok {
+		return
 	}
+	delete(feed.subscribers, ch)
+	s.subscriberCount.Add(-1)
 	close(ch)
 }
 
-// broadcastUpdate sends an update to all subscribers of a game
+// SubscriberCount returns the number of active game update subscriptions
+func (s *TicTacToeServer) SubscriberCount() int {
+	return int(s.subscriberCount.Load())
+}
+
+// broadcastUpdate numbers an update, records it for replay and sends it to
+// all subscribers of a game. Broadcasts hold the game's feed lock so every
+// subscriber of a game receives concurrent updates, chat included, in
+// sequence order; a full subscriber is handled by the overflow policy.
 func (s *TicTacToeServer) broadcastUpdate(gameID string, update *pb.GameUpdate) {
-	s.subscribersMu.RLock()
-	defer s.subscribersMu.RUnlock()
-
-	if subs, ok := s.subscribers[gameID]; ok {
-		for ch := range subs {
-			select {
-			case ch <- update:
-			default:
-				// Channel full, skip (non-blocking)
-			}
+	var feed *gameFeed
+	if s.feed(gameID) == nil {
+		// A late update for a removed game must not bring its feed back
+		if _, err := s.gameStore.Get(gameID); err != nil {
+			return
 		}
+		feed = s.openFeed(gameID)
+	} else if feed = s.lockFeed(gameID); feed == nil {
+		return
+	}
+	defer feed.mu.Unlock()
+
+	feed.history.add(update)
+	for ch := range feed.subscribers {
+		s.deliver(ch, update)
 	}
 }
 
-// recordGameResult records the game result in stats
-func (s *TicTacToeServer) recordGameResult(snapshot game.GameSnapshot) {
-	if snapshot.IsDraw() {
-		s.statsStore.RecordGameResult(snapshot.PlayerX, snapshot.PlayerO, true)
-	} else {
-		s.statsStore.RecordGameResult(snapshot.GetWinner(), snapshot.GetLoser(), false)
+// broadcastFinal sends the last update of a game removed from the store or
+// ended by the server, then closes its subscribers' channels and drops its
+// feed, so neither outlives the game
+func (s *TicTacToeServer) broadcastFinal(gameID string, update *pb.GameUpdate) {
+	feed := s.lockFeed(gameID)
+	if feed == nil {
+		return
+	}
+	defer feed.mu.Unlock()
+
+	feed.history.add(update)
+	for ch := range feed.subscribers {
+		s.deliverLast(ch, update)
+	}
+	s.dropFeed(gameID, feed)
+}
+
+// forgetGame closes the channels of a removed game's subscribers and drops
+// its history
+func (s *TicTacToeServer) forgetGame(gameID string) {
+	feed := s.lockFeed(gameID)
+	if feed == nil {
+		return
+	}
+	defer feed.mu.Unlock()
+	s.dropFeed(gameID, feed)
+}
+
+// dropFeed does the work of forgetGame (must hold feed.mu)
+func (s *TicTacToeServer) dropFeed(gameID string, feed *gameFeed) {
+	for ch := range feed.subscribers {
+		close(ch)
+	}
+	s.subscriberCount.Add(-int64(len(feed.subscribers)))
+	feed.subscribers = nil
+	feed.removed = true
+
+	s.feedsMu.Lock()
+	if s.feeds[gameID] == feed {
+		delete(s.feeds, gameID)
+	}
+	s.feedsMu.Unlock()
+}
+
+// sendToUser sends an update to a game's subscribers streaming as userID
+// only. Such updates are private, so they are not recorded for replay; they
+// carry the game's latest sequence number without advancing it. If current
+// is not nil the update is dropped unless it reports true. It is checked
+// under the game's feed lock, which the broadcast of any later change waits
+// for, so an update it lets through never follows one it predates.
+func (s *TicTacToeServer) sendToUser(gameID, userID string, update *pb.GameUpdate, current func() bool) {
+	feed := s.lockFeed(gameID)
+	if feed == nil {
+		return
+	}
+	defer feed.mu.Unlock()
+
+	if current != nil && !current() {
+		return
+	}
+
+	update.Sequence = feed.history.latest
+	for ch, subscriber := range feed.subscribers {
+		if subscriber == userID {
+			s.deliver(ch, update)
+		}
+	}
+}
+
+// updateFingerprint keeps the fingerprint index in sync with a game's board.
+// Only in-progress games with at least one move are indexed: every fresh board
+// looks the same, and finished games can no longer be driven by a script.
+func (s *TicTacToeServer) updateFingerprint(snapshot game.GameSnapshot) {
+	if s.fingerprints == nil {
+		return
+	}
+	if snapshot.Status != game.StatusInProgress || snapshot.Board.IsEmpty() {
+		s.fingerprints.Remove(snapshot.ID)
+		return
+	}
+	s.fingerprints.Update(snapshot.ID, snapshot.Board.Fingerprint())
+}
+
+// ListDuplicateGames reports in-progress games sharing an identical board
+func (s *TicTacToeServer) ListDuplicateGames(ctx context.Context, req *pb.ListDuplicateGamesRequest) (*pb.ListDuplicateGamesResponse, error) {
+	if s.fingerprints == nil {
+		return nil, status.Error(codes.FailedPrecondition, "fingerprint index is not enabled")
+	}
+
+	groups := s.fingerprints.Duplicates(int(req.MinGames))
+
+	pbGroups := make([]*pb.DuplicateGameGroup, len(groups))
+	for i, group := range groups {
+		pbGroups[i] = &pb.DuplicateGameGroup{
+			Fingerprint: fmt.Sprintf("%016x", group.Fingerprint),
+			GameIds:     group.GameIDs,
+		}
+	}
+
+	return &pb.ListDuplicateGamesResponse{
+		Groups: pbGroups,
+	}, nil
+}
+
+// recordGameResult records the game result in stats, and in the game's
+// tournament if it has one, at most once per game. Games set up from a
+// position never count towards player stats: the position can hand either
+// player a result they did not play for.
+func (s *TicTacToeServer) recordGameResult(g *game.Game, snapshot game.GameSnapshot) {
+	if !g.MarkResultRecorded() {
+		return
+	}
+	s.gameStore.MarkFinished(snapshot.ID)
+	switch {
+	case snapshot.FromPosition:
+	case snapshot.Status == game.StatusAbandoned:
+		s.statsStore.RecordAbandonment(snapshot.GetAbandoner(), snapshot.Board.Size, s.abandonPolicy)
+	case snapshot.NumPlayers > 2:
+		s.statsStore.RecordMultiPlayerResult(snapshot.GetWinner(), snapshot.Players(), snapshot.Board.Size)
+	case snapshot.IsDraw():
+		s.statsStore.RecordGameResult(snapshot.PlayerX, snapshot.PlayerO, true, snapshot.Board.Size)
+	default:
+		s.statsStore.RecordGameResult(snapshot.GetWinner(), snapshot.GetLoser(), false, snapshot.Board.Size)
+	}
+	if standardGame(snapshot) && decidedOnBoard(snapshot) {
+		s.outcomes.Record(store.BoardConfig{BoardSize: snapshot.Board.Size, WinLength: snapshot.Board.WinLength}, snapshot.Status)
+	}
+	s.advanceTournament(snapshot)
+}
+
+// standardGame reports whether a game counts towards the outcome stats: two
+// players taking turns in classic mode on a square flat board, with no rule
+// that changes who benefits from moving first and no hints from the search,
+// played here from an empty board rather than set up from a position or
+// imported part way through
+func standardGame(snapshot game.GameSnapshot) bool {
+	return snapshot.NumPlayers == 2 && snapshot.Mode == game.ModeClassic && snapshot.Board.IsSquare() &&
+		!snapshot.Board.IsCube() && !snapshot.Misere && !snapshot.Hints &&
+		!snapshot.FromPosition && !snapshot.Imported
+}
+
+// decidedOnBoard reports whether a finished game's result was reached by
+// play: a win needs a completed line and a draw a board on which neither
+// player can complete one, so forfeits and early agreed draws are left out
+func decidedOnBoard(snapshot game.GameSnapshot) bool {
+	switch {
+	case snapshot.IsDraw():
+		return !snapshot.Board.WinPossible(game.MarkX) && !snapshot.Board.WinPossible(game.MarkO)
+	case snapshot.GetWinner() != "":
+		return len(snapshot.Board.WinningLines()) > 0
+	default:
+		return false
 	}
 }
 
@@ -434,13 +2451,18 @@ func (s *TicTacToeServer) getUpdateMessage(snapshot game.GameSnapshot) string {
 		return "Player X wins!"
 	case game.StatusOWon:
 		return "Player O wins!"
+	case game.StatusTriangleWon:
+		return "Player △ wins!"
 	case game.StatusDraw:
 		return "Game ended in a draw!"
+	case game.StatusAbandoned:
+		return fmt.Sprintf("Player %s abandoned the game", markToChar(snapshot.AbandonedBy))
+	case game.StatusCancelled:
+		return "Game cancelled by its creator"
 	case game.StatusInProgress:
-		if snapshot.Turn == game.MarkX {
-			return "Player X's turn"
-		}
-		return "Player O's turn"
+		return fmt.Sprintf("Player %s's turn", markToChar(snapshot.Turn))
+	case game.StatusReady:
+		return fmt.Sprintf("%d of %d players ready", len(snapshot.ReadyPlayers), snapshot.NumPlayers)
 	default:
 		return ""
 	}
@@ -450,5 +2472,8 @@ func (s *TicTacToeServer) getUpdateMessage(snapshot game.GameSnapshot) string {
 func isGameFinished(status pb.GameStatus) bool {
 	return status == pb.GameStatus_GAME_STATUS_X_WON ||
 		status == pb.GameStatus_GAME_STATUS_O_WON ||
-		status == pb.GameStatus_GAME_STATUS_DRAW
+		status == pb.GameStatus_GAME_STATUS_TRIANGLE_WON ||
+		status == pb.GameStatus_GAME_STATUS_DRAW ||
+		status == pb.GameStatus_GAME_STATUS_ABANDONED ||
+		status == pb.GameStatus_GAME_STATUS_CANCELLED
 }
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/internal/game"
+)
+
+// playedGame returns a game on a size x size board after X and O have moved
+// to the given cells in turn
+func playedGame(t *testing.T, size, winLength int, moves ...[2]int) *game.Game {
+	t.Helper()
+	g, err := game.NewGame("board", "x", size, winLength)
+	require.NoError(t, err)
+	require.NoError(t, g.Join("o"))
+	players := []string{"x", "o"}
+	for i, move := range moves {
+		_, err := g.MakeMove(players[i%2], move[0], move[1])
+		require.NoError(t, err)
+	}
+	return g
+}
+
+func TestSnapshotToBoardResponse_Coordinates(t *testing.T) {
+	g := playedGame(t, 3, 3, [2]int{1, 1}, [2]int{0, 2})
+
+	// Unlabeled by default
+	plain := snapshotToBoardResponse(g.GetSnapshot(), false)
+	assert.Equal(t, ""+
+		"+---+---+---+\n"+
+		"|   |   | O |\n"+
+		"+---+---+---+\n"+
+		"|   | X |   |\n"+
+		"+---+---+---+\n"+
+		"|   |   |   |\n"+
+		"+---+---+---+\n", plain.BoardDisplay)
+
+	labeled := snapshotToBoardResponse(g.GetSnapshot(), true)
+	assert.Equal(t, ""+
+		"    0   1   2\n"+
+		"  +---+---+---+\n"+
+		"0 |   |   | O |\n"+
+		"  +---+---+---+\n"+
+		"1 |   | X |   |\n"+
+		"  +---+---+---+\n"+
+		"2 |   |   |   |\n"+
+		"  +---+---+---+\n", labeled.BoardDisplay)
+	assert.Equal(t, plain.Rows, labeled.Rows, "only the display is labeled")
+}
+
+func TestSnapshotToBoardResponse_CoordinatesTwoDigits(t *testing.T) {
+	g := playedGame(t, 11, 5, [2]int{0, 10}, [2]int{10, 0})
+
+	// Row labels are right-aligned and column labels sit over their cells
+	labeled := snapshotToBoardResponse(g.GetSnapshot(), true)
+	assert.Equal(t, ""+
+		"     0   1   2   3   4   5   6   7   8   9  10\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 0 |   |   |   |   |   |   |   |   |   |   | X |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 1 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 2 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 3 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 4 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 5 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 6 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 7 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 8 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		" 9 |   |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n"+
+		"10 | O |   |   |   |   |   |   |   |   |   |   |\n"+
+		"   +---+---+---+---+---+---+---+---+---+---+---+\n", labeled.BoardDisplay)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/game"
+	"tictactoe/internal/store"
+)
+
+// drain returns the updates waiting on ch without blocking, stopping at
+// the end of a closed channel
+func drain(ch chan *pb.GameUpdate) []*pb.GameUpdate {
+	var updates []*pb.GameUpdate
+	for {
+		select {
+		case update, open := <-ch:
+			if !open {
+				return updates
+			}
+			updates = append(updates, update)
+		default:
+			return updates
+		}
+	}
+}
+
+func TestSendToUser(t *testing.T) {
+	s := NewTicTacToeServer(store.NewGameStore(1), store.NewStatsStore(1))
+
+	// alice streams from two tabs; bob and an anonymous spectator watch too
+	alice1 := make(chan *pb.GameUpdate, 10)
+	alice2 := make(chan *pb.GameUpdate, 10)
+	bob := make(chan *pb.GameUpdate, 10)
+	spectator := make(chan *pb.GameUpdate, 10)
+	s.subscribe("game", alice1, "alice", 0)
+	s.subscribe("game", alice2, "alice", 0)
+	s.subscribe("game", bob, "bob", 0)
+	s.subscribe("game", spectator, "", 0)
+	other := make(chan *pb.GameUpdate, 10)
+	s.subscribe("other", other, "alice", 0)
+
+	s.broadcastUpdate("game", &pb.GameUpdate{Message: "everyone"})
+	s.sendToUser("game", "alice", &pb.GameUpdate{Message: "alice only"}, nil)
+
+	for _, ch := range []chan *pb.GameUpdate{alice1, alice2} {
+		updates := drain(ch)
+		if assert.Len(t, updates, 2) {
+			assert.Equal(t, "alice only", updates[1].Message)
+			// A targeted update shares the latest sequence instead of taking a new one
+			assert.Equal(t, uint64(1), updates[1].Sequence)
+		}
+	}
+	for _, ch := range []chan *pb.GameUpdate{bob, spectator} {
+		updates := drain(ch)
+		if assert.Len(t, updates, 1) {
+			assert.Equal(t, "everyone", updates[0].Message)
+		}
+	}
+	assert.Empty(t, drain(other), "updates stay within their game")
+
+	// Targeted updates are not replayed to a reconnecting subscriber
+	s.broadcastUpdate("game", &pb.GameUpdate{Message: "everyone again"})
+	_, missed, ok := s.subscribe("game", make(chan *pb.GameUpdate, 10), "alice", 1)
+	assert.True(t, ok)
+	if assert.Len(t, missed, 1) {
+		assert.Equal(t, "everyone again", missed[0].Message)
+		assert.Equal(t, uint64(2), missed[0].Sequence)
+	}
+
+	drain(alice1)
+	drain(spectator)
+
+	// Sending to a user without an open stream reaches nobody
+	s.unsubscribe("game", bob)
+	s.sendToUser("game", "bob", &pb.GameUpdate{Message: "gone"}, nil)
+	assert.Empty(t, drain(alice1))
+	assert.Empty(t, drain(spectator))
+
+	// An update no longer current when its turn to be sent comes is dropped
+	s.sendToUser("game", "alice", &pb.GameUpdate{Message: "stale"}, func() bool { return false })
+	assert.Empty(t, drain(alice1))
+}
+
+func TestUnsubscribe_ConcurrentBroadcasts(t *testing.T) {
+	s := NewTicTacToeServer(store.NewGameStore(1), store.NewStatsStore(1), WithStreamBuffer(1))
+
+	// Streams come and go while updates are broadcast and sent to their
+	// users; none may be sent an update after it is closed
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				s.broadcastUpdate("game", &pb.GameUpdate{})
+				s.sendToUser("game", "alice", &pb.GameUpdate{}, nil)
+			}
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				ch := make(chan *pb.GameUpdate, s.streamBuffer)
+				s.subscribe("game", ch, "alice", 0)
+				drain(ch)
+				s.unsubscribe("game", ch)
+				s.unsubscribe("game", ch)
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	assert.Zero(t, s.SubscriberCount())
+}
+
+func TestBroadcastFinal_ForgetsRemovedGame(t *testing.T) {
+	gameStore := store.NewGameStore(1)
+	s := NewTicTacToeServer(gameStore, store.NewStatsStore(1))
+
+	g, err := game.NewGame("game", "alice", 3, 3)
+	require.NoError(t, err)
+	require.NoError(t, gameStore.Create(g))
+	ch := make(chan *pb.GameUpdate, 10)
+	s.subscribe("game", ch, "alice", 0)
+	s.broadcastUpdate("game", &pb.GameUpdate{Message: "before"})
+
+	// Expiry removes the game; its stream gets the notice, then is closed
+	s.expirePendingGames(time.Now().Add(time.Second))
+	updates := drain(ch)
+	if assert.Len(t, updates, 2) {
+		assert.Equal(t, "Game expired, no opponent joined", updates[1].Message)
+	}
+	_, open := <-ch
+	assert.False(t, open)
+	assert.Zero(t, s.SubscriberCount())
+
+	// A late update for the removed game leaves nothing behind
+	s.broadcastUpdate("game", &pb.GameUpdate{Message: "late"})
+	s.feedsMu.RLock()
+	defer s.feedsMu.RUnlock()
+	assert.Empty(t, s.feeds)
+}
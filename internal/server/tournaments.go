@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/game"
+	"tictactoe/internal/store"
+)
+
+const (
+	// MaxTournamentParticipants is the most participants one tournament may have
+	MaxTournamentParticipants = 64
+	// MaxTournamentNameLength is the longest tournament name accepted, in characters
+	MaxTournamentNameLength = 64
+
+	// DefaultMaxTournamentsPerUser caps the tournaments under way a user may create
+	DefaultMaxTournamentsPerUser = 3
+
+	// DefaultFinishedTournamentTTL is how long a decided tournament is kept
+	DefaultFinishedTournamentTTL = 24 * time.Hour
+
+	// tournamentSweepInterval is how often stalled matches are retried and
+	// old tournaments removed
+	tournamentSweepInterval = 10 * time.Second
+)
+
+// WithMaxTournamentsPerUser caps the tournaments under way each user may
+// create; creating more fails with ResourceExhausted until one is decided.
+// Zero means unlimited; DefaultMaxTournamentsPerUser applies otherwise.
+func WithMaxTournamentsPerUser(limit int) Option {
+	return func(s *TicTacToeServer) {
+		s.maxTournamentsPerUser = limit
+	}
+}
+
+// WithFinishedTournamentTTL removes tournaments ttl after they are decided,
+// after which reading them fails with NotFound. Zero keeps them forever;
+// DefaultFinishedTournamentTTL applies otherwise.
+func WithFinishedTournamentTTL(ttl time.Duration) Option {
+	return func(s *TicTacToeServer) {
+		s.finishedTournamentTTL = ttl
+	}
+}
+
+// CreateTournament starts a single-elimination tournament between the
+// participants, in seeding order. When their number is not a power of two
+// the top seeds get byes into the second round. The server creates each
+// match's game as a private game with both players seated, and advances the
+// bracket as games finish; a drawn game is replayed with the marks swapped.
+// Only a participant or an admin may create a tournament.
+func (s *TicTacToeServer) CreateTournament(ctx context.Context, req *pb.CreateTournamentRequest) (*pb.CreateTournamentResponse, error) {
+	userID, err := s.actingUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if !utf8.ValidString(name) || utf8.RuneCountInString(name) > MaxTournamentNameLength {
+		return nil, status.Errorf(codes.InvalidArgument, "name must be valid UTF-8 of at most %d characters", MaxTournamentNameLength)
+	}
+	if len(req.ParticipantIds) < 2 || len(req.ParticipantIds) > MaxTournamentParticipants {
+		return nil, status.Errorf(codes.InvalidArgument, "participant_ids must list between 2 and %d users", MaxTournamentParticipants)
+	}
+	if slices.Contains(req.ParticipantIds, "") {
+		return nil, status.Error(codes.InvalidArgument, "participant_ids must not contain empty IDs")
+	}
+	if !slices.Contains(req.ParticipantIds, userID) {
+		if err := s.requireAdmin(ctx); err != nil {
+			return nil, status.Error(status.Code(err), "only a participant or an admin may create a tournament")
+		}
+	}
+
+	boardSize := int(req.BoardSize)
+	if boardSize == 0 {
+		boardSize = s.defaultBoardSize
+	}
+	if boardSize < 3 || boardSize > s.maxBoardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "board_size must be between 3 and %d", s.maxBoardSize)
+	}
+	winLength := int(req.WinLength)
+	if winLength == 0 {
+		winLength = s.defaultWinLengthFor(boardSize)
+	}
+	if winLength < 3 || winLength > boardSize {
+		return nil, status.Errorf(codes.InvalidArgument, "win_length must be between 3 and board_size (%d)", boardSize)
+	}
+
+	cfg := store.BoardConfig{BoardSize: boardSize, WinLength: winLength}
+	t, pairings, err := s.tournaments.Create(uuid.New().String(), name, userID, req.ParticipantIds, cfg, s.maxTournamentsPerUser)
+	if err != nil {
+		switch err {
+		case store.ErrDuplicateParticipant:
+			return nil, status.Error(codes.InvalidArgument, "participant_ids must be distinct")
+		case store.ErrTooFewParticipants:
+			return nil, status.Errorf(codes.InvalidArgument, "participant_ids must list between 2 and %d users", MaxTournamentParticipants)
+		case store.ErrTooManyTournaments:
+			return nil, status.Error(codes.ResourceExhausted, "too many tournaments under way")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create tournament: %v", err)
+	}
+	s.startMatches(pairings)
+
+	// Report the games just started along with the byes
+	t, err = s.tournaments.Get(t.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tournament: %v", err)
+	}
+	return &pb.CreateTournamentResponse{
+		Tournament: tournamentToProto(t),
+	}, nil
+}
+
+// GetTournament retrieves a tournament's participants and winner
+func (s *TicTacToeServer) GetTournament(ctx context.Context, req *pb.GetTournamentRequest) (*pb.GetTournamentResponse, error) {
+	t, err := s.getTournament(req.TournamentId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetTournamentResponse{
+		Tournament: tournamentToProto(t),
+	}, nil
+}
+
+// GetTournamentBracket retrieves every match of a tournament, round by round,
+// with the games played in each
+func (s *TicTacToeServer) GetTournamentBracket(ctx context.Context, req *pb.GetTournamentBracketRequest) (*pb.GetTournamentBracketResponse, error) {
+	t, err := s.getTournament(req.TournamentId)
+	if err != nil {
+		return nil, err
+	}
+
+	rounds := make([]*pb.TournamentRound, len(t.Rounds))
+	for r, round := range t.Rounds {
+		matches := make([]*pb.TournamentMatch, len(round))
+		for i, m := range round {
+			matches[i] = &pb.TournamentMatch{
+				Round:     int32(r),
+				Index:     int32(i),
+				PlayerAId: m.PlayerA,
+				PlayerBId: m.PlayerB,
+				Bye:       m.Bye,
+				GameIds:   m.GameIDs,
+				WinnerId:  m.Winner,
+			}
+		}
+		rounds[r] = &pb.TournamentRound{
+			Round:   int32(r),
+			Matches: matches,
+		}
+	}
+	return &pb.GetTournamentBracketResponse{
+		TournamentId: t.ID,
+		Rounds:       rounds,
+	}, nil
+}
+
+// getTournament looks a tournament up for the read RPCs
+func (s *TicTacToeServer) getTournament(id string) (store.Tournament, error) {
+	if id == "" {
+		return store.Tournament{}, requiredFieldError("tournament_id")
+	}
+	t, err := s.tournaments.Get(id)
+	if err != nil {
+		if err == store.ErrTournamentNotFound {
+			return store.Tournament{}, status.Error(codes.NotFound, "tournament not found")
+		}
+		return store.Tournament{}, status.Errorf(codes.Internal, "failed to get tournament: %v", err)
+	}
+	return t, nil
+}
+
+// startMatches creates a game for each claimed pairing and seats both
+// players. A game that cannot be created, say because a player is at their
+// active game limit, is cancelled, and its match tried again by
+// retryStalledMatches.
+func (s *TicTacToeServer) startMatches(pairings []store.Pairing) {
+	for _, p := range pairings {
+		gameID := uuid.New().String()
+		s.tournaments.StartGame(p, gameID)
+		if err := s.startMatch(p, gameID); err != nil {
+			s.tournaments.CancelGame(gameID)
+		}
+	}
+}
+
+// startMatch creates and fills a private game for a pairing. The join code
+// keeps anyone else from taking the second seat.
+func (s *TicTacToeServer) startMatch(p store.Pairing, gameID string) error {
+	joinCode, err := newJoinCode()
+	if err != nil {
+		return err
+	}
+	g, err := game.NewGame(gameID, p.PlayerX, p.Config.BoardSize, p.Config.WinLength,
+		game.WithJoinCode(joinCode),
+		game.WithMoveAdmissionLimit(s.moveAdmissionLimit),
+		game.WithMaxMoves(s.maxMoves),
+	)
+	if err != nil {
+		return err
+	}
+	if err := s.gameStore.Create(g); err != nil {
+		return err
+	}
+	if _, err := s.gameStore.Join(gameID, p.PlayerO, game.UsingJoinCode(joinCode)); err != nil {
+		s.gameStore.Delete(gameID)
+		return err
+	}
+	s.metrics.recordGameCreated()
+	return nil
+}
+
+// advanceTournament passes a finished game's result to its tournament, if
+// it has one, and starts the matches that result makes ready. Only the
+// winner counts: a player who abandons loses, and a draw is replayed. The
+// game's players are free again, so stalled matches get another try too.
+func (s *TicTacToeServer) advanceTournament(snapshot game.GameSnapshot) {
+	winner := snapshot.GetWinner()
+	if abandoner := snapshot.GetAbandoner(); abandoner != "" {
+		winner = snapshot.PlayerX
+		if abandoner == snapshot.PlayerX {
+			winner = snapshot.PlayerO
+		}
+	}
+	if pairings, ok := s.tournaments.RecordResult(snapshot.ID, winner); ok {
+		s.startMatches(pairings)
+	}
+	s.retryStalledMatches()
+}
+
+// retryStalledMatches starts the matches whose game could not be created
+func (s *TicTacToeServer) retryStalledMatches() {
+	if pairings := s.tournaments.ClaimStalled(); len(pairings) > 0 {
+		s.startMatches(pairings)
+	}
+}
+
+// sweepTournaments retries stalled matches and removes tournaments decided
+// longer than the finished tournament TTL ago, every
+// tournamentSweepInterval until Close
+func (s *TicTacToeServer) sweepTournaments() {
+	ticker := time.NewTicker(tournamentSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.retryStalledMatches()
+			if s.finishedTournamentTTL > 0 {
+				s.tournaments.RemoveFinished(time.Now().Add(-s.finishedTournamentTTL))
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+// tournamentToProto converts a tournament to protobuf
+func tournamentToProto(t store.Tournament) *pb.Tournament {
+	return &pb.Tournament{
+		TournamentId:   t.ID,
+		Name:           t.Name,
+		CreatedBy:      t.CreatedBy,
+		ParticipantIds: t.Participants,
+		BoardSize:      int32(t.Config.BoardSize),
+		WinLength:      int32(t.Config.WinLength),
+		NumRounds:      int32(len(t.Rounds)),
+		WinnerId:       t.Winner,
+		Finished:       t.Winner != "",
+		CreatedAt:      t.CreatedAt.Unix(),
+	}
+}
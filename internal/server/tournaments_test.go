@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/store"
+)
+
+func TestTimeOutGames_ReplaysTournamentMatch(t *testing.T) {
+	s := NewTicTacToeServer(store.NewGameStore(1), store.NewStatsStore(1))
+	defer s.Close()
+	ctx := context.Background()
+
+	created, err := s.CreateTournament(ctx, &pb.CreateTournamentRequest{UserId: "alice", ParticipantIds: []string{"alice", "bob"}})
+	require.NoError(t, err)
+	id := created.Tournament.TournamentId
+
+	// A game that runs out of time is replayed, not lost by the player on turn
+	s.timeOutGames(time.Now().Add(time.Hour))
+	tournament, err := s.tournaments.Get(id)
+	require.NoError(t, err)
+	match := tournament.Rounds[0][0]
+	assert.Empty(t, match.Winner)
+	require.Len(t, match.GameIDs, 2)
+	g, err := s.gameStore.Get(match.GameIDs[1])
+	require.NoError(t, err)
+	assert.Equal(t, "bob", g.GetSnapshot().PlayerX)
+}
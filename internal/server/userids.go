@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UserIDPolicy decides which user IDs JoinGame treats as the same user when
+// keeping a player out of a second seat in one game
+type UserIDPolicy int
+
+const (
+	UserIDExact UserIDPolicy = iota // IDs must match exactly
+	UserIDTrim                      // Surrounding whitespace is ignored
+	UserIDFold                      // Surrounding whitespace and case are ignored
+)
+
+func (p UserIDPolicy) String() string {
+	switch p {
+	case UserIDExact:
+		return "exact"
+	case UserIDTrim:
+		return "trim"
+	case UserIDFold:
+		return "fold"
+	default:
+		return fmt.Sprintf("UserIDPolicy(%d)", int(p))
+	}
+}
+
+// ParseUserIDPolicy parses a policy name as returned by UserIDPolicy.String
+func ParseUserIDPolicy(name string) (UserIDPolicy, error) {
+	for _, p := range []UserIDPolicy{UserIDExact, UserIDTrim, UserIDFold} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return UserIDExact, fmt.Errorf("unknown user ID policy %q", name)
+}
+
+// normalize maps a user ID to the form compared under the policy
+func (p UserIDPolicy) normalize(userID string) string {
+	switch p {
+	case UserIDTrim:
+		return strings.TrimSpace(userID)
+	case UserIDFold:
+		return strings.ToLower(strings.TrimSpace(userID))
+	default:
+		return userID
+	}
+}
+
+// WithUserIDPolicy sets which user IDs count as the same user when joining
+// a game (UserIDExact by default). It guards against one user holding two
+// seats under look-alike IDs, for example when auth is disabled or tokens
+// map differently spelled IDs to one person. Stats still key on the exact ID.
+func WithUserIDPolicy(policy UserIDPolicy) Option {
+	return func(s *TicTacToeServer) {
+		s.userIDPolicy = policy
+	}
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"sort"
+	"sync"
+)
+
+// DuplicateGroup is a set of games that currently share a board fingerprint
+type DuplicateGroup struct {
+	Fingerprint uint64
+	GameIDs     []string
+}
+
+// FingerprintIndex maps board fingerprints to the games currently in that position.
+// Keeping it up to date costs a hash and a lock per move, so it is opt-in.
+type FingerprintIndex struct {
+	mu            sync.RWMutex
+	byFingerprint map[uint64]map[string]struct{}
+	byGame        map[string]uint64
+}
+
+// NewFingerprintIndex creates an empty fingerprint index
+func NewFingerprintIndex() *FingerprintIndex {
+	return &FingerprintIndex{
+		byFingerprint: make(map[uint64]map[string]struct{}),
+		byGame:        make(map[string]uint64),
+	}
+}
+
+// Update records the current fingerprint for a game, replacing any previous one
+func (idx *FingerprintIndex) Update(gameID string, fingerprint uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, ok := idx.byGame[gameID]; ok {
+		if old == fingerprint {
+			return
+		}
+		idx.removeLocked(gameID, old)
+	}
+
+	games := idx.byFingerprint[fingerprint]
+	if games == nil {
+		games = make(map[string]struct{})
+		idx.byFingerprint[fingerprint] = games
+	}
+	games[gameID] = struct{}{}
+	idx.byGame[gameID] = fingerprint
+}
+
+// Remove drops a game from the index
+func (idx *FingerprintIndex) Remove(gameID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, ok := idx.byGame[gameID]; ok {
+		idx.removeLocked(gameID, old)
+	}
+}
+
+// removeLocked removes a game from its fingerprint bucket; caller must hold the lock
+func (idx *FingerprintIndex) removeLocked(gameID string, fingerprint uint64) {
+	delete(idx.byGame, gameID)
+	if games, ok := idx.byFingerprint[fingerprint]; ok {
+		delete(games, gameID)
+		if len(games) == 0 {
+			delete(idx.byFingerprint, fingerprint)
+		}
+	}
+}
+
+// Duplicates returns every fingerprint shared by at least minGames games,
+// largest groups first. Game IDs within a group are sorted.
+func (idx *FingerprintIndex) Duplicates(minGames int) []DuplicateGroup {
+	if minGames < 2 {
+		minGames = 2
+	}
+
+	idx.mu.RLock()
+	var groups []DuplicateGroup
+	for fingerprint, games := range idx.byFingerprint {
+		if len(games) < minGames {
+			continue
+		}
+		ids := make([]string, 0, len(games))
+		for id := range games {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		groups = append(groups, DuplicateGroup{Fingerprint: fingerprint, GameIDs: ids})
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].GameIDs) != len(groups[j].GameIDs) {
+			return len(groups[i].GameIDs) > len(groups[j].GameIDs)
+		}
+		return groups[i].Fingerprint < groups[j].Fingerprint
+	})
+	return groups
+}
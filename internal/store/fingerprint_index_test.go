@@ -0,0 +1,67 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tictactoe/internal/game"
+)
+
+func TestFingerprintIndex_IdenticalScriptedGames(t *testing.T) {
+	idx := NewFingerprintIndex()
+
+	// Three games follow the same script, one deviates
+	script := [][2]int{{1, 1}, {0, 0}, {2, 2}}
+	for i := 0; i < 4; i++ {
+		g, err := game.NewGame(fmt.Sprintf("game-%d", i), "x", 3, 3)
+		require.NoError(t, err)
+		require.NoError(t, g.Join("o"))
+
+		moves := script
+		if i == 3 {
+			moves = [][2]int{{1, 1}, {0, 0}, {0, 2}}
+		}
+		players := []string{"x", "o"}
+		for n, m := range moves {
+			_, err := g.MakeMove(players[n%2], m[0], m[1])
+			require.NoError(t, err)
+			snapshot := g.GetSnapshot()
+			idx.Update(g.ID, snapshot.Board.Fingerprint())
+		}
+	}
+
+	groups := idx.Duplicates(2)
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{"game-0", "game-1", "game-2"}, groups[0].GameIDs)
+
+	// A larger threshold filters the group out
+	assert.Empty(t, idx.Duplicates(4))
+}
+
+func TestFingerprintIndex_UpdateMovesBetweenGroups(t *testing.T) {
+	idx := NewFingerprintIndex()
+
+	idx.Update("a", 1)
+	idx.Update("b", 1)
+	require.Len(t, idx.Duplicates(2), 1)
+
+	// Game "b" moves on to a new position
+	idx.Update("b", 2)
+	assert.Empty(t, idx.Duplicates(2))
+
+	idx.Update("a", 2)
+	groups := idx.Duplicates(2)
+	require.Len(t, groups, 1)
+	assert.Equal(t, uint64(2), groups[0].Fingerprint)
+	assert.Equal(t, []string{"a", "b"}, groups[0].GameIDs)
+
+	// Removal shrinks the group
+	idx.Remove("a")
+	assert.Empty(t, idx.Duplicates(2))
+
+	// Removing an unknown game is a no-op
+	idx.Remove("missing")
+}
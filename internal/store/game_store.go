@@ -2,7 +2,10 @@ package store
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"tictactoe/internal/game"
 )
@@ -10,6 +13,10 @@ import (
 var (
 	ErrGameNotFound      = errors.New("game not found")
 	ErrGameAlreadyExists = errors.New("game already exists")
+	ErrTooManyGames      = errors.New("user has too many active games")
+	ErrStoreFull         = errors.New("game store is full of games in progress")
+	ErrTooManyPending    = errors.New("too many games waiting for an opponent")
+	ErrJoinRaceLost      = errors.New("another player joined first")
 )
 
 // GameStore provides thread-safe storage for games
@@ -17,16 +24,77 @@ var (
 type GameStore struct {
 	shards    []*gameShard
 	numShards int
+
+	// players indexes games by the users seated in them
+	players *playerIndex
+
+	// Optional cap on each user's pending and in-progress games (0 = unlimited)
+	maxActiveGames int
+
+	// Optional cap on the games held (0 = unlimited); see WithMaxGames
+	maxGames int
+
+	// held counts the games held, including those being added
+	held atomic.Int64
+
+	// onEvict is told about each game removed to make room (may be nil)
+	onEvict func(*game.Game)
+
+	// recent orders the games eviction may pick
+	recent *recency
+
+	// Optional cap on pending games across all users (0 = unlimited)
+	maxPending int
+
+	// pendingCount counts the games in the shards' pending sets
+	pendingCount atomic.Int64
+}
+
+// GameStoreOption configures optional game store behavior
+type GameStoreOption func(*GameStore)
+
+// WithMaxActiveGames caps how many pending or in-progress games a user may
+// hold a seat in. Creating or joining beyond the cap fails with
+// ErrTooManyGames; a seat is freed when its game is marked finished or
+// deleted. Zero means unlimited.
+func WithMaxActiveGames(maxGames int) GameStoreOption {
+	return func(s *GameStore) {
+		s.maxActiveGames = maxGames
+	}
+}
+
+// WithMaxGames caps how many games the store holds. Adding a game to a full
+// store first evicts the least recently updated finished game, or failing
+// that pending game, which is cancelled; games that are ready or in progress
+// are never evicted, so when only those are left adding fails with
+// ErrStoreFull. Games are kept in order of the last change the store saw,
+// a join or finish, so eviction takes no scan. Zero means unlimited.
+func WithMaxGames(maxGames int) GameStoreOption {
+	return func(s *GameStore) {
+		s.maxGames = maxGames
+	}
+}
+
+// WithMaxPendingGames caps how many games may wait for an opponent at once,
+// across all users. Creating a game beyond the cap fails with
+// ErrTooManyPending until one is joined or removed. Zero means unlimited.
+func WithMaxPendingGames(maxGames int) GameStoreOption {
+	return func(s *GameStore) {
+		s.maxPending = maxGames
+	}
 }
 
 type gameShard struct {
 	mu    sync.RWMutex
 	games map[string]*game.Game
+
+	// pending holds the IDs of the games counted in pendingCount
+	pending map[string]struct{}
 }
 
 // NewGameStore creates a new game store with the specified number of shards
 // More shards = less contention but more memory overhead
-func NewGameStore(numShards int) *GameStore {
+func NewGameStore(numShards int, opts ...GameStoreOption) *GameStore {
 	if numShards < 1 {
 		numShards = 64 // Default for good concurrency
 	}
@@ -34,40 +102,269 @@ func NewGameStore(numShards int) *GameStore {
 	shards := make([]*gameShard, numShards)
 	for i := range shards {
 		shards[i] = &gameShard{
-			games: make(map[string]*game.Game),
+			games:   make(map[string]*game.Game),
+			pending: make(map[string]struct{}),
 		}
 	}
 
-	return &GameStore{
+	s := &GameStore{
 		shards:    shards,
 		numShards: numShards,
+		players:   newPlayerIndex(numShards),
+		recent:    newRecency(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// OnEvict sets a function told about each game evicted to make room under
+// WithMaxGames, after it has left the store. A pending game arrives
+// cancelled. Set it before the store is used.
+func (s *GameStore) OnEvict(fn func(*game.Game)) {
+	s.onEvict = fn
 }
 
 // getShard returns the shard for a given game ID
 func (s *GameStore) getShard(gameID string) *gameShard {
-	// Simple hash function for sharding
-	hash := uint32(0)
-	for _, c := range gameID {
-		hash = hash*31 + uint32(c)
-	}
-	return s.shards[hash%uint32(s.numShards)]
+	return s.shards[shardIndex(gameID, s.numShards)]
 }
 
-// Create stores a new game
+// Create stores a new game, evicting an older one if the store is full (see
+// WithMaxGames)
 func (s *GameStore) Create(g *game.Game) error {
+	// A new game is pending, so it needs a place in the pending pool
+	if err := s.reservePending(); err != nil {
+		return err
+	}
+	// Make room before taking the shard lock; eviction locks other shards
+	if err := s.reserve(); err != nil {
+		s.pendingCount.Add(-1)
+		return err
+	}
 	shard := s.getShard(g.ID)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
 	if _, exists := shard.games[g.ID]; exists {
+		s.held.Add(-1)
+		s.pendingCount.Add(-1)
 		return ErrGameAlreadyExists
 	}
 
+	// A new game has only its creator seated, as X or O
+	snapshot := g.GetSnapshot()
+	creator := snapshot.PlayerX
+	if creator == "" {
+		creator = snapshot.PlayerO
+	}
+	if ok, _ := s.players.add(creator, g.ID, s.maxActiveGames); !ok {
+		s.held.Add(-1)
+		s.pendingCount.Add(-1)
+		return ErrTooManyGames
+	}
+
 	shard.games[g.ID] = g
+	shard.pending[g.ID] = struct{}{}
+	s.recent.touchPending(g.ID)
 	return nil
 }
 
+// Import stores a game restored from an export, indexing all of its
+// players. It is an administrative action, so the active-game cap does not
+// apply; a finished game takes no active seats. A pending game still needs a
+// place in the pending pool, failing with ErrTooManyPending as for Create,
+// and a full store makes room as for Create.
+func (s *GameStore) Import(g *game.Game) error {
+	snapshot := g.GetSnapshot()
+	pending := snapshot.Status == game.StatusPending
+	if pending {
+		if err := s.reservePending(); err != nil {
+			return err
+		}
+	}
+	if err := s.reserve(); err != nil {
+		if pending {
+			s.pendingCount.Add(-1)
+		}
+		return err
+	}
+	shard := s.getShard(g.ID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if _, exists := shard.games[g.ID]; exists {
+		s.held.Add(-1)
+		if pending {
+			s.pendingCount.Add(-1)
+		}
+		return ErrGameAlreadyExists
+	}
+
+	for _, player := range snapshot.Players() {
+		s.players.add(player, g.ID, 0)
+		if snapshot.Status.IsFinished() {
+			s.players.finish(player, g.ID)
+		}
+	}
+
+	shard.games[g.ID] = g
+	switch {
+	case pending:
+		shard.pending[g.ID] = struct{}{}
+		s.recent.touchPending(g.ID)
+	case snapshot.Status.IsFinished():
+		s.recent.touchFinished(g.ID)
+	}
+	return nil
+}
+
+// Join seats playerID in a stored pending game and indexes them as one of its
+// players. A game whose last seat another player has taken, before or while
+// this join was under way, fails with ErrJoinRaceLost, so the caller knows to
+// try a different game; one that ended without filling up fails with
+// game.ErrGameAlreadyStarted.
+func (s *GameStore) Join(gameID, playerID string, opts ...game.JoinOption) (*game.Game, error) {
+	g, err := s.Get(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if g.GetStatus() != game.StatusPending {
+		return nil, joinRefusal(g)
+	}
+
+	// Reserve the seat first so concurrent joins cannot exceed the cap
+	ok, added := s.players.add(playerID, gameID, s.maxActiveGames)
+	if !ok {
+		return nil, ErrTooManyGames
+	}
+	if err := g.Join(playerID, opts...); err != nil {
+		if added {
+			s.players.remove(playerID, gameID)
+		}
+		if err == game.ErrGameAlreadyStarted {
+			return nil, joinRefusal(g)
+		}
+		return nil, err
+	}
+
+	// The join that fills the last seat takes the game out of the pending pool
+	shard := s.getShard(gameID)
+	shard.mu.Lock()
+	if _, stored := shard.games[gameID]; stored {
+		if g.GetStatus() != game.StatusPending {
+			s.forgetPending(shard, gameID)
+			s.recent.forget(gameID)
+		} else {
+			s.recent.touchPending(gameID)
+		}
+	}
+	shard.mu.Unlock()
+	return g, nil
+}
+
+// joinRefusal returns the error for joining a game that is no longer
+// pending: ErrJoinRaceLost if its seats were filled, whether it is waiting to
+// be started, under way or over, and game.ErrGameAlreadyStarted otherwise
+func joinRefusal(g *game.Game) error {
+	snapshot := g.GetSnapshot()
+	if snapshot.Status == game.StatusReady || !snapshot.StartedAt.IsZero() {
+		return ErrJoinRaceLost
+	}
+	return game.ErrGameAlreadyStarted
+}
+
+// Leave takes playerID out of a stored game (see game.Game.Leave). A game its
+// creator cancels is removed. A player who only gives up a seat is no longer
+// indexed as one of the game's players, and the game is back in the pending
+// pool, even a full one: the seat was already counted against the cap.
+func (s *GameStore) Leave(gameID, playerID string) (*game.Game, error) {
+	g, err := s.Get(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Leave(playerID); err != nil {
+		return nil, err
+	}
+
+	switch g.GetStatus() {
+	case game.StatusCancelled:
+		if err := s.Delete(gameID); err != nil && err != ErrGameNotFound {
+			return nil, err
+		}
+	case game.StatusPending:
+		s.players.remove(playerID, gameID)
+		shard := s.getShard(gameID)
+		shard.mu.Lock()
+		_, stored := shard.games[gameID]
+		_, pending := shard.pending[gameID]
+		if stored && g.GetStatus() == game.StatusPending {
+			if !pending {
+				shard.pending[gameID] = struct{}{}
+				s.pendingCount.Add(1)
+			}
+			s.recent.touchPending(gameID)
+		}
+		shard.mu.Unlock()
+	}
+	return g, nil
+}
+
+// PendingCount returns how many games are waiting for an opponent, without
+// scanning the store
+func (s *GameStore) PendingCount() int {
+	return int(s.pendingCount.Load())
+}
+
+// reservePending counts a new pending game, failing with ErrTooManyPending
+// when the pool is full. The caller undoes the count if the add fails.
+func (s *GameStore) reservePending() error {
+	for {
+		pending := s.pendingCount.Load()
+		if s.maxPending > 0 && pending >= int64(s.maxPending) {
+			return ErrTooManyPending
+		}
+		if s.pendingCount.CompareAndSwap(pending, pending+1) {
+			return nil
+		}
+	}
+}
+
+// forgetPending drops a game from the pending pool if it is in it (must hold
+// the shard's lock)
+func (s *GameStore) forgetPending(shard *gameShard, gameID string) {
+	if _, ok := shard.pending[gameID]; ok {
+		delete(shard.pending, gameID)
+		s.pendingCount.Add(-1)
+	}
+}
+
+// MarkFinished frees the active-game seats of a finished game's players.
+// The game stays in the store and in its players' history, and becomes the
+// most recently finished for eviction.
+func (s *GameStore) MarkFinished(gameID string) {
+	shard := s.getShard(gameID)
+	shard.mu.Lock()
+	g, exists := shard.games[gameID]
+	if exists {
+		s.recent.touchFinished(gameID)
+	}
+	shard.mu.Unlock()
+	if !exists {
+		return
+	}
+	snapshot := g.GetSnapshot()
+	for _, player := range snapshot.Players() {
+		s.players.finish(player, gameID)
+	}
+}
+
+// ActiveGameCount returns how many pending or in-progress games userID is seated in
+func (s *GameStore) ActiveGameCount(userID string) int {
+	return s.players.activeCount(userID)
+}
+
 // Get retrieves a game by ID
 func (s *GameStore) Get(gameID string) (*game.Game, error) {
 	shard := s.getShard(gameID)
@@ -82,36 +379,236 @@ func (s *GameStore) Get(gameID string) (*game.Game, error) {
 	return g, nil
 }
 
+// GetMany retrieves several games by ID, taking each game's shard lock in
+// turn. Found games are returned in request order; IDs with no game are
+// returned in missing. Duplicate IDs are looked up once.
+func (s *GameStore) GetMany(gameIDs []string) (found []*game.Game, missing []string) {
+	seen := make(map[string]struct{}, len(gameIDs))
+	for _, gameID := range gameIDs {
+		if _, dup := seen[gameID]; dup {
+			continue
+		}
+		seen[gameID] = struct{}{}
+
+		g, err := s.Get(gameID)
+		if err != nil {
+			missing = append(missing, gameID)
+			continue
+		}
+		found = append(found, g)
+	}
+	return found, missing
+}
+
 // Delete removes a game by ID
 func (s *GameStore) Delete(gameID string) error {
 	shard := s.getShard(gameID)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
 
-	if _, exists := shard.games[gameID]; !exists {
+	g, exists := shard.games[gameID]
+	if !exists {
 		return ErrGameNotFound
 	}
 
 	delete(shard.games, gameID)
+	s.held.Add(-1)
+	s.forgetPending(shard, gameID)
+	s.recent.forget(gameID)
+	snapshot := g.GetSnapshot()
+	for _, player := range snapshot.Players() {
+		s.players.remove(player, gameID)
+	}
 	return nil
 }
 
-// ListPending returns all pending games with pagination
-func (s *GameStore) ListPending(limit, offset int) ([]*game.GameSnapshot, int) {
-	var pending []*game.GameSnapshot
+// ExpirePending cancels and removes every pending or ready game that has not
+// changed since before cutoff (see game.Game.Expire), returning the removed
+// games
+func (s *GameStore) ExpirePending(cutoff time.Time) []*game.Game {
+	var expired []*game.Game
+	for _, shard := range s.shards {
+		shard.mu.Lock()
+		for gameID, g := range shard.games {
+			if !g.Expire(cutoff) {
+				continue
+			}
+			delete(shard.games, gameID)
+			s.held.Add(-1)
+			s.forgetPending(shard, gameID)
+			s.recent.forget(gameID)
+			snapshot := g.GetSnapshot()
+			for _, player := range snapshot.Players() {
+				s.players.remove(player, gameID)
+			}
+			expired = append(expired, g)
+		}
+		shard.mu.Unlock()
+	}
+	return expired
+}
 
-	// Collect pending games from all shards
+// reserve counts a game about to be added, first evicting games until the
+// store has room for it. The caller undoes the count if the add fails.
+func (s *GameStore) reserve() error {
+	for {
+		held := s.held.Load()
+		if s.maxGames <= 0 || held < int64(s.maxGames) {
+			if s.held.CompareAndSwap(held, held+1) {
+				return nil
+			}
+			continue
+		}
+		if !s.evictOne() {
+			return ErrStoreFull
+		}
+	}
+}
+
+// evictOne removes the least recently updated finished game, or failing that
+// pending game, and reports false if there was none. A pending candidate
+// that changes before it can be removed is kept, and true is still returned
+// so the caller looks again.
+func (s *GameStore) evictOne() bool {
+	gameID, ok := s.recent.oldest()
+	if !ok {
+		return false
+	}
+
+	shard := s.getShard(gameID)
+	shard.mu.Lock()
+	victim, stored := shard.games[gameID]
+	if !stored {
+		s.recent.forget(gameID)
+		shard.mu.Unlock()
+		return true
+	}
+	snapshot := victim.GetSnapshot()
+	if !snapshot.Status.IsFinished() {
+		// A game someone has joined is no longer a candidate; its join takes
+		// it out of the list too. One still pending is only cancelled if it
+		// has not changed since the snapshot.
+		if snapshot.Status != game.StatusPending {
+			s.recent.forget(gameID)
+			shard.mu.Unlock()
+			return true
+		}
+		if !victim.Expire(snapshot.UpdatedAt.Add(time.Nanosecond)) {
+			shard.mu.Unlock()
+			return true
+		}
+	}
+	delete(shard.games, gameID)
+	s.held.Add(-1)
+	s.forgetPending(shard, gameID)
+	s.recent.forget(gameID)
+	for _, player := range snapshot.Players() {
+		s.players.remove(player, gameID)
+	}
+	shard.mu.Unlock()
+
+	if s.onEvict != nil {
+		s.onEvict(victim)
+	}
+	return true
+}
+
+// TimeOutInProgress abandons every in-progress game that started, and every
+// ready game created, before cutoff (see game.Game.TimeOut), returning the
+// games it ended. They stay in
+// the store like any other finished game.
+func (s *GameStore) TimeOutInProgress(cutoff time.Time) []*game.Game {
+	var timedOut []*game.Game
 	for _, shard := range s.shards {
 		shard.mu.RLock()
 		for _, g := range shard.games {
-			if g.GetStatus() == game.StatusPending {
-				snapshot := g.GetSnapshot()
-				pending = append(pending, &snapshot)
+			if g.TimeOut(cutoff) {
+				timedOut = append(timedOut, g)
 			}
 		}
 		shard.mu.RUnlock()
 	}
+	return timedOut
+}
+
+// ListFinishedByPlayer returns the finished games userID played in, most
+// recently finished first (ties broken by ID), with pagination. Games removed
+// from the store no longer appear. The total count covers all such games.
+func (s *GameStore) ListFinishedByPlayer(userID string, limit, offset int) ([]*game.GameSnapshot, int) {
+	finished := []*game.GameSnapshot{}
+	for _, gameID := range s.players.gameIDs(userID) {
+		g, err := s.Get(gameID)
+		if err != nil {
+			continue
+		}
+		snapshot := g.GetSnapshot()
+		if snapshot.Status.IsFinished() {
+			finished = append(finished, &snapshot)
+		}
+	}
+
+	// A game's last update is the one that finished it
+	sort.Slice(finished, func(i, j int) bool {
+		if !finished[i].UpdatedAt.Equal(finished[j].UpdatedAt) {
+			return finished[i].UpdatedAt.After(finished[j].UpdatedAt)
+		}
+		return finished[i].ID < finished[j].ID
+	})
 
+	totalCount := len(finished)
+	if offset >= len(finished) {
+		return []*game.GameSnapshot{}, totalCount
+	}
+	finished = finished[offset:]
+	if limit > 0 && len(finished) > limit {
+		finished = finished[:limit]
+	}
+	return finished, totalCount
+}
+
+// PendingFilter narrows the games returned by ListPending.
+// Zero-valued fields match any game.
+type PendingFilter struct {
+	BoardSize int
+	WinLength int
+}
+
+// matches reports whether a snapshot satisfies the filter
+func (f PendingFilter) matches(snapshot *game.GameSnapshot) bool {
+	if f.BoardSize != 0 && snapshot.Board.Size != f.BoardSize {
+		return false
+	}
+	if f.WinLength != 0 && snapshot.Board.WinLength != f.WinLength {
+		return false
+	}
+	return true
+}
+
+// PendingCursor marks a position in the order ListPending returns games in:
+// oldest first, ties broken by ID. The zero cursor is before every game.
+type PendingCursor struct {
+	CreatedAt time.Time
+	GameID    string
+}
+
+// CursorFor returns the cursor positioned at a game
+func CursorFor(snapshot *game.GameSnapshot) PendingCursor {
+	return PendingCursor{CreatedAt: snapshot.CreatedAt, GameID: snapshot.ID}
+}
+
+// before reports whether the cursor sorts before a game
+func (c PendingCursor) before(snapshot *game.GameSnapshot) bool {
+	if !c.CreatedAt.Equal(snapshot.CreatedAt) {
+		return c.CreatedAt.Before(snapshot.CreatedAt)
+	}
+	return c.GameID < snapshot.ID
+}
+
+// ListPending returns pending games matching the filter with pagination.
+// Games are ordered oldest first (ties broken by ID) so pages are stable.
+// The total count reflects only the matching games.
+func (s *GameStore) ListPending(filter PendingFilter, limit, offset int) ([]*game.GameSnapshot, int) {
+	pending := s.sortedPending(filter)
 	totalCount := len(pending)
 
 	// Apply pagination
@@ -127,6 +624,86 @@ func (s *GameStore) ListPending(limit, offset int) ([]*game.GameSnapshot, int) {
 	return pending, totalCount
 }
 
+// ListActive returns public in-progress games with pagination, most recently
+// updated first (ties broken by ID). The total count covers every such game.
+func (s *GameStore) ListActive(limit, offset int) ([]*game.GameSnapshot, int) {
+	active := []*game.GameSnapshot{}
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for _, g := range shard.games {
+			if g.GetStatus() == game.StatusInProgress {
+				snapshot := g.GetSnapshot()
+				if !snapshot.Private {
+					active = append(active, &snapshot)
+				}
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		if !active[i].UpdatedAt.Equal(active[j].UpdatedAt) {
+			return active[i].UpdatedAt.After(active[j].UpdatedAt)
+		}
+		return active[i].ID < active[j].ID
+	})
+	totalCount := len(active)
+
+	if offset >= len(active) {
+		return []*game.GameSnapshot{}, totalCount
+	}
+	active = active[offset:]
+	if limit > 0 && len(active) > limit {
+		active = active[:limit]
+	}
+	return active, totalCount
+}
+
+// ListPendingAfter returns up to limit pending games matching the filter that
+// sort after the cursor. Unlike an offset, a cursor keeps its place when
+// earlier games are joined or created between calls.
+// The total count reflects all matching games, not just those after the cursor.
+func (s *GameStore) ListPendingAfter(filter PendingFilter, after PendingCursor, limit int) ([]*game.GameSnapshot, int) {
+	pending := s.sortedPending(filter)
+	totalCount := len(pending)
+
+	start := sort.Search(len(pending), func(i int) bool {
+		return after.before(pending[i])
+	})
+	pending = pending[start:]
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+
+	return pending, totalCount
+}
+
+// sortedPending collects the public pending games matching the filter in
+// list order. Private games are only found by ID.
+func (s *GameStore) sortedPending(filter PendingFilter) []*game.GameSnapshot {
+	pending := []*game.GameSnapshot{}
+
+	// Collect pending games from all shards
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for _, g := range shard.games {
+			if g.GetStatus() == game.StatusPending {
+				snapshot := g.GetSnapshot()
+				if !snapshot.Private && filter.matches(&snapshot) {
+					pending = append(pending, &snapshot)
+				}
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	// Shard iteration order is random; sort so pages mean the same thing across calls
+	sort.Slice(pending, func(i, j int) bool {
+		return CursorFor(pending[i]).before(pending[j])
+	})
+	return pending
+}
+
 // Count returns the total number of games
 func (s *GameStore) Count() int {
 	count := 0
@@ -137,3 +714,69 @@ func (s *GameStore) Count() int {
 	}
 	return count
 }
+
+// ShardSizes returns the number of games in each shard, in shard order, to
+// show how evenly game IDs spread over the shards
+func (s *GameStore) ShardSizes() []int {
+	sizes := make([]int, len(s.shards))
+	for i, shard := range s.shards {
+		shard.mu.RLock()
+		sizes[i] = len(shard.games)
+		shard.mu.RUnlock()
+	}
+	return sizes
+}
+
+// StatusCounts breaks down the stored games by status
+type StatusCounts struct {
+	Pending    int
+	InProgress int
+	Finished   int
+}
+
+// Total returns the number of games counted
+func (c StatusCounts) Total() int {
+	return c.Pending + c.InProgress + c.Finished
+}
+
+// CountByStatus counts the games in each status in a single pass
+func (s *GameStore) CountByStatus() StatusCounts {
+	var counts StatusCounts
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for _, g := range shard.games {
+			switch status := g.GetStatus(); {
+			case status == game.StatusPending:
+				counts.Pending++
+			case status == game.StatusInProgress || status == game.StatusReady:
+				// Ready games are full and about to start
+				counts.InProgress++
+			case status.IsFinished():
+				counts.Finished++
+			}
+		}
+		shard.mu.RUnlock()
+	}
+	return counts
+}
+
+// ActivePlayers returns the users in games that are pending or in progress
+func (s *GameStore) ActivePlayers() map[string]struct{} {
+	active := make(map[string]struct{})
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for _, g := range shard.games {
+			snapshot := g.GetSnapshot()
+			if snapshot.Status.IsFinished() {
+				continue
+			}
+			for _, player := range snapshot.Players() {
+				if player != "" {
+					active[player] = struct{}{}
+				}
+			}
+		}
+		shard.mu.RUnlock()
+	}
+	return active
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,6 +40,25 @@ func TestGameStore_GetNotFound(t *testing.T) {
 	assert.ErrorIs(t, err, ErrGameNotFound)
 }
 
+func TestGameStore_GetMany(t *testing.T) {
+	store := NewGameStore(4)
+	for _, id := range []string{"game-1", "game-2", "game-3"} {
+		g, err := game.NewGame(id, "player-1", 3, 3)
+		require.NoError(t, err)
+		require.NoError(t, store.Create(g))
+	}
+
+	found, missing := store.GetMany([]string{"game-3", "nope", "game-1", "game-3", "gone"})
+	require.Len(t, found, 2)
+	assert.Equal(t, "game-3", found[0].ID)
+	assert.Equal(t, "game-1", found[1].ID)
+	assert.Equal(t, []string{"nope", "gone"}, missing)
+
+	found, missing = store.GetMany(nil)
+	assert.Empty(t, found)
+	assert.Empty(t, missing)
+}
+
 func TestGameStore_Delete(t *testing.T) {
 	store := NewGameStore(4)
 
@@ -54,6 +76,221 @@ func TestGameStore_Delete(t *testing.T) {
 	assert.ErrorIs(t, err, ErrGameNotFound)
 }
 
+func TestGameStore_ExpirePending(t *testing.T) {
+	store := NewGameStore(4, WithMaxActiveGames(1))
+
+	idle, _ := game.NewGame("idle", "player-1", 3, 3)
+	require.NoError(t, store.Create(idle))
+	started, _ := game.NewGame("started", "player-2", 3, 3)
+	require.NoError(t, store.Create(started))
+	_, err := store.Join("started", "player-3")
+	require.NoError(t, err)
+
+	// Nothing is idle before the cutoff
+	assert.Empty(t, store.ExpirePending(idle.GetSnapshot().UpdatedAt))
+
+	expired := store.ExpirePending(time.Now().Add(time.Second))
+	require.Len(t, expired, 1)
+	assert.Equal(t, "idle", expired[0].ID)
+	assert.Equal(t, game.StatusCancelled, expired[0].GetSnapshot().Status)
+	_, err = store.Get("idle")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+	_, err = store.Get("started")
+	require.NoError(t, err)
+
+	// The creator's seat is freed
+	assert.Equal(t, 0, store.ActiveGameCount("player-1"))
+
+	// A full game its players never start expires as well
+	ready, _ := game.NewGame("ready", "player-5", 3, 3, game.WithReadyCheck())
+	require.NoError(t, store.Create(ready))
+	_, err = store.Join("ready", "player-6")
+	require.NoError(t, err)
+	expired = store.ExpirePending(time.Now().Add(time.Second))
+	require.Len(t, expired, 1)
+	assert.Equal(t, "ready", expired[0].ID)
+	assert.Equal(t, 0, store.ActiveGameCount("player-6"))
+}
+
+func TestGameStore_MaxGames(t *testing.T) {
+	store := NewGameStore(4, WithMaxGames(3), WithMaxActiveGames(1))
+	var evicted []*game.Game
+	store.OnEvict(func(g *game.Game) { evicted = append(evicted, g) })
+
+	idle, _ := game.NewGame("idle", "player-1", 3, 3)
+	require.NoError(t, store.Create(idle))
+	finished, _ := game.NewGame("finished", "player-2", 3, 3)
+	require.NoError(t, store.Create(finished))
+	_, err := store.Join("finished", "player-3")
+	require.NoError(t, err)
+	require.NoError(t, finished.Abandon("player-2"))
+	store.MarkFinished("finished")
+	playing, _ := game.NewGame("playing", "player-4", 3, 3)
+	require.NoError(t, store.Create(playing))
+	_, err = store.Join("playing", "player-5")
+	require.NoError(t, err)
+
+	// A finished game goes first, even one updated after a pending game
+	next, _ := game.NewGame("next", "player-6", 3, 3)
+	require.NoError(t, store.Create(next))
+	require.Len(t, evicted, 1)
+	assert.Equal(t, "finished", evicted[0].ID)
+	_, err = store.Get("finished")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	// Then the least recently updated pending game, cancelled
+	last, _ := game.NewGame("last", "player-7", 3, 3)
+	require.NoError(t, store.Create(last))
+	require.Len(t, evicted, 2)
+	assert.Equal(t, "idle", evicted[1].ID)
+	assert.Equal(t, game.StatusCancelled, evicted[1].GetStatus())
+	assert.Equal(t, 0, store.ActiveGameCount("player-1"))
+	assert.Equal(t, 3, store.Count())
+
+	// Games in progress are never evicted
+	for _, gameID := range []string{"next", "last"} {
+		_, err = store.Join(gameID, "joiner-"+gameID)
+		require.NoError(t, err)
+	}
+	full, _ := game.NewGame("full", "player-8", 3, 3)
+	assert.ErrorIs(t, store.Create(full), ErrStoreFull)
+	assert.Len(t, evicted, 2)
+	assert.Equal(t, 3, store.Count())
+	assert.Equal(t, 0, store.ActiveGameCount("player-8"))
+
+	// Room freed by a delete is used again
+	require.NoError(t, store.Delete("playing"))
+	require.NoError(t, store.Create(full))
+}
+
+func TestGameStore_MaxGames_EvictsLeastRecentlyChanged(t *testing.T) {
+	store := NewGameStore(4, WithMaxGames(2))
+	var evicted []string
+	store.OnEvict(func(g *game.Game) { evicted = append(evicted, g.ID) })
+
+	older, _ := game.NewGame("older", "player-1", 3, 3, game.WithPlayers(3))
+	require.NoError(t, store.Create(older))
+	newer, _ := game.NewGame("newer", "player-2", 3, 3)
+	require.NoError(t, store.Create(newer))
+
+	// A join that leaves a game pending makes it the most recent
+	_, err := store.Join("older", "player-3")
+	require.NoError(t, err)
+	next, _ := game.NewGame("next", "player-4", 3, 3)
+	require.NoError(t, store.Create(next))
+	assert.Equal(t, []string{"newer"}, evicted)
+
+	// So does finishing one, but finished games still go first
+	_, err = store.Join("next", "player-5")
+	require.NoError(t, err)
+	require.NoError(t, next.Abandon("player-4"))
+	store.MarkFinished("next")
+	last, _ := game.NewGame("last", "player-6", 3, 3)
+	require.NoError(t, store.Create(last))
+	assert.Equal(t, []string{"newer", "next"}, evicted)
+}
+
+func TestGameStore_MaxGames_Concurrent(t *testing.T) {
+	store := NewGameStore(4, WithMaxGames(10))
+	var evictions atomic.Int32
+	store.OnEvict(func(*game.Game) { evictions.Add(1) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			g, _ := game.NewGame(fmt.Sprintf("game-%d", id), fmt.Sprintf("player-%d", id), 3, 3)
+			assert.NoError(t, store.Create(g))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, store.Count())
+	assert.Equal(t, int32(90), evictions.Load())
+}
+
+func TestGameStore_MaxPendingGames(t *testing.T) {
+	store := NewGameStore(4, WithMaxPendingGames(2))
+
+	first, _ := game.NewGame("first", "player-1", 3, 3)
+	require.NoError(t, store.Create(first))
+	second, _ := game.NewGame("second", "player-2", 3, 3, game.WithPlayers(3))
+	require.NoError(t, store.Create(second))
+	assert.Equal(t, 2, store.PendingCount())
+
+	blocked, _ := game.NewGame("blocked", "player-3", 3, 3)
+	assert.ErrorIs(t, store.Create(blocked), ErrTooManyPending)
+	assert.Equal(t, 0, store.ActiveGameCount("player-3"))
+
+	// A three-player game stays pending until its last seat is filled
+	_, err := store.Join("second", "player-4")
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.PendingCount())
+	assert.ErrorIs(t, store.Create(blocked), ErrTooManyPending)
+
+	// Joining a game makes room
+	_, err = store.Join("first", "player-5")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.PendingCount())
+	require.NoError(t, store.Create(blocked))
+	assert.Equal(t, 2, store.PendingCount())
+
+	// So does removing one, and a failed join changes nothing
+	_, err = store.Join("first", "player-6")
+	assert.ErrorIs(t, err, ErrJoinRaceLost)
+	require.NoError(t, store.Delete("blocked"))
+	require.NoError(t, store.Delete("first"))
+	assert.Equal(t, 1, store.PendingCount())
+	assert.Len(t, store.ExpirePending(time.Now().Add(time.Second)), 1)
+	assert.Equal(t, 0, store.PendingCount())
+}
+
+func TestGameStore_MaxPendingGames_ConcurrentJoins(t *testing.T) {
+	store := NewGameStore(4, WithMaxPendingGames(50))
+	for i := 0; i < 50; i++ {
+		g, _ := game.NewGame(fmt.Sprintf("game-%d", i), "creator", 3, 3)
+		require.NoError(t, store.Create(g))
+	}
+
+	// Many joiners race for each game; only the winner's join leaves the pool
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func(id, joiner int) {
+				defer wg.Done()
+				store.Join(fmt.Sprintf("game-%d", id), fmt.Sprintf("joiner-%d-%d", id, joiner))
+			}(i, j)
+		}
+	}
+	wg.Wait()
+	assert.Equal(t, 0, store.PendingCount())
+}
+
+func TestGameStore_TimeOutInProgress(t *testing.T) {
+	store := NewGameStore(4)
+
+	pending, _ := game.NewGame("pending", "player-1", 3, 3)
+	require.NoError(t, store.Create(pending))
+	started, _ := game.NewGame("started", "player-2", 3, 3)
+	require.NoError(t, store.Create(started))
+	_, err := store.Join("started", "player-3")
+	require.NoError(t, err)
+
+	assert.Empty(t, store.TimeOutInProgress(started.GetSnapshot().StartedAt))
+
+	timedOut := store.TimeOutInProgress(time.Now().Add(time.Second))
+	require.Len(t, timedOut, 1)
+	assert.Equal(t, "started", timedOut[0].ID)
+	assert.Equal(t, game.StatusAbandoned, timedOut[0].GetStatus())
+	assert.Equal(t, game.StatusPending, pending.GetStatus())
+
+	// The abandoned game stays for history
+	_, err = store.Get("started")
+	require.NoError(t, err)
+}
+
 func TestGameStore_ListPending(t *testing.T) {
 	store := NewGameStore(4)
 
@@ -68,20 +305,454 @@ func TestGameStore_ListPending(t *testing.T) {
 	g.Join("player-2")
 
 	// List pending
-	pending, total := store.ListPending(10, 0)
+	pending, total := store.ListPending(PendingFilter{}, 10, 0)
 	assert.Equal(t, 4, total) // One game is in progress
 	assert.Len(t, pending, 4)
 
 	// Test pagination
-	pending, total = store.ListPending(2, 0)
+	pending, total = store.ListPending(PendingFilter{}, 2, 0)
 	assert.Equal(t, 4, total)
 	assert.Len(t, pending, 2)
 
-	pending, total = store.ListPending(2, 3)
+	pending, total = store.ListPending(PendingFilter{}, 2, 3)
 	assert.Equal(t, 4, total)
 	assert.Len(t, pending, 1)
 }
 
+func TestGameStore_ListActive(t *testing.T) {
+	store := NewGameStore(4)
+
+	// Start games a-d, each updated later than the last; e stays pending
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		g, _ := game.NewGame(string(rune('a'+i)), "player", 3, 3)
+		store.Create(g)
+		if i < 4 {
+			require.NoError(t, g.Join("player-2"))
+			g.UpdatedAt = base.Add(time.Duration(i) * time.Second)
+		}
+	}
+	// A private game in progress is not listed
+	private, _ := game.NewGame("p", "player", 3, 3, game.WithJoinCode("code"))
+	store.Create(private)
+	require.NoError(t, private.Join("player-2", game.UsingJoinCode("code")))
+
+	ids := func(games []*game.GameSnapshot) []string {
+		var ids []string
+		for _, g := range games {
+			ids = append(ids, g.ID)
+		}
+		return ids
+	}
+
+	active, total := store.ListActive(10, 0)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"d", "c", "b", "a"}, ids(active))
+
+	active, total = store.ListActive(3, 0)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"d", "c", "b"}, ids(active))
+
+	active, _ = store.ListActive(3, 3)
+	assert.Equal(t, []string{"a"}, ids(active))
+
+	active, total = store.ListActive(3, 10)
+	assert.Equal(t, 4, total)
+	assert.Empty(t, active)
+}
+
+func TestGameStore_ListPending_Filter(t *testing.T) {
+	store := NewGameStore(4)
+
+	configs := []struct {
+		id        string
+		size      int
+		winLength int
+	}{
+		{"a", 3, 3},
+		{"b", 3, 3},
+		{"c", 5, 4},
+		{"d", 5, 4},
+		{"e", 5, 5},
+		{"f", 7, 5},
+	}
+	for _, c := range configs {
+		g, err := game.NewGame(c.id, "player", c.size, c.winLength)
+		require.NoError(t, err)
+		require.NoError(t, store.Create(g))
+	}
+
+	// Started games are excluded even when they match
+	g, _ := store.Get("d")
+	g.Join("player-2")
+
+	// No filter returns everything pending
+	_, total := store.ListPending(PendingFilter{}, 10, 0)
+	assert.Equal(t, 5, total)
+
+	// Board size only
+	pending, total := store.ListPending(PendingFilter{BoardSize: 5}, 10, 0)
+	assert.Equal(t, 2, total)
+	for _, p := range pending {
+		assert.Equal(t, 5, p.Board.Size)
+	}
+
+	// Win length only
+	pending, total = store.ListPending(PendingFilter{WinLength: 5}, 10, 0)
+	assert.Equal(t, 2, total)
+	for _, p := range pending {
+		assert.Equal(t, 5, p.Board.WinLength)
+	}
+
+	// Both
+	pending, total = store.ListPending(PendingFilter{BoardSize: 5, WinLength: 4}, 10, 0)
+	assert.Equal(t, 1, total)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "c", pending[0].ID)
+
+	// Pagination counts only matching games
+	pending, total = store.ListPending(PendingFilter{BoardSize: 3}, 1, 1)
+	assert.Equal(t, 2, total)
+	assert.Len(t, pending, 1)
+
+	pending, total = store.ListPending(PendingFilter{BoardSize: 3}, 1, 2)
+	assert.Equal(t, 2, total)
+	assert.Empty(t, pending)
+
+	// No matches
+	pending, total = store.ListPending(PendingFilter{BoardSize: 9}, 10, 0)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, pending)
+}
+
+func TestGameStore_ListPending_StableOrder(t *testing.T) {
+	store := NewGameStore(8)
+
+	// Creation times deliberately disagree with ID order; the last two tie
+	base := time.Unix(1_700_000_000, 0)
+	offsets := []int{5, 3, 0, 4, 1, 2, 6, 6}
+	for i, off := range offsets {
+		g, err := game.NewGame(fmt.Sprintf("game-%d", i), "player", 3, 3)
+		require.NoError(t, err)
+		g.CreatedAt = base.Add(time.Duration(off) * time.Second)
+		require.NoError(t, store.Create(g))
+	}
+
+	want := []string{"game-2", "game-4", "game-5", "game-1", "game-3", "game-0", "game-6", "game-7"}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		var got []string
+		for offset := 0; offset < len(want); offset += 3 {
+			page, total := store.ListPending(PendingFilter{}, 3, offset)
+			assert.Equal(t, len(want), total)
+			for _, p := range page {
+				got = append(got, p.ID)
+			}
+		}
+		require.Equal(t, want, got, "attempt %d", attempt)
+	}
+}
+
+func TestGameStore_ListPendingAfter(t *testing.T) {
+	store := NewGameStore(8)
+
+	base := time.Unix(1_700_000_000, 0)
+	offsets := []int{5, 3, 0, 4, 1, 2, 6, 6}
+	for i, off := range offsets {
+		g, err := game.NewGame(fmt.Sprintf("game-%d", i), "player", 3, 3)
+		require.NoError(t, err)
+		g.CreatedAt = base.Add(time.Duration(off) * time.Second)
+		require.NoError(t, store.Create(g))
+	}
+
+	// The zero cursor starts at the beginning
+	page, total := store.ListPendingAfter(PendingFilter{}, PendingCursor{}, 3)
+	assert.Equal(t, 8, total)
+	require.Len(t, page, 3)
+	assert.Equal(t, []string{"game-2", "game-4", "game-5"}, []string{page[0].ID, page[1].ID, page[2].ID})
+	cursor := CursorFor(page[2])
+
+	// Joining a game already paged past does not shift the next page
+	g, err := store.Get("game-4")
+	require.NoError(t, err)
+	require.NoError(t, g.Join("joiner"))
+
+	page, total = store.ListPendingAfter(PendingFilter{}, cursor, 3)
+	assert.Equal(t, 7, total)
+	require.Len(t, page, 3)
+	assert.Equal(t, []string{"game-1", "game-3", "game-0"}, []string{page[0].ID, page[1].ID, page[2].ID})
+
+	// Ties on creation time are ordered by ID
+	page, _ = store.ListPendingAfter(PendingFilter{}, CursorFor(page[2]), 3)
+	require.Len(t, page, 2)
+	assert.Equal(t, "game-6", page[0].ID)
+	page, _ = store.ListPendingAfter(PendingFilter{}, CursorFor(page[0]), 3)
+	require.Len(t, page, 1)
+	assert.Equal(t, "game-7", page[0].ID)
+
+	// Past the end
+	page, _ = store.ListPendingAfter(PendingFilter{}, CursorFor(page[0]), 3)
+	assert.Empty(t, page)
+}
+
+func TestGameStore_ListFinishedByPlayer(t *testing.T) {
+	store := NewGameStore(4)
+
+	// alice wins one, draws one, and has one still in progress
+	base := time.Unix(1_700_000_000, 0)
+	finish := func(id, opponent string, draw bool, at time.Time) {
+		g, err := game.NewGame(id, "alice", 3, 3)
+		require.NoError(t, err)
+		require.NoError(t, store.Create(g))
+		_, err = store.Join(id, opponent)
+		require.NoError(t, err)
+		if draw {
+			require.NoError(t, g.OfferDraw("alice"))
+			require.NoError(t, g.RespondDraw(opponent, true))
+		} else {
+			for _, m := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}} {
+				player := "alice"
+				if g.GetSnapshot().Turn == game.MarkO {
+					player = opponent
+				}
+				_, err := g.MakeMove(player, m[0], m[1])
+				require.NoError(t, err)
+			}
+		}
+		g.UpdatedAt = at
+	}
+	finish("game-1", "bob", false, base)
+	finish("game-2", "carol", true, base.Add(time.Minute))
+	playing, _ := game.NewGame("game-3", "dave", 3, 3)
+	require.NoError(t, store.Create(playing))
+	_, err := store.Join("game-3", "alice")
+	require.NoError(t, err)
+
+	games, total := store.ListFinishedByPlayer("alice", 10, 0)
+	assert.Equal(t, 2, total)
+	require.Len(t, games, 2)
+	assert.Equal(t, "game-2", games[0].ID, "most recently finished first")
+	assert.Equal(t, "game-1", games[1].ID)
+
+	games, total = store.ListFinishedByPlayer("bob", 10, 0)
+	assert.Equal(t, 1, total)
+	require.Len(t, games, 1)
+	assert.Equal(t, "bob", games[0].GetLoser())
+
+	games, _ = store.ListFinishedByPlayer("alice", 1, 1)
+	require.Len(t, games, 1)
+	assert.Equal(t, "game-1", games[0].ID)
+
+	// Deleted games drop out of the index
+	require.NoError(t, store.Delete("game-1"))
+	_, total = store.ListFinishedByPlayer("alice", 10, 0)
+	assert.Equal(t, 1, total)
+	games, total = store.ListFinishedByPlayer("bob", 10, 0)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, games)
+}
+
+func TestGameStore_Join(t *testing.T) {
+	store := NewGameStore(4)
+
+	_, err := store.Join("missing", "bob")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	g, _ := game.NewGame("game-1", "alice", 3, 3)
+	require.NoError(t, store.Create(g))
+	joined, err := store.Join("game-1", "bob")
+	require.NoError(t, err)
+	assert.Same(t, g, joined)
+	assert.Equal(t, game.StatusInProgress, g.GetStatus())
+
+	// A full game was lost to another joiner; one cancelled before filling up was not
+	_, err = store.Join("game-1", "carol")
+	assert.ErrorIs(t, err, ErrJoinRaceLost)
+	cancelled, _ := game.NewGame("game-2", "alice", 3, 3)
+	require.NoError(t, store.Create(cancelled))
+	require.NoError(t, cancelled.Leave("alice"))
+	_, err = store.Join("game-2", "carol")
+	assert.ErrorIs(t, err, game.ErrGameAlreadyStarted)
+}
+
+func TestGameStore_Join_Race(t *testing.T) {
+	store := NewGameStore(4)
+	g, _ := game.NewGame("game-1", "alice", 3, 3)
+	require.NoError(t, store.Create(g))
+
+	// Ten joiners race for the one open seat
+	errs := make(chan error, 10)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, err := store.Join("game-1", fmt.Sprintf("joiner-%d", i))
+			errs <- err
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	winners := 0
+	for err := range errs {
+		if err == nil {
+			winners++
+			continue
+		}
+		// A loser is told the seat went to someone else, however far its join got
+		assert.ErrorIs(t, err, ErrJoinRaceLost)
+	}
+	assert.Equal(t, 1, winners)
+	assert.Equal(t, game.StatusInProgress, g.GetStatus())
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("joiner-%d", i)
+		if g.GetPlayerMark(id) == game.MarkEmpty {
+			assert.Zero(t, store.ActiveGameCount(id), "a losing joiner keeps no seat")
+		}
+	}
+}
+
+func TestGameStore_MaxActiveGames(t *testing.T) {
+	store := NewGameStore(4, WithMaxActiveGames(2))
+
+	for i := 0; i < 2; i++ {
+		g, _ := game.NewGame(fmt.Sprintf("mine-%d", i), "alice", 3, 3)
+		require.NoError(t, store.Create(g))
+	}
+	assert.Equal(t, 2, store.ActiveGameCount("alice"))
+
+	g, _ := game.NewGame("mine-2", "alice", 3, 3)
+	assert.ErrorIs(t, store.Create(g), ErrTooManyGames)
+	_, err := store.Get("mine-2")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+
+	theirs, _ := game.NewGame("theirs", "bob", 3, 3)
+	require.NoError(t, store.Create(theirs))
+	_, err = store.Join("theirs", "alice")
+	assert.ErrorIs(t, err, ErrTooManyGames)
+	assert.Equal(t, game.StatusPending, theirs.GetStatus())
+
+	// A failed join does not take a seat
+	_, err = store.Join("mine-0", "alice")
+	assert.ErrorIs(t, err, game.ErrCannotJoinOwnGame)
+	assert.Equal(t, 2, store.ActiveGameCount("alice"))
+
+	// Finishing or deleting a game frees its seat
+	mine, _ := store.Join("mine-0", "carol")
+	require.NoError(t, mine.OfferDraw("alice"))
+	require.NoError(t, mine.RespondDraw("carol", true))
+	store.MarkFinished("mine-0")
+	assert.Equal(t, 1, store.ActiveGameCount("alice"))
+	_, err = store.Join("theirs", "alice")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete("mine-1"))
+	assert.Equal(t, 1, store.ActiveGameCount("alice"))
+	assert.Equal(t, 0, store.ActiveGameCount("carol"))
+}
+
+func TestGameStore_MaxActiveGames_ConcurrentJoins(t *testing.T) {
+	store := NewGameStore(8, WithMaxActiveGames(3))
+
+	const numGames = 50
+	for i := 0; i < numGames; i++ {
+		g, _ := game.NewGame(fmt.Sprintf("game-%d", i), fmt.Sprintf("host-%d", i), 3, 3)
+		require.NoError(t, store.Create(g))
+	}
+
+	var joined int64
+	var wg sync.WaitGroup
+	for i := 0; i < numGames; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := store.Join(fmt.Sprintf("game-%d", i), "greedy"); err == nil {
+				atomic.AddInt64(&joined, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(3), joined)
+	assert.Equal(t, 3, store.ActiveGameCount("greedy"))
+}
+
+func TestGameStore_Leave(t *testing.T) {
+	store := NewGameStore(4)
+
+	g, err := game.NewGame("game", "alice", 3, 3, game.WithReadyCheck())
+	require.NoError(t, err)
+	require.NoError(t, store.Create(g))
+	_, err = store.Join("game", "bob")
+	require.NoError(t, err)
+	assert.Zero(t, store.PendingCount())
+
+	// The joiner gives up the seat: the game is pending again without them
+	_, err = store.Leave("game", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.PendingCount())
+	assert.Zero(t, store.ActiveGameCount("bob"))
+	assert.Equal(t, 1, store.ActiveGameCount("alice"))
+
+	// The creator cancels it, which removes it
+	_, err = store.Leave("game", "alice")
+	require.NoError(t, err)
+	_, err = store.Get("game")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+	assert.Zero(t, store.PendingCount())
+	assert.Zero(t, store.ActiveGameCount("alice"))
+
+	_, err = store.Leave("game", "alice")
+	assert.ErrorIs(t, err, ErrGameNotFound)
+}
+
+func TestGameStore_Import(t *testing.T) {
+	store := NewGameStore(4, WithMaxActiveGames(1))
+
+	busy, _ := game.NewGame("busy", "alice", 3, 3)
+	require.NoError(t, store.Create(busy))
+
+	// Imports bypass the cap and index both players
+	g, _ := game.NewGame("imported", "alice", 3, 3)
+	require.NoError(t, g.Join("bob"))
+	require.NoError(t, store.Import(g))
+	assert.Equal(t, 2, store.ActiveGameCount("alice"))
+	assert.Equal(t, 1, store.ActiveGameCount("bob"))
+	assert.ErrorIs(t, store.Import(g), ErrGameAlreadyExists)
+
+	// A finished import shows up in history without taking a seat
+	done, _ := game.NewGame("done", "carol", 3, 3)
+	require.NoError(t, done.Join("dave"))
+	require.NoError(t, done.OfferDraw("carol"))
+	require.NoError(t, done.RespondDraw("dave", true))
+	require.NoError(t, store.Import(done))
+	assert.Equal(t, 0, store.ActiveGameCount("carol"))
+	games, total := store.ListFinishedByPlayer("dave", 10, 0)
+	assert.Equal(t, 1, total)
+	require.Len(t, games, 1)
+	assert.Equal(t, "done", games[0].ID)
+}
+
+func TestGameStore_Import_PendingCap(t *testing.T) {
+	store := NewGameStore(4, WithMaxPendingGames(1))
+
+	first, _ := game.NewGame("first", "alice", 3, 3)
+	require.NoError(t, store.Import(first))
+	assert.Equal(t, 1, store.PendingCount())
+
+	// A pending import counts against the pool; a started one does not
+	second, _ := game.NewGame("second", "bob", 3, 3)
+	assert.ErrorIs(t, store.Import(second), ErrTooManyPending)
+	assert.Equal(t, 1, store.PendingCount())
+	require.NoError(t, second.Join("carol"))
+	require.NoError(t, store.Import(second))
+	assert.Equal(t, 1, store.PendingCount())
+}
+
 func TestGameStore_Count(t *testing.T) {
 	store := NewGameStore(4)
 
@@ -95,6 +766,43 @@ func TestGameStore_Count(t *testing.T) {
 	assert.Equal(t, 2, store.Count())
 }
 
+func TestGameStore_ShardSizes(t *testing.T) {
+	store := NewGameStore(8)
+	assert.Equal(t, make([]int, 8), store.ShardSizes())
+
+	for i := 0; i < 100; i++ {
+		g, _ := game.NewGame(fmt.Sprintf("game-%d", i), "player-1", 3, 3)
+		store.Create(g)
+	}
+
+	sizes := store.ShardSizes()
+	require.Len(t, sizes, 8)
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	assert.Equal(t, store.Count(), total)
+}
+
+func TestGameStore_CountByStatus(t *testing.T) {
+	store := NewGameStore(4)
+
+	pending, _ := game.NewGame("game-1", "player-1", 3, 3)
+	playing, _ := game.NewGame("game-2", "player-2", 3, 3)
+	playing.Join("player-3")
+	finished, _ := game.NewGame("game-3", "player-4", 3, 3)
+	finished.Join("player-5")
+	finished.OfferDraw("player-4")
+	finished.RespondDraw("player-5", true)
+	for _, g := range []*game.Game{pending, playing, finished} {
+		require.NoError(t, store.Create(g))
+	}
+
+	counts := store.CountByStatus()
+	assert.Equal(t, StatusCounts{Pending: 1, InProgress: 1, Finished: 1}, counts)
+	assert.Equal(t, store.Count(), counts.Total())
+}
+
 func TestGameStore_Concurrent(t *testing.T) {
 	store := NewGameStore(4)
 	var wg sync.WaitGroup
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LeaderboardEntry is one ranked row of the leaderboard
+type LeaderboardEntry struct {
+	Rank   int
+	UserID string
+	Record Record
+}
+
+// TieBreak orders leaderboard users who have the same number of wins.
+// Every tie-break falls back to user ID so the order is deterministic and
+// pages do not shift between calls.
+type TieBreak int
+
+const (
+	TieBreakMostGames   TieBreak = iota // More games played first, then user ID
+	TieBreakFewestGames                 // Fewer games played first, then user ID
+	TieBreakUserID                      // User ID only
+)
+
+func (t TieBreak) String() string {
+	switch t {
+	case TieBreakMostGames:
+		return "most-games"
+	case TieBreakFewestGames:
+		return "fewest-games"
+	case TieBreakUserID:
+		return "user-id"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseTieBreak parses a tie-break name as returned by TieBreak.String
+func ParseTieBreak(name string) (TieBreak, error) {
+	for _, t := range []TieBreak{TieBreakMostGames, TieBreakFewestGames, TieBreakUserID} {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown tie-break %q (want most-games, fewest-games or user-id)", name)
+}
+
+// less reports whether a ranks above b among users with equal wins
+func (t TieBreak) less(a, b LeaderboardEntry) bool {
+	games, otherGames := a.Record.TotalGames(), b.Record.TotalGames()
+	switch {
+	case t == TieBreakMostGames && games != otherGames:
+		return games > otherGames
+	case t == TieBreakFewestGames && games != otherGames:
+		return games < otherGames
+	}
+	return a.UserID < b.UserID
+}
+
+// Snapshot returns a point-in-time copy of every user's stats.
+// Each user's counters are read atomically, but users are not read at the same instant.
+func (s *StatsStore) Snapshot() []UserStats {
+	var all []UserStats
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for _, stats := range shard.stats {
+			all = append(all, loadStats(stats))
+		}
+		shard.mu.RUnlock()
+	}
+	return all
+}
+
+// Leaderboard ranks users by wins within a bracket with pagination.
+// Users without games in the bracket are left out; ties are ordered by tieBreak.
+// It returns the page and the total number of ranked users.
+func (s *StatsStore) Leaderboard(bracket Bracket, tieBreak TieBreak, limit, offset int) ([]LeaderboardEntry, int) {
+	var entries []LeaderboardEntry
+	for _, stats := range s.Snapshot() {
+		rec := stats.Bracket(bracket)
+		if rec.TotalGames() == 0 {
+			continue
+		}
+		entries = append(entries, LeaderboardEntry{UserID: stats.UserID, Record: rec})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Record.Wins != entries[j].Record.Wins {
+			return entries[i].Record.Wins > entries[j].Record.Wins
+		}
+		return tieBreak.less(entries[i], entries[j])
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	totalCount := len(entries)
+
+	// Apply pagination
+	if offset >= len(entries) {
+		return []LeaderboardEntry{}, totalCount
+	}
+
+	entries = entries[offset:]
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, totalCount
+}
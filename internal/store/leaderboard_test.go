@@ -0,0 +1,142 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBracketForSize(t *testing.T) {
+	assert.Equal(t, BracketSmall, BracketForSize(3))
+	assert.Equal(t, BracketSmall, BracketForSize(4))
+	assert.Equal(t, BracketMedium, BracketForSize(5))
+	assert.Equal(t, BracketMedium, BracketForSize(9))
+	assert.Equal(t, BracketLarge, BracketForSize(10))
+	assert.Equal(t, BracketLarge, BracketForSize(20))
+}
+
+func TestStatsStore_RecordGameResult_Brackets(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.RecordGameResult("alice", "bob", false, 3)
+	store.RecordGameResult("bob", "alice", false, 15)
+	store.RecordGameResult("alice", "bob", true, 15)
+
+	alice := store.Get("alice")
+	assert.Equal(t, int32(3), alice.TotalGames())
+	assert.Equal(t, Record{Wins: 1}, alice.Bracket(BracketSmall))
+	assert.Equal(t, Record{}, alice.Bracket(BracketMedium))
+	assert.Equal(t, Record{Losses: 1, Draws: 1}, alice.Bracket(BracketLarge))
+	assert.Equal(t, Record{Wins: 1, Losses: 1, Draws: 1}, alice.Bracket(BracketAll))
+}
+
+func TestStatsStore_Leaderboard_Brackets(t *testing.T) {
+	store := NewStatsStore(4)
+
+	// Small-board specialists
+	store.RecordGameResult("tic", "tac", false, 3)
+	store.RecordGameResult("tic", "tac", false, 3)
+	store.RecordGameResult("tac", "tic", false, 4)
+
+	// Large-board specialists
+	store.RecordGameResult("gomoku", "renju", false, 15)
+	store.RecordGameResult("gomoku", "renju", false, 15)
+	store.RecordGameResult("gomoku", "renju", false, 15)
+
+	// Small bracket only ranks the small-board players
+	entries, total := store.Leaderboard(BracketSmall, TieBreakMostGames, 10, 0)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, LeaderboardEntry{Rank: 1, UserID: "tic", Record: Record{Wins: 2, Losses: 1}}, entries[0])
+	assert.Equal(t, LeaderboardEntry{Rank: 2, UserID: "tac", Record: Record{Wins: 1, Losses: 2}}, entries[1])
+
+	// Large bracket is isolated from small-board results
+	entries, total = store.Leaderboard(BracketLarge, TieBreakMostGames, 10, 0)
+	assert.Equal(t, 2, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "gomoku", entries[0].UserID)
+	assert.Equal(t, int32(3), entries[0].Record.Wins)
+	assert.Equal(t, "renju", entries[1].UserID)
+
+	// Nobody has played a medium board
+	entries, total = store.Leaderboard(BracketMedium, TieBreakMostGames, 10, 0)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, entries)
+
+	// The overall board combines every bracket
+	entries, total = store.Leaderboard(BracketAll, TieBreakMostGames, 2, 0)
+	assert.Equal(t, 4, total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "gomoku", entries[0].UserID)
+	assert.Equal(t, "tic", entries[1].UserID)
+
+	// Ranks continue across pages
+	entries, _ = store.Leaderboard(BracketAll, TieBreakMostGames, 2, 2)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 3, entries[0].Rank)
+	assert.Equal(t, "tac", entries[0].UserID)
+	assert.Equal(t, 4, entries[1].Rank)
+	assert.Equal(t, "renju", entries[1].UserID)
+}
+
+func TestStatsStore_Leaderboard_TieBreak(t *testing.T) {
+	store := NewStatsStore(4)
+
+	// Everyone has one win; they differ only in games played
+	store.RecordGameResult("dave", "loser", false, 3)
+	store.RecordGameResult("carol", "loser", false, 3)
+	store.RecordGameResult("carol", "loser", true, 3)
+	store.RecordGameResult("bob", "loser", false, 3)
+	store.RecordGameResult("bob", "loser", true, 3)
+	store.RecordGameResult("alice", "loser", false, 3)
+	store.RecordGameResult("erin", "loser", false, 3)
+	store.RecordGameResult("erin", "loser", true, 3)
+	store.RecordGameResult("erin", "loser", true, 3)
+
+	userIDs := func(tieBreak TieBreak, limit, offset int) []string {
+		entries, total := store.Leaderboard(BracketAll, tieBreak, limit, offset)
+		assert.Equal(t, 6, total)
+		ids := make([]string, len(entries))
+		for i, e := range entries {
+			ids[i] = e.UserID
+		}
+		return ids
+	}
+
+	// "loser" has the fewest wins and always ranks last
+	tests := []struct {
+		tieBreak TieBreak
+		want     []string
+	}{
+		{TieBreakMostGames, []string{"erin", "bob", "carol", "alice", "dave", "loser"}},
+		{TieBreakFewestGames, []string{"alice", "dave", "bob", "carol", "erin", "loser"}},
+		{TieBreakUserID, []string{"alice", "bob", "carol", "dave", "erin", "loser"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tieBreak.String(), func(t *testing.T) {
+			// Repeated calls return the same order
+			for i := 0; i < 5; i++ {
+				assert.Equal(t, tt.want, userIDs(tt.tieBreak, 10, 0))
+			}
+
+			// Pages stitch together into the full order without gaps or repeats
+			var paged []string
+			for offset := 0; offset < 6; offset += 2 {
+				paged = append(paged, userIDs(tt.tieBreak, 2, offset)...)
+			}
+			assert.Equal(t, tt.want, paged)
+		})
+	}
+}
+
+func TestParseTieBreak(t *testing.T) {
+	for _, tieBreak := range []TieBreak{TieBreakMostGames, TieBreakFewestGames, TieBreakUserID} {
+		parsed, err := ParseTieBreak(tieBreak.String())
+		require.NoError(t, err)
+		assert.Equal(t, tieBreak, parsed)
+	}
+
+	_, err := ParseTieBreak("random")
+	assert.Error(t, err)
+}
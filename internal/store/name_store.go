@@ -0,0 +1,81 @@
+package store
+
+import "sync"
+
+// NameStore maps user IDs to the display names UIs show for them. It is
+// sharded like StatsStore, since every game response looks names up.
+type NameStore struct {
+	shards    []*nameShard
+	numShards int
+}
+
+type nameShard struct {
+	mu    sync.RWMutex
+	names map[string]string
+}
+
+// NewNameStore creates an empty name store with the specified number of shards
+func NewNameStore(numShards int) *NameStore {
+	if numShards < 1 {
+		numShards = 64
+	}
+
+	shards := make([]*nameShard, numShards)
+	for i := range shards {
+		shards[i] = &nameShard{
+			names: make(map[string]string),
+		}
+	}
+	return &NameStore{
+		shards:    shards,
+		numShards: numShards,
+	}
+}
+
+// getShard returns the shard for a given user ID
+func (s *NameStore) getShard(userID string) *nameShard {
+	return s.shards[shardIndex(userID, s.numShards)]
+}
+
+// Set records a user's display name; an empty name removes it
+func (s *NameStore) Set(userID, name string) {
+	shard := s.getShard(userID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if name == "" {
+		delete(shard.names, userID)
+		return
+	}
+	shard.names[userID] = name
+}
+
+// Get returns a user's display name, if they have set one
+func (s *NameStore) Get(userID string) (string, bool) {
+	shard := s.getShard(userID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	name, ok := shard.names[userID]
+	return name, ok
+}
+
+// DisplayName returns a user's display name, or their ID if they have not
+// set one. An empty ID, as for an open seat, stays empty.
+func (s *NameStore) DisplayName(userID string) string {
+	if name, ok := s.Get(userID); ok {
+		return name
+	}
+	return userID
+}
+
+// Count returns the number of users with a display name
+func (s *NameStore) Count() int {
+	count := 0
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		count += len(shard.names)
+		shard.mu.RUnlock()
+	}
+	return count
+}
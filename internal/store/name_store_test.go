@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameStore_SetGet(t *testing.T) {
+	s := NewNameStore(4)
+
+	_, ok := s.Get("alice")
+	assert.False(t, ok)
+	assert.Equal(t, "alice", s.DisplayName("alice"), "falls back to the ID")
+	assert.Equal(t, "", s.DisplayName(""))
+
+	s.Set("alice", "Alice")
+	name, ok := s.Get("alice")
+	require.True(t, ok)
+	assert.Equal(t, "Alice", name)
+	assert.Equal(t, "Alice", s.DisplayName("alice"))
+	assert.Equal(t, "bob", s.DisplayName("bob"))
+	assert.Equal(t, 1, s.Count())
+
+	// An empty name clears it again
+	s.Set("alice", "")
+	_, ok = s.Get("alice")
+	assert.False(t, ok)
+	assert.Equal(t, "alice", s.DisplayName("alice"))
+	assert.Zero(t, s.Count())
+}
+
+func TestNameStore_Concurrent(t *testing.T) {
+	s := NewNameStore(8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			userID := fmt.Sprintf("user-%d", i)
+			s.Set(userID, fmt.Sprintf("Player %d", i))
+			_ = s.DisplayName(userID)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, s.Count())
+	assert.Equal(t, "Player 7", s.DisplayName("user-7"))
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"sync"
+
+	"tictactoe/internal/game"
+)
+
+// BoardConfig identifies the board settings outcomes are tallied under
+type BoardConfig struct {
+	BoardSize int
+	WinLength int
+}
+
+// Outcomes tallies how games under one board configuration ended
+type Outcomes struct {
+	XWins int64
+	OWins int64
+	Draws int64
+}
+
+// Total returns the number of games tallied
+func (o Outcomes) Total() int64 {
+	return o.XWins + o.OWins + o.Draws
+}
+
+// XWinPercent returns the share of games X won, from 0 to 100, or 0 when no
+// games have been tallied
+func (o Outcomes) XWinPercent() float64 {
+	if o.Total() == 0 {
+		return 0
+	}
+	return 100 * float64(o.XWins) / float64(o.Total())
+}
+
+// OutcomeStore tallies game outcomes per board configuration, showing how
+// much moving first is worth under each
+type OutcomeStore struct {
+	mu       sync.RWMutex
+	outcomes map[BoardConfig]Outcomes
+}
+
+// NewOutcomeStore creates an empty outcome store
+func NewOutcomeStore() *OutcomeStore {
+	return &OutcomeStore{
+		outcomes: make(map[BoardConfig]Outcomes),
+	}
+}
+
+// Record tallies a finished game under cfg. Only wins by X or O and draws
+// are tallied; other statuses, such as abandoned games, are ignored.
+func (s *OutcomeStore) Record(cfg BoardConfig, status game.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	outcomes := s.outcomes[cfg]
+	switch status {
+	case game.StatusXWon:
+		outcomes.XWins++
+	case game.StatusOWon:
+		outcomes.OWins++
+	case game.StatusDraw:
+		outcomes.Draws++
+	default:
+		return
+	}
+	s.outcomes[cfg] = outcomes
+}
+
+// Get returns the outcomes tallied under cfg
+func (s *OutcomeStore) Get(cfg BoardConfig) Outcomes {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.outcomes[cfg]
+}
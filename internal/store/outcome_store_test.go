@@ -0,0 +1,49 @@
+package store
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tictactoe/internal/game"
+)
+
+func TestOutcomeStore(t *testing.T) {
+	s := NewOutcomeStore()
+	classic := BoardConfig{BoardSize: 3, WinLength: 3}
+	assert.Equal(t, Outcomes{}, s.Get(classic))
+	assert.Zero(t, s.Get(classic).XWinPercent())
+
+	for _, status := range []game.Status{game.StatusXWon, game.StatusXWon, game.StatusOWon, game.StatusDraw,
+		game.StatusAbandoned, game.StatusCancelled, game.StatusInProgress} {
+		s.Record(classic, status)
+	}
+	s.Record(BoardConfig{BoardSize: 4, WinLength: 3}, game.StatusOWon)
+
+	outcomes := s.Get(classic)
+	assert.Equal(t, Outcomes{XWins: 2, OWins: 1, Draws: 1}, outcomes)
+	assert.Equal(t, int64(4), outcomes.Total())
+	assert.Equal(t, 50.0, outcomes.XWinPercent())
+	assert.Equal(t, Outcomes{OWins: 1}, s.Get(BoardConfig{BoardSize: 4, WinLength: 3}))
+	assert.Equal(t, Outcomes{}, s.Get(BoardConfig{BoardSize: 4, WinLength: 4}))
+}
+
+func TestOutcomeStore_Concurrent(t *testing.T) {
+	s := NewOutcomeStore()
+	cfg := BoardConfig{BoardSize: 3, WinLength: 3}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				s.Record(cfg, game.StatusXWon)
+				s.Get(cfg)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1000), s.Get(cfg).XWins)
+}
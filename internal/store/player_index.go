@@ -0,0 +1,127 @@
+package store
+
+import (
+	"sync"
+)
+
+// playerIndex maps each user to the games they hold a seat in, tracking
+// which of those are still active (pending or in progress).
+// It is sharded by user ID like the stats store.
+type playerIndex struct {
+	shards []*playerShard
+}
+
+type playerShard struct {
+	mu    sync.RWMutex
+	users map[string]*userGames
+}
+
+// userGames holds a user's game IDs; active is a subset of all
+type userGames struct {
+	all    map[string]struct{}
+	active map[string]struct{}
+}
+
+func newPlayerIndex(numShards int) *playerIndex {
+	shards := make([]*playerShard, numShards)
+	for i := range shards {
+		shards[i] = &playerShard{
+			users: make(map[string]*userGames),
+		}
+	}
+	return &playerIndex{shards: shards}
+}
+
+// getShard returns the shard for a given user ID
+func (idx *playerIndex) getShard(userID string) *playerShard {
+	return idx.shards[shardIndex(userID, len(idx.shards))]
+}
+
+// add records that userID plays in the active game gameID, unless that would
+// give them more than maxActive active games (0 = unlimited). It reports
+// whether the user may play, and whether the game was newly added; only a
+// newly added game should be rolled back with remove.
+func (idx *playerIndex) add(userID, gameID string, maxActive int) (ok, added bool) {
+	if userID == "" {
+		return true, false
+	}
+	shard := idx.getShard(userID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	games := shard.users[userID]
+	if games == nil {
+		games = &userGames{all: make(map[string]struct{}), active: make(map[string]struct{})}
+		shard.users[userID] = games
+	}
+	if _, exists := games.all[gameID]; exists {
+		return true, false
+	}
+	if maxActive > 0 && len(games.active) >= maxActive {
+		return false, false
+	}
+	games.all[gameID] = struct{}{}
+	games.active[gameID] = struct{}{}
+	return true, true
+}
+
+// finish marks gameID as no longer active for userID
+func (idx *playerIndex) finish(userID, gameID string) {
+	if userID == "" {
+		return
+	}
+	shard := idx.getShard(userID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if games, ok := shard.users[userID]; ok {
+		delete(games.active, gameID)
+	}
+}
+
+// remove forgets that userID plays in gameID
+func (idx *playerIndex) remove(userID, gameID string) {
+	if userID == "" {
+		return
+	}
+	shard := idx.getShard(userID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if games, ok := shard.users[userID]; ok {
+		delete(games.all, gameID)
+		delete(games.active, gameID)
+		if len(games.all) == 0 {
+			delete(shard.users, userID)
+		}
+	}
+}
+
+// gameIDs returns the games userID plays in, in no particular order
+func (idx *playerIndex) gameIDs(userID string) []string {
+	shard := idx.getShard(userID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	games, ok := shard.users[userID]
+	if !ok {
+		return nil
+	}
+	ids := make([]string, 0, len(games.all))
+	for id := range games.all {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// activeCount returns the number of active games userID plays in
+func (idx *playerIndex) activeCount(userID string) int {
+	shard := idx.getShard(userID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	if games, ok := shard.users[userID]; ok {
+		return len(games.active)
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+)
+
+// recency orders the games the store may evict by their last change the
+// store saw, least recent first: finished games in one list and pending
+// games in another, so finding the next victim takes no scan
+type recency struct {
+	mu       sync.Mutex
+	finished *list.List
+	pending  *list.List
+
+	// entries holds each listed game's element, in whichever list it is
+	entries map[string]*list.Element
+}
+
+func newRecency() *recency {
+	return &recency{
+		finished: list.New(),
+		pending:  list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// touchPending moves gameID to the back of the pending list
+func (r *recency) touchPending(gameID string) {
+	r.touch(r.pending, gameID)
+}
+
+// touchFinished moves gameID to the back of the finished list
+func (r *recency) touchFinished(gameID string) {
+	r.touch(r.finished, gameID)
+}
+
+func (r *recency) touch(l *list.List, gameID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unlink(gameID)
+	r.entries[gameID] = l.PushBack(gameID)
+}
+
+// forget takes gameID out of whichever list holds it
+func (r *recency) forget(gameID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unlink(gameID)
+}
+
+// unlink removes gameID's element (must hold r.mu)
+func (r *recency) unlink(gameID string) {
+	elem, ok := r.entries[gameID]
+	if !ok {
+		return
+	}
+	// Remove ignores an element from the other list
+	r.finished.Remove(elem)
+	r.pending.Remove(elem)
+	delete(r.entries, gameID)
+}
+
+// oldest returns the least recently changed finished game, or failing that
+// pending game, and false if neither list holds one
+func (r *recency) oldest() (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, l := range []*list.List{r.finished, r.pending} {
+		if front := l.Front(); front != nil {
+			return front.Value.(string), true
+		}
+	}
+	return "", false
+}
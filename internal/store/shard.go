@@ -0,0 +1,19 @@
+package store
+
+// FNV-1a parameters, as in hash/fnv
+const (
+	fnvOffset32 = 2166136261
+	fnvPrime32  = 16777619
+)
+
+// shardIndex picks the shard for key out of numShards. It hashes the key with
+// 32-bit FNV-1a, which spreads sequential and similar IDs evenly, without the
+// allocation of a hash.Hash32 on every lookup.
+func shardIndex(key string, numShards int) int {
+	hash := uint32(fnvOffset32)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= fnvPrime32
+	}
+	return int(hash % uint32(numShards))
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardIndex_MatchesFNV1a(t *testing.T) {
+	for _, key := range []string{"", "a", "game-1", "ünïcode", uuid.New().String()} {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		assert.Equal(t, int(h.Sum32()%64), shardIndex(key, 64), "key %q", key)
+	}
+}
+
+func TestShardIndex_Distribution(t *testing.T) {
+	const numShards = 64
+	const numKeys = numShards * 1000
+
+	keySets := map[string]func(i int) string{
+		"uuid":       func(int) string { return uuid.New().String() },
+		"sequential": func(i int) string { return fmt.Sprintf("game-%d", i) },
+		"numeric":    func(i int) string { return fmt.Sprint(i) },
+	}
+	for name, key := range keySets {
+		t.Run(name, func(t *testing.T) {
+			counts := make([]int, numShards)
+			for i := 0; i < numKeys; i++ {
+				counts[shardIndex(key(i), numShards)]++
+			}
+
+			// Every shard stays within 20% of the mean (about six standard deviations)
+			mean := numKeys / numShards
+			for shard, count := range counts {
+				assert.InDelta(t, mean, count, float64(mean)/5, "shard %d", shard)
+			}
+		})
+	}
+}
@@ -1,16 +1,90 @@
 package store
 
 import (
+	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// Bracket groups board sizes with comparable play so stats can be compared fairly
+type Bracket int
+
+const (
+	BracketAll    Bracket = iota // Every board size combined
+	BracketSmall                 // 3x3 and 4x4
+	BracketMedium                // 5x5 to 9x9
+	BracketLarge                 // 10x10 and up
+)
+
+// NumBrackets is the number of board-size brackets (excluding BracketAll)
+const NumBrackets = 3
+
+// BracketForSize returns the bracket a board size belongs to
+func BracketForSize(boardSize int) Bracket {
+	switch {
+	case boardSize <= 4:
+		return BracketSmall
+	case boardSize <= 9:
+		return BracketMedium
+	default:
+		return BracketLarge
+	}
+}
+
+func (b Bracket) String() string {
+	switch b {
+	case BracketAll:
+		return "ALL"
+	case BracketSmall:
+		return "SMALL"
+	case BracketMedium:
+		return "MEDIUM"
+	case BracketLarge:
+		return "LARGE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Record holds win/loss/draw counts
+type Record struct {
+	Wins   int32
+	Losses int32
+	Draws  int32
+}
+
+// TotalGames returns the total number of games in the record
+func (r Record) TotalGames() int32 {
+	return r.Wins + r.Losses + r.Draws
+}
+
 // UserStats holds win/loss/draw statistics for a user
 type UserStats struct {
 	UserID string
 	Wins   int32
 	Losses int32
 	Draws  int32
+
+	// Brackets holds the per-bracket records, indexed by Bracket-1
+	Brackets [NumBrackets]Record
+
+	// CurrentStreak counts the latest run of results: consecutive wins when
+	// positive, consecutive losses when negative. A draw ends either run
+	// without starting one, leaving it zero. LongestStreak is the longest
+	// run of wins. Both are read-modify-write, so unlike the counters they
+	// are guarded by the shard lock rather than updated atomically.
+	CurrentStreak int32
+	LongestStreak int32
+
+	// lastUpdate is when a result was last recorded, in Unix nanoseconds
+	lastUpdate int64
+}
+
+// LastUpdated returns when a result was last recorded for the user
+func (s *UserStats) LastUpdated() time.Time {
+	return time.Unix(0, s.lastUpdate)
 }
 
 // TotalGames returns the total number of games played
@@ -18,11 +92,54 @@ func (s *UserStats) TotalGames() int32 {
 	return s.Wins + s.Losses + s.Draws
 }
 
+// Bracket returns the user's record for a bracket; BracketAll gives the overall record
+func (s *UserStats) Bracket(b Bracket) Record {
+	if b == BracketAll {
+		return Record{Wins: s.Wins, Losses: s.Losses, Draws: s.Draws}
+	}
+	return s.Brackets[b-1]
+}
+
 // StatsStore provides thread-safe storage for user statistics
 // Uses sharding similar to GameStore for scalability
 type StatsStore struct {
 	shards    []*statsShard
 	numShards int
+
+	// Optional cap on tracked users (0 = unlimited)
+	maxUsers    int
+	activeUsers func() map[string]struct{}
+	userCount   int64
+	evictMu     sync.Mutex
+
+	// watchers are signalled after records change; see Watch
+	watchMu  sync.Mutex
+	watchers map[chan struct{}]struct{}
+	watching atomic.Int32
+
+	// version counts changes to records; see Version
+	version atomic.Uint64
+}
+
+// StatsOption configures optional stats store behavior
+type StatsOption func(*StatsStore)
+
+// WithMaxUsers caps the number of users tracked. When a new user pushes the
+// count over the cap, the least recently updated users are evicted until the
+// store is back to 90% of the cap, so a flood of new IDs pays for one scan
+// per batch rather than per user. Zero means unlimited.
+func WithMaxUsers(maxUsers int) StatsOption {
+	return func(s *StatsStore) {
+		s.maxUsers = maxUsers
+	}
+}
+
+// WithActiveUsers protects users from eviction while they are in a game.
+// active is called once per eviction batch and returns the set of such users.
+func WithActiveUsers(active func() map[string]struct{}) StatsOption {
+	return func(s *StatsStore) {
+		s.activeUsers = active
+	}
 }
 
 type statsShard struct {
@@ -31,7 +148,7 @@ type statsShard struct {
 }
 
 // NewStatsStore creates a new stats store with the specified number of shards
-func NewStatsStore(numShards int) *StatsStore {
+func NewStatsStore(numShards int, opts ...StatsOption) *StatsStore {
 	if numShards < 1 {
 		numShards = 64
 	}
@@ -43,19 +160,19 @@ func NewStatsStore(numShards int) *StatsStore {
 		}
 	}
 
-	return &StatsStore{
+	s := &StatsStore{
 		shards:    shards,
 		numShards: numShards,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // getShard returns the shard for a given user ID
 func (s *StatsStore) getShard(userID string) *statsShard {
-	hash := uint32(0)
-	for _, c := range userID {
-		hash = hash*31 + uint32(c)
-	}
-	return s.shards[hash%uint32(s.numShards)]
+	return s.shards[shardIndex(userID, s.numShards)]
 }
 
 // getOrCreate returns existing stats or creates new ones
@@ -73,62 +190,377 @@ func (s *StatsStore) getOrCreate(userID string) *UserStats {
 
 	// Need to create - use write lock
 	shard.mu.Lock()
-	defer shard.mu.Unlock()
 
 	// Double-check after acquiring write lock
 	if stats, exists = shard.stats[userID]; exists {
+		shard.mu.Unlock()
 		return stats
 	}
 
-	stats = &UserStats{UserID: userID}
+	stats = &UserStats{UserID: userID, lastUpdate: time.Now().UnixNano()}
 	shard.stats[userID] = stats
+	shard.mu.Unlock()
+
+	// The new user is the most recently updated, so eviction leaves it in place
+	if count := atomic.AddInt64(&s.userCount, 1); s.maxUsers > 0 && count > int64(s.maxUsers) {
+		s.evict()
+	}
 	return stats
 }
 
-// Get returns stats for a user
-func (s *StatsStore) Get(userID string) UserStats {
+// update returns the user's stats for recording a result, marking them as just updated
+func (s *StatsStore) update(userID string) *UserStats {
 	stats := s.getOrCreate(userID)
-	return UserStats{
-		UserID: userID,
-		Wins:   atomic.LoadInt32(&stats.Wins),
-		Losses: atomic.LoadInt32(&stats.Losses),
-		Draws:  atomic.LoadInt32(&stats.Draws),
+	atomic.StoreInt64(&stats.lastUpdate, time.Now().UnixNano())
+	return stats
+}
+
+// evict drops the least recently updated users until the store is at 90% of
+// its cap. Users reported active are skipped, as are users updated after
+// the scan.
+func (s *StatsStore) evict() {
+	s.evictMu.Lock()
+	defer s.evictMu.Unlock()
+
+	count := atomic.LoadInt64(&s.userCount)
+	if count <= int64(s.maxUsers) {
+		return
+	}
+	keep := int64(s.maxUsers - s.maxUsers/10)
+
+	var active map[string]struct{}
+	if s.activeUsers != nil {
+		active = s.activeUsers()
+	}
+
+	type candidate struct {
+		userID     string
+		lastUpdate int64
+	}
+	var candidates []candidate
+	for _, shard := range s.shards {
+		shard.mu.RLock()
+		for userID, stats := range shard.stats {
+			if _, ok := active[userID]; !ok {
+				candidates = append(candidates, candidate{userID, atomic.LoadInt64(&stats.lastUpdate)})
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].lastUpdate != candidates[j].lastUpdate {
+			return candidates[i].lastUpdate < candidates[j].lastUpdate
+		}
+		return candidates[i].userID < candidates[j].userID
+	})
+
+	for _, c := range candidates {
+		if count <= keep {
+			break
+		}
+		shard := s.getShard(c.userID)
+		shard.mu.Lock()
+		if stats, ok := shard.stats[c.userID]; ok && atomic.LoadInt64(&stats.lastUpdate) == c.lastUpdate {
+			delete(shard.stats, c.userID)
+			count = atomic.AddInt64(&s.userCount, -1)
+		}
+		shard.mu.Unlock()
+	}
+	s.changed()
+}
+
+// Get returns stats for a user. Unknown users get zero stats and are not stored.
+func (s *StatsStore) Get(userID string) UserStats {
+	shard := s.getShard(userID)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	stats, exists := shard.stats[userID]
+	if !exists {
+		return UserStats{UserID: userID}
+	}
+	return loadStats(stats)
+}
+
+// GetMany retrieves stats for several users, in the order given, with
+// zeroed stats for unknown users as Get returns. The IDs are grouped by
+// shard so each shard is locked once however many of them it holds.
+func (s *StatsStore) GetMany(userIDs []string) []UserStats {
+	byShard := make(map[int][]int)
+	for i, userID := range userIDs {
+		idx := shardIndex(userID, s.numShards)
+		byShard[idx] = append(byShard[idx], i)
+	}
+
+	out := make([]UserStats, len(userIDs))
+	for idx, positions := range byShard {
+		shard := s.shards[idx]
+		shard.mu.RLock()
+		for _, i := range positions {
+			if stats, exists := shard.stats[userIDs[i]]; exists {
+				out[i] = loadStats(stats)
+			} else {
+				out[i] = UserStats{UserID: userIDs[i]}
+			}
+		}
+		shard.mu.RUnlock()
 	}
+	return out
 }
 
-// RecordWin records a win for a user
+// Delete removes a user's stats and reports whether there were any.
+// Deleting an unknown user is a no-op.
+func (s *StatsStore) Delete(userID string) bool {
+	shard := s.getShard(userID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if _, exists := shard.stats[userID]; !exists {
+		return false
+	}
+	delete(shard.stats, userID)
+	atomic.AddInt64(&s.userCount, -1)
+	s.changed()
+	return true
+}
+
+// Reset zeroes a user's overall and bracket records and streaks. The user
+// stays tracked; resetting an unknown user is a no-op.
+func (s *StatsStore) Reset(userID string) {
+	shard := s.getShard(userID)
+	shard.mu.Lock()
+	stats, exists := shard.stats[userID]
+	if exists {
+		swapStats(stats)
+		atomic.StoreInt64(&stats.lastUpdate, time.Now().UnixNano())
+	}
+	shard.mu.Unlock()
+
+	if exists {
+		s.changed()
+	}
+}
+
+// ResetAll zeroes every user's records, as at the end of a season, and
+// returns the records of users who had played, in no particular order. Each
+// counter is swapped to zero atomically, so a result recorded concurrently
+// lands either in the returned records or in the new ones, never in neither,
+// though the players of one game may land on different sides. Users stay
+// tracked and keep their last update time for eviction.
+func (s *StatsStore) ResetAll() []UserStats {
+	var archived []UserStats
+	for _, shard := range s.shards {
+		shard.mu.Lock()
+		for _, stats := range shard.stats {
+			if old := swapStats(stats); old.TotalGames() > 0 {
+				archived = append(archived, old)
+			}
+		}
+		shard.mu.Unlock()
+	}
+	s.changed()
+	return archived
+}
+
+// Count returns the number of users tracked
+func (s *StatsStore) Count() int {
+	return int(atomic.LoadInt64(&s.userCount))
+}
+
+// ShardSizes returns the number of users tracked in each shard, in shard
+// order, to show how evenly user IDs spread over the shards
+func (s *StatsStore) ShardSizes() []int {
+	sizes := make([]int, len(s.shards))
+	for i, shard := range s.shards {
+		shard.mu.RLock()
+		sizes[i] = len(shard.stats)
+		shard.mu.RUnlock()
+	}
+	return sizes
+}
+
+// loadStats copies stats using atomic loads (must hold the shard's lock for
+// the streaks)
+func loadStats(stats *UserStats) UserStats {
+	out := UserStats{
+		UserID:        stats.UserID,
+		Wins:          atomic.LoadInt32(&stats.Wins),
+		Losses:        atomic.LoadInt32(&stats.Losses),
+		Draws:         atomic.LoadInt32(&stats.Draws),
+		CurrentStreak: stats.CurrentStreak,
+		LongestStreak: stats.LongestStreak,
+		lastUpdate:    atomic.LoadInt64(&stats.lastUpdate),
+	}
+	for i := range stats.Brackets {
+		out.Brackets[i] = Record{
+			Wins:   atomic.LoadInt32(&stats.Brackets[i].Wins),
+			Losses: atomic.LoadInt32(&stats.Brackets[i].Losses),
+			Draws:  atomic.LoadInt32(&stats.Brackets[i].Draws),
+		}
+	}
+	return out
+}
+
+// swapStats zeroes the overall and bracket records using atomic swaps, and
+// the streaks, and returns what they held (must hold the shard's write lock)
+func swapStats(stats *UserStats) UserStats {
+	out := UserStats{
+		UserID:        stats.UserID,
+		Wins:          atomic.SwapInt32(&stats.Wins, 0),
+		Losses:        atomic.SwapInt32(&stats.Losses, 0),
+		Draws:         atomic.SwapInt32(&stats.Draws, 0),
+		CurrentStreak: stats.CurrentStreak,
+		LongestStreak: stats.LongestStreak,
+		lastUpdate:    atomic.LoadInt64(&stats.lastUpdate),
+	}
+	stats.CurrentStreak, stats.LongestStreak = 0, 0
+	for i := range stats.Brackets {
+		out.Brackets[i] = Record{
+			Wins:   atomic.SwapInt32(&stats.Brackets[i].Wins, 0),
+			Losses: atomic.SwapInt32(&stats.Brackets[i].Losses, 0),
+			Draws:  atomic.SwapInt32(&stats.Brackets[i].Draws, 0),
+		}
+	}
+	return out
+}
+
+// RecordWin records a win for a user (not attributed to any bracket)
 func (s *StatsStore) RecordWin(userID string) {
-	stats := s.getOrCreate(userID)
+	stats := s.update(userID)
 	atomic.AddInt32(&stats.Wins, 1)
+	s.recordStreak(userID, stats, outcomeWin)
+	s.changed()
 }
 
-// RecordLoss records a loss for a user
+// RecordLoss records a loss for a user (not attributed to any bracket)
 func (s *StatsStore) RecordLoss(userID string) {
-	stats := s.getOrCreate(userID)
+	stats := s.update(userID)
 	atomic.AddInt32(&stats.Losses, 1)
+	s.recordStreak(userID, stats, outcomeLoss)
+	s.changed()
 }
 
-// RecordDraw records a draw for a user
+// RecordDraw records a draw for a user (not attributed to any bracket)
 func (s *StatsStore) RecordDraw(userID string) {
-	stats := s.getOrCreate(userID)
+	stats := s.update(userID)
 	atomic.AddInt32(&stats.Draws, 1)
+	s.recordStreak(userID, stats, outcomeDraw)
+	s.changed()
 }
 
-// RecordGameResult records the result for both players
-func (s *StatsStore) RecordGameResult(winnerID, loserID string, isDraw bool) {
+// RecordGameResult records the result for both players, overall and in the
+// bracket of the board size the game was played on
+func (s *StatsStore) RecordGameResult(winnerID, loserID string, isDraw bool, boardSize int) {
+	bracket := BracketForSize(boardSize)
 	if isDraw {
-		if winnerID != "" {
-			s.RecordDraw(winnerID)
-		}
-		if loserID != "" {
-			s.RecordDraw(loserID)
-		}
+		s.record(winnerID, bracket, outcomeDraw)
+		s.record(loserID, bracket, outcomeDraw)
 	} else {
-		if winnerID != "" {
-			s.RecordWin(winnerID)
+		s.record(winnerID, bracket, outcomeWin)
+		s.record(loserID, bracket, outcomeLoss)
+	}
+}
+
+// RecordMultiPlayerResult records a game with any number of players, in the
+// bracket of its board size: a win for winnerID and a loss for each other
+// player, or a draw for everyone when winnerID is empty
+func (s *StatsStore) RecordMultiPlayerResult(winnerID string, playerIDs []string, boardSize int) {
+	bracket := BracketForSize(boardSize)
+	for _, playerID := range playerIDs {
+		switch {
+		case winnerID == "":
+			s.record(playerID, bracket, outcomeDraw)
+		case playerID == winnerID:
+			s.record(playerID, bracket, outcomeWin)
+		default:
+			s.record(playerID, bracket, outcomeLoss)
 		}
-		if loserID != "" {
-			s.RecordLoss(loserID)
+	}
+}
+
+// AbandonPolicy decides how an abandoned game counts in stats
+type AbandonPolicy int
+
+const (
+	AbandonUncounted AbandonPolicy = iota // Abandoned games count for neither player
+	AbandonLoss                           // The player who abandoned takes a loss; the opponent gets nothing
+)
+
+func (p AbandonPolicy) String() string {
+	switch p {
+	case AbandonUncounted:
+		return "uncounted"
+	case AbandonLoss:
+		return "loss"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseAbandonPolicy parses a policy name as returned by AbandonPolicy.String
+func ParseAbandonPolicy(name string) (AbandonPolicy, error) {
+	for _, p := range []AbandonPolicy{AbandonUncounted, AbandonLoss} {
+		if p.String() == name {
+			return p, nil
 		}
 	}
+	return 0, fmt.Errorf("unknown abandon policy %q (want uncounted or loss)", name)
+}
+
+// RecordAbandonment records an abandoned game according to policy: a loss
+// for the abandoner, overall and in the board size's bracket, or nothing
+func (s *StatsStore) RecordAbandonment(abandonerID string, boardSize int, policy AbandonPolicy) {
+	if policy == AbandonLoss {
+		s.record(abandonerID, BracketForSize(boardSize), outcomeLoss)
+	}
+}
+
+type outcome int
+
+const (
+	outcomeWin outcome = iota
+	outcomeLoss
+	outcomeDraw
+)
+
+// record adds an outcome to a user's overall and bracket counters
+func (s *StatsStore) record(userID string, bracket Bracket, o outcome) {
+	if userID == "" {
+		return
+	}
+	stats := s.update(userID)
+	rec := &stats.Brackets[bracket-1]
+	switch o {
+	case outcomeWin:
+		atomic.AddInt32(&stats.Wins, 1)
+		atomic.AddInt32(&rec.Wins, 1)
+	case outcomeLoss:
+		atomic.AddInt32(&stats.Losses, 1)
+		atomic.AddInt32(&rec.Losses, 1)
+	case outcomeDraw:
+		atomic.AddInt32(&stats.Draws, 1)
+		atomic.AddInt32(&rec.Draws, 1)
+	}
+	s.recordStreak(userID, stats, o)
+	s.changed()
+}
+
+// recordStreak extends or ends the user's streak with an outcome: a win
+// extends a run of wins or starts one, a loss likewise for losses, and a
+// draw ends either run
+func (s *StatsStore) recordStreak(userID string, stats *UserStats, o outcome) {
+	shard := s.getShard(userID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	switch o {
+	case outcomeWin:
+		stats.CurrentStreak = max(stats.CurrentStreak, 0) + 1
+		stats.LongestStreak = max(stats.LongestStreak, stats.CurrentStreak)
+	case outcomeLoss:
+		stats.CurrentStreak = min(stats.CurrentStreak, 0) - 1
+	case outcomeDraw:
+		stats.CurrentStreak = 0
+	}
 }
@@ -1,10 +1,12 @@
 package store
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStatsStore_Get(t *testing.T) {
@@ -18,6 +20,23 @@ func TestStatsStore_Get(t *testing.T) {
 	assert.Equal(t, int32(0), stats.Draws)
 }
 
+func TestStatsStore_ShardSizes(t *testing.T) {
+	store := NewStatsStore(8)
+	assert.Equal(t, make([]int, 8), store.ShardSizes())
+
+	for i := 0; i < 100; i++ {
+		store.RecordWin(fmt.Sprintf("user-%d", i))
+	}
+
+	sizes := store.ShardSizes()
+	require.Len(t, sizes, 8)
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	assert.Equal(t, store.Count(), total)
+}
+
 func TestStatsStore_RecordWin(t *testing.T) {
 	store := NewStatsStore(4)
 
@@ -58,7 +77,7 @@ func TestStatsStore_RecordGameResult(t *testing.T) {
 	store := NewStatsStore(4)
 
 	// Record a win/loss
-	store.RecordGameResult("winner", "loser", false)
+	store.RecordGameResult("winner", "loser", false, 3)
 
 	winnerStats := store.Get("winner")
 	assert.Equal(t, int32(1), winnerStats.Wins)
@@ -69,7 +88,7 @@ func TestStatsStore_RecordGameResult(t *testing.T) {
 	assert.Equal(t, int32(1), loserStats.Losses)
 
 	// Record a draw
-	store.RecordGameResult("player1", "player2", true)
+	store.RecordGameResult("player1", "player2", true, 3)
 
 	p1Stats := store.Get("player1")
 	assert.Equal(t, int32(1), p1Stats.Draws)
@@ -78,6 +97,38 @@ func TestStatsStore_RecordGameResult(t *testing.T) {
 	assert.Equal(t, int32(1), p2Stats.Draws)
 }
 
+func TestStatsStore_RecordMultiPlayerResult(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.RecordMultiPlayerResult("carol", []string{"alice", "bob", "carol"}, 4)
+	assert.Equal(t, int32(1), store.Get("carol").Wins)
+	assert.Equal(t, int32(1), store.Get("alice").Losses)
+	assert.Equal(t, int32(1), store.Get("bob").Losses)
+
+	// A draw counts for every player
+	store.RecordMultiPlayerResult("", []string{"alice", "bob", "carol"}, 4)
+	for _, userID := range []string{"alice", "bob", "carol"} {
+		assert.Equal(t, int32(1), store.Get(userID).Draws, userID)
+	}
+}
+
+func TestStatsStore_GetMany(t *testing.T) {
+	store := NewStatsStore(4)
+	store.RecordWin("user-1")
+	store.RecordLoss("user-2")
+
+	stats := store.GetMany([]string{"user-2", "unknown", "user-1", "user-2"})
+	require.Len(t, stats, 4)
+	assert.Equal(t, "user-2", stats[0].UserID)
+	assert.Equal(t, int32(1), stats[0].Losses)
+	assert.Equal(t, UserStats{UserID: "unknown"}, stats[1])
+	assert.Equal(t, int32(1), stats[2].Wins)
+	assert.Equal(t, stats[0], stats[3])
+
+	// Reading unknown users does not start tracking them
+	assert.Equal(t, 2, store.Count())
+}
+
 func TestStatsStore_TotalGames(t *testing.T) {
 	store := NewStatsStore(4)
 
@@ -118,3 +169,321 @@ func TestStatsStore_Concurrent(t *testing.T) {
 	assert.Equal(t, int32(100), stats.Draws)
 	assert.Equal(t, int32(300), stats.TotalGames())
 }
+
+func TestStatsStore_Streaks(t *testing.T) {
+	store := NewStatsStore(4)
+
+	steps := []struct {
+		result           string
+		current, longest int32
+	}{
+		{"win", 1, 1},
+		{"win", 2, 2},
+		{"win", 3, 3},
+		{"loss", -1, 3},
+		{"loss", -2, 3},
+		{"draw", 0, 3}, // A draw ends a losing run without starting another
+		{"win", 1, 3},
+		{"draw", 0, 3}, // and likewise a winning one
+		{"draw", 0, 3},
+		{"loss", -1, 3},
+		{"win", 1, 3},
+	}
+	for i, step := range steps {
+		switch step.result {
+		case "win":
+			store.RecordGameResult("user-1", "user-2", false, 3)
+		case "loss":
+			store.RecordGameResult("user-2", "user-1", false, 3)
+		case "draw":
+			store.RecordGameResult("user-1", "user-2", true, 3)
+		}
+		stats := store.Get("user-1")
+		assert.Equal(t, step.current, stats.CurrentStreak, "step %d", i)
+		assert.Equal(t, step.longest, stats.LongestStreak, "step %d", i)
+	}
+	assert.Equal(t, int32(-1), store.Get("user-2").CurrentStreak)
+
+	// Resetting starts the streaks afresh
+	store.Reset("user-1")
+	stats := store.Get("user-1")
+	assert.Zero(t, stats.CurrentStreak)
+	assert.Zero(t, stats.LongestStreak)
+}
+
+func TestStatsStore_Streaks_Concurrent(t *testing.T) {
+	store := NewStatsStore(4)
+	var wg sync.WaitGroup
+
+	// Concurrent wins each extend the streak exactly once, while readers look on
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			store.RecordGameResult("winner", fmt.Sprintf("loser-%d", i%10), false, 3)
+		}()
+		go func() {
+			defer wg.Done()
+			stats := store.Get("winner")
+			assert.Equal(t, stats.CurrentStreak, stats.LongestStreak)
+		}()
+	}
+	wg.Wait()
+
+	stats := store.Get("winner")
+	assert.Equal(t, int32(100), stats.CurrentStreak)
+	assert.Equal(t, int32(100), stats.LongestStreak)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, int32(-10), store.Get(fmt.Sprintf("loser-%d", i)).CurrentStreak)
+	}
+
+	// Mixed results leave a streak the final run can explain
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				store.RecordWin("mixed")
+			case 1:
+				store.RecordLoss("mixed")
+			default:
+				store.RecordDraw("mixed")
+			}
+		}()
+	}
+	wg.Wait()
+	stats = store.Get("mixed")
+	assert.LessOrEqual(t, stats.CurrentStreak, stats.LongestStreak)
+	assert.LessOrEqual(t, stats.LongestStreak, int32(100))
+	assert.GreaterOrEqual(t, stats.CurrentStreak, int32(-100))
+}
+
+func TestStatsStore_Get_DoesNotTrackUnknownUsers(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.Get("lurker")
+	assert.Equal(t, 0, store.Count())
+}
+
+func TestStatsStore_Delete(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.RecordGameResult("user-1", "user-2", false, 3)
+	assert.True(t, store.Delete("user-1"))
+	assert.Equal(t, 1, store.Count())
+
+	stats := store.Get("user-1")
+	assert.Equal(t, UserStats{UserID: "user-1"}, stats)
+	assert.Equal(t, int32(1), store.Get("user-2").Losses)
+
+	// Deleting again, or deleting an unknown user, is harmless
+	assert.False(t, store.Delete("user-1"))
+	assert.False(t, store.Delete("nobody"))
+	assert.Equal(t, 1, store.Count())
+}
+
+func TestStatsStore_RecordAbandonment(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.RecordAbandonment("quitter", 3, AbandonUncounted)
+	assert.Equal(t, 0, store.Count())
+
+	store.RecordAbandonment("quitter", 7, AbandonLoss)
+	stats := store.Get("quitter")
+	assert.Equal(t, int32(1), stats.Losses)
+	assert.Equal(t, int32(1), stats.TotalGames())
+	assert.Equal(t, Record{Losses: 1}, stats.Bracket(BracketMedium))
+}
+
+func TestParseAbandonPolicy(t *testing.T) {
+	for _, policy := range []AbandonPolicy{AbandonUncounted, AbandonLoss} {
+		parsed, err := ParseAbandonPolicy(policy.String())
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+
+	_, err := ParseAbandonPolicy("draw")
+	assert.Error(t, err)
+}
+
+func TestStatsStore_Reset(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.RecordGameResult("user-1", "user-2", false, 3)
+	store.RecordGameResult("user-1", "user-2", true, 12)
+	store.Reset("user-1")
+
+	stats := store.Get("user-1")
+	assert.Equal(t, int32(0), stats.TotalGames())
+	for b := BracketSmall; b <= BracketLarge; b++ {
+		assert.Equal(t, Record{}, stats.Bracket(b), b.String())
+	}
+	assert.Equal(t, 2, store.Count(), "reset keeps the user tracked")
+	other := store.Get("user-2")
+	assert.Equal(t, int32(2), other.TotalGames())
+
+	// New results count from zero
+	store.RecordGameResult("user-1", "user-2", false, 3)
+	assert.Equal(t, int32(1), store.Get("user-1").Wins)
+
+	store.Reset("nobody")
+	assert.Equal(t, 2, store.Count())
+}
+
+func TestStatsStore_ResetAll(t *testing.T) {
+	store := NewStatsStore(4)
+
+	store.RecordGameResult("user-1", "user-2", false, 3)
+	store.RecordGameResult("user-1", "user-2", true, 12)
+	store.RecordWin("user-3")
+	store.Reset("user-3")
+
+	archived := store.ResetAll()
+	require.Len(t, archived, 2, "users without games this season are left out")
+	byUser := make(map[string]UserStats)
+	for _, stats := range archived {
+		byUser[stats.UserID] = stats
+	}
+	user1, user2 := byUser["user-1"], byUser["user-2"]
+	assert.Equal(t, Record{Wins: 1, Draws: 1}, user1.Bracket(BracketAll))
+	assert.Equal(t, Record{Losses: 1}, user2.Bracket(BracketSmall))
+
+	for _, userID := range []string{"user-1", "user-2"} {
+		stats := store.Get(userID)
+		assert.Equal(t, int32(0), stats.TotalGames())
+		for b := BracketSmall; b <= BracketLarge; b++ {
+			assert.Equal(t, Record{}, stats.Bracket(b), b.String())
+		}
+	}
+	assert.Equal(t, 3, store.Count(), "reset keeps users tracked")
+	assert.Empty(t, store.ResetAll())
+}
+
+func TestStatsStore_ResetAll_ConcurrentRecording(t *testing.T) {
+	store := NewStatsStore(4)
+
+	// Every win lands in exactly one season
+	const workers, wins = 8, 500
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < wins; i++ {
+				store.RecordGameResult(fmt.Sprintf("user-%d", w), "opponent", false, 3)
+			}
+		}(w)
+	}
+	var seasons [][]UserStats
+	for i := 0; i < 5; i++ {
+		seasons = append(seasons, store.ResetAll())
+	}
+	wg.Wait()
+	seasons = append(seasons, store.ResetAll())
+
+	var total, bracketTotal, losses int32
+	for _, season := range seasons {
+		for _, stats := range season {
+			total += stats.Wins
+			bracketTotal += stats.Bracket(BracketSmall).Wins
+			losses += stats.Losses
+		}
+	}
+	assert.Equal(t, int32(workers*wins), total)
+	assert.Equal(t, int32(workers*wins), bracketTotal)
+	assert.Equal(t, int32(workers*wins), losses)
+}
+
+func TestStatsStore_MaxUsers_EvictsLeastRecentlyUpdated(t *testing.T) {
+	store := NewStatsStore(4, WithMaxUsers(3))
+
+	store.RecordWin("oldest")
+	store.RecordWin("middle")
+	store.RecordWin("newest")
+
+	// Touching the oldest user makes "middle" the least recently updated
+	store.RecordWin("oldest")
+	assert.Equal(t, 3, store.Count())
+
+	store.RecordWin("newcomer")
+	assert.Equal(t, 3, store.Count())
+
+	assert.Equal(t, int32(0), store.Get("middle").Wins)
+	assert.Equal(t, int32(2), store.Get("oldest").Wins)
+	assert.Equal(t, int32(1), store.Get("newest").Wins)
+	assert.Equal(t, int32(1), store.Get("newcomer").Wins)
+}
+
+func TestStatsStore_MaxUsers_SkipsActiveUsers(t *testing.T) {
+	active := map[string]struct{}{"oldest": {}}
+	store := NewStatsStore(4, WithMaxUsers(2), WithActiveUsers(func() map[string]struct{} {
+		return active
+	}))
+
+	store.RecordWin("oldest")
+	store.RecordWin("middle")
+	store.RecordWin("newest")
+
+	assert.Equal(t, 2, store.Count())
+	assert.Equal(t, int32(1), store.Get("oldest").Wins)
+	assert.Equal(t, int32(0), store.Get("middle").Wins)
+	assert.Equal(t, int32(1), store.Get("newest").Wins)
+}
+
+func TestStatsStore_MaxUsers_Flood(t *testing.T) {
+	store := NewStatsStore(4, WithMaxUsers(100))
+	var wg sync.WaitGroup
+
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store.RecordGameResult(fmt.Sprintf("winner-%d", i), fmt.Sprintf("loser-%d", i), false, 3)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, store.Count(), 100)
+	assert.LessOrEqual(t, len(store.Snapshot()), 100)
+}
+
+func TestStatsStore_Watch(t *testing.T) {
+	store := NewStatsStore(4)
+	changes, stop := store.Watch()
+	other, stopOther := store.Watch()
+	defer stopOther()
+
+	// A burst of changes leaves a single signal waiting
+	store.RecordGameResult("alice", "bob", false, 3)
+	store.RecordWin("alice")
+	store.Reset("bob")
+	for _, ch := range []<-chan struct{}{changes, other} {
+		select {
+		case <-ch:
+		default:
+			t.Fatal("no signal after a change")
+		}
+		select {
+		case <-ch:
+			t.Fatal("signals were not coalesced")
+		default:
+		}
+	}
+
+	// A stopped watch hears nothing more; the others still do
+	stop()
+	stop()
+	store.Delete("alice")
+	select {
+	case <-changes:
+		t.Fatal("signal after the watch was stopped")
+	default:
+	}
+	select {
+	case <-other:
+	default:
+		t.Fatal("no signal after a delete")
+	}
+	assert.Equal(t, int32(1), store.watching.Load())
+}
@@ -0,0 +1,51 @@
+package store
+
+// Watch returns a channel that receives a value after any user's records
+// change, and a function that ends the watch. Signals are coalesced: changes
+// made while a value is already waiting add nothing, so a watcher that
+// rereads the store after each value sees every change without the store
+// ever blocking on a slow watcher.
+func (s *StatsStore) Watch() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	s.watchMu.Lock()
+	if s.watchers == nil {
+		s.watchers = make(map[chan struct{}]struct{})
+	}
+	s.watchers[ch] = struct{}{}
+	s.watching.Add(1)
+	s.watchMu.Unlock()
+
+	return ch, func() {
+		s.watchMu.Lock()
+		defer s.watchMu.Unlock()
+		if _, ok := s.watchers[ch]; ok {
+			delete(s.watchers, ch)
+			s.watching.Add(-1)
+		}
+	}
+}
+
+// Version returns a number that grows with every change to the records, so
+// a reader can tell whether something derived from them is stale
+func (s *StatsStore) Version() uint64 {
+	return s.version.Load()
+}
+
+// changed signals every watcher that records have changed
+func (s *StatsStore) changed() {
+	s.version.Add(1)
+
+	// Recording results is hot; skip the lock when nobody watches
+	if s.watching.Load() == 0 {
+		return
+	}
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	for ch := range s.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenStore maps bearer tokens to the user IDs they authenticate
+type TokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]tokenEntry
+}
+
+// tokenEntry is a token's user and when it stops working (zero = never)
+type tokenEntry struct {
+	userID  string
+	expires time.Time
+}
+
+// NewTokenStore creates an empty token store
+func NewTokenStore() *TokenStore {
+	return &TokenStore{
+		tokens: make(map[string]tokenEntry),
+	}
+}
+
+// Add issues a token for a user, replacing any previous owner of the token
+func (s *TokenStore) Add(token, userID string) {
+	s.AddUntil(token, userID, time.Time{})
+}
+
+// AddUntil issues a token for a user that stops authenticating at expires.
+// An expired token is only looked up as missing; Revoke removes it.
+func (s *TokenStore) AddUntil(token, userID string, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = tokenEntry{userID: userID, expires: expires}
+}
+
+// Revoke invalidates a token
+func (s *TokenStore) Revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+}
+
+// Lookup returns the user a token belongs to
+func (s *TokenStore) Lookup(token string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.tokens[token]
+	if !ok || (!entry.expires.IsZero() && !time.Now().Before(entry.expires)) {
+		return "", false
+	}
+	return entry.userID, true
+}
+
+// Load adds tokens from r, one "<token> <user_id>" pair per line.
+// Blank lines and lines starting with # are ignored.
+func (s *TokenStore) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expected \"<token> <user_id>\"", line)
+		}
+		s.Add(fields[0], fields[1])
+	}
+	return scanner.Err()
+}
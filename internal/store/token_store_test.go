@@ -0,0 +1,56 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenStore_AddLookupRevoke(t *testing.T) {
+	s := NewTokenStore()
+	s.Add("secret-1", "alice")
+
+	userID, ok := s.Lookup("secret-1")
+	require.True(t, ok)
+	assert.Equal(t, "alice", userID)
+
+	_, ok = s.Lookup("unknown")
+	assert.False(t, ok)
+
+	s.Revoke("secret-1")
+	_, ok = s.Lookup("secret-1")
+	assert.False(t, ok)
+}
+
+func TestTokenStore_AddUntil(t *testing.T) {
+	s := NewTokenStore()
+	s.AddUntil("live", "alice", time.Now().Add(time.Hour))
+	s.AddUntil("expired", "bob", time.Now().Add(-time.Second))
+
+	userID, ok := s.Lookup("live")
+	require.True(t, ok)
+	assert.Equal(t, "alice", userID)
+	_, ok = s.Lookup("expired")
+	assert.False(t, ok)
+}
+
+func TestTokenStore_Load(t *testing.T) {
+	s := NewTokenStore()
+	err := s.Load(strings.NewReader("# tokens\nsecret-1 alice\n\n  secret-2   bob  \n"))
+	require.NoError(t, err)
+
+	userID, ok := s.Lookup("secret-1")
+	require.True(t, ok)
+	assert.Equal(t, "alice", userID)
+
+	userID, ok = s.Lookup("secret-2")
+	require.True(t, ok)
+	assert.Equal(t, "bob", userID)
+
+	// Malformed lines are reported with their line number
+	err = NewTokenStore().Load(strings.NewReader("secret-1 alice\nsecret-only\n"))
+	assert.EqualError(t, err, `line 2: expected "<token> <user_id>"`)
+}
@@ -0,0 +1,342 @@
+package store
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTournamentNotFound   = errors.New("tournament not found")
+	ErrTournamentExists     = errors.New("tournament already exists")
+	ErrTooFewParticipants   = errors.New("a tournament needs at least two participants")
+	ErrDuplicateParticipant = errors.New("participants must be distinct")
+	ErrTooManyTournaments   = errors.New("user has too many tournaments under way")
+)
+
+// Tournament is a single-elimination bracket. When the number of
+// participants is not a power of two, the top seeds get byes into the
+// second round.
+type Tournament struct {
+	ID           string
+	Name         string
+	CreatedBy    string
+	Participants []string // In seeding order, best first
+	Config       BoardConfig
+	Rounds       [][]Match // Rounds[0] is the first round; the last holds only the final
+	Winner       string    // Empty until the final is decided
+	CreatedAt    time.Time
+	FinishedAt   time.Time // Zero until the final is decided
+}
+
+// Match is one pairing in a bracket. Its players are filled in as the
+// matches feeding it are decided.
+type Match struct {
+	PlayerA string
+	PlayerB string
+	Bye     bool     // PlayerA advances without playing
+	GameIDs []string // In play order; a drawn game is replayed, so a match may take several
+	Winner  string
+
+	// playing is set while a game for the match is being set up or played
+	playing bool
+}
+
+// ready reports whether the match needs a game: both players are known,
+// neither has won and no game is under way
+func (m *Match) ready() bool {
+	return m.PlayerA != "" && m.PlayerB != "" && m.Winner == "" && !m.playing
+}
+
+// Pairing is a match claimed for its next game. The caller registers the
+// game with StartGame before creating it, and cancels it with CancelGame if
+// it cannot be created, to claim it again later with ClaimStalled.
+type Pairing struct {
+	TournamentID string
+	Round        int
+	Match        int
+	Config       BoardConfig
+	// Seats for the game; the players swap marks on each replay
+	PlayerX string
+	PlayerO string
+}
+
+// matchRef locates a match in the store
+type matchRef struct {
+	tournamentID string
+	round, match int
+}
+
+// TournamentStore holds tournaments and advances their brackets as their
+// games finish. Tournaments are few and small, so one lock covers them all.
+type TournamentStore struct {
+	mu          sync.Mutex
+	tournaments map[string]*Tournament
+	games       map[string]matchRef   // Games under way, by game ID
+	stalled     map[matchRef]struct{} // Matches whose game was cancelled
+}
+
+// NewTournamentStore creates an empty tournament store
+func NewTournamentStore() *TournamentStore {
+	return &TournamentStore{
+		tournaments: make(map[string]*Tournament),
+		games:       make(map[string]matchRef),
+		stalled:     make(map[matchRef]struct{}),
+	}
+}
+
+// Create builds the bracket for participants, in seeding order, and stores
+// it. Byes are decided at once; the first games to play are returned
+// claimed. A creator who already has maxPerCreator tournaments under way
+// (0 = unlimited) gets ErrTooManyTournaments.
+func (s *TournamentStore) Create(id, name, createdBy string, participants []string, cfg BoardConfig, maxPerCreator int) (Tournament, []Pairing, error) {
+	if len(participants) < 2 {
+		return Tournament{}, nil, ErrTooFewParticipants
+	}
+	seen := make(map[string]bool, len(participants))
+	for _, p := range participants {
+		if seen[p] {
+			return Tournament{}, nil, ErrDuplicateParticipant
+		}
+		seen[p] = true
+	}
+
+	t := &Tournament{
+		ID:           id,
+		Name:         name,
+		CreatedBy:    createdBy,
+		Participants: slices.Clone(participants),
+		Config:       cfg,
+		CreatedAt:    time.Now(),
+	}
+
+	size := 2
+	for size < len(participants) {
+		size *= 2
+	}
+	seeds := seedOrder(size)
+	first := make([]Match, size/2)
+	for i := range first {
+		// The better seed of each pair comes first, so only PlayerB is ever a bye
+		first[i].PlayerA = participants[seeds[2*i]]
+		if b := seeds[2*i+1]; b < len(participants) {
+			first[i].PlayerB = participants[b]
+		} else {
+			first[i].Bye = true
+		}
+	}
+	t.Rounds = append(t.Rounds, first)
+	for n := size / 4; n >= 1; n /= 2 {
+		t.Rounds = append(t.Rounds, make([]Match, n))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tournaments[id]; exists {
+		return Tournament{}, nil, ErrTournamentExists
+	}
+	if maxPerCreator > 0 {
+		underWay := 0
+		for _, other := range s.tournaments {
+			if other.CreatedBy == createdBy && other.Winner == "" {
+				underWay++
+			}
+		}
+		if underWay >= maxPerCreator {
+			return Tournament{}, nil, ErrTooManyTournaments
+		}
+	}
+	s.tournaments[id] = t
+
+	for i := range first {
+		if first[i].Bye {
+			s.decide(t, 0, i, first[i].PlayerA)
+		}
+	}
+	return t.clone(), s.claimReady(t), nil
+}
+
+// seedOrder lists the seeds (0 best) of a bracket of size slots so that
+// seeds 2i and 2i+1 of the list meet in the first round, the best seeds
+// meet the worst, and the top two can only meet in the final
+func seedOrder(size int) []int {
+	order := []int{0}
+	for n := 2; n <= size; n *= 2 {
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n-1-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// Get returns a copy of a tournament
+func (s *TournamentStore) Get(id string) (Tournament, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tournaments[id]
+	if !ok {
+		return Tournament{}, ErrTournamentNotFound
+	}
+	return t.clone(), nil
+}
+
+// ClaimStalled claims the matches whose game CancelGame cancelled, if they
+// still need one. Each cancellation is offered once; cancel the game again
+// to have it offered again.
+func (s *TournamentStore) ClaimStalled() []Pairing {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pairings []Pairing
+	for ref := range s.stalled {
+		delete(s.stalled, ref)
+		t, ok := s.tournaments[ref.tournamentID]
+		if !ok {
+			continue
+		}
+		if p, ok := s.claim(t, ref.round, ref.match); ok {
+			pairings = append(pairings, p)
+		}
+	}
+	return pairings
+}
+
+// RemoveFinished removes the tournaments decided before cutoff, returning
+// how many it removed
+func (s *TournamentStore) RemoveFinished(cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for id, t := range s.tournaments {
+		if t.Winner != "" && t.FinishedAt.Before(cutoff) {
+			delete(s.tournaments, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// StartGame records the game to be created for a claimed pairing. Register
+// the game before anyone can play it, so its result cannot arrive first.
+func (s *TournamentStore) StartGame(p Pairing, gameID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tournaments[p.TournamentID]
+	if !ok {
+		return
+	}
+	m := &t.Rounds[p.Round][p.Match]
+	m.GameIDs = append(m.GameIDs, gameID)
+	s.games[gameID] = matchRef{tournamentID: p.TournamentID, round: p.Round, match: p.Match}
+}
+
+// CancelGame forgets a game StartGame recorded that could not be created,
+// so ClaimStalled offers its match again
+func (s *TournamentStore) CancelGame(gameID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref, ok := s.games[gameID]
+	if !ok {
+		return
+	}
+	delete(s.games, gameID)
+	m := &s.tournaments[ref.tournamentID].Rounds[ref.round][ref.match]
+	m.GameIDs = slices.DeleteFunc(m.GameIDs, func(id string) bool { return id == gameID })
+	m.playing = false
+	s.stalled[ref] = struct{}{}
+}
+
+// RecordResult records the outcome of a finished tournament game: the
+// winner advances, or with no winner the match is replayed. It returns the
+// matches now ready, claimed, and false if the game is not a tournament
+// game under way.
+func (s *TournamentStore) RecordResult(gameID, winner string) ([]Pairing, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ref, ok := s.games[gameID]
+	if !ok {
+		return nil, false
+	}
+	delete(s.games, gameID)
+	t := s.tournaments[ref.tournamentID]
+	m := &t.Rounds[ref.round][ref.match]
+	m.playing = false
+	if winner == m.PlayerA || winner == m.PlayerB {
+		s.decide(t, ref.round, ref.match, winner)
+	}
+	return s.claimReady(t), true
+}
+
+// decide records a match's winner and moves them into their next match
+// (must hold s.mu)
+func (s *TournamentStore) decide(t *Tournament, round, match int, winner string) {
+	t.Rounds[round][match].Winner = winner
+	if round == len(t.Rounds)-1 {
+		t.Winner = winner
+		t.FinishedAt = time.Now()
+		return
+	}
+	next := &t.Rounds[round+1][match/2]
+	if match%2 == 0 {
+		next.PlayerA = winner
+	} else {
+		next.PlayerB = winner
+	}
+}
+
+// claimReady claims every match of t that is ready for a game (must hold s.mu)
+func (s *TournamentStore) claimReady(t *Tournament) []Pairing {
+	var pairings []Pairing
+	for r, round := range t.Rounds {
+		for i := range round {
+			if p, ok := s.claim(t, r, i); ok {
+				pairings = append(pairings, p)
+			}
+		}
+	}
+	return pairings
+}
+
+// claim claims a match of t if it is ready for a game (must hold s.mu)
+func (s *TournamentStore) claim(t *Tournament, round, match int) (Pairing, bool) {
+	m := &t.Rounds[round][match]
+	if !m.ready() {
+		return Pairing{}, false
+	}
+	m.playing = true
+	p := Pairing{
+		TournamentID: t.ID,
+		Round:        round,
+		Match:        match,
+		Config:       t.Config,
+		PlayerX:      m.PlayerA,
+		PlayerO:      m.PlayerB,
+	}
+	if len(m.GameIDs)%2 == 1 {
+		p.PlayerX, p.PlayerO = p.PlayerO, p.PlayerX
+	}
+	return p, true
+}
+
+// clone returns a deep copy of t (must hold the store's lock)
+func (t *Tournament) clone() Tournament {
+	out := *t
+	out.Participants = slices.Clone(t.Participants)
+	out.Rounds = make([][]Match, len(t.Rounds))
+	for r, round := range t.Rounds {
+		out.Rounds[r] = slices.Clone(round)
+		for i := range round {
+			out.Rounds[r][i].GameIDs = slices.Clone(round[i].GameIDs)
+		}
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var classicConfig = BoardConfig{BoardSize: 3, WinLength: 3}
+
+// startAll registers a game for each pairing, named after its match
+func startAll(s *TournamentStore, pairings []Pairing) []string {
+	var gameIDs []string
+	for _, p := range pairings {
+		gameID := fmt.Sprintf("r%d-m%d-%s", p.Round, p.Match, p.PlayerX)
+		s.StartGame(p, gameID)
+		gameIDs = append(gameIDs, gameID)
+	}
+	return gameIDs
+}
+
+func TestSeedOrder(t *testing.T) {
+	assert.Equal(t, []int{0, 1}, seedOrder(2))
+	assert.Equal(t, []int{0, 3, 1, 2}, seedOrder(4))
+	assert.Equal(t, []int{0, 7, 3, 4, 1, 6, 2, 5}, seedOrder(8))
+}
+
+func TestTournamentStore_Create(t *testing.T) {
+	s := NewTournamentStore()
+
+	_, _, err := s.Create("t0", "", "alice", []string{"alice"}, classicConfig, 0)
+	assert.ErrorIs(t, err, ErrTooFewParticipants)
+	_, _, err = s.Create("t0", "", "alice", []string{"alice", "bob", "alice"}, classicConfig, 0)
+	assert.ErrorIs(t, err, ErrDuplicateParticipant)
+
+	tournament, pairings, err := s.Create("t1", "Cup", "alice", []string{"p1", "p2", "p3", "p4"}, classicConfig, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Cup", tournament.Name)
+	require.Len(t, tournament.Rounds, 2)
+	assert.Len(t, tournament.Rounds[0], 2)
+	assert.Len(t, tournament.Rounds[1], 1)
+	// The top seeds can only meet in the final
+	assert.Equal(t, []Pairing{
+		{TournamentID: "t1", Round: 0, Match: 0, Config: classicConfig, PlayerX: "p1", PlayerO: "p4"},
+		{TournamentID: "t1", Round: 0, Match: 1, Config: classicConfig, PlayerX: "p2", PlayerO: "p3"},
+	}, pairings)
+
+	_, _, err = s.Create("t1", "", "alice", []string{"p1", "p2"}, classicConfig, 0)
+	assert.ErrorIs(t, err, ErrTournamentExists)
+
+	_, err = s.Get("missing")
+	assert.ErrorIs(t, err, ErrTournamentNotFound)
+}
+
+func TestTournamentStore_Byes(t *testing.T) {
+	s := NewTournamentStore()
+
+	// Five players fill an eight-slot bracket, so the top three seeds get byes
+	tournament, pairings, err := s.Create("t", "", "alice", []string{"p1", "p2", "p3", "p4", "p5"}, classicConfig, 0)
+	require.NoError(t, err)
+	require.Len(t, tournament.Rounds, 3)
+
+	var byes []string
+	for _, m := range tournament.Rounds[0] {
+		if m.Bye {
+			assert.Empty(t, m.PlayerB)
+			assert.Equal(t, m.PlayerA, m.Winner)
+			byes = append(byes, m.PlayerA)
+		}
+	}
+	assert.ElementsMatch(t, []string{"p1", "p2", "p3"}, byes)
+
+	// Only p4 and p5 play in the first round, and p2 and p3 meet at once
+	require.Len(t, pairings, 2)
+	assert.Equal(t, Pairing{TournamentID: "t", Round: 0, Match: 1, Config: classicConfig, PlayerX: "p4", PlayerO: "p5"}, pairings[0])
+	assert.Equal(t, Pairing{TournamentID: "t", Round: 1, Match: 1, Config: classicConfig, PlayerX: "p2", PlayerO: "p3"}, pairings[1])
+
+	// Three players: the top seed waits for the winner of the other two
+	_, pairings, err = s.Create("t3", "", "alice", []string{"p1", "p2", "p3"}, classicConfig, 0)
+	require.NoError(t, err)
+	require.Len(t, pairings, 1)
+	assert.Equal(t, "p2", pairings[0].PlayerX)
+	assert.Equal(t, "p3", pairings[0].PlayerO)
+}
+
+func TestTournamentStore_RecordResult(t *testing.T) {
+	s := NewTournamentStore()
+	_, pairings, err := s.Create("t", "", "alice", []string{"p1", "p2", "p3", "p4"}, classicConfig, 0)
+	require.NoError(t, err)
+	games := startAll(s, pairings)
+
+	_, ok := s.RecordResult("unknown", "p1")
+	assert.False(t, ok)
+
+	// The final waits for both semi-finals
+	next, ok := s.RecordResult(games[0], "p4")
+	assert.True(t, ok)
+	assert.Empty(t, next)
+	next, ok = s.RecordResult(games[1], "p2")
+	assert.True(t, ok)
+	require.Len(t, next, 1)
+	assert.Equal(t, Pairing{TournamentID: "t", Round: 1, Match: 0, Config: classicConfig, PlayerX: "p4", PlayerO: "p2"}, next[0])
+
+	// A result is only recorded once
+	_, ok = s.RecordResult(games[0], "p4")
+	assert.False(t, ok)
+
+	final := startAll(s, next)
+	next, ok = s.RecordResult(final[0], "p2")
+	assert.True(t, ok)
+	assert.Empty(t, next)
+
+	tournament, err := s.Get("t")
+	require.NoError(t, err)
+	assert.Equal(t, "p2", tournament.Winner)
+	assert.Equal(t, "p4", tournament.Rounds[0][0].Winner)
+	assert.Equal(t, []string{games[0]}, tournament.Rounds[0][0].GameIDs)
+	assert.Equal(t, "p2", tournament.Rounds[1][0].Winner)
+
+	// A decided tournament is kept until removed
+	assert.Zero(t, s.RemoveFinished(tournament.FinishedAt))
+	assert.Equal(t, 1, s.RemoveFinished(time.Now().Add(time.Second)))
+	_, err = s.Get("t")
+	assert.ErrorIs(t, err, ErrTournamentNotFound)
+}
+
+func TestTournamentStore_MaxPerCreator(t *testing.T) {
+	s := NewTournamentStore()
+	_, pairings, err := s.Create("t1", "", "alice", []string{"p1", "p2"}, classicConfig, 1)
+	require.NoError(t, err)
+	_, _, err = s.Create("t2", "", "alice", []string{"p1", "p2"}, classicConfig, 1)
+	assert.ErrorIs(t, err, ErrTooManyTournaments)
+	_, _, err = s.Create("t2", "", "bob", []string{"p1", "p2"}, classicConfig, 1)
+	require.NoError(t, err)
+
+	// A decided tournament no longer counts
+	games := startAll(s, pairings)
+	s.RecordResult(games[0], "p1")
+	_, _, err = s.Create("t3", "", "alice", []string{"p1", "p2"}, classicConfig, 1)
+	require.NoError(t, err)
+}
+
+func TestTournamentStore_DrawReplaysWithSwappedMarks(t *testing.T) {
+	s := NewTournamentStore()
+	_, pairings, err := s.Create("t", "", "alice", []string{"p1", "p2"}, classicConfig, 0)
+	require.NoError(t, err)
+	require.Len(t, pairings, 1)
+	first := startAll(s, pairings)
+
+	next, ok := s.RecordResult(first[0], "")
+	assert.True(t, ok)
+	require.Len(t, next, 1)
+	assert.Equal(t, "p2", next[0].PlayerX)
+	assert.Equal(t, "p1", next[0].PlayerO)
+
+	// A winner who is not in the match counts as a draw too
+	second := startAll(s, next)
+	next, ok = s.RecordResult(second[0], "stranger")
+	assert.True(t, ok)
+	require.Len(t, next, 1)
+	assert.Equal(t, "p1", next[0].PlayerX)
+
+	third := startAll(s, next)
+	_, ok = s.RecordResult(third[0], "p2")
+	assert.True(t, ok)
+
+	tournament, err := s.Get("t")
+	require.NoError(t, err)
+	assert.Equal(t, "p2", tournament.Winner)
+	assert.Equal(t, []string{first[0], second[0], third[0]}, tournament.Rounds[0][0].GameIDs)
+}
+
+func TestTournamentStore_CancelGame(t *testing.T) {
+	s := NewTournamentStore()
+	_, pairings, err := s.Create("t", "", "alice", []string{"p1", "p2"}, classicConfig, 0)
+	require.NoError(t, err)
+
+	// A claimed match is not offered again until its game is cancelled
+	assert.Empty(t, s.ClaimStalled())
+	games := startAll(s, pairings)
+	assert.Empty(t, s.ClaimStalled())
+
+	s.CancelGame(games[0])
+	tournament, err := s.Get("t")
+	require.NoError(t, err)
+	assert.Empty(t, tournament.Rounds[0][0].GameIDs)
+	_, ok := s.RecordResult(games[0], "p1")
+	assert.False(t, ok)
+
+	// Each cancellation offers the match once
+	assert.Equal(t, pairings, s.ClaimStalled())
+	assert.Empty(t, s.ClaimStalled())
+}
+
+func TestTournamentStore_GetReturnsCopy(t *testing.T) {
+	s := NewTournamentStore()
+	_, pairings, err := s.Create("t", "", "alice", []string{"p1", "p2"}, classicConfig, 0)
+	require.NoError(t, err)
+	startAll(s, pairings)
+
+	tournament, err := s.Get("t")
+	require.NoError(t, err)
+	tournament.Participants[0] = "changed"
+	tournament.Rounds[0][0].GameIDs[0] = "changed"
+
+	tournament, err = s.Get("t")
+	require.NoError(t, err)
+	assert.Equal(t, "p1", tournament.Participants[0])
+	assert.NotEqual(t, "changed", tournament.Rounds[0][0].GameIDs[0])
+}
@@ -1,20 +1,37 @@
 package acceptance
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/game"
+	"tictactoe/internal/ratelimit"
 	"tictactoe/internal/server"
 	"tictactoe/internal/store"
 )
@@ -22,19 +39,23 @@ import (
 // testServer holds the server and client for acceptance tests
 type testServer struct {
 	grpcServer *grpc.Server
+	ticTacToe  *server.TicTacToeServer
 	client     pb.TicTacToeServiceClient
 	conn       *grpc.ClientConn
 	addr       string
 }
 
-func setupTestServer(t *testing.T) *testServer {
-	// Create stores
-	gameStore := store.NewGameStore(4)
-	statsStore := store.NewStatsStore(4)
+func setupTestServer(t testing.TB, opts ...server.Option) *testServer {
+	return setupTestServerWithStores(t, store.NewGameStore(4), store.NewStatsStore(4), opts...)
+}
 
-	// Create gRPC server
-	grpcServer := grpc.NewServer()
-	ticTacToeServer := server.NewTicTacToeServer(gameStore, statsStore)
+// setupTestServerWithStores serves the given stores, for tests and benchmarks
+// that configure them
+func setupTestServerWithStores(t testing.TB, gameStore *store.GameStore, statsStore *store.StatsStore, opts ...server.Option) *testServer {
+	// Create gRPC server; request logging is discarded unless a test overrides it
+	opts = append([]server.Option{server.WithRequestLogging(slog.New(slog.NewTextHandler(io.Discard, nil)))}, opts...)
+	ticTacToeServer := server.NewTicTacToeServer(gameStore, statsStore, opts...)
+	grpcServer := grpc.NewServer(ticTacToeServer.GRPCServerOptions()...)
 	pb.RegisterTicTacToeServiceServer(grpcServer, ticTacToeServer)
 
 	// Start listening on random port
@@ -52,6 +73,7 @@ func setupTestServer(t *testing.T) *testServer {
 
 	return &testServer{
 		grpcServer: grpcServer,
+		ticTacToe:  ticTacToeServer,
 		client:     client,
 		conn:       conn,
 		addr:       addr,
@@ -60,6 +82,7 @@ func setupTestServer(t *testing.T) *testServer {
 
 func (ts *testServer) cleanup() {
 	ts.conn.Close()
+	ts.ticTacToe.Close()
 	ts.grpcServer.Stop()
 }
 
@@ -147,6 +170,85 @@ func TestAcceptance_CreateGame_InvalidInput(t *testing.T) {
 	assert.Equal(t, codes.InvalidArgument, status.Code(err))
 }
 
+func TestAcceptance_CreateGame_DegenerateWarning(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	// Win length equal to a large board size is allowed but flagged
+	resp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1", BoardSize: 7, WinLength: 7})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_PENDING, resp.Game.Status)
+	assert.Contains(t, resp.Warning, "almost always be drawn")
+
+	// Classic 3x3 and shorter lines are not
+	for _, req := range []*pb.CreateGameRequest{
+		{UserId: "player-2"},
+		{UserId: "player-3", BoardSize: 7, WinLength: 5},
+	} {
+		resp, err = ts.client.CreateGame(ctx, req)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Warning)
+	}
+}
+
+func TestAcceptance_CreateGame_ConfiguredDefaults(t *testing.T) {
+	require.NoError(t, server.ValidateDefaultBoard(5, 4, server.MaxBoardSize))
+	assert.Error(t, server.ValidateDefaultBoard(4, 5, server.MaxBoardSize))
+	assert.Error(t, server.ValidateDefaultBoard(2, 2, server.MaxBoardSize))
+	assert.Error(t, server.ValidateDefaultBoard(server.MaxBoardSize+1, 3, server.MaxBoardSize))
+	assert.Error(t, server.ValidateDefaultBoard(5, 4, 4))
+
+	ts := setupTestServer(t, server.WithDefaultBoard(5, 4))
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	resp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), resp.Game.BoardSize)
+	assert.Equal(t, int32(4), resp.Game.WinLength)
+
+	// The default win length is shortened to fit a smaller requested board
+	resp, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-2", BoardSize: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.Game.BoardSize)
+	assert.Equal(t, int32(3), resp.Game.WinLength)
+
+	// Explicit values still win over the defaults
+	resp, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-3", BoardSize: 7, WinLength: 5})
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), resp.Game.BoardSize)
+	assert.Equal(t, int32(5), resp.Game.WinLength)
+}
+
+func TestAcceptance_ConfiguredLimits(t *testing.T) {
+	ts := setupTestServer(t, server.WithMaxBoardSize(30), server.WithMaxListLimit(2))
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	// A board at the configured cap is accepted, above it is not
+	resp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1", BoardSize: 30, WinLength: 5})
+	require.NoError(t, err)
+	assert.Equal(t, int32(30), resp.Game.BoardSize)
+
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1", BoardSize: 31, WinLength: 5})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "between 3 and 30")
+
+	// Pages are clamped to the configured list limit
+	for i := 2; i <= 4; i++ {
+		_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: fmt.Sprintf("player-%d", i)})
+		require.NoError(t, err)
+	}
+	listResp, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{Limit: 50})
+	require.NoError(t, err)
+	assert.Len(t, listResp.Games, 2)
+	assert.Equal(t, int32(4), listResp.TotalCount)
+}
+
 func TestAcceptance_ListPendingGames(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.cleanup()
@@ -208,6 +310,50 @@ func TestAcceptance_JoinGame(t *testing.T) {
 	assert.Equal(t, int32(0), listResp.TotalCount)
 }
 
+func TestAcceptance_PrivateGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	publicResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1"})
+	require.NoError(t, err)
+	assert.False(t, publicResp.Game.IsPrivate)
+	assert.Empty(t, publicResp.JoinCode)
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{
+		UserId:    "player-2",
+		IsPrivate: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, createResp.Game.IsPrivate)
+	require.Len(t, createResp.JoinCode, 8)
+	gameID := createResp.Game.GameId
+
+	// Only the public game is listed
+	listResp, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), listResp.TotalCount)
+	require.Len(t, listResp.Games, 1)
+	assert.Equal(t, publicResp.Game.GameId, listResp.Games[0].GameId)
+
+	// A missing or wrong code is rejected and leaves the game open
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "player-3", GameId: gameID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "player-3", GameId: gameID, JoinCode: "WRONGCDE"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The friend with the code gets in
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{
+		UserId:   "player-3",
+		GameId:   gameID,
+		JoinCode: createResp.JoinCode,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "player-3", joinResp.Game.PlayerOId)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, joinResp.Game.Status)
+}
+
 func TestAcceptance_JoinGame_Errors(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.cleanup()
@@ -236,12 +382,12 @@ func TestAcceptance_JoinGame_Errors(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	// Cannot join again
+	// A full game was taken by someone else
 	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{
 		UserId: "player-3",
 		GameId: gameID,
 	})
-	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, codes.Aborted, status.Code(err))
 
 	// Game not found
 	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{
@@ -533,6 +679,125 @@ func TestAcceptance_GetGame(t *testing.T) {
 	assert.Equal(t, codes.NotFound, status.Code(err))
 }
 
+func TestAcceptance_GetGameCompact(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1", BoardSize: 15, WinLength: 5})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "player-2", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "player-1", GameId: gameID, Row: 7, Col: 7})
+	require.NoError(t, err)
+	moveResp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "player-2", GameId: gameID, Row: 14, Col: 14})
+	require.NoError(t, err)
+
+	resp, err := ts.client.GetGameCompact(ctx, &pb.GetGameCompactRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, int32(15), resp.BoardSize)
+	assert.Equal(t, int32(2), resp.Dimensions)
+	assert.Equal(t, pb.Mark_MARK_X, resp.CurrentTurn)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, resp.Status)
+	assert.Len(t, resp.PackedBoard, 57) // 225 cells at 4 per byte
+
+	// The packed board decodes to the same cells as the full representation
+	board, err := game.UnpackBoard(int(resp.BoardSize), int(resp.WinLength), resp.PackedBoard)
+	require.NoError(t, err)
+	toProto := map[game.Mark]pb.Mark{game.MarkEmpty: pb.Mark_MARK_EMPTY, game.MarkX: pb.Mark_MARK_X, game.MarkO: pb.Mark_MARK_O}
+	require.Len(t, board.Cells, len(moveResp.Game.Board))
+	for i, cell := range board.Cells {
+		assert.Equal(t, moveResp.Game.Board[i], toProto[cell], "cell %d", i)
+	}
+
+	_, err = ts.client.GetGameCompact(ctx, &pb.GetGameCompactRequest{GameId: "nonexistent"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAcceptance_BatchGetGames(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	var gameIDs []string
+	for _, user := range []string{"player-1", "player-2"} {
+		createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: user})
+		require.NoError(t, err)
+		gameIDs = append(gameIDs, createResp.Game.GameId)
+	}
+
+	// Missing IDs are reported without failing the call
+	resp, err := ts.client.BatchGetGames(ctx, &pb.BatchGetGamesRequest{
+		GameIds: []string{gameIDs[1], "nonexistent", gameIDs[0], gameIDs[1]},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Games, 2)
+	assert.Equal(t, gameIDs[1], resp.Games[0].GameId)
+	assert.Equal(t, "player-2", resp.Games[0].PlayerXId)
+	assert.Equal(t, gameIDs[0], resp.Games[1].GameId)
+	assert.Equal(t, []string{"nonexistent"}, resp.MissingGameIds)
+
+	// Empty and oversized requests are rejected
+	_, err = ts.client.BatchGetGames(ctx, &pb.BatchGetGamesRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	tooMany := make([]string, server.MaxBatchGetGames+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("game-%d", i)
+	}
+	_, err = ts.client.BatchGetGames(ctx, &pb.BatchGetGamesRequest{GameIds: tooMany})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = ts.client.BatchGetGames(ctx, &pb.BatchGetGamesRequest{GameIds: []string{gameIDs[0], ""}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_BatchGetUserStats(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	// Stats come back in request order, zeroed for unknown users
+	resp, err := ts.client.BatchGetUserStats(ctx, &pb.BatchGetUserStatsRequest{
+		UserIds: []string{"bob", "nobody", "alice"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Stats, 3)
+	assert.Equal(t, "bob", resp.Stats[0].UserId)
+	assert.Equal(t, int32(1), resp.Stats[0].Losses)
+	assert.Equal(t, "nobody", resp.Stats[1].UserId)
+	assert.Equal(t, int32(0), resp.Stats[1].TotalGames)
+	assert.Len(t, resp.Stats[1].Brackets, 3)
+	assert.Equal(t, "alice", resp.Stats[2].UserId)
+	assert.Equal(t, int32(1), resp.Stats[2].Wins)
+
+	// Empty and oversized requests are rejected
+	_, err = ts.client.BatchGetUserStats(ctx, &pb.BatchGetUserStatsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	tooMany := make([]string, server.MaxBatchGetUserStats+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("user-%d", i)
+	}
+	_, err = ts.client.BatchGetUserStats(ctx, &pb.BatchGetUserStatsRequest{UserIds: tooMany})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = ts.client.BatchGetUserStats(ctx, &pb.BatchGetUserStatsRequest{UserIds: []string{"alice", ""}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestAcceptance_GetUserStats(t *testing.T) {
 	ts := setupTestServer(t)
 	defer ts.cleanup()
@@ -595,3 +860,3664 @@ func TestAcceptance_StreamGameUpdates(t *testing.T) {
 	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, update.Game.Status)
 	assert.Contains(t, update.Message, "started")
 }
+
+func TestAcceptance_ListDuplicateGames(t *testing.T) {
+	ts := setupTestServer(t, server.WithFingerprintIndex(store.NewFingerprintIndex()))
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	// Play the same scripted opening in three games, and a different one in a fourth
+	playOpening := func(i int, moves [][2]int32) string {
+		playerX := fmt.Sprintf("bot-x-%d", i)
+		playerO := fmt.Sprintf("bot-o-%d", i)
+
+		createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: playerX})
+		require.NoError(t, err)
+		gameID := createResp.Game.GameId
+
+		_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: playerO, GameId: gameID})
+		require.NoError(t, err)
+
+		players := []string{playerX, playerO}
+		for n, m := range moves {
+			_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{
+				UserId: players[n%2],
+				GameId: gameID,
+				Row:    m[0],
+				Col:    m[1],
+			})
+			require.NoError(t, err)
+		}
+		return gameID
+	}
+
+	script := [][2]int32{{1, 1}, {0, 0}, {2, 0}}
+	var scripted []string
+	for i := 0; i < 3; i++ {
+		scripted = append(scripted, playOpening(i, script))
+	}
+	playOpening(3, [][2]int32{{0, 1}, {1, 1}})
+
+	resp, err := ts.client.ListDuplicateGames(ctx, &pb.ListDuplicateGamesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Groups, 1)
+	assert.NotEmpty(t, resp.Groups[0].Fingerprint)
+	assert.ElementsMatch(t, scripted, resp.Groups[0].GameIds)
+
+	// Diverging one of the scripted games shrinks the group
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{
+		UserId: "bot-o-0",
+		GameId: scripted[0],
+		Row:    2,
+		Col:    2,
+	})
+	require.NoError(t, err)
+
+	resp, err = ts.client.ListDuplicateGames(ctx, &pb.ListDuplicateGamesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Groups, 1)
+	assert.ElementsMatch(t, scripted[1:], resp.Groups[0].GameIds)
+}
+
+func TestAcceptance_ListDuplicateGames_Disabled(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	_, err := ts.client.ListDuplicateGames(context.Background(), &pb.ListDuplicateGamesRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAcceptance_DropMove(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{
+		UserId:    "player-1",
+		BoardSize: 3,
+		WinLength: 3,
+		Mode:      pb.GameMode_GAME_MODE_GRAVITY,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameMode_GAME_MODE_GRAVITY, createResp.Game.Mode)
+	gameID := createResp.Game.GameId
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "player-2", GameId: gameID})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv() // Initial state
+	require.NoError(t, err)
+
+	// Fill column 2 from the bottom up
+	players := []string{"player-1", "player-2"}
+	for i := 0; i < 3; i++ {
+		resp, err := ts.client.DropMove(ctx, &pb.DropMoveRequest{
+			UserId: players[i%2],
+			GameId: gameID,
+			Col:    2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2-i), resp.Row)
+
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, pb.GameMode_GAME_MODE_GRAVITY, update.Game.Mode)
+		assert.Equal(t, resp.Game.Board, update.Game.Board)
+	}
+
+	_, err = ts.client.DropMove(ctx, &pb.DropMoveRequest{UserId: "player-2", GameId: gameID, Col: 2})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Explicit moves must still land on the lowest empty row
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "player-2", GameId: gameID, Row: 0, Col: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Classic games reject drops
+	classic, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-3"})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameMode_GAME_MODE_CLASSIC, classic.Game.Mode)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "player-4", GameId: classic.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.DropMove(ctx, &pb.DropMoveRequest{UserId: "player-3", GameId: classic.Game.GameId, Col: 0})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAcceptance_MakeMove_Nonce(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "player-1"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "player-2", GameId: gameID})
+	require.NoError(t, err)
+
+	move := func(user string, row, col int32, nonce uint64) error {
+		_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{
+			UserId: user,
+			GameId: gameID,
+			Row:    row,
+			Col:    col,
+			Nonce:  nonce,
+		})
+		return err
+	}
+
+	// Increasing nonces are accepted
+	require.NoError(t, move("player-1", 0, 0, 10))
+	require.NoError(t, move("player-2", 1, 1, 1))
+	require.NoError(t, move("player-1", 0, 1, 11))
+	require.NoError(t, move("player-2", 2, 2, 2))
+
+	// A captured request replayed verbatim is rejected
+	err = move("player-1", 0, 1, 11)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// An older nonce is rejected even for a fresh position
+	err = move("player-1", 0, 2, 10)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// The board is unchanged by the rejected moves
+	getResp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.Mark_MARK_EMPTY, getResp.Game.Board[2])
+	assert.Equal(t, pb.Mark_MARK_X, getResp.Game.CurrentTurn)
+
+	require.NoError(t, move("player-1", 0, 2, 12))
+}
+
+// playXWins plays a game in which X completes the top row while O fills the row below,
+// returning the game ID
+func playXWins(t *testing.T, ts *testServer, playerX, playerO string, boardSize, winLength int32) string {
+	t.Helper()
+	ctx := context.Background()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{
+		UserId:    playerX,
+		BoardSize: boardSize,
+		WinLength: winLength,
+	})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: playerO, GameId: gameID})
+	require.NoError(t, err)
+
+	var resp *pb.MakeMoveResponse
+	for col := int32(0); col < winLength; col++ {
+		resp, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: playerX, GameId: gameID, Row: 0, Col: col})
+		require.NoError(t, err)
+		if col == winLength-1 {
+			break
+		}
+		_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: playerO, GameId: gameID, Row: 1, Col: col})
+		require.NoError(t, err)
+	}
+	require.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, resp.Game.Status)
+	return gameID
+}
+
+func TestAcceptance_GetLeaderboard_Brackets(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	playXWins(t, ts, "small-ace", "small-rookie", 3, 3)
+	playXWins(t, ts, "small-ace", "small-rookie", 4, 3)
+	playXWins(t, ts, "large-ace", "large-rookie", 15, 5)
+
+	// The small bracket only ranks small-board players
+	resp, err := ts.client.GetLeaderboard(ctx, &pb.GetLeaderboardRequest{Bracket: pb.BoardBracket_BOARD_BRACKET_SMALL})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.TotalCount)
+	require.Len(t, resp.Entries, 2)
+	assert.Equal(t, "small-ace", resp.Entries[0].UserId)
+	assert.Equal(t, int32(1), resp.Entries[0].Rank)
+	assert.Equal(t, int32(2), resp.Entries[0].Wins)
+	assert.Equal(t, "small-rookie", resp.Entries[1].UserId)
+	assert.Equal(t, int32(2), resp.Entries[1].Losses)
+
+	resp, err = ts.client.GetLeaderboard(ctx, &pb.GetLeaderboardRequest{Bracket: pb.BoardBracket_BOARD_BRACKET_LARGE})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 2)
+	assert.Equal(t, "large-ace", resp.Entries[0].UserId)
+	assert.Equal(t, int32(1), resp.Entries[0].TotalGames)
+
+	// No bracket means every board size
+	resp, err = ts.client.GetLeaderboard(ctx, &pb.GetLeaderboardRequest{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), resp.TotalCount)
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, "small-ace", resp.Entries[0].UserId)
+
+	// User stats carry the per-bracket breakdown
+	statsResp, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "small-ace"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), statsResp.Wins)
+	require.Len(t, statsResp.Brackets, 3)
+	assert.Equal(t, pb.BoardBracket_BOARD_BRACKET_SMALL, statsResp.Brackets[0].Bracket)
+	assert.Equal(t, int32(2), statsResp.Brackets[0].Wins)
+	assert.Equal(t, int32(0), statsResp.Brackets[1].TotalGames)
+	assert.Equal(t, int32(0), statsResp.Brackets[2].TotalGames)
+}
+
+func TestAcceptance_Misere_XMakesLineLoses(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{
+		UserId: "misere-x",
+		Misere: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, createResp.Game.Misere)
+	gameID := createResp.Game.GameId
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "misere-o", GameId: gameID})
+	require.NoError(t, err)
+
+	// X completes the left column
+	moves := []struct {
+		user     string
+		row, col int32
+	}{
+		{"misere-x", 0, 0},
+		{"misere-o", 0, 1},
+		{"misere-x", 1, 0},
+		{"misere-o", 2, 2},
+		{"misere-x", 2, 0},
+	}
+	var resp *pb.MakeMoveResponse
+	for _, m := range moves {
+		resp, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: m.user, GameId: gameID, Row: m.row, Col: m.col})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_O_WON, resp.Game.Status)
+
+	// Stats credit the win to O
+	xStats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "misere-x"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), xStats.Losses)
+	assert.Equal(t, int32(0), xStats.Wins)
+
+	oStats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "misere-o"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), oStats.Wins)
+}
+
+func TestAcceptance_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	ts := setupTestServer(t, server.WithMetrics(reg))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	playXWins(t, ts, "metrics-x", "metrics-o", 3, 3)
+
+	// A failing call is counted under its status code
+	_, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: "missing"})
+	require.Error(t, err)
+
+	// An open stream shows up in the subscriber gauge
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "metrics-x"})
+	require.NoError(t, err)
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: createResp.Game.GameId})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+
+	assert.Contains(t, out, `tictactoe_grpc_requests_total{code="OK",method="/tictactoe.TicTacToeService/CreateGame"} 2`)
+	assert.Contains(t, out, `tictactoe_grpc_requests_total{code="OK",method="/tictactoe.TicTacToeService/MakeMove"} 5`)
+	assert.Contains(t, out, `tictactoe_grpc_requests_total{code="NotFound",method="/tictactoe.TicTacToeService/GetGame"} 1`)
+	assert.Contains(t, out, `tictactoe_grpc_request_duration_seconds_count{method="/tictactoe.TicTacToeService/MakeMove"} 5`)
+	assert.Contains(t, out, "tictactoe_games_created_total 2\n")
+	assert.Contains(t, out, "tictactoe_moves_total 5\n")
+	assert.Contains(t, out, "tictactoe_games 2\n")
+	assert.Contains(t, out, "tictactoe_stream_subscribers 1\n")
+	assert.Contains(t, out, `tictactoe_game_store_shard_games{shard="3"}`)
+	assert.Contains(t, out, `tictactoe_stats_store_shard_users{shard="3"}`)
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAcceptance_RequestLogging(t *testing.T) {
+	var logs syncBuffer
+	ts := setupTestServer(t, server.WithRequestLogging(slog.New(slog.NewTextHandler(&logs, nil))))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A server-generated request ID is returned in the response header
+	var header metadata.MD
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "log-x"}, grpc.Header(&header))
+	require.NoError(t, err)
+	generated := header.Get(server.RequestIDHeader)
+	require.Len(t, generated, 1)
+	assert.NotEmpty(t, generated[0])
+
+	// A client-supplied request ID is propagated back unchanged
+	header = nil
+	tagged := metadata.AppendToOutgoingContext(ctx, server.RequestIDHeader, "req-123")
+	_, err = ts.client.JoinGame(tagged, &pb.JoinGameRequest{GameId: createResp.Game.GameId, UserId: "log-o"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"req-123"}, header.Get(server.RequestIDHeader))
+
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: "missing"})
+	require.Error(t, err)
+
+	out := logs.String()
+	assert.Contains(t, out, "method=/tictactoe.TicTacToeService/CreateGame request_id="+generated[0]+" user_id=log-x")
+	assert.Contains(t, out, "method=/tictactoe.TicTacToeService/JoinGame request_id=req-123 user_id=log-o game_id="+createResp.Game.GameId)
+	assert.Contains(t, out, "game_id=missing")
+	assert.Contains(t, out, "code=NotFound")
+	assert.Contains(t, out, "code=OK")
+}
+
+func TestAcceptance_Auth(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-alice", "alice")
+	tokens.Add("token-bob", "bob")
+	ts := setupTestServer(t, server.WithAuth(tokens, server.DefaultPublicMethods))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+	asBob := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-bob")
+
+	// Missing and unknown tokens are rejected
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	badToken := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer nope")
+	_, err = ts.client.CreateGame(badToken, &pb.CreateGameRequest{UserId: "alice"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The token's user acts, with or without user_id in the request
+	createResp, err := ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", createResp.Game.PlayerXId)
+	gameID := createResp.Game.GameId
+
+	_, err = ts.client.JoinGame(asBob, &pb.JoinGameRequest{GameId: gameID, UserId: "bob"})
+	require.NoError(t, err)
+
+	// Bob cannot move as Alice by naming her in the request
+	_, err = ts.client.MakeMove(asBob, &pb.MakeMoveRequest{GameId: gameID, UserId: "alice", Row: 0, Col: 0})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = ts.client.MakeMove(asAlice, &pb.MakeMoveRequest{GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+
+	// Read-only methods stay open without a token
+	getResp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.Mark_MARK_X, getResp.Game.Board[0])
+
+	// Methods outside the allowlist are not
+	_, err = ts.client.ListDuplicateGames(ctx, &pb.ListDuplicateGamesRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Users may only delete their own stats
+	_, err = ts.client.DeleteUserStats(asBob, &pb.DeleteUserStatsRequest{UserId: "alice"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = ts.client.DeleteUserStats(asBob, &pb.DeleteUserStatsRequest{})
+	require.NoError(t, err)
+}
+
+func TestAcceptance_RateLimit(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-alice", "alice")
+	tokens.Add("token-bob", "bob")
+	ts := setupTestServer(t,
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+		server.WithRateLimit(ratelimit.New(10, 3)),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+	asBob := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-bob")
+
+	// Alice's burst is spent, then she is limited
+	for i := 0; i < 3; i++ {
+		_, err := ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+		require.NoError(t, err, "request %d", i)
+	}
+	_, err := ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "retry in")
+
+	// Bob shares the connection's IP but has his own budget
+	_, err = ts.client.CreateGame(asBob, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+
+	// Alice recovers once her bucket refills
+	time.Sleep(150 * time.Millisecond)
+	_, err = ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+}
+
+func TestAcceptance_MakeMove_ImmediatelyAfterJoin(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Many games at once so any window between Join returning and the game
+	// being fully in progress would show up
+	const numGames = 100
+	var wg sync.WaitGroup
+	for i := 0; i < numGames; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			playerX := fmt.Sprintf("fast-x-%d", i)
+			playerO := fmt.Sprintf("fast-o-%d", i)
+
+			createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: playerX})
+			if !assert.NoError(t, err) {
+				return
+			}
+			gameID := createResp.Game.GameId
+
+			stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, err = stream.Recv()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			// No delay between join and the first moves
+			_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{GameId: gameID, UserId: playerO})
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: playerX, Row: 0, Col: 0})
+			if !assert.NoError(t, err, "move right after join") {
+				return
+			}
+			_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: playerO, Row: 1, Col: 1})
+			if !assert.NoError(t, err, "reply right after the first move") {
+				return
+			}
+
+			// Subscribers see the join before either move, in order
+			update, err := stream.Recv()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Contains(t, update.Message, "started")
+			assert.Equal(t, pb.Mark_MARK_EMPTY, update.Game.Board[0])
+
+			update, err = stream.Recv()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, pb.Mark_MARK_X, update.Game.Board[0])
+			assert.Equal(t, pb.Mark_MARK_EMPTY, update.Game.Board[4])
+
+			update, err = stream.Recv()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, pb.Mark_MARK_O, update.Game.Board[4])
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestAcceptance_RenderBoard(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	gameID := playXWins(t, ts, "render-x", "render-o", 3, 3)
+
+	// ASCII is the default and matches GetGameBoard
+	boardResp, err := ts.client.GetGameBoard(ctx, &pb.GetGameBoardRequest{GameId: gameID})
+	require.NoError(t, err)
+	resp, err := ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.RenderFormat_RENDER_FORMAT_ASCII, resp.Format)
+	assert.Equal(t, "text/plain", resp.ContentType)
+	assert.Equal(t, boardResp.BoardDisplay, resp.Content)
+
+	resp, err = ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: gameID, Format: pb.RenderFormat_RENDER_FORMAT_UNICODE_BOX})
+	require.NoError(t, err)
+	assert.Equal(t, "┌───┬───┬───┐\n"+
+		"│ X │ X │ X │\n"+
+		"├───┼───┼───┤\n"+
+		"│ O │ O │   │\n"+
+		"├───┼───┼───┤\n"+
+		"│   │   │   │\n"+
+		"└───┴───┴───┘\n", resp.Content)
+
+	// SVG highlights the winning top row
+	resp, err = ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: gameID, Format: pb.RenderFormat_RENDER_FORMAT_SVG})
+	require.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", resp.ContentType)
+	assert.True(t, strings.HasPrefix(resp.Content, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	assert.True(t, strings.HasSuffix(resp.Content, "</svg>"))
+	assert.Equal(t, 3, strings.Count(resp.Content, `class="win"`))
+	assert.Equal(t, 3, strings.Count(resp.Content, `class="x"`))
+	assert.Equal(t, 2, strings.Count(resp.Content, `class="o"`))
+	assert.Contains(t, resp.Content, `<line class="strike" x1="40" y1="40" x2="160" y2="40"`)
+
+	// Games still in play have nothing highlighted
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "render-x"})
+	require.NoError(t, err)
+	resp, err = ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: createResp.Game.GameId, Format: pb.RenderFormat_RENDER_FORMAT_SVG})
+	require.NoError(t, err)
+	assert.NotContains(t, resp.Content, `class="win"`)
+	assert.NotContains(t, resp.Content, `class="strike"`)
+
+	_, err = ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: gameID, Format: pb.RenderFormat(99)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAcceptance_MakeMove_AlgebraicCell(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "algebra-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "algebra-o", GameId: gameID})
+	require.NoError(t, err)
+
+	// "a1" is the bottom-left cell, "c3" the top-right
+	resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "algebra-x", GameId: gameID, Cell: "a1"})
+	require.NoError(t, err)
+	assert.Equal(t, pb.Mark_MARK_X, resp.Game.Board[6])
+
+	resp, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "algebra-o", GameId: gameID, Cell: "C3"})
+	require.NoError(t, err)
+	assert.Equal(t, pb.Mark_MARK_O, resp.Game.Board[2])
+
+	// Exactly one addressing mode may be used
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "algebra-x", GameId: gameID, Cell: "b2", Row: 1, Col: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Malformed and off-board cells are rejected
+	for _, cell := range []string{"2b", "d1", "a4"} {
+		_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "algebra-x", GameId: gameID, Cell: cell})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), cell)
+	}
+
+	// Occupied cells are reported the same way as with row/col
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "algebra-x", GameId: gameID, Cell: "a1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "occupied")
+}
+
+func TestAcceptance_CreateGame_CreatorPlaysO(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "o-creator", CreatorMark: pb.Mark_MARK_O})
+	require.NoError(t, err)
+	assert.Empty(t, createResp.Game.PlayerXId)
+	assert.Equal(t, "o-creator", createResp.Game.PlayerOId)
+	gameID := createResp.Game.GameId
+
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "x-joiner", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, "x-joiner", joinResp.Game.PlayerXId)
+	assert.Equal(t, pb.Mark_MARK_X, joinResp.Game.CurrentTurn)
+
+	// The joiner moves first
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "o-creator", GameId: gameID, Row: 0, Col: 0})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "x-joiner", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "o-creator", GameId: gameID, Row: 1, Col: 1})
+	require.NoError(t, err)
+
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "o-creator", CreatorMark: pb.Mark_MARK_EMPTY})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_DrawByAgreement(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "draw-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "draw-o", GameId: gameID})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// Responding without an offer is a failed precondition
+	_, err = ts.client.RespondDraw(ctx, &pb.RespondDrawRequest{UserId: "draw-o", GameId: gameID, Accept: true})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Offer, decline, offer again, accept
+	offerResp, err := ts.client.OfferDraw(ctx, &pb.OfferDrawRequest{UserId: "draw-x", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.Mark_MARK_X, offerResp.Game.DrawOfferedBy)
+
+	respondResp, err := ts.client.RespondDraw(ctx, &pb.RespondDrawRequest{UserId: "draw-o", GameId: gameID, Accept: false})
+	require.NoError(t, err)
+	assert.Equal(t, pb.Mark_MARK_EMPTY, respondResp.Game.DrawOfferedBy)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, respondResp.Game.Status)
+
+	_, err = ts.client.OfferDraw(ctx, &pb.OfferDrawRequest{UserId: "draw-o", GameId: gameID})
+	require.NoError(t, err)
+	respondResp, err = ts.client.RespondDraw(ctx, &pb.RespondDrawRequest{UserId: "draw-x", GameId: gameID, Accept: true})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_DRAW, respondResp.Game.Status)
+
+	// Subscribers see every step, and the stream ends with the game
+	var messages []string
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		messages = append(messages, update.Message)
+	}
+	assert.Equal(t, []string{
+		"Player X offers a draw",
+		"Player O declined the draw",
+		"Player O offers a draw",
+		"Player X accepted the draw. Game ended in a draw!",
+	}, messages)
+
+	// Both players are credited with a draw
+	for _, userID := range []string{"draw-x", "draw-o"} {
+		stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: userID})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), stats.Draws, userID)
+	}
+}
+
+func TestAcceptance_GetAvailableMoves(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Pending games have no moves yet
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "avail-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	resp, err := ts.client.GetAvailableMoves(ctx, &pb.GetAvailableMovesRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Moves)
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "avail-o", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "avail-x", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+
+	resp, err = ts.client.GetAvailableMoves(ctx, &pb.GetAvailableMovesRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Len(t, resp.Moves, 8)
+	assert.Equal(t, int32(0), resp.Moves[0].Row)
+	assert.Equal(t, int32(1), resp.Moves[0].Col)
+
+	// Gravity games offer only the landing cell of each column
+	gravity, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "avail-x", Mode: pb.GameMode_GAME_MODE_GRAVITY})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "avail-o", GameId: gravity.Game.GameId})
+	require.NoError(t, err)
+	resp, err = ts.client.GetAvailableMoves(ctx, &pb.GetAvailableMovesRequest{GameId: gravity.Game.GameId})
+	require.NoError(t, err)
+	require.Len(t, resp.Moves, 3)
+	for col, pos := range resp.Moves {
+		assert.Equal(t, int32(2), pos.Row)
+		assert.Equal(t, int32(col), pos.Col)
+	}
+
+	// Finished games have none
+	finished := playXWins(t, ts, "avail-x", "avail-o", 3, 3)
+	resp, err = ts.client.GetAvailableMoves(ctx, &pb.GetAvailableMovesRequest{GameId: finished})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Moves)
+
+	_, err = ts.client.GetAvailableMoves(ctx, &pb.GetAvailableMovesRequest{GameId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAcceptance_MakeMove_ConcurrentStats(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Both players of each game fire moves at every cell concurrently until
+	// the game ends; each game must be recorded exactly once for each player
+	const numGames = 50
+	var wg sync.WaitGroup
+	for i := 0; i < numGames; i++ {
+		playerX := fmt.Sprintf("stress-x-%d", i)
+		playerO := fmt.Sprintf("stress-o-%d", i)
+		createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: playerX, BoardSize: 4, WinLength: 3})
+		require.NoError(t, err)
+		gameID := createResp.Game.GameId
+		_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: playerO, GameId: gameID})
+		require.NoError(t, err)
+
+		for _, player := range []string{playerX, playerO} {
+			wg.Add(1)
+			go func(player string) {
+				defer wg.Done()
+				for {
+					for cell := 0; cell < 16; cell++ {
+						_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: player, GameId: gameID, Row: int32(cell / 4), Col: int32(cell % 4)})
+						if err != nil && status.Convert(err).Message() == "game is not in progress" {
+							return
+						}
+					}
+					if ctx.Err() != nil {
+						return
+					}
+				}
+			}(player)
+		}
+	}
+	wg.Wait()
+
+	for i := 0; i < numGames; i++ {
+		for _, userID := range []string{fmt.Sprintf("stress-x-%d", i), fmt.Sprintf("stress-o-%d", i)} {
+			stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: userID})
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), stats.TotalGames, userID)
+			assert.Equal(t, stats.TotalGames, stats.Wins+stats.Losses+stats.Draws, userID)
+		}
+	}
+}
+
+func TestAcceptance_MakeMove_RetriedFinishingMove(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	gameID := playXWins(t, ts, "retry-x", "retry-o", 3, 3)
+
+	// A client retrying the winning move after a lost response is refused
+	// and the win is not counted again
+	_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "retry-x", GameId: gameID, Row: 0, Col: 2})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "retry-x"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), stats.Wins)
+	assert.Equal(t, int32(1), stats.TotalGames)
+}
+
+func TestAcceptance_DeleteUserStats(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	playXWins(t, ts, "forget-x", "forget-o", 3, 3)
+
+	resp, err := ts.client.DeleteUserStats(ctx, &pb.DeleteUserStatsRequest{UserId: "forget-x"})
+	require.NoError(t, err)
+	assert.True(t, resp.Deleted)
+
+	stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "forget-x"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), stats.Wins)
+	assert.Equal(t, int32(0), stats.TotalGames)
+	for _, b := range stats.Brackets {
+		assert.Equal(t, int32(0), b.TotalGames)
+	}
+
+	// The opponent's record is untouched
+	stats, err = ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "forget-o"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), stats.Losses)
+
+	// Deleting again succeeds
+	resp, err = ts.client.DeleteUserStats(ctx, &pb.DeleteUserStatsRequest{UserId: "forget-x"})
+	require.NoError(t, err)
+	assert.False(t, resp.Deleted)
+
+	_, err = ts.client.DeleteUserStats(ctx, &pb.DeleteUserStatsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_ResetUserStats(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-admin", "admin")
+	tokens.Add("token-alice", "alice")
+	tokens.Add("token-bob", "bob")
+	ts := setupTestServer(t,
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+		server.WithAdmins([]string{"admin"}),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-admin")
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+	asBob := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-bob")
+
+	createResp, err := ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(asBob, &pb.JoinGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	for _, m := range []struct {
+		ctx      context.Context
+		row, col int32
+	}{{asAlice, 0, 0}, {asBob, 1, 0}, {asAlice, 0, 1}, {asBob, 1, 1}, {asAlice, 0, 2}} {
+		_, err = ts.client.MakeMove(m.ctx, &pb.MakeMoveRequest{GameId: gameID, Row: m.row, Col: m.col})
+		require.NoError(t, err)
+	}
+
+	// Players cannot wipe their own or anyone else's record
+	_, err = ts.client.ResetUserStats(asBob, &pb.ResetUserStatsRequest{UserId: "bob"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = ts.client.ResetUserStats(ctx, &pb.ResetUserStatsRequest{UserId: "bob"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = ts.client.ResetUserStats(asAdmin, &pb.ResetUserStatsRequest{UserId: "bob"})
+	require.NoError(t, err)
+
+	stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), stats.Losses)
+	assert.Equal(t, int32(0), stats.TotalGames)
+	for _, b := range stats.Brackets {
+		assert.Equal(t, int32(0), b.TotalGames)
+	}
+
+	stats, err = ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), stats.Wins)
+}
+
+func TestAcceptance_Chat(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "chat-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+
+	// Two subscribers, both past the initial state
+	var streams []pb.TicTacToeService_StreamGameUpdatesClient
+	for i := 0; i < 2; i++ {
+		stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, update.Type)
+		streams = append(streams, stream)
+	}
+
+	// Spectators may chat in open games
+	resp, err := ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "watcher", Text: " good luck "})
+	require.NoError(t, err)
+	assert.Equal(t, "watcher", resp.Chat.Sender)
+	assert.Equal(t, "good luck", resp.Chat.Text)
+	assert.NotZero(t, resp.Chat.SentAt)
+
+	// Concurrent messages arrive in the same order for every subscriber
+	const numMessages = 8
+	var wg sync.WaitGroup
+	for i := 0; i < numMessages; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "chat-x", Text: fmt.Sprintf("msg %d", i)})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	var orders [2][]string
+	for i, stream := range streams {
+		for n := 0; n < numMessages+1; n++ {
+			update, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, pb.UpdateType_UPDATE_TYPE_CHAT, update.Type)
+			assert.Nil(t, update.Game)
+			orders[i] = append(orders[i], update.Chat.Sender+": "+update.Chat.Text)
+		}
+	}
+	assert.Equal(t, "watcher: good luck", orders[0][0])
+	assert.Equal(t, orders[0], orders[1])
+
+	// State updates are typed as such
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "chat-o", GameId: gameID})
+	require.NoError(t, err)
+	update, err := streams[0].Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, update.Type)
+	assert.Nil(t, update.Chat)
+
+	// Empty and overlong messages are rejected
+	_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "chat-x", Text: "   "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "chat-x", Text: strings.Repeat("é", server.MaxChatMessageLength+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "chat-x", Text: strings.Repeat("é", server.MaxChatMessageLength)})
+	assert.NoError(t, err)
+
+	_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: "missing", UserId: "chat-x", Text: "hi"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAcceptance_Chat_PlayersOnly(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "private-x", PlayersOnlyChat: true})
+	require.NoError(t, err)
+	assert.True(t, createResp.Game.PlayersOnlyChat)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "private-o", GameId: gameID})
+	require.NoError(t, err)
+
+	_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "watcher", Text: "hi"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	for _, userID := range []string{"private-x", "private-o"} {
+		_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: userID, Text: "gg"})
+		assert.NoError(t, err, userID)
+	}
+}
+
+func TestAcceptance_StreamGameUpdates_Resume(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "resume-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+
+	streamCtx, disconnect := context.WithCancel(ctx)
+	stream, err := ts.client.StreamGameUpdates(streamCtx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	initial, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), initial.Sequence)
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "resume-o", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "resume-x", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+	var lastSeen uint64
+	for want := uint64(1); want <= 2; want++ {
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, want, update.Sequence)
+		lastSeen = update.Sequence
+	}
+	disconnect()
+
+	// Events while offline
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "resume-o", GameId: gameID, Row: 1, Col: 1})
+	require.NoError(t, err)
+	_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "resume-o", Text: "your move"})
+	require.NoError(t, err)
+
+	// Resuming replays exactly what was missed, then continues live
+	stream, err = ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID, LastSeenSequence: lastSeen})
+	require.NoError(t, err)
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), update.Sequence)
+	assert.False(t, update.Resync)
+	assert.Equal(t, pb.Mark_MARK_O, update.Game.Board[4])
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), update.Sequence)
+	assert.Equal(t, "your move", update.Chat.Text)
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "resume-x", GameId: gameID, Row: 0, Col: 1})
+	require.NoError(t, err)
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), update.Sequence)
+	assert.Equal(t, pb.Mark_MARK_X, update.Game.Board[1])
+}
+
+func TestAcceptance_StreamGameUpdates_Resync(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "resync-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "resync-o", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "resync-x", GameId: gameID, Row: 2, Col: 2})
+	require.NoError(t, err)
+
+	// Push the first updates out of the history
+	for i := 0; i < server.UpdateHistorySize; i++ {
+		_, err = ts.client.SendChatMessage(ctx, &pb.SendChatMessageRequest{GameId: gameID, UserId: "resync-o", Text: "spam"})
+		require.NoError(t, err)
+	}
+	latest := uint64(2 + server.UpdateHistorySize)
+
+	// Too old to replay, and ahead of anything sent: both get the full state
+	for _, lastSeen := range []uint64{1, latest + 10} {
+		stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID, LastSeenSequence: lastSeen})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		assert.True(t, update.Resync, "last seen %d", lastSeen)
+		assert.Equal(t, latest, update.Sequence)
+		assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, update.Type)
+		assert.Equal(t, pb.Mark_MARK_X, update.Game.Board[8])
+	}
+
+	// The oldest update still held can be resumed from
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID, LastSeenSequence: latest - server.UpdateHistorySize})
+	require.NoError(t, err)
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.False(t, update.Resync)
+	assert.Equal(t, latest-server.UpdateHistorySize+1, update.Sequence)
+}
+
+func TestAcceptance_GetServerStats(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	playXWins(t, ts, "ops-x", "ops-o", 3, 3)
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "ops-pending"})
+	require.NoError(t, err)
+	playing, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "ops-a"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "ops-b", GameId: playing.Game.GameId})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: playing.Game.GameId})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	resp, err := ts.client.GetServerStats(ctx, &pb.GetServerStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.TotalGames)
+	assert.Equal(t, int32(1), resp.PendingGames)
+	assert.Equal(t, int32(1), resp.InProgressGames)
+	assert.Equal(t, int32(1), resp.FinishedGames)
+	assert.Equal(t, int32(2), resp.Users)
+	assert.Equal(t, int32(1), resp.StreamSubscribers)
+	assert.NotZero(t, resp.GeneratedAt)
+}
+
+func TestAcceptance_GetServerStats_Cached(t *testing.T) {
+	ts := setupTestServer(t, server.WithServerStatsCache(time.Hour))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, err := ts.client.GetServerStats(ctx, &pb.GetServerStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), first.TotalGames)
+
+	// Within the TTL the cached counts are served
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "cached"})
+	require.NoError(t, err)
+	second, err := ts.client.GetServerStats(ctx, &pb.GetServerStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), second.TotalGames)
+	assert.Equal(t, first.GeneratedAt, second.GeneratedAt)
+}
+
+func TestAcceptance_ListPendingGames_PageToken(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var created []string
+	for i := 0; i < 5; i++ {
+		resp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: fmt.Sprintf("page-%d", i)})
+		require.NoError(t, err)
+		created = append(created, resp.Game.GameId)
+	}
+
+	first, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first.Games, 2)
+	require.NotEmpty(t, first.NextPageToken)
+	assert.Equal(t, int32(5), first.TotalCount)
+
+	// A game on the first page is joined; with an offset the next page would
+	// skip a game, with the token it picks up right where it left off
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "joiner", GameId: first.Games[0].GameId})
+	require.NoError(t, err)
+
+	seen := []string{first.Games[0].GameId, first.Games[1].GameId}
+	token := first.NextPageToken
+	for token != "" {
+		page, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{Limit: 2, PageToken: token})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), page.TotalCount)
+		for _, g := range page.Games {
+			seen = append(seen, g.GameId)
+		}
+		token = page.NextPageToken
+	}
+	assert.ElementsMatch(t, created, seen)
+	assert.Len(t, seen, 5)
+
+	// The offset path still works and hands out a token too
+	byOffset, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, byOffset.Games, 2)
+	require.NotEmpty(t, byOffset.NextPageToken)
+	rest, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{Limit: 2, PageToken: byOffset.NextPageToken})
+	require.NoError(t, err)
+	assert.Len(t, rest.Games, 1)
+	assert.Empty(t, rest.NextPageToken)
+
+	_, err = ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{PageToken: "not a token"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{PageToken: first.NextPageToken, Offset: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_GetGameHistory(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	won := playXWins(t, ts, "history-a", "history-b", 3, 3)
+	lost := playXWins(t, ts, "history-c", "history-a", 3, 3)
+
+	// An unfinished game is not history yet
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "history-a"})
+	require.NoError(t, err)
+
+	resp, err := ts.client.GetGameHistory(ctx, &pb.GetGameHistoryRequest{UserId: "history-a"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.TotalCount)
+	require.Len(t, resp.Games, 2)
+
+	results := map[string]*pb.GameHistoryEntry{}
+	for _, entry := range resp.Games {
+		results[entry.Game.GameId] = entry
+		assert.NotZero(t, entry.FinishedAt)
+	}
+	assert.Equal(t, pb.GameResult_GAME_RESULT_WIN, results[won].Result)
+	assert.Equal(t, "history-b", results[won].OpponentId)
+	assert.Equal(t, pb.GameResult_GAME_RESULT_LOSS, results[lost].Result)
+	assert.Equal(t, "history-c", results[lost].OpponentId)
+
+	page, err := ts.client.GetGameHistory(ctx, &pb.GetGameHistoryRequest{UserId: "history-a", Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Len(t, page.Games, 1)
+	assert.Equal(t, int32(2), page.TotalCount)
+
+	resp, err = ts.client.GetGameHistory(ctx, &pb.GetGameHistoryRequest{UserId: "nobody"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Games)
+
+	_, err = ts.client.GetGameHistory(ctx, &pb.GetGameHistoryRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_RenderBoard_DoubleWin(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "double-x", BoardSize: 5, WinLength: 3})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "double-o", GameId: gameID})
+	require.NoError(t, err)
+
+	// X's last move at (0, 2) completes the top row and the third column at once
+	xMoves := [][2]int32{{0, 0}, {0, 1}, {1, 2}, {2, 2}, {0, 2}}
+	oMoves := [][2]int32{{4, 0}, {4, 2}, {3, 4}, {2, 0}}
+	for i, m := range xMoves {
+		resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "double-x", GameId: gameID, Row: m[0], Col: m[1]})
+		require.NoError(t, err)
+		if i < len(oMoves) {
+			require.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, resp.Game.Status)
+			_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "double-o", GameId: gameID, Row: oMoves[i][0], Col: oMoves[i][1]})
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, resp.Game.Status)
+		}
+	}
+
+	resp, err := ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: gameID, Format: pb.RenderFormat_RENDER_FORMAT_SVG})
+	require.NoError(t, err)
+	assert.Equal(t, 5, strings.Count(resp.Content, `class="win"`), "shared corner is shaded once")
+	assert.Equal(t, 2, strings.Count(resp.Content, `class="strike"`))
+}
+
+func TestAcceptance_MaxActiveGames(t *testing.T) {
+	ts := setupTestServerWithStores(t, store.NewGameStore(4, store.WithMaxActiveGames(2)), store.NewStatsStore(4))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "busy"})
+	require.NoError(t, err)
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "busy"})
+	require.NoError(t, err)
+
+	// A third game, created or joined, is over the cap
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "busy"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	other, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "other"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "busy", GameId: other.Game.GameId})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Finishing a game frees its seat
+	gameID := first.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "rival", GameId: gameID})
+	require.NoError(t, err)
+	for i, m := range [][2]int32{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}} {
+		player := []string{"busy", "rival"}[i%2]
+		_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: player, GameId: gameID, Row: m[0], Col: m[1]})
+		require.NoError(t, err)
+	}
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "busy", GameId: other.Game.GameId})
+	require.NoError(t, err)
+}
+
+func TestAcceptance_ExportImportGame(t *testing.T) {
+	source := setupTestServer(t)
+	defer source.cleanup()
+	target := setupTestServer(t)
+	defer target.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := source.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", BoardSize: 4})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = source.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = source.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 1, Col: 2})
+	require.NoError(t, err)
+
+	exported, err := source.client.ExportGame(ctx, &pb.ExportGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Contains(t, exported.GameJson, `"id":"`+gameID+`"`)
+
+	imported, err := target.client.ImportGame(ctx, &pb.ImportGameRequest{GameJson: exported.GameJson})
+	require.NoError(t, err)
+	original, err := source.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, original.Game.PlayerXId, imported.Game.PlayerXId)
+	assert.Equal(t, original.Game.PlayerOId, imported.Game.PlayerOId)
+	assert.Equal(t, original.Game.Board, imported.Game.Board)
+	assert.Equal(t, pb.Mark_MARK_O, imported.Game.CurrentTurn)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, imported.Game.Status)
+	assert.Equal(t, original.Game.CreatedAt, imported.Game.CreatedAt)
+	assert.Equal(t, original.Game.UpdatedAt, imported.Game.UpdatedAt)
+
+	// The imported game plays on where the export left off
+	_, err = target.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+
+	_, err = target.client.ImportGame(ctx, &pb.ImportGameRequest{GameJson: exported.GameJson})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	truncated := strings.Replace(exported.GameJson, `"cells":["",`, `"cells":[`, 1)
+	_, err = target.client.ImportGame(ctx, &pb.ImportGameRequest{GameJson: truncated})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = source.client.ExportGame(ctx, &pb.ExportGameRequest{GameId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAcceptance_ExportImportGame_AdminOnly(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-admin", "admin")
+	tokens.Add("token-alice", "alice")
+	ts := setupTestServer(t,
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+		server.WithAdmins([]string{"admin"}),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-admin")
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+
+	createResp, err := ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+
+	_, err = ts.client.ExportGame(asAlice, &pb.ExportGameRequest{GameId: gameID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	exported, err := ts.client.ExportGame(asAdmin, &pb.ExportGameRequest{GameId: gameID})
+	require.NoError(t, err)
+
+	_, err = ts.client.ImportGame(asAlice, &pb.ImportGameRequest{GameJson: exported.GameJson})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAcceptance_StreamGameUpdates_Shutdown(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 1, Col: 1})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	ts.ticTacToe.Close()
+
+	// The open stream gets a final update and ends cleanly
+	final, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Server shutting down", final.Message)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, final.Game.Status)
+	assert.Equal(t, pb.Mark_MARK_X, final.Game.Board[4])
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// New streams are refused
+	refused, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = refused.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Nothing is left holding up a graceful stop
+	stopped := make(chan struct{})
+	go func() {
+		ts.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("GracefulStop did not return after Close")
+	}
+}
+
+func TestAcceptance_3DGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", Dimensions: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), createResp.Game.Dimensions)
+	assert.Len(t, createResp.Game.Board, 27)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	movesResp, err := ts.client.GetAvailableMoves(ctx, &pb.GetAvailableMovesRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Len(t, movesResp.Moves, 27)
+	assert.Equal(t, int32(2), movesResp.Moves[26].Layer)
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 0, Col: 0, Layer: 3})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Alice wins along a space diagonal while Bob stays on the bottom layer
+	var last *pb.MakeMoveResponse
+	for i, m := range [][3]int32{{0, 0, 0}, {0, 1, 0}, {1, 1, 1}, {0, 2, 0}, {2, 2, 2}} {
+		player := []string{"alice", "bob"}[i%2]
+		last, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: player, GameId: gameID, Row: m[0], Col: m[1], Layer: m[2]})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, last.Game.Status)
+	assert.Equal(t, pb.Mark_MARK_X, last.Game.Board[13])
+
+	boardResp, err := ts.client.GetGameBoard(ctx, &pb.GetGameBoardRequest{GameId: gameID})
+	require.NoError(t, err)
+	require.Len(t, boardResp.Rows, 9)
+	assert.Equal(t, " | |X", boardResp.Rows[8])
+	assert.Contains(t, boardResp.BoardDisplay, "Layer 2")
+
+	_, err = ts.client.RenderBoard(ctx, &pb.RenderBoardRequest{GameId: gameID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAcceptance_3DGame_Validation(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, req := range []*pb.CreateGameRequest{
+		{UserId: "alice", Dimensions: 4},
+		{UserId: "alice", Dimensions: 3, BoardSize: server.MaxCubeSize + 1},
+		{UserId: "alice", Dimensions: 3, Mode: pb.GameMode_GAME_MODE_GRAVITY},
+	} {
+		_, err := ts.client.CreateGame(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "request %v", req)
+	}
+
+	// Flat games report two dimensions and only have layer 0
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), createResp.Game.Dimensions)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: createResp.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: createResp.Game.GameId, Layer: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_ReplayGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	gameID := playXWins(t, ts, "alice", "bob", 3, 3)
+
+	stream, err := ts.client.ReplayGame(ctx, &pb.ReplayGameRequest{GameId: gameID, Speed: server.MaxReplaySpeed})
+	require.NoError(t, err)
+
+	var updates []*pb.GameUpdate
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		updates = append(updates, update)
+	}
+
+	// The empty board, then the board after each of the five moves
+	require.Len(t, updates, 6)
+	for _, cell := range updates[0].Game.Board {
+		assert.Equal(t, pb.Mark_MARK_EMPTY, cell)
+	}
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, updates[0].Game.Status)
+
+	assert.Equal(t, pb.Mark_MARK_X, updates[1].Game.Board[0])
+	assert.Equal(t, pb.Mark_MARK_O, updates[1].Game.CurrentTurn)
+	assert.Equal(t, pb.Mark_MARK_O, updates[2].Game.Board[3])
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, updates[4].Game.Status)
+	assert.Equal(t, "Move 3 of 5", updates[3].Message)
+
+	final, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, final.Game.Board, updates[5].Game.Board)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, updates[5].Game.Status)
+	assert.Equal(t, "Move 5 of 5: Player X wins!", updates[5].Message)
+}
+
+func TestAcceptance_ReplayGame_Errors(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	replayErr := func(req *pb.ReplayGameRequest) error {
+		stream, err := ts.client.ReplayGame(ctx, req)
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		return err
+	}
+
+	assert.Equal(t, codes.NotFound, status.Code(replayErr(&pb.ReplayGameRequest{GameId: "missing"})))
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(replayErr(&pb.ReplayGameRequest{GameId: gameID})))
+
+	// A game drawn by agreement before any move has nothing to replay
+	_, err = ts.client.OfferDraw(ctx, &pb.OfferDrawRequest{UserId: "alice", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.RespondDraw(ctx, &pb.RespondDrawRequest{UserId: "bob", GameId: gameID, Accept: true})
+	require.NoError(t, err)
+	err = replayErr(&pb.ReplayGameRequest{GameId: gameID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "no recorded history")
+
+	finishedID := playXWins(t, ts, "carol", "dave", 3, 3)
+	assert.Equal(t, codes.InvalidArgument, status.Code(replayErr(&pb.ReplayGameRequest{GameId: finishedID, Speed: -1})))
+	assert.Equal(t, codes.InvalidArgument, status.Code(replayErr(&pb.ReplayGameRequest{GameId: finishedID, Speed: server.MaxReplaySpeed + 1})))
+}
+
+func TestAcceptance_ReplayGame_Cancel(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	gameID := playXWins(t, ts, "alice", "bob", 3, 3)
+
+	// At normal speed the first move is a second away; cancelling ends the replay
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := ts.client.ReplayGame(ctx, &pb.ReplayGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	start := time.Now()
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestAcceptance_AbandonGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "alice", GameId: gameID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "carol", GameId: gameID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Streams see the abandonment as the final update and close
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	resp, err := ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_ABANDONED, resp.Game.Status)
+	assert.Equal(t, pb.Mark_MARK_O, resp.Game.AbandonedBy)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Player O abandoned the game", update.Message)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// By default abandoned games are left out of stats
+	for _, user := range []string{"alice", "bob"} {
+		stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: user})
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), stats.TotalGames, user)
+	}
+
+	history, err := ts.client.GetGameHistory(ctx, &pb.GetGameHistoryRequest{UserId: "alice"})
+	require.NoError(t, err)
+	require.Len(t, history.Games, 1)
+	assert.Equal(t, pb.GameResult_GAME_RESULT_ABANDONED, history.Games[0].Result)
+}
+
+func TestAcceptance_LeaveGame_Pending(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+
+	// Someone without a seat cannot leave the game
+	_, err = ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "bob", GameId: gameID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	resp, err := ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "alice", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_CANCELLED, resp.Game.Status)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_CANCELLED, update.Game.Status)
+	assert.Equal(t, "Game cancelled by its creator", update.Message)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// The cancelled game is gone
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	listResp, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), listResp.TotalCount)
+}
+
+func TestAcceptance_LeaveGame_FreesSeat(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	autoStart := false
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", AutoStart: &autoStart})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_READY, joinResp.Game.Status)
+
+	// bob leaving before the start frees his seat without cancelling the game
+	resp, err := ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_PENDING, resp.Game.Status)
+	assert.Empty(t, resp.Game.PlayerOId)
+	listResp, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), listResp.TotalCount)
+
+	joinResp, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "carol", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, "carol", joinResp.Game.PlayerOId)
+}
+
+func TestAcceptance_LeaveGame_Forfeit(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// The joiner leaves before any move: alice wins by forfeit
+	resp, err := ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, resp.Game.Status)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Player O left the game. Player X wins!", update.Message)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// A forfeit is an ordinary win and loss
+	aliceStats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), aliceStats.Wins)
+	bobStats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), bobStats.Losses)
+
+	// Finished games cannot be left
+	_, err = ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "alice", GameId: gameID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "alice", GameId: "nonexistent"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAcceptance_AbandonGame_LossPolicy(t *testing.T) {
+	ts := setupTestServer(t, server.WithAbandonPolicy(store.AbandonLoss))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	// Only the player who left is charged
+	bob, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), bob.Losses)
+	assert.Equal(t, int32(1), bob.TotalGames)
+	alice, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), alice.TotalGames)
+}
+
+func TestAcceptance_AnalyzePosition(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+	const (
+		E = pb.Mark_MARK_EMPTY
+		X = pb.Mark_MARK_X
+		O = pb.Mark_MARK_O
+	)
+
+	// X to move can win at once on the top row
+	resp, err := ts.client.AnalyzePosition(ctx, &pb.AnalyzePositionRequest{
+		Board: []pb.Mark{
+			X, E, X,
+			O, O, E,
+			E, E, E,
+		},
+		Turn: X,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), resp.BestMove.Row)
+	assert.Equal(t, int32(1), resp.BestMove.Col)
+	assert.Equal(t, pb.GameResult_GAME_RESULT_WIN, resp.Evaluation)
+
+	// The empty board is a draw with perfect play
+	resp, err = ts.client.AnalyzePosition(ctx, &pb.AnalyzePositionRequest{
+		Board: make([]pb.Mark, 9),
+		Turn:  X,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameResult_GAME_RESULT_DRAW, resp.Evaluation)
+	assert.True(t, resp.Exact)
+
+	// Larger boards get a depth-limited estimate
+	cells := make([]pb.Mark, 49)
+	cells[24] = X
+	resp, err = ts.client.AnalyzePosition(ctx, &pb.AnalyzePositionRequest{
+		BoardSize: 7,
+		WinLength: 4,
+		Board:     cells,
+		Turn:      O,
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Exact)
+	assert.LessOrEqual(t, resp.Depth, int32(server.MaxAnalysisDepth))
+	assert.NotEqual(t, int32(24), resp.BestMove.Row*7+resp.BestMove.Col)
+
+	// Nothing is stored
+	statsResp, err := ts.client.GetServerStats(ctx, &pb.GetServerStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), statsResp.TotalGames)
+
+	// Invalid positions
+	for _, req := range []*pb.AnalyzePositionRequest{
+		{Board: make([]pb.Mark, 8), Turn: X},
+		{Board: []pb.Mark{X, E, E, E, E, E, E, E, E}, Turn: X},
+		{Board: make([]pb.Mark, 9)},
+		{BoardSize: 4, WinLength: 5, Board: make([]pb.Mark, 16), Turn: X},
+	} {
+		_, err = ts.client.AnalyzePosition(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", req)
+	}
+	_, err = ts.client.AnalyzePosition(ctx, &pb.AnalyzePositionRequest{
+		Board: []pb.Mark{X, X, X, O, O, E, E, E, E},
+		Turn:  O,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_ThreePlayerGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", NumPlayers: 4})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", NumPlayers: 3, Misere: true})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{
+		UserId:     "alice",
+		BoardSize:  4,
+		WinLength:  3,
+		NumPlayers: 3,
+	})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.Equal(t, int32(3), createResp.Game.NumPlayers)
+
+	// The game waits for both other seats to fill
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_PENDING, joinResp.Game.Status)
+	joinResp, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "carol", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, joinResp.Game.Status)
+	assert.Equal(t, "carol", joinResp.Game.PlayerTriangleId)
+
+	// Draw offers need exactly two players
+	_, err = ts.client.OfferDraw(ctx, &pb.OfferDrawRequest{UserId: "alice", GameId: gameID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	move := func(userID string, row, col int32) *pb.Game {
+		t.Helper()
+		resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: userID, GameId: gameID, Row: row, Col: col})
+		require.NoError(t, err)
+		return resp.Game
+	}
+	move("alice", 0, 0)
+	g := move("bob", 1, 0)
+	assert.Equal(t, pb.Mark_MARK_TRIANGLE, g.CurrentTurn)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 3, Col: 0})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	g = move("carol", 3, 1)
+	assert.Equal(t, pb.Mark_MARK_TRIANGLE, g.Board[13])
+	assert.Equal(t, pb.Mark_MARK_X, g.CurrentTurn)
+	move("alice", 0, 3)
+	move("bob", 1, 3)
+	move("carol", 3, 2)
+	move("alice", 2, 1)
+	move("bob", 0, 1)
+	g = move("carol", 3, 3)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_TRIANGLE_WON, g.Status)
+
+	// The winner beats both other players
+	for userID, want := range map[string][2]int32{"alice": {0, 1}, "bob": {0, 1}, "carol": {1, 0}} {
+		stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: userID})
+		require.NoError(t, err)
+		assert.Equal(t, want, [2]int32{stats.Wins, stats.Losses}, userID)
+	}
+}
+
+func TestAcceptance_PendingGameExpires(t *testing.T) {
+	ts := setupTestServer(t, server.WithPendingGameTTL(100*time.Millisecond))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	startedResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "carol"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "dave", GameId: startedResp.Game.GameId})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// The creator hears of the expiry before the stream ends
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_CANCELLED, update.Game.Status)
+	assert.Equal(t, "Game expired, no opponent joined", update.Message)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Games that found an opponent stay
+	getResp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: startedResp.Game.GameId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, getResp.Game.Status)
+}
+
+func TestAcceptance_RectangularBoard(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, req := range []*pb.CreateGameRequest{
+		{UserId: "alice", Rows: 3},
+		{UserId: "alice", Rows: 3, Cols: 4, BoardSize: 3},
+		{UserId: "alice", Rows: 2, Cols: 4},
+		{UserId: "alice", Rows: 3, Cols: 4, WinLength: 5},
+		{UserId: "alice", Rows: 3, Cols: 4, Dimensions: 3},
+	} {
+		_, err := ts.client.CreateGame(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", req)
+	}
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", Rows: 3, Cols: 4, WinLength: 4})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.Equal(t, int32(3), createResp.Game.Rows)
+	assert.Equal(t, int32(4), createResp.Game.Cols)
+	assert.Equal(t, int32(4), createResp.Game.BoardSize)
+	assert.Len(t, createResp.Game.Board, 12)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	// X takes the bottom row while O plays the middle one
+	var g *pb.Game
+	for col := int32(0); col < 4; col++ {
+		resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 2, Col: col})
+		require.NoError(t, err)
+		g = resp.Game
+		if col < 3 {
+			_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 1, Col: col})
+			require.NoError(t, err)
+		}
+	}
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, g.Status)
+
+	boardResp, err := ts.client.GetGameBoard(ctx, &pb.GetGameBoardRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{" | | | ", "O|O|O| ", "X|X|X|X"}, boardResp.Rows)
+}
+
+func TestAcceptance_EarlyDrawDetection(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", EarlyDrawDetection: true})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.True(t, createResp.Game.EarlyDrawDetection)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	// After the eighth move no line is open to either player
+	var g *pb.Game
+	for i, cell := range [][2]int32{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {2, 0}, {2, 1}, {2, 2}} {
+		userID := "alice"
+		if i%2 == 1 {
+			userID = "bob"
+		}
+		resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: userID, GameId: gameID, Row: cell[0], Col: cell[1]})
+		require.NoError(t, err)
+		g = resp.Game
+	}
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_DRAW, g.Status)
+	assert.Equal(t, pb.Mark_MARK_EMPTY, g.Board[5])
+
+	stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), stats.Draws)
+}
+
+func TestAcceptance_MoveHints(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", Hints: true, Mode: pb.GameMode_GAME_MODE_GRAVITY})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", Hints: true})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.True(t, createResp.Game.Hints)
+
+	// Both players and an anonymous spectator watch the game
+	open := func(userID string) pb.TicTacToeService_StreamGameUpdatesClient {
+		stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID, UserId: userID})
+		require.NoError(t, err)
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "Connected to game", update.Message)
+		return stream
+	}
+	alice, bob, spectator := open("alice"), open("bob"), open("")
+	recv := func(stream pb.TicTacToeService_StreamGameUpdatesClient) *pb.GameUpdate {
+		update, err := stream.Recv()
+		require.NoError(t, err)
+		return update
+	}
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	// X is on turn once the game starts, so only alice gets a hint
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, recv(alice).Type)
+	hint := recv(alice)
+	require.Equal(t, pb.UpdateType_UPDATE_TYPE_HINT, hint.Type)
+	require.NotNil(t, hint.Hint)
+	assert.Equal(t, pb.Mark_MARK_X, hint.Game.CurrentTurn)
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: hint.Hint.Row, Col: hint.Hint.Col})
+	require.NoError(t, err)
+
+	// Now bob is on turn; his hint names an empty cell
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, recv(bob).Type)
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, recv(bob).Type)
+	hint = recv(bob)
+	require.Equal(t, pb.UpdateType_UPDATE_TYPE_HINT, hint.Type)
+	assert.Equal(t, pb.Mark_MARK_EMPTY, hint.Game.Board[hint.Hint.Row*3+hint.Hint.Col])
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, recv(alice).Type)
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: hint.Hint.Row, Col: hint.Hint.Col})
+	require.NoError(t, err)
+
+	// The spectator only ever sees state updates, and alice gets no hint meant for bob
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, recv(spectator).Type)
+	}
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_STATE, recv(alice).Type)
+	assert.Equal(t, pb.UpdateType_UPDATE_TYPE_HINT, recv(alice).Type)
+}
+
+func TestAcceptance_ListActiveGames(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Three started games, one pending and one private game in progress
+	var started []string
+	for i := 0; i < 3; i++ {
+		createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: fmt.Sprintf("x%d", i), BoardSize: int32(3 + i)})
+		require.NoError(t, err)
+		_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: fmt.Sprintf("o%d", i), GameId: createResp.Game.GameId})
+		require.NoError(t, err)
+		started = append(started, createResp.Game.GameId)
+	}
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "waiting"})
+	require.NoError(t, err)
+	privateResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "secret", IsPrivate: true})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "friend", GameId: privateResp.Game.GameId, JoinCode: privateResp.JoinCode})
+	require.NoError(t, err)
+
+	// A move makes the first game the most recently updated
+	time.Sleep(10 * time.Millisecond)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "x0", GameId: started[0], Row: 1, Col: 1})
+	require.NoError(t, err)
+
+	resp, err := ts.client.ListActiveGames(ctx, &pb.ListActiveGamesRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.TotalCount)
+	require.Len(t, resp.Games, 2)
+	assert.Equal(t, started[0], resp.Games[0].GameId)
+	assert.Equal(t, "x0", resp.Games[0].PlayerXId)
+	assert.Equal(t, "o0", resp.Games[0].PlayerOId)
+	assert.Equal(t, int32(3), resp.Games[0].BoardSize)
+
+	next, err := ts.client.ListActiveGames(ctx, &pb.ListActiveGamesRequest{Limit: 2, Offset: 2})
+	require.NoError(t, err)
+	require.Len(t, next.Games, 1)
+
+	var listed []string
+	for _, g := range append(resp.Games, next.Games...) {
+		assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, g.Status)
+		listed = append(listed, g.GameId)
+	}
+	assert.ElementsMatch(t, started, listed)
+}
+
+func TestAcceptance_ArchiveSeason(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-admin", "admin")
+	tokens.Add("token-alice", "alice")
+	tokens.Add("token-bob", "bob")
+	ts := setupTestServer(t,
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+		server.WithAdmins([]string{"admin"}),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-admin")
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+	asBob := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-bob")
+
+	createResp, err := ts.client.CreateGame(asAlice, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(asBob, &pb.JoinGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	for _, m := range []struct {
+		ctx      context.Context
+		row, col int32
+	}{{asAlice, 0, 0}, {asBob, 1, 0}, {asAlice, 0, 1}, {asBob, 1, 1}, {asAlice, 0, 2}} {
+		_, err = ts.client.MakeMove(m.ctx, &pb.MakeMoveRequest{GameId: gameID, Row: m.row, Col: m.col})
+		require.NoError(t, err)
+	}
+
+	_, err = ts.client.ArchiveSeason(asAlice, &pb.ArchiveSeasonRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	resp, err := ts.client.ArchiveSeason(asAdmin, &pb.ArchiveSeasonRequest{})
+	require.NoError(t, err)
+	assert.NotZero(t, resp.ArchivedAt)
+	require.Len(t, resp.Stats, 2)
+	assert.Equal(t, "alice", resp.Stats[0].UserId)
+	assert.Equal(t, int32(1), resp.Stats[0].Wins)
+	assert.Equal(t, "bob", resp.Stats[1].UserId)
+	assert.Equal(t, int32(1), resp.Stats[1].Losses)
+
+	// The new season starts from zero
+	stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), stats.TotalGames)
+	leaderboard, err := ts.client.GetLeaderboard(ctx, &pb.GetLeaderboardRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, leaderboard.Entries)
+}
+
+func TestAcceptance_Ping(t *testing.T) {
+	tokens := store.NewTokenStore()
+	ts := setupTestServer(t,
+		server.WithVersion("v1.2.3"),
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Ping needs no token
+	before := time.Now().UnixMilli()
+	resp, err := ts.client.Ping(ctx, &pb.PingRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", resp.Version)
+	assert.GreaterOrEqual(t, resp.ServerTimeMs, before)
+	assert.LessOrEqual(t, resp.ServerTimeMs, time.Now().UnixMilli())
+
+	// Untagged builds report "dev"
+	plain := setupTestServer(t)
+	defer plain.cleanup()
+	resp, err = plain.client.Ping(ctx, &pb.PingRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", resp.Version)
+}
+
+func TestAcceptance_ErrorInfoDetails(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// errorInfo decodes the ErrorInfo detail of a status error
+	errorInfo := func(err error) *errdetails.ErrorInfo {
+		t.Helper()
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		for _, detail := range st.Details() {
+			if info, ok := detail.(*errdetails.ErrorInfo); ok {
+				return info
+			}
+		}
+		require.Fail(t, "no ErrorInfo detail", "error: %v", err)
+		return nil
+	}
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 1, Col: 1})
+	require.NoError(t, err)
+
+	// Both are InvalidArgument; the reason tells them apart
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 1, Col: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	info := errorInfo(err)
+	assert.Equal(t, server.ReasonCellOccupied, info.Reason)
+	assert.Equal(t, server.ErrorDomain, info.Domain)
+	assert.Equal(t, gameID, info.Metadata["game_id"])
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 5, Col: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, server.ReasonInvalidPosition, errorInfo(err).Reason)
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 0, Col: 0})
+	assert.Equal(t, server.ReasonNotYourTurn, errorInfo(err).Reason)
+
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	info = errorInfo(err)
+	assert.Equal(t, server.ReasonGameNotFound, info.Reason)
+	assert.Equal(t, "missing", info.Metadata["game_id"])
+
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{})
+	info = errorInfo(err)
+	assert.Equal(t, server.ReasonFieldRequired, info.Reason)
+	assert.Equal(t, "game_id", info.Metadata["field"])
+}
+
+func TestAcceptance_MakeMove_ExpectedMoveNumber(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	seen := joinResp.Game.MoveCount
+	assert.Equal(t, int32(0), seen)
+
+	// Two tabs submit a move from the same view; only the first lands
+	resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 0, Col: 0, ExpectedMoveNumber: &seen})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.Game.MoveCount)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 2, Col: 2, ExpectedMoveNumber: &seen})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// Leaving the field unset keeps the old behavior
+	resp, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 1, Col: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.Game.MoveCount)
+	assert.Equal(t, pb.Mark_MARK_EMPTY, resp.Game.Board[8])
+}
+
+func TestAcceptance_CreateAnonymousUser(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Disabled unless configured
+	plain := setupTestServer(t)
+	defer plain.cleanup()
+	_, err := plain.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Without auth the ID alone is enough
+	open := setupTestServer(t, server.WithAnonymousUsers("guest-"))
+	defer open.cleanup()
+	resp, err := open.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(resp.UserId, "guest-"), resp.UserId)
+	assert.Empty(t, resp.Token)
+	assert.InDelta(t, time.Now().Add(server.DefaultAnonymousUserTTL).Unix(), resp.ExpiresAt, 5)
+
+	// Identities are capped, and each caller is rate limited
+	capped := setupTestServer(t, server.WithAnonymousUsers("capped-"), server.WithAnonymousUserLimits(time.Hour, 1))
+	defer capped.cleanup()
+	_, err = capped.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	require.NoError(t, err)
+	_, err = capped.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	limited := setupTestServer(t, server.WithAnonymousUsers("limited-"), server.WithAnonymousUserRateLimit(ratelimit.New(0.001, 1)))
+	defer limited.cleanup()
+	_, err = limited.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	require.NoError(t, err)
+	_, err = limited.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// With auth the issued token acts as the new user
+	tokens := store.NewTokenStore()
+	tokens.Add("token-bob", "bob")
+	ts := setupTestServer(t,
+		server.WithAnonymousUsers("anon-"),
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+	)
+	defer ts.cleanup()
+
+	anon, err := ts.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	require.NoError(t, err)
+	require.NotEmpty(t, anon.Token)
+	other, err := ts.client.CreateAnonymousUser(ctx, &pb.CreateAnonymousUserRequest{})
+	require.NoError(t, err)
+	assert.NotEqual(t, anon.UserId, other.UserId)
+	assert.NotEqual(t, anon.Token, other.Token)
+
+	asAnon := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+anon.Token)
+	asBob := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-bob")
+	createResp, err := ts.client.CreateGame(asAnon, &pb.CreateGameRequest{})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.Equal(t, anon.UserId, createResp.Game.PlayerXId)
+
+	// The anonymous user's results count like anyone's
+	_, err = ts.client.JoinGame(asBob, &pb.JoinGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	for _, m := range []struct {
+		ctx      context.Context
+		row, col int32
+	}{{asAnon, 0, 0}, {asBob, 1, 0}, {asAnon, 0, 1}, {asBob, 1, 1}, {asAnon, 0, 2}} {
+		_, err = ts.client.MakeMove(m.ctx, &pb.MakeMoveRequest{GameId: gameID, Row: m.row, Col: m.col})
+		require.NoError(t, err)
+	}
+	stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: anon.UserId})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), stats.Wins)
+}
+
+func TestAcceptance_StreamGameUpdates_FinishedGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "finished-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "finished-o", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "finished-o", GameId: gameID})
+	require.NoError(t, err)
+
+	// Streaming a finished game sends its final state, then ends instead of
+	// waiting for updates that will never come
+	streamCtx, streamCancel := context.WithTimeout(ctx, time.Second)
+	defer streamCancel()
+	stream, err := ts.client.StreamGameUpdates(streamCtx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	initial, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_ABANDONED, initial.Game.Status)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// So does resuming after the final update
+	stream, err = ts.client.StreamGameUpdates(streamCtx, &pb.StreamGameUpdatesRequest{GameId: gameID, LastSeenSequence: initial.Sequence})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestAcceptance_ThinkTime(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "think-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "think-o", GameId: gameID})
+	require.NoError(t, err)
+	assert.NotZero(t, joinResp.Game.StartedAt)
+
+	// X takes its time over every move; O answers at once
+	var last *pb.Game
+	moves := []struct {
+		player   string
+		row, col int32
+	}{{"think-x", 0, 0}, {"think-o", 1, 0}, {"think-x", 0, 1}, {"think-o", 1, 1}, {"think-x", 0, 2}}
+	for _, move := range moves {
+		if move.player == "think-x" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: move.player, GameId: gameID, Row: move.row, Col: move.col})
+		require.NoError(t, err)
+		last = resp.Game
+	}
+	require.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, last.Status)
+	assert.GreaterOrEqual(t, last.ThinkTimeXMs, int64(60))
+	assert.Less(t, last.ThinkTimeOMs, last.ThinkTimeXMs)
+
+	// Each player's history shows their own think time
+	for _, player := range []string{"think-x", "think-o"} {
+		resp, err := ts.client.GetGameHistory(ctx, &pb.GetGameHistoryRequest{UserId: player})
+		require.NoError(t, err)
+		require.Len(t, resp.Games, 1)
+		want := last.ThinkTimeXMs
+		if player == "think-o" {
+			want = last.ThinkTimeOMs
+		}
+		assert.Equal(t, want, resp.Games[0].ThinkTimeMs, player)
+	}
+}
+
+func TestAcceptance_StartGame(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	autoStart := false
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "ready-x", AutoStart: &autoStart})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.True(t, createResp.Game.ReadyCheck)
+
+	// Starting before everyone has joined is a precondition failure
+	_, err = ts.client.StartGame(ctx, &pb.StartGameRequest{UserId: "ready-x", GameId: gameID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "ready-o", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_READY, joinResp.Game.Status)
+	assert.Zero(t, joinResp.Game.StartedAt)
+
+	// No moves until both players have started the game
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "ready-x", GameId: gameID, Row: 0, Col: 0})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = ts.client.StartGame(ctx, &pb.StartGameRequest{UserId: "spectator", GameId: gameID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	startResp, err := ts.client.StartGame(ctx, &pb.StartGameRequest{UserId: "ready-o", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_READY, startResp.Game.Status)
+	assert.Equal(t, []string{"ready-o"}, startResp.Game.ReadyPlayerIds)
+
+	startResp, err = ts.client.StartGame(ctx, &pb.StartGameRequest{UserId: "ready-x", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, startResp.Game.Status)
+	assert.NotZero(t, startResp.Game.StartedAt)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "ready-x", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+
+	// Auto-start stays the default
+	createResp, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "ready-x"})
+	require.NoError(t, err)
+	joinResp, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "ready-o", GameId: createResp.Game.GameId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, joinResp.Game.Status)
+	_, err = ts.client.StartGame(ctx, &pb.StartGameRequest{UserId: "ready-x", GameId: createResp.Game.GameId})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAcceptance_JoinGame_UserIDPolicy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// By default look-alike IDs are different users
+	ts := setupTestServer(t)
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "Alice"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "alice", GameId: createResp.Game.GameId})
+	require.NoError(t, err)
+	ts.cleanup()
+
+	ts = setupTestServer(t, server.WithUserIDPolicy(server.UserIDFold))
+	defer ts.cleanup()
+	createResp, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "Alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	for _, userID := range []string{"alice", " ALICE", "Alice\t"} {
+		_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: userID, GameId: gameID})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%q", userID)
+	}
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, joinResp.Game.Status)
+}
+
+func TestAcceptance_MaxGameDuration(t *testing.T) {
+	ts := setupTestServer(t, server.WithMaxGameDuration(100*time.Millisecond))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pendingResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "carol"})
+	require.NoError(t, err)
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "alice", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// bob never moves, so the game is abandoned in his name
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_ABANDONED, update.Game.Status)
+	assert.Equal(t, pb.Mark_MARK_O, update.Game.AbandonedBy)
+	assert.Contains(t, update.Message, "time limit")
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 1, Col: 1})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Games waiting for an opponent have not started, so they are left alone
+	getResp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: pendingResp.Game.GameId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_PENDING, getResp.Game.Status)
+}
+
+func TestAcceptance_GetGame_CurrentTurnUserID(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The creator plays O, so the joiner moves first
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "alice", CreatorMark: pb.Mark_MARK_O})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	assert.Empty(t, createResp.Game.CurrentTurnUserId)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "bob", GameId: gameID})
+	require.NoError(t, err)
+
+	getGame := func() *pb.Game {
+		t.Helper()
+		resp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+		require.NoError(t, err)
+		return resp.Game
+	}
+	g := getGame()
+	assert.Equal(t, g.PlayerXId, g.CurrentTurnUserId)
+	assert.Equal(t, "bob", g.CurrentTurnUserId)
+
+	moves := []struct {
+		player   string
+		row, col int32
+	}{{"bob", 0, 0}, {"alice", 1, 1}, {"bob", 0, 1}, {"alice", 2, 2}}
+	for _, move := range moves {
+		_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: move.player, GameId: gameID, Row: move.row, Col: move.col})
+		require.NoError(t, err)
+		g = getGame()
+		want := g.PlayerXId
+		if g.CurrentTurn == pb.Mark_MARK_O {
+			want = g.PlayerOId
+		}
+		assert.Equal(t, want, g.CurrentTurnUserId)
+		assert.NotEqual(t, move.player, g.CurrentTurnUserId)
+	}
+
+	// Nobody is on turn once the game is over
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "bob", GameId: gameID, Row: 0, Col: 2})
+	require.NoError(t, err)
+	g = getGame()
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, g.Status)
+	assert.Empty(t, g.CurrentTurnUserId)
+}
+
+func TestAcceptance_GetOutcomeStats(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	playXWins(t, ts, "odds-x1", "odds-o1", 3, 3)
+	playXWins(t, ts, "odds-x2", "odds-o2", 3, 3)
+	playXWins(t, ts, "odds-x3", "odds-o3", 4, 3)
+
+	// A forfeit is not decided on the board, so it is not counted
+	left, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "odds-quitter"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "odds-stayer", GameId: left.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.LeaveGame(ctx, &pb.LeaveGameRequest{UserId: "odds-quitter", GameId: left.Game.GameId})
+	require.NoError(t, err)
+
+	// Nor are games that did not start here from an empty board: one set up
+	// from a position, and one imported part way through
+	const (
+		E = pb.Mark_MARK_EMPTY
+		X = pb.Mark_MARK_X
+		O = pb.Mark_MARK_O
+	)
+	posed, err := ts.client.CreateGameFromPosition(ctx, &pb.CreateGameFromPositionRequest{
+		Board:     []pb.Mark{X, X, E, O, O, E, E, E, E},
+		Turn:      X,
+		PlayerXId: "odds-posed-x",
+		PlayerOId: "odds-posed-o",
+	})
+	require.NoError(t, err)
+	moveResp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "odds-posed-x", GameId: posed.Game.GameId, Row: 0, Col: 2})
+	require.NoError(t, err)
+	require.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, moveResp.Game.Status)
+
+	source := setupTestServer(t)
+	defer source.cleanup()
+	moved, err := source.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "odds-moved-x"})
+	require.NoError(t, err)
+	_, err = source.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "odds-moved-o", GameId: moved.Game.GameId})
+	require.NoError(t, err)
+	for _, move := range []struct {
+		player   string
+		row, col int32
+	}{{"odds-moved-x", 0, 0}, {"odds-moved-o", 1, 0}, {"odds-moved-x", 0, 1}, {"odds-moved-o", 1, 1}} {
+		_, err = source.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: move.player, GameId: moved.Game.GameId, Row: move.row, Col: move.col})
+		require.NoError(t, err)
+	}
+	exported, err := source.client.ExportGame(ctx, &pb.ExportGameRequest{GameId: moved.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.ImportGame(ctx, &pb.ImportGameRequest{GameJson: exported.GameJson})
+	require.NoError(t, err)
+	moveResp, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "odds-moved-x", GameId: moved.Game.GameId, Row: 0, Col: 2})
+	require.NoError(t, err)
+	require.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, moveResp.Game.Status)
+
+	resp, err := ts.client.GetOutcomeStats(ctx, &pb.GetOutcomeStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), resp.BoardSize)
+	assert.Equal(t, int32(3), resp.WinLength)
+	assert.Equal(t, int64(2), resp.XWins)
+	assert.Equal(t, int64(2), resp.TotalGames)
+	assert.InDelta(t, 100.0, resp.XWinPercent, 0.001)
+
+	resp, err = ts.client.GetOutcomeStats(ctx, &pb.GetOutcomeStatsRequest{BoardSize: 4, WinLength: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.TotalGames)
+
+	// A configuration nobody has finished a game on reports zeros
+	resp, err = ts.client.GetOutcomeStats(ctx, &pb.GetOutcomeStatsRequest{BoardSize: 5})
+	require.NoError(t, err)
+	assert.Zero(t, resp.TotalGames)
+	assert.Zero(t, resp.XWinPercent)
+
+	_, err = ts.client.GetOutcomeStats(ctx, &pb.GetOutcomeStatsRequest{BoardSize: 3, WinLength: 4})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_CreateGame_RandomStart(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "coin-o", RandomStart: true, CreatorMark: pb.Mark_MARK_O})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "coin-a", RandomStart: true})
+	require.NoError(t, err)
+	assert.True(t, createResp.Game.RandomStart)
+	assert.Equal(t, "coin-a", createResp.Game.PlayerXId)
+
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "coin-b", GameId: createResp.Game.GameId})
+	require.NoError(t, err)
+	joined := joinResp.Game
+	assert.ElementsMatch(t, []string{"coin-a", "coin-b"}, []string{joined.PlayerXId, joined.PlayerOId})
+	assert.Equal(t, joined.PlayerXId, joined.CurrentTurnUserId)
+
+	// Whoever drew X moves first
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: joined.PlayerOId, GameId: joined.GameId, Row: 0, Col: 0})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: joined.PlayerXId, GameId: joined.GameId, Row: 0, Col: 0})
+	require.NoError(t, err)
+}
+
+func TestAcceptance_GetGame_IfVersion(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	createResp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "poll-x"})
+	require.NoError(t, err)
+	gameID := createResp.Game.GameId
+	version := createResp.Game.Version
+	assert.Equal(t, int64(1), version)
+
+	var header metadata.MD
+	resp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID, IfVersion: version}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.True(t, resp.NotModified)
+	assert.Nil(t, resp.Game)
+	assert.Equal(t, []string{`"1"`}, header.Get(server.ETagHeader))
+
+	// Every join and move moves the version on
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "poll-o", GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, version+1, joinResp.Game.Version)
+	moveResp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "poll-x", GameId: gameID, Row: 0, Col: 0})
+	require.NoError(t, err)
+	assert.Equal(t, version+2, moveResp.Game.Version)
+
+	resp, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID, IfVersion: version})
+	require.NoError(t, err)
+	assert.False(t, resp.NotModified)
+	require.NotNil(t, resp.Game)
+	assert.Equal(t, version+2, resp.Game.Version)
+}
+
+func TestAcceptance_MaxGames(t *testing.T) {
+	ts := setupTestServerWithStores(t, store.NewGameStore(4, store.WithMaxGames(2)), store.NewStatsStore(4))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	waiting, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "cap-a"})
+	require.NoError(t, err)
+	stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: waiting.Game.GameId})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	playing, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "cap-b"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "cap-c", GameId: playing.Game.GameId})
+	require.NoError(t, err)
+
+	// The pending game makes room, and its watchers hear it was cancelled
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "cap-d"})
+	require.NoError(t, err)
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_CANCELLED, update.Game.Status)
+	assert.Contains(t, update.Message, "make room")
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: waiting.Game.GameId})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: playing.Game.GameId})
+	require.NoError(t, err)
+}
+
+func TestAcceptance_MaxPendingGames(t *testing.T) {
+	ts := setupTestServerWithStores(t, store.NewGameStore(4, store.WithMaxPendingGames(1)), store.NewStatsStore(4))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	waiting, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "pool-a"})
+	require.NoError(t, err)
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "pool-b"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Once the waiting game is joined there is room again
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "pool-c", GameId: waiting.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "pool-b"})
+	require.NoError(t, err)
+}
+
+func TestAcceptance_WatchLeaderboard(t *testing.T) {
+	ts := setupTestServer(t, server.WithLeaderboardDebounce(10*time.Millisecond))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	stream, err := ts.client.WatchLeaderboard(watchCtx, &pb.WatchLeaderboardRequest{Limit: 1})
+	require.NoError(t, err)
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Empty(t, update.Entries)
+
+	playXWins(t, ts, "watch-a", "watch-b", 3, 3)
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, update.Entries, 1)
+	assert.Equal(t, "watch-a", update.Entries[0].UserId)
+	assert.Equal(t, int32(1), update.Entries[0].Wins)
+
+	// A draw between players below the top entry changes nothing watched
+	drawn, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "watch-c"})
+	require.NoError(t, err)
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "watch-d", GameId: drawn.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.OfferDraw(ctx, &pb.OfferDrawRequest{UserId: "watch-c", GameId: drawn.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.RespondDraw(ctx, &pb.RespondDrawRequest{UserId: "watch-d", GameId: drawn.Game.GameId, Accept: true})
+	require.NoError(t, err)
+
+	// So the next update is the top player's second win
+	playXWins(t, ts, "watch-a", "watch-b", 3, 3)
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, update.Entries, 1)
+	assert.Equal(t, int32(2), update.Entries[0].Wins)
+	assert.Equal(t, int32(4), update.TotalCount)
+
+	stopWatching()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+func TestAcceptance_JoinGame_Race(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "race-host"})
+	require.NoError(t, err)
+
+	codesSeen := make(chan codes.Code, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{
+				UserId: fmt.Sprintf("race-joiner-%d", i),
+				GameId: created.Game.GameId,
+			})
+			codesSeen <- status.Code(err)
+		}(i)
+	}
+	wg.Wait()
+	close(codesSeen)
+
+	// Exactly one joiner takes the seat; the rest are told to look elsewhere
+	joined := 0
+	for code := range codesSeen {
+		switch code {
+		case codes.OK:
+			joined++
+		case codes.Aborted:
+		default:
+			t.Errorf("unexpected code %v", code)
+		}
+	}
+	assert.Equal(t, 1, joined)
+}
+
+func TestAcceptance_SpectatorStreamLifetime(t *testing.T) {
+	ts := setupTestServer(t, server.WithSpectatorStreamLifetime(50*time.Millisecond))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "lifetime-x"})
+	require.NoError(t, err)
+	gameID := created.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "lifetime-o", GameId: gameID})
+	require.NoError(t, err)
+
+	streams := make(map[string]pb.TicTacToeService_StreamGameUpdatesClient)
+	for _, userID := range []string{"lifetime-x", "lifetime-o", "", "lifetime-watcher"} {
+		stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID, UserId: userID})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.NoError(t, err)
+		streams[userID] = stream
+	}
+
+	// Both spectators, named or not, are sent off once the lifetime elapses
+	for _, userID := range []string{"", "lifetime-watcher"} {
+		update, err := streams[userID].Recv()
+		require.NoError(t, err)
+		assert.Equal(t, "Spectator session expired", update.Message)
+		assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, update.Game.Status)
+		_, err = streams[userID].Recv()
+		assert.Equal(t, io.EOF, err)
+	}
+
+	// The players' streams outlive it
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: "lifetime-x", Row: 0, Col: 0})
+	require.NoError(t, err)
+	for _, userID := range []string{"lifetime-x", "lifetime-o"} {
+		update, err := streams[userID].Recv()
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), update.Game.MoveCount)
+	}
+}
+
+func TestAcceptance_CreateGame_IdempotencyKey(t *testing.T) {
+	gameStore := store.NewGameStore(4)
+	ts := setupTestServerWithStores(t, gameStore, store.NewStatsStore(4))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.CreateGameRequest{UserId: "retry-alice", IsPrivate: true, IdempotencyKey: "create-1"}
+	first, err := ts.client.CreateGame(ctx, req)
+	require.NoError(t, err)
+	retry, err := ts.client.CreateGame(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.Game.GameId, retry.Game.GameId)
+	assert.Equal(t, first.JoinCode, retry.JoinCode)
+
+	assert.Equal(t, 1, gameStore.Count(), "the retry created no second game")
+
+	// Concurrent retries all wait for the one game
+	ids := make(chan string, 10)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "retry-carol", IdempotencyKey: "burst"})
+			if assert.NoError(t, err) {
+				ids <- resp.Game.GameId
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := make(map[string]bool)
+	for id := range ids {
+		seen[id] = true
+	}
+	assert.Len(t, seen, 1)
+
+	// A new key, or the same key from another user, is a new game
+	other, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "retry-alice", IdempotencyKey: "create-2"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Game.GameId, other.Game.GameId)
+	theirs, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "retry-bob", IdempotencyKey: "create-1"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Game.GameId, theirs.Game.GameId)
+
+	// A retry returns the game as it stands, without changing it
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "retry-bob", GameId: other.Game.GameId})
+	require.NoError(t, err)
+	again, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "retry-alice", IdempotencyKey: "create-2"})
+	require.NoError(t, err)
+	assert.Equal(t, other.Game.GameId, again.Game.GameId)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, again.Game.Status)
+}
+
+func TestAcceptance_CreateGame_IdempotencyKeyExpires(t *testing.T) {
+	ts := setupTestServer(t, server.WithIdempotencyTTL(20*time.Millisecond))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := &pb.CreateGameRequest{UserId: "expiry-alice", IdempotencyKey: "create"}
+	first, err := ts.client.CreateGame(ctx, req)
+	require.NoError(t, err)
+	time.Sleep(40 * time.Millisecond)
+	later, err := ts.client.CreateGame(ctx, req)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Game.GameId, later.Game.GameId)
+
+	_, err = ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "expiry-alice", IdempotencyKey: strings.Repeat("k", server.MaxIdempotencyKeyLength+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAcceptance_PreviewMove(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "preview-x"})
+	require.NoError(t, err)
+	gameID := created.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "preview-o", GameId: gameID})
+	require.NoError(t, err)
+	for _, m := range []struct {
+		user     string
+		row, col int32
+	}{{"preview-x", 0, 0}, {"preview-o", 1, 0}, {"preview-x", 0, 1}, {"preview-o", 1, 1}} {
+		_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: m.user, Row: m.row, Col: m.col})
+		require.NoError(t, err)
+	}
+
+	preview, err := ts.client.PreviewMove(ctx, &pb.PreviewMoveRequest{GameId: gameID, UserId: "preview-x", Row: 0, Col: 2})
+	require.NoError(t, err)
+	assert.True(t, preview.Wins)
+	assert.False(t, preview.Draws)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, preview.Game.Status)
+
+	preview, err = ts.client.PreviewMove(ctx, &pb.PreviewMoveRequest{GameId: gameID, UserId: "preview-x", Cell: "c1"})
+	require.NoError(t, err)
+	assert.False(t, preview.Wins)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, preview.Game.Status)
+
+	// Invalid moves fail with MakeMove's codes
+	_, err = ts.client.PreviewMove(ctx, &pb.PreviewMoveRequest{GameId: gameID, UserId: "preview-o", Row: 2, Col: 2})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = ts.client.PreviewMove(ctx, &pb.PreviewMoveRequest{GameId: gameID, UserId: "preview-x", Row: 0, Col: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.PreviewMove(ctx, &pb.PreviewMoveRequest{GameId: gameID, UserId: "preview-x", Row: 5, Col: 0})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The stored game is untouched
+	got, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, got.Game.Status)
+	assert.Equal(t, int32(4), got.Game.MoveCount)
+}
+
+func TestAcceptance_GetUserStats_Streaks(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	playXWins(t, ts, "streak-a", "streak-b", 3, 3)
+	playXWins(t, ts, "streak-a", "streak-b", 3, 3)
+	playXWins(t, ts, "streak-b", "streak-a", 3, 3)
+
+	a, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "streak-a"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(-1), a.CurrentStreak)
+	assert.Equal(t, int32(2), a.LongestStreak)
+
+	b, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "streak-b"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), b.CurrentStreak)
+	assert.Equal(t, int32(1), b.LongestStreak)
+}
+
+func TestAcceptance_DisplayNames(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Users without a display name are shown by ID
+	got, err := ts.client.GetDisplayName(ctx, &pb.GetDisplayNameRequest{UserId: "name-a"})
+	require.NoError(t, err)
+	assert.Equal(t, "name-a", got.DisplayName)
+	assert.False(t, got.IsSet)
+
+	// Names are trimmed and their inner whitespace collapsed
+	set, err := ts.client.SetDisplayName(ctx, &pb.SetDisplayNameRequest{UserId: "name-a", DisplayName: "  Ada \t Lovelace "})
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", set.DisplayName)
+
+	got, err = ts.client.GetDisplayName(ctx, &pb.GetDisplayNameRequest{UserId: "name-a"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", got.DisplayName)
+	assert.True(t, got.IsSet)
+
+	for _, name := range []string{
+		strings.Repeat("x", server.MaxDisplayNameLength+1),
+		"bell\a",
+		"right\u202eto left",
+	} {
+		_, err = ts.client.SetDisplayName(ctx, &pb.SetDisplayNameRequest{UserId: "name-a", DisplayName: name})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "name %q", name)
+	}
+
+	// Games and the leaderboard show names, falling back to IDs
+	gameID := playXWins(t, ts, "name-a", "name-b", 3, 3)
+	fetched, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	assert.Equal(t, "Ada Lovelace", fetched.Game.PlayerXName)
+	assert.Equal(t, "name-b", fetched.Game.PlayerOName)
+	assert.Empty(t, fetched.Game.PlayerTriangleName)
+
+	board, err := ts.client.GetLeaderboard(ctx, &pb.GetLeaderboardRequest{})
+	require.NoError(t, err)
+	names := make(map[string]string)
+	for _, e := range board.Entries {
+		names[e.UserId] = e.DisplayName
+	}
+	assert.Equal(t, map[string]string{"name-a": "Ada Lovelace", "name-b": "name-b"}, names)
+
+	// An empty name clears it
+	set, err = ts.client.SetDisplayName(ctx, &pb.SetDisplayNameRequest{UserId: "name-a"})
+	require.NoError(t, err)
+	assert.Equal(t, "name-a", set.DisplayName)
+	got, err = ts.client.GetDisplayName(ctx, &pb.GetDisplayNameRequest{UserId: "name-a"})
+	require.NoError(t, err)
+	assert.False(t, got.IsSet)
+}
+
+func TestAcceptance_IsLegalMove(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "legal-x"})
+	require.NoError(t, err)
+	gameID := created.Game.GameId
+
+	check := func(user string, row, col int32) *pb.IsLegalMoveResponse {
+		t.Helper()
+		resp, err := ts.client.IsLegalMove(ctx, &pb.IsLegalMoveRequest{GameId: gameID, UserId: user, Row: row, Col: col})
+		require.NoError(t, err)
+		return resp
+	}
+
+	assert.Equal(t, server.ReasonGameNotInProgress, check("legal-x", 0, 0).Reason)
+
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "legal-o", GameId: gameID})
+	require.NoError(t, err)
+	assert.True(t, check("legal-x", 0, 0).Legal)
+	_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: "legal-x", Row: 0, Col: 0})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		user     string
+		row, col int32
+		reason   string
+	}{
+		{"legal-x", 1, 1, server.ReasonNotYourTurn},
+		{"legal-o", 0, 0, server.ReasonCellOccupied},
+		{"legal-o", 3, 0, server.ReasonInvalidPosition},
+		{"legal-z", 1, 1, server.ReasonNotAPlayer},
+	} {
+		resp := check(tc.user, tc.row, tc.col)
+		assert.False(t, resp.Legal)
+		assert.Equal(t, tc.reason, resp.Reason, "%s at (%d, %d)", tc.user, tc.row, tc.col)
+		assert.NotEmpty(t, resp.Message)
+	}
+
+	// A legal answer is one MakeMove agrees with, and checking changed nothing
+	require.True(t, check("legal-o", 1, 1).Legal)
+	moved, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: "legal-o", Row: 1, Col: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), moved.Game.MoveCount)
+
+	_, err = ts.client.IsLegalMove(ctx, &pb.IsLegalMoveRequest{GameId: "missing", UserId: "legal-x"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// encodingRecorder records the compression of each response header a client receives
+type encodingRecorder struct {
+	mu        sync.Mutex
+	encodings []string
+}
+
+func (r *encodingRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *encodingRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		r.mu.Lock()
+		r.encodings = append(r.encodings, header.Compression)
+		r.mu.Unlock()
+	}
+}
+
+func (r *encodingRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *encodingRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+// streamEncoding streams a game on a large board through a new connection
+// while a move is played, checks the updates decode, and returns the
+// compression the server chose for the stream
+func streamEncoding(t *testing.T, ts *testServer, opts ...grpc.CallOption) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recorder := &encodingRecorder{}
+	conn, err := grpc.NewClient(ts.addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithStatsHandler(recorder))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := pb.NewTicTacToeServiceClient(conn)
+
+	created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "gzip-x", BoardSize: 15, WinLength: 5})
+	require.NoError(t, err)
+	gameID := created.Game.GameId
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "gzip-o", GameId: gameID})
+	require.NoError(t, err)
+
+	stream, err := client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID}, opts...)
+	require.NoError(t, err)
+	initial, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Len(t, initial.Game.Board, 15*15)
+
+	moved, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "gzip-x", GameId: gameID, Row: 7, Col: 7})
+	require.NoError(t, err)
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, moved.Game.Board, update.Game.Board)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Len(t, recorder.encodings, 1)
+	return recorder.encodings[0]
+}
+
+func TestAcceptance_StreamCompression(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	// Compression is opt-in per call
+	assert.Empty(t, streamEncoding(t, ts))
+	assert.Equal(t, gzip.Name, streamEncoding(t, ts, grpc.UseCompressor(gzip.Name)))
+
+	// Unless the server compresses streams for every client that accepts gzip
+	compressing := setupTestServer(t, server.WithStreamCompression(true))
+	defer compressing.cleanup()
+	assert.Equal(t, gzip.Name, streamEncoding(t, compressing))
+}
+
+func TestAcceptance_StreamGameUpdates_ConcurrentFinish(t *testing.T) {
+	ts := setupTestServer(t, server.WithStreamBuffer(1))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Streams join, watch and leave games while they are joined and won, so
+	// finishing broadcasts race the streams' ends
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			x, o := fmt.Sprintf("race-x-%d", i), fmt.Sprintf("race-o-%d", i)
+			created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: x})
+			if !assert.NoError(t, err) {
+				return
+			}
+			gameID := created.Game.GameId
+
+			var watchers sync.WaitGroup
+			for j := 0; j < 6; j++ {
+				watchers.Add(1)
+				go func(j int) {
+					defer watchers.Done()
+					streamCtx, stopStream := context.WithCancel(ctx)
+					defer stopStream()
+					stream, err := ts.client.StreamGameUpdates(streamCtx, &pb.StreamGameUpdatesRequest{GameId: gameID})
+					if !assert.NoError(t, err) {
+						return
+					}
+					// Half the watchers leave after a few updates
+					for n := 0; j%2 == 0 || n < j; n++ {
+						if _, err := stream.Recv(); err != nil {
+							return
+						}
+					}
+				}(j)
+			}
+
+			_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: o, GameId: gameID})
+			assert.NoError(t, err)
+			for _, m := range []struct {
+				user     string
+				row, col int32
+			}{{x, 0, 0}, {o, 1, 0}, {x, 0, 1}, {o, 1, 1}, {x, 0, 2}} {
+				_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{GameId: gameID, UserId: m.user, Row: m.row, Col: m.col})
+				assert.NoError(t, err)
+			}
+			watchers.Wait()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Eventually(t, func() bool { return ts.ticTacToe.SubscriberCount() == 0 },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestAcceptance_CreateGame_Seed(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// X follows every hint it is sent while O plays the same cells in each
+	// game, and the hints are collected
+	play := func(x, o string, seed uint64) []*pb.Position {
+		created, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: x, Hints: true, Seed: &seed})
+		require.NoError(t, err)
+		gameID := created.Game.GameId
+		stream, err := ts.client.StreamGameUpdates(ctx, &pb.StreamGameUpdatesRequest{GameId: gameID, UserId: x})
+		require.NoError(t, err)
+		_, err = stream.Recv() // Subscribed before the game starts
+		require.NoError(t, err)
+		nextHint := func() *pb.Position {
+			for {
+				update, err := stream.Recv()
+				require.NoError(t, err)
+				if update.Type == pb.UpdateType_UPDATE_TYPE_HINT {
+					return update.Hint
+				}
+			}
+		}
+		_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: o, GameId: gameID})
+		require.NoError(t, err)
+
+		var hints []*pb.Position
+		for turn := 0; turn < 3; turn++ {
+			hint := nextHint()
+			hints = append(hints, hint)
+			_, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: x, GameId: gameID, Row: hint.Row, Col: hint.Col})
+			require.NoError(t, err)
+
+			// O takes the first empty cell
+			got, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+			require.NoError(t, err)
+			if got.Game.Status != pb.GameStatus_GAME_STATUS_IN_PROGRESS {
+				break
+			}
+			cell := slices.Index(got.Game.Board, pb.Mark_MARK_EMPTY)
+			_, err = ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: o, GameId: gameID, Row: int32(cell / 3), Col: int32(cell % 3)})
+			require.NoError(t, err)
+		}
+		return hints
+	}
+
+	first := play("seed-x-1", "seed-o-1", 7)
+	second := play("seed-x-2", "seed-o-2", 7)
+	require.Len(t, second, len(first))
+	for i := range first {
+		assert.True(t, proto.Equal(first[i], second[i]), "hint %d: %v and %v", i, first[i], second[i])
+	}
+}
+
+// winTournamentGame plays a 3x3 tournament game out so that winner completes
+// the top row
+func winTournamentGame(t *testing.T, ts *testServer, gameID, winner string) {
+	t.Helper()
+	ctx := context.Background()
+
+	getResp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: gameID})
+	require.NoError(t, err)
+	g := getResp.Game
+	require.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, g.Status)
+	require.Contains(t, []string{g.PlayerXId, g.PlayerOId}, winner)
+
+	winnerCells := [][2]int32{{0, 0}, {0, 1}, {0, 2}}
+	loserCells := [][2]int32{{2, 0}, {2, 1}, {1, 2}}
+	for g.Status == pb.GameStatus_GAME_STATUS_IN_PROGRESS {
+		player := g.PlayerXId
+		if g.CurrentTurn == pb.Mark_MARK_O {
+			player = g.PlayerOId
+		}
+		cells := &loserCells
+		if player == winner {
+			cells = &winnerCells
+		}
+		cell := (*cells)[0]
+		*cells = (*cells)[1:]
+		resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: player, GameId: gameID, Row: cell[0], Col: cell[1]})
+		require.NoError(t, err)
+		g = resp.Game
+	}
+}
+
+func TestAcceptance_Tournament(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	_, err := ts.client.CreateTournament(ctx, &pb.CreateTournamentRequest{UserId: "cup-1", ParticipantIds: []string{"cup-1"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.CreateTournament(ctx, &pb.CreateTournamentRequest{UserId: "cup-1", ParticipantIds: []string{"cup-1", "cup-1"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = ts.client.GetTournament(ctx, &pb.GetTournamentRequest{TournamentId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Three players: the top seed gets a bye into the final
+	createResp, err := ts.client.CreateTournament(ctx, &pb.CreateTournamentRequest{
+		UserId:         "cup-2",
+		Name:           " Spring Cup ",
+		ParticipantIds: []string{"cup-1", "cup-2", "cup-3"},
+	})
+	require.NoError(t, err)
+	tournament := createResp.Tournament
+	assert.Equal(t, "Spring Cup", tournament.Name)
+	assert.Equal(t, "cup-2", tournament.CreatedBy)
+	assert.Equal(t, int32(3), tournament.BoardSize)
+	assert.Equal(t, int32(2), tournament.NumRounds)
+	assert.False(t, tournament.Finished)
+
+	bracket := func() []*pb.TournamentRound {
+		resp, err := ts.client.GetTournamentBracket(ctx, &pb.GetTournamentBracketRequest{TournamentId: tournament.TournamentId})
+		require.NoError(t, err)
+		require.Len(t, resp.Rounds, 2)
+		return resp.Rounds
+	}
+
+	rounds := bracket()
+	require.Len(t, rounds[0].Matches, 2)
+	bye, semi := rounds[0].Matches[0], rounds[0].Matches[1]
+	assert.True(t, bye.Bye)
+	assert.Equal(t, "cup-1", bye.WinnerId)
+	assert.Empty(t, bye.GameIds)
+	assert.Equal(t, "cup-2", semi.PlayerAId)
+	assert.Equal(t, "cup-3", semi.PlayerBId)
+	require.Len(t, semi.GameIds, 1)
+	final := rounds[1].Matches[0]
+	assert.Equal(t, "cup-1", final.PlayerAId)
+	assert.Empty(t, final.PlayerBId)
+
+	// The semi-final game is private and already under way
+	getResp, err := ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: semi.GameIds[0]})
+	require.NoError(t, err)
+	assert.True(t, getResp.Game.IsPrivate)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, getResp.Game.Status)
+	assert.Equal(t, "cup-2", getResp.Game.PlayerXId)
+	assert.Equal(t, "cup-3", getResp.Game.PlayerOId)
+
+	// A draw is replayed with the marks swapped
+	_, err = ts.client.OfferDraw(ctx, &pb.OfferDrawRequest{UserId: "cup-2", GameId: semi.GameIds[0]})
+	require.NoError(t, err)
+	_, err = ts.client.RespondDraw(ctx, &pb.RespondDrawRequest{UserId: "cup-3", GameId: semi.GameIds[0], Accept: true})
+	require.NoError(t, err)
+
+	semi = bracket()[0].Matches[1]
+	require.Len(t, semi.GameIds, 2)
+	assert.Empty(t, semi.WinnerId)
+	getResp, err = ts.client.GetGame(ctx, &pb.GetGameRequest{GameId: semi.GameIds[1]})
+	require.NoError(t, err)
+	assert.Equal(t, "cup-3", getResp.Game.PlayerXId)
+	assert.Equal(t, "cup-2", getResp.Game.PlayerOId)
+
+	// The winner advances to meet the top seed
+	winTournamentGame(t, ts, semi.GameIds[1], "cup-3")
+	rounds = bracket()
+	assert.Equal(t, "cup-3", rounds[0].Matches[1].WinnerId)
+	final = rounds[1].Matches[0]
+	assert.Equal(t, "cup-3", final.PlayerBId)
+	require.Len(t, final.GameIds, 1)
+
+	// Abandoning the final hands the tournament to the opponent
+	_, err = ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "cup-3", GameId: final.GameIds[0]})
+	require.NoError(t, err)
+
+	getTournamentResp, err := ts.client.GetTournament(ctx, &pb.GetTournamentRequest{TournamentId: tournament.TournamentId})
+	require.NoError(t, err)
+	assert.True(t, getTournamentResp.Tournament.Finished)
+	assert.Equal(t, "cup-1", getTournamentResp.Tournament.WinnerId)
+	assert.Equal(t, "cup-1", bracket()[1].Matches[0].WinnerId)
+}
+
+func TestAcceptance_Tournament_FourPlayers(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	createResp, err := ts.client.CreateTournament(ctx, &pb.CreateTournamentRequest{
+		UserId:         "four-1",
+		ParticipantIds: []string{"four-1", "four-2", "four-3", "four-4"},
+	})
+	require.NoError(t, err)
+	id := createResp.Tournament.TournamentId
+
+	bracketResp, err := ts.client.GetTournamentBracket(ctx, &pb.GetTournamentBracketRequest{TournamentId: id})
+	require.NoError(t, err)
+	semis := bracketResp.Rounds[0].Matches
+	require.Len(t, semis, 2)
+	for _, m := range semis {
+		assert.False(t, m.Bye)
+		require.Len(t, m.GameIds, 1)
+	}
+	winTournamentGame(t, ts, semis[0].GameIds[0], "four-4")
+	winTournamentGame(t, ts, semis[1].GameIds[0], "four-2")
+
+	bracketResp, err = ts.client.GetTournamentBracket(ctx, &pb.GetTournamentBracketRequest{TournamentId: id})
+	require.NoError(t, err)
+	final := bracketResp.Rounds[1].Matches[0]
+	assert.Equal(t, "four-4", final.PlayerAId)
+	assert.Equal(t, "four-2", final.PlayerBId)
+	require.Len(t, final.GameIds, 1)
+	winTournamentGame(t, ts, final.GameIds[0], "four-2")
+
+	getResp, err := ts.client.GetTournament(ctx, &pb.GetTournamentRequest{TournamentId: id})
+	require.NoError(t, err)
+	assert.Equal(t, "four-2", getResp.Tournament.WinnerId)
+
+	// Tournament games count in the players' stats like any other
+	statsResp, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: "four-2"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), statsResp.Wins)
+}
+
+func TestAcceptance_Tournament_Creators(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-admin", "admin")
+	tokens.Add("token-alice", "alice")
+	ts := setupTestServer(t,
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+		server.WithAdmins([]string{"admin"}),
+		server.WithMaxTournamentsPerUser(1),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-admin")
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+
+	// Only participants and admins may create a tournament
+	_, err := ts.client.CreateTournament(asAlice, &pb.CreateTournamentRequest{ParticipantIds: []string{"bob", "carol"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = ts.client.CreateTournament(asAdmin, &pb.CreateTournamentRequest{ParticipantIds: []string{"bob", "carol"}})
+	require.NoError(t, err)
+
+	// Each creator may only have so many under way
+	_, err = ts.client.CreateTournament(asAlice, &pb.CreateTournamentRequest{ParticipantIds: []string{"alice", "bob"}})
+	require.NoError(t, err)
+	_, err = ts.client.CreateTournament(asAlice, &pb.CreateTournamentRequest{ParticipantIds: []string{"alice", "carol"}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestAcceptance_Tournament_StalledMatch(t *testing.T) {
+	ts := setupTestServerWithStores(t, store.NewGameStore(4, store.WithMaxActiveGames(1)), store.NewStatsStore(4))
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	busy, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{UserId: "stall-1"})
+	require.NoError(t, err)
+	createResp, err := ts.client.CreateTournament(ctx, &pb.CreateTournamentRequest{
+		UserId:         "stall-1",
+		ParticipantIds: []string{"stall-1", "stall-2"},
+	})
+	require.NoError(t, err)
+	id := createResp.Tournament.TournamentId
+
+	// The first player is at their game limit, and reading the bracket does not retry
+	matchGames := func() []string {
+		resp, err := ts.client.GetTournamentBracket(ctx, &pb.GetTournamentBracketRequest{TournamentId: id})
+		require.NoError(t, err)
+		return resp.Rounds[0].Matches[0].GameIds
+	}
+	assert.Empty(t, matchGames())
+	assert.Empty(t, matchGames())
+
+	// Their other game finishing frees the seat, and the match starts
+	_, err = ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "stall-3", GameId: busy.Game.GameId})
+	require.NoError(t, err)
+	_, err = ts.client.AbandonGame(ctx, &pb.AbandonGameRequest{UserId: "stall-3", GameId: busy.Game.GameId})
+	require.NoError(t, err)
+	assert.Len(t, matchGames(), 1)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/store"
 )
 
 // LoadTestResult holds the results of the load test
@@ -342,48 +343,15 @@ func TestLoadTest_MixedBoardSizes(t *testing.T) {
 
 // playFullGame plays a complete game and returns the number of moves, outcome, and any error
 func playFullGame(ctx context.Context, client pb.TicTacToeServiceClient, playerX, playerO string, boardSize, winLength int32) (int, pb.GameStatus, error) {
-	// Create game
-	createResp, err := client.CreateGame(ctx, &pb.CreateGameRequest{
-		UserId:    playerX,
-		BoardSize: boardSize,
-		WinLength: winLength,
-	})
+	gameID, err := startGame(ctx, client, playerX, playerO, boardSize, winLength)
 	if err != nil {
-		return 0, pb.GameStatus_GAME_STATUS_UNSPECIFIED, fmt.Errorf("create game: %w", err)
-	}
-
-	gameID := createResp.Game.GameId
-
-	// Join game
-	_, err = client.JoinGame(ctx, &pb.JoinGameRequest{
-		UserId: playerO,
-		GameId: gameID,
-	})
-	if err != nil {
-		return 0, pb.GameStatus_GAME_STATUS_UNSPECIFIED, fmt.Errorf("join game: %w", err)
+		return 0, pb.GameStatus_GAME_STATUS_UNSPECIFIED, err
 	}
 
 	// Play the game with random moves
 	moves := 0
 	currentPlayer := playerX
-	size := int(boardSize)
-
-	// Track available cells
-	available := make([]struct{ row, col int }, 0, size*size)
-	for r := 0; r < size; r++ {
-		for c := 0; c < size; c++ {
-			available = append(available, struct{ row, col int }{r, c})
-		}
-	}
-
-	// Shuffle for randomness
-	rand.Shuffle(len(available), func(i, j int) {
-		available[i], available[j] = available[j], available[i]
-	})
-
-	for i := 0; i < len(available); i++ {
-		cell := available[i]
-
+	for _, cell := range shuffledCells(int(boardSize)) {
 		resp, err := client.MakeMove(ctx, &pb.MakeMoveRequest{
 			UserId: currentPlayer,
 			GameId: gameID,
@@ -412,3 +380,147 @@ func playFullGame(ctx context.Context, client pb.TicTacToeServiceClient, playerX
 	// Should not reach here for a valid game
 	return moves, pb.GameStatus_GAME_STATUS_DRAW, nil
 }
+
+// startGame creates a game for playerX, has playerO join it and returns its ID
+func startGame(ctx context.Context, client pb.TicTacToeServiceClient, playerX, playerO string, boardSize, winLength int32) (string, error) {
+	createResp, err := client.CreateGame(ctx, &pb.CreateGameRequest{
+		UserId:    playerX,
+		BoardSize: boardSize,
+		WinLength: winLength,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create game: %w", err)
+	}
+
+	gameID := createResp.Game.GameId
+	_, err = client.JoinGame(ctx, &pb.JoinGameRequest{
+		UserId: playerO,
+		GameId: gameID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("join game: %w", err)
+	}
+	return gameID, nil
+}
+
+// shuffledCells returns every cell of a size x size board in random order
+func shuffledCells(size int) []struct{ row, col int } {
+	cells := make([]struct{ row, col int }, 0, size*size)
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			cells = append(cells, struct{ row, col int }{r, c})
+		}
+	}
+	rand.Shuffle(len(cells), func(i, j int) {
+		cells[i], cells[j] = cells[j], cells[i]
+	})
+	return cells
+}
+
+// benchBoards are the board sizes, with their win lengths, and benchShards the
+// store shard counts that the benchmarks run against
+var (
+	benchBoards = []struct{ size, winLength int32 }{{3, 3}, {5, 4}, {10, 5}}
+	benchShards = []int{1, 4, 64}
+)
+
+// benchmarkBoards runs bench as a sub-benchmark for each board size and shard
+// count, each against a fresh in-process server, reporting allocations
+func benchmarkBoards(b *testing.B, bench func(b *testing.B, ts *testServer, size, winLength int32)) {
+	for _, board := range benchBoards {
+		for _, shards := range benchShards {
+			b.Run(fmt.Sprintf("size=%d/shards=%d", board.size, shards), func(b *testing.B) {
+				ts := setupTestServerWithStores(b, store.NewGameStore(shards), store.NewStatsStore(shards))
+				defer ts.cleanup()
+				b.ReportAllocs()
+				bench(b, ts, board.size, board.winLength)
+			})
+		}
+	}
+}
+
+// BenchmarkMakeMove times single moves; starting each new game is not timed
+func BenchmarkMakeMove(b *testing.B) {
+	benchmarkBoards(b, func(b *testing.B, ts *testServer, size, winLength int32) {
+		ctx := context.Background()
+		players := []string{"bench-x", "bench-o"}
+		var gameID string
+		var cells []struct{ row, col int }
+		var turn int
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if len(cells) == 0 {
+				b.StopTimer()
+				id, err := startGame(ctx, ts.client, players[0], players[1], size, winLength)
+				if err != nil {
+					b.Fatal(err)
+				}
+				gameID, cells, turn = id, shuffledCells(int(size)), 0
+				b.StartTimer()
+			}
+
+			resp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{
+				UserId: players[turn%2],
+				GameId: gameID,
+				Row:    int32(cells[0].row),
+				Col:    int32(cells[0].col),
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+			cells = cells[1:]
+			turn++
+			if resp.Game.Status != pb.GameStatus_GAME_STATUS_IN_PROGRESS {
+				cells = nil
+			}
+		}
+	})
+}
+
+// BenchmarkFullGame times creating, joining and playing out a game with random moves
+func BenchmarkFullGame(b *testing.B) {
+	benchmarkBoards(b, func(b *testing.B, ts *testServer, size, winLength int32) {
+		ctx := context.Background()
+		totalMoves := 0
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			moves, _, err := playFullGame(ctx, ts.client, "bench-x", "bench-o", size, winLength)
+			if err != nil {
+				b.Fatal(err)
+			}
+			totalMoves += moves
+		}
+		b.ReportMetric(float64(totalMoves)/float64(b.N), "moves/op")
+	})
+}
+
+// BenchmarkListPending times listing a page of pending games out of a
+// thousand waiting for an opponent
+func BenchmarkListPending(b *testing.B) {
+	benchmarkBoards(b, func(b *testing.B, ts *testServer, size, winLength int32) {
+		ctx := context.Background()
+		for i := 0; i < 1000; i++ {
+			_, err := ts.client.CreateGame(ctx, &pb.CreateGameRequest{
+				UserId:    fmt.Sprintf("bench-creator-%d", i),
+				BoardSize: size,
+				WinLength: winLength,
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			resp, err := ts.client.ListPendingGames(ctx, &pb.ListPendingGamesRequest{Limit: 50})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(resp.Games) != 50 {
+				b.Fatalf("listed %d games, want 50", len(resp.Games))
+			}
+		}
+	})
+}
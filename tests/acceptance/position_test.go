@@ -0,0 +1,151 @@
+package acceptance
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "tictactoe/api/gen/tictactoe"
+	"tictactoe/internal/server"
+	"tictactoe/internal/store"
+)
+
+func TestAcceptance_CreateGameFromPosition(t *testing.T) {
+	ts := setupTestServer(t)
+	defer ts.cleanup()
+
+	ctx := context.Background()
+
+	const (
+		E = pb.Mark_MARK_EMPTY
+		X = pb.Mark_MARK_X
+		O = pb.Mark_MARK_O
+	)
+	// X to move and win in one, at the bottom right
+	position := []pb.Mark{
+		X, O, E,
+		E, X, O,
+		E, E, E,
+	}
+	createResp, err := ts.client.CreateGameFromPosition(ctx, &pb.CreateGameFromPositionRequest{
+		Board:     position,
+		Turn:      X,
+		PlayerXId: "puzzler",
+		PlayerOId: "sparring",
+	})
+	require.NoError(t, err)
+	g := createResp.Game
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, g.Status)
+	assert.Equal(t, position, g.Board)
+	assert.Equal(t, X, g.CurrentTurn)
+	assert.Equal(t, "puzzler", g.PlayerXId)
+	assert.Equal(t, "sparring", g.PlayerOId)
+
+	moveResp, err := ts.client.MakeMove(ctx, &pb.MakeMoveRequest{UserId: "puzzler", GameId: g.GameId, Row: 2, Col: 2})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, moveResp.Game.Status)
+
+	// A replay starts from the position, not an empty board
+	stream, err := ts.client.ReplayGame(ctx, &pb.ReplayGameRequest{GameId: g.GameId, Speed: server.MaxReplaySpeed})
+	require.NoError(t, err)
+	var updates []*pb.GameUpdate
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		updates = append(updates, update)
+	}
+	require.Len(t, updates, 2)
+	assert.Equal(t, position, updates[0].Game.Board)
+	assert.Equal(t, X, updates[0].Game.CurrentTurn)
+	assert.Equal(t, X, updates[1].Game.Board[8])
+
+	// With one player given, the opponent joins like in any other game, and
+	// a decided position ends as it starts without a result in anyone's stats
+	won := []pb.Mark{X, X, X, O, O, E, E, E, E}
+	createResp, err = ts.client.CreateGameFromPosition(ctx, &pb.CreateGameFromPositionRequest{
+		UserId:       "poser",
+		Board:        won,
+		Turn:         O,
+		PlayerOId:    "poser",
+		AllowDecided: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_PENDING, createResp.Game.Status)
+	joinResp, err := ts.client.JoinGame(ctx, &pb.JoinGameRequest{UserId: "winner", GameId: createResp.Game.GameId})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_X_WON, joinResp.Game.Status)
+	for _, userID := range []string{"winner", "poser", "puzzler", "sparring"} {
+		stats, err := ts.client.GetUserStats(ctx, &pb.GetUserStatsRequest{UserId: userID})
+		require.NoError(t, err)
+		assert.Zero(t, stats.TotalGames, userID)
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.CreateGameFromPositionRequest
+		code codes.Code
+	}{
+		{"no players", &pb.CreateGameFromPositionRequest{UserId: "a", Board: make([]pb.Mark, 9), Turn: X}, codes.InvalidArgument},
+		{"caller not a player", &pb.CreateGameFromPositionRequest{UserId: "a", Board: make([]pb.Mark, 9), Turn: X, PlayerXId: "b"}, codes.InvalidArgument},
+		{"same player twice", &pb.CreateGameFromPositionRequest{Board: make([]pb.Mark, 9), Turn: X, PlayerXId: "a", PlayerOId: "a"}, codes.InvalidArgument},
+		{"wrong cell count", &pb.CreateGameFromPositionRequest{UserId: "a", Board: make([]pb.Mark, 8), Turn: X, PlayerXId: "a"}, codes.InvalidArgument},
+		{"no turn", &pb.CreateGameFromPositionRequest{UserId: "a", Board: make([]pb.Mark, 9), PlayerXId: "a"}, codes.InvalidArgument},
+		{"counts off", &pb.CreateGameFromPositionRequest{UserId: "a", Board: []pb.Mark{X, X, E, E, O, E, E, E, E}, Turn: X, PlayerXId: "a"}, codes.InvalidArgument},
+		{"already won", &pb.CreateGameFromPositionRequest{UserId: "a", Board: won, Turn: O, PlayerXId: "a"}, codes.InvalidArgument},
+		{"already drawn", &pb.CreateGameFromPositionRequest{UserId: "a", Board: []pb.Mark{X, O, X, X, O, O, O, X, X}, Turn: O, PlayerXId: "a"}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ts.client.CreateGameFromPosition(ctx, tt.req)
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestAcceptance_CreateGameFromPosition_SeatingNeedsAdmin(t *testing.T) {
+	tokens := store.NewTokenStore()
+	tokens.Add("token-admin", "admin")
+	tokens.Add("token-alice", "alice")
+	ts := setupTestServer(t,
+		server.WithAuth(tokens, server.DefaultPublicMethods),
+		server.WithAdmins([]string{"admin"}),
+	)
+	defer ts.cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	asAdmin := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-admin")
+	asAlice := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-alice")
+
+	// Seating both players puts the opponent in a game they never agreed to
+	req := &pb.CreateGameFromPositionRequest{
+		Board:     make([]pb.Mark, 9),
+		Turn:      pb.Mark_MARK_X,
+		PlayerXId: "alice",
+		PlayerOId: "bob",
+	}
+	_, err := ts.client.CreateGameFromPosition(asAlice, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	resp, err := ts.client.CreateGameFromPosition(asAdmin, req)
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_IN_PROGRESS, resp.Game.Status)
+
+	// A player may still set up a position for anyone to join
+	resp, err = ts.client.CreateGameFromPosition(asAlice, &pb.CreateGameFromPositionRequest{
+		Board:     make([]pb.Mark, 9),
+		Turn:      pb.Mark_MARK_X,
+		PlayerXId: "alice",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, pb.GameStatus_GAME_STATUS_PENDING, resp.Game.Status)
+}